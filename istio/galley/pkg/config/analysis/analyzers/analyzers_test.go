@@ -27,11 +27,13 @@ import (
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/annotations"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/authz"
+	"istio.io/istio/galley/pkg/config/analysis/analyzers/ca"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/deployment"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/deprecation"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/destinationrule"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/gateway"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/injection"
+	"istio.io/istio/galley/pkg/config/analysis/analyzers/localratelimit"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/multicluster"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/service"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/serviceentry"
@@ -83,6 +85,14 @@ var testGrid = []testCase{
 			{msg.DeprecatedAnnotation, "Deployment fortio-deploy"},
 		},
 	},
+	{
+		name:       "caKeyPolicy",
+		inputFiles: []string{"testdata/ca-keypolicy.yaml"},
+		analyzer:   &ca.KeyPolicyAnalyzer{},
+		expected: []message{
+			{msg.CACertificateKeyPolicyViolation, "Secret cacerts.istio-system"},
+		},
+	},
 	{
 		name:       "deprecation",
 		inputFiles: []string{"testdata/deprecation.yaml"},
@@ -213,6 +223,15 @@ var testGrid = []testCase{
 			{msg.MultipleSidecarsWithoutWorkloadSelectors, "Sidecar has-conflict-1.ns2"},
 		},
 	},
+	{
+		name:       "localRateLimitOverlap",
+		inputFiles: []string{"testdata/localratelimit-overlap.yaml"},
+		analyzer:   &localratelimit.OverlapAnalyzer{},
+		expected: []message{
+			{msg.ConflictingLocalRateLimits, "LocalRateLimit namespace-wide.default"},
+			{msg.ConflictingLocalRateLimits, "LocalRateLimit productpage.default"},
+		},
+	},
 	{
 		name:       "sidecarSelector",
 		inputFiles: []string{"testdata/sidecar-selector.yaml"},
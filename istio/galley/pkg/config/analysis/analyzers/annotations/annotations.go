@@ -21,6 +21,7 @@ import (
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
+	"istio.io/istio/pilot/pkg/serviceregistry/kube"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
 	"istio.io/istio/pkg/config/schema/collections"
@@ -30,7 +31,23 @@ import (
 // K8sAnalyzer checks for misplaced and invalid Istio annotations in K8s resources
 type K8sAnalyzer struct{}
 
-var istioAnnotations = annotation.AllResourceAnnotations()
+// istioAnnotations are the annotations defined in istio.io/api, and the ones not yet defined there.
+var istioAnnotations = append(annotation.AllResourceAnnotations(),
+	&annotation.Instance{
+		Name: kube.ClusterLocalAnnotation,
+		Description: "If set to true on a Service, the endpoints of the service are only sent to the proxies of " +
+			"the same cluster, and the service is not exposed to other networks through the network gateways.",
+		FeatureStatus: annotation.Alpha,
+		Resources:     []annotation.ResourceTypes{annotation.Service},
+	},
+	&annotation.Instance{
+		Name: inject.SidecarTrafficIncludeOutboundUDPPorts,
+		Description: "A comma separated list of outbound UDP ports for which traffic is to be redirected to " +
+			"Envoy. Each port is forwarded to the service with a UDP port of the same value.",
+		FeatureStatus: annotation.Alpha,
+		Resources:     []annotation.ResourceTypes{annotation.Pod},
+	},
+)
 
 // Metadata implements analyzer.Analyzer
 func (*K8sAnalyzer) Metadata() analysis.Metadata {
@@ -0,0 +1,100 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	apps_v1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+
+	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/collection"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+const (
+	// caKeyPolicyEnv is the istiod setting restricting the keys of the CA certificate chain.
+	caKeyPolicyEnv = "CA_KEY_POLICY"
+	// pluggedCASecret holds the plugged-in CA certificates, used instead of selfSignedCASecret if present.
+	pluggedCASecret = "cacerts"
+	// selfSignedCASecret holds the certificate of the self-signed CA.
+	selfSignedCASecret = "istio-ca-secret"
+)
+
+// caCertFiles are the certificate files of the CA secrets.
+var caCertFiles = []string{"ca-cert.pem", "cert-chain.pem", "root-cert.pem"}
+
+// KeyPolicyAnalyzer checks that the CA certificates comply with the CA_KEY_POLICY of istiod.
+type KeyPolicyAnalyzer struct{}
+
+var _ analysis.Analyzer = &KeyPolicyAnalyzer{}
+
+// Metadata implements Analyzer
+func (a *KeyPolicyAnalyzer) Metadata() analysis.Metadata {
+	return analysis.Metadata{
+		Name:        "ca.KeyPolicyAnalyzer",
+		Description: "Checks that the CA certificates comply with the CA_KEY_POLICY of istiod",
+		Inputs: collection.Names{
+			collections.K8SAppsV1Deployments.Name(),
+			collections.K8SCoreV1Secrets.Name(),
+		},
+	}
+}
+
+// Analyze implements Analyzer
+func (a *KeyPolicyAnalyzer) Analyze(c analysis.Context) {
+	c.ForEach(collections.K8SAppsV1Deployments.Name(), func(r *resource.Instance) bool {
+		d := r.Message.(*apps_v1.Deployment)
+		policyString := caKeyPolicy(d)
+		if policyString == "" {
+			return true
+		}
+		policy, err := util.ParseKeyPolicy(policyString)
+		if err != nil {
+			// istiod ignores an invalid policy, and logs an error.
+			return true
+		}
+
+		secret := c.Find(collections.K8SCoreV1Secrets.Name(), resource.NewFullName(r.Metadata.FullName.Namespace, pluggedCASecret))
+		if secret == nil {
+			secret = c.Find(collections.K8SCoreV1Secrets.Name(), resource.NewFullName(r.Metadata.FullName.Namespace, selfSignedCASecret))
+		}
+		if secret == nil {
+			return true
+		}
+		s := secret.Message.(*v1.Secret)
+		for _, file := range caCertFiles {
+			if err := policy.CheckPemCertificates(s.Data[file]); err != nil {
+				c.Report(collections.K8SCoreV1Secrets.Name(),
+					msg.NewCACertificateKeyPolicyViolation(secret, file, policy.String(), r.Metadata.FullName.String(), err.Error()))
+			}
+		}
+		return true
+	})
+}
+
+// caKeyPolicy returns the CA_KEY_POLICY set on the containers of a deployment, empty if none.
+func caKeyPolicy(d *apps_v1.Deployment) string {
+	for _, container := range d.Spec.Template.Spec.Containers {
+		for _, e := range container.Env {
+			if e.Name == caKeyPolicyEnv {
+				return e.Value
+			}
+		}
+	}
+	return ""
+}
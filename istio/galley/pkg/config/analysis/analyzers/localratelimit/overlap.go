@@ -0,0 +1,157 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localratelimit
+
+import (
+	"sort"
+
+	"github.com/gogo/protobuf/types"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"istio.io/istio/galley/pkg/config/analysis"
+	"istio.io/istio/galley/pkg/config/analysis/analyzers/util"
+	"istio.io/istio/galley/pkg/config/analysis/msg"
+	"istio.io/istio/galley/pkg/config/util/pb"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema/collection"
+	"istio.io/istio/pkg/config/schema/collections"
+)
+
+// OverlapAnalyzer validates, per namespace, that there aren't multiple LocalRateLimit resources selecting the same
+// pods with rules matching the same routes, in which case only the oldest one applies.
+type OverlapAnalyzer struct{}
+
+var _ analysis.Analyzer = &OverlapAnalyzer{}
+
+type localRateLimit struct {
+	rs   *resource.Instance
+	spec *networkingv1beta1.LocalRateLimitSpec
+}
+
+// Metadata implements Analyzer
+func (a *OverlapAnalyzer) Metadata() analysis.Metadata {
+	return analysis.Metadata{
+		Name:        "localratelimit.OverlapAnalyzer",
+		Description: "Validates that there aren't multiple local rate limits limiting the same routes of a workload",
+		Inputs: collection.Names{
+			collections.IstioNetworkingV1Beta1Localratelimits.Name(),
+			collections.K8SCoreV1Pods.Name(),
+		},
+	}
+}
+
+// Analyze implements Analyzer
+func (a *OverlapAnalyzer) Analyze(c analysis.Context) {
+	byNamespace := make(map[resource.Namespace][]localRateLimit)
+	c.ForEach(collections.IstioNetworkingV1Beta1Localratelimits.Name(), func(rs *resource.Instance) bool {
+		s, ok := rs.Message.(*types.Struct)
+		if !ok {
+			return true
+		}
+		spec := &networkingv1beta1.LocalRateLimitSpec{}
+		if err := pb.UnmarshalStruct(s, spec); err != nil {
+			// Reported by the schema validation analyzer.
+			return true
+		}
+		ns := rs.Metadata.FullName.Namespace
+		byNamespace[ns] = append(byNamespace[ns], localRateLimit{rs: rs, spec: spec})
+		return true
+	})
+
+	c.ForEach(collections.K8SCoreV1Pods.Name(), func(rp *resource.Instance) bool {
+		pod := rp.Message.(*v1.Pod)
+		podLabels := labels.Set(pod.ObjectMeta.Labels)
+
+		var selecting []localRateLimit
+		for _, l := range byNamespace[rp.Metadata.FullName.Namespace] {
+			if l.spec.Selector == nil || labels.SelectorFromSet(l.spec.Selector.MatchLabels).Matches(podLabels) {
+				selecting = append(selecting, l)
+			}
+		}
+
+		conflicting := map[*resource.Instance]struct{}{}
+		for i := range selecting {
+			for j := i + 1; j < len(selecting); j++ {
+				if overlap(selecting[i].spec, selecting[j].spec) {
+					conflicting[selecting[i].rs] = struct{}{}
+					conflicting[selecting[j].rs] = struct{}{}
+				}
+			}
+		}
+		if len(conflicting) == 0 {
+			return true
+		}
+
+		var rsList []*resource.Instance
+		for rs := range conflicting {
+			rsList = append(rsList, rs)
+		}
+		sort.Slice(rsList, func(i, j int) bool {
+			return rsList[i].Metadata.FullName.String() < rsList[j].Metadata.FullName.String()
+		})
+		names := make([]string, 0, len(rsList))
+		for _, rs := range rsList {
+			names = append(names, string(rs.Metadata.FullName.Name))
+		}
+		for _, rs := range rsList {
+			m := msg.NewConflictingLocalRateLimits(rs, names,
+				rp.Metadata.FullName.Namespace.String(), rp.Metadata.FullName.Name.String())
+
+			if line, ok := util.ErrorLine(rs, util.MetadataName); ok {
+				m.Line = line
+			}
+
+			c.Report(collections.IstioNetworkingV1Beta1Localratelimits.Name(), m)
+		}
+		return true
+	})
+}
+
+// overlap returns true if a rule of each LocalRateLimit matches the same routes.
+func overlap(a, b *networkingv1beta1.LocalRateLimitSpec) bool {
+	for _, ra := range a.Rules {
+		for _, rb := range b.Rules {
+			if matchesOverlap(ra.Match, rb.Match) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func matchesOverlap(a, b *networkingv1beta1.LocalRateLimitMatch) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	portsOverlap := len(a.Ports) == 0 || len(b.Ports) == 0
+	for _, pa := range a.Ports {
+		for _, pb := range b.Ports {
+			if pa == pb {
+				portsOverlap = true
+			}
+		}
+	}
+	routesOverlap := len(a.Routes) == 0 || len(b.Routes) == 0
+	for _, ra := range a.Routes {
+		for _, rb := range b.Routes {
+			if ra == rb {
+				routesOverlap = true
+			}
+		}
+	}
+	return portsOverlap && routesOverlap
+}
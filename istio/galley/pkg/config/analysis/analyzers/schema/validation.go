@@ -16,10 +16,12 @@ package schema
 import (
 	"fmt"
 
+	"github.com/gogo/protobuf/types"
 	"github.com/hashicorp/go-multierror"
 
 	"istio.io/istio/galley/pkg/config/analysis"
 	"istio.io/istio/galley/pkg/config/analysis/msg"
+	"istio.io/istio/galley/pkg/config/util/pb"
 	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/resource"
 	"istio.io/istio/pkg/config/schema/collection"
@@ -61,12 +63,22 @@ func (a *ValidationAnalyzer) Analyze(ctx analysis.Context) {
 		ns := r.Metadata.FullName.Namespace
 		name := r.Metadata.FullName.Name
 
+		var spec config.Spec = r.Message
+		if s, ok := r.Message.(*types.Struct); ok {
+			// Resources whose spec is a plain Go type are carried as structs, decode them to validate them.
+			spec = a.s.Resource().MustNewInstance()
+			if err := pb.UnmarshalStruct(s, spec); err != nil {
+				ctx.Report(c, msg.NewSchemaValidationError(r, err))
+				return true
+			}
+		}
+
 		warnings, err := a.s.Resource().ValidateConfig(config.Config{
 			Meta: config.Meta{
 				Name:      string(name),
 				Namespace: string(ns),
 			},
-			Spec: r.Message,
+			Spec: spec,
 		})
 		if err != nil {
 			if multiErr, ok := err.(*multierror.Error); ok {
@@ -152,6 +152,14 @@ var (
 	// InvalidWebhook defines a diag.MessageType for message "InvalidWebhook".
 	// Description: Webhook is invalid or references a control plane service that does not exist.
 	InvalidWebhook = diag.NewMessageType(diag.Error, "IST0139", "%v")
+
+	// CACertificateKeyPolicyViolation defines a diag.MessageType for message "CACertificateKeyPolicyViolation".
+	// Description: A CA certificate has a key that is not allowed by the CA_KEY_POLICY of istiod.
+	CACertificateKeyPolicyViolation = diag.NewMessageType(diag.Error, "IST0140", "The CA certificates in %q do not comply with the CA_KEY_POLICY %q of deployment %s: %v")
+
+	// ConflictingLocalRateLimits defines a diag.MessageType for message "ConflictingLocalRateLimits".
+	// Description: Several LocalRateLimit resources limit the same routes of a workload
+	ConflictingLocalRateLimits = diag.NewMessageType(diag.Warning, "IST0141", "The LocalRateLimits %v in namespace %q limit the same routes of workload pod %q, only the oldest one applies to them.")
 )
 
 // All returns a list of all known message types.
@@ -193,6 +201,8 @@ func All() []*diag.MessageType {
 		DeploymentConflictingPorts,
 		GatewayDuplicateCertificate,
 		InvalidWebhook,
+		CACertificateKeyPolicyViolation,
+		ConflictingLocalRateLimits,
 	}
 }
 
@@ -557,3 +567,26 @@ func NewInvalidWebhook(r *resource.Instance, error string) diag.Message {
 		error,
 	)
 }
+
+// NewCACertificateKeyPolicyViolation returns a new diag.Message based on CACertificateKeyPolicyViolation.
+func NewCACertificateKeyPolicyViolation(r *resource.Instance, file string, policy string, deployment string, error string) diag.Message {
+	return diag.NewMessage(
+		CACertificateKeyPolicyViolation,
+		r,
+		file,
+		policy,
+		deployment,
+		error,
+	)
+}
+
+// NewConflictingLocalRateLimits returns a new diag.Message based on ConflictingLocalRateLimits.
+func NewConflictingLocalRateLimits(r *resource.Instance, conflictingLocalRateLimits []string, namespace string, workloadPod string) diag.Message {
+	return diag.NewMessage(
+		ConflictingLocalRateLimits,
+		r,
+		conflictingLocalRateLimits,
+		namespace,
+		workloadPod,
+	)
+}
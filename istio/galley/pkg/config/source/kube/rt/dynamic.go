@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -47,7 +48,14 @@ func (p *Provider) getDynamicAdapter(r resource.Schema) *Adapter {
 				return nil, fmt.Errorf("extractResource: not unstructured: %v", o)
 			}
 
-			pr := r.MustNewInstance().(proto.Message)
+			// Resources whose spec is a plain Go type rather than a proto are carried as structs. Analyzers
+			// decode them with pb.UnmarshalStruct.
+			var pr proto.Message = &types.Struct{}
+			if spec, err := r.NewInstance(); err == nil {
+				if p, ok := spec.(proto.Message); ok {
+					pr = p
+				}
+			}
 			if err := pb.UnmarshalData(pr, u.Object["spec"]); err != nil {
 				return nil, err
 			}
@@ -15,11 +15,13 @@
 package pb
 
 import (
+	"encoding/json"
 	"strings"
 
 	"github.com/ghodss/yaml"
 	"github.com/gogo/protobuf/jsonpb"
 	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
 	yaml2 "gopkg.in/yaml.v2"
 )
 
@@ -33,6 +35,15 @@ func UnmarshalData(pb proto.Message, data interface{}) error {
 	return err
 }
 
+// UnmarshalStruct decodes the struct into out, which must be a pointer to a Go type that can be unmarshaled from JSON.
+func UnmarshalStruct(s *types.Struct, out interface{}) error {
+	js, err := (&jsonpb.Marshaler{}).MarshalToString(s)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(js), out)
+}
+
 func toJSON(data interface{}) (string, error) {
 	var result string
 	b, err := yaml2.Marshal(data)
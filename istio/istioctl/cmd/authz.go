@@ -16,15 +16,25 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	authpb "istio.io/api/security/v1beta1"
 	"istio.io/istio/istioctl/pkg/authz"
 	"istio.io/istio/istioctl/pkg/util/configdump"
 	"istio.io/istio/istioctl/pkg/util/handlers"
+	"istio.io/istio/pilot/pkg/config/kube/crd"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/security/authz/evaluator"
+	"istio.io/istio/pilot/pkg/security/trustdomain"
+	configlabels "istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/kube"
 	"istio.io/pkg/log"
 )
@@ -91,6 +101,255 @@ The command also supports reading from a standalone config dump file with flag -
 	},
 }
 
+type authzEvalArgs struct {
+	policyFiles   []string
+	workload      []string
+	rootNamespace string
+	request       evaluator.Request
+	port          uint
+	headers       []string
+	claims        []string
+	claimsFile    string
+}
+
+func authzEvalCmd() *cobra.Command {
+	evalArgs := &authzEvalArgs{}
+	cmd := &cobra.Command{
+		Use:   "eval [<type>/]<name>[.<namespace>]",
+		Short: "Evaluate AuthorizationPolicy for a request to a workload.",
+		Long: `Eval evaluates whether a request to a workload is allowed by the AuthorizationPolicy
+applied to it, without sending the request. It reports the decision (ALLOW, DENY or CUSTOM)
+together with the policy and rule that made the decision. DENY policies are evaluated before
+ALLOW policies, and a CUSTOM policy delegates the request to its extension provider before both.
+
+The workload is either a pod in the cluster, whose labels and namespace are used, or described
+with --labels and --namespace. The policies are read from the cluster, or from the YAML files
+given with -f.`,
+		Example: `  # Check whether the sleep service account in namespace foo can GET /ip on pod httpbin-88ddbcfdd-nt5jb:
+  istioctl x authz eval httpbin-88ddbcfdd-nt5jb.foo --source-principal cluster.local/ns/foo/sa/sleep --path /ip
+
+  # Evaluate the policies in a file for a workload with the label app=httpbin in namespace foo:
+  istioctl x authz eval -f policies.yaml -n foo -l app=httpbin --source-namespace bar --method POST --path /post
+
+  # Evaluate a request with JWT claims:
+  istioctl x authz eval -f policies.yaml -n foo -l app=httpbin --claim iss=https://issuer.example.com \
+    --claim sub=alice --claim groups=admin`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				cmd.Println(cmd.UsageString())
+				return fmt.Errorf("eval requires only <pod-name>[.<pod-namespace>]")
+			}
+			if len(args) == 0 && len(evalArgs.policyFiles) == 0 {
+				cmd.Println(cmd.UsageString())
+				return fmt.Errorf("eval requires a pod name or policy files")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req, err := evalArgs.buildRequest()
+			if err != nil {
+				return err
+			}
+
+			var client kube.ExtendedClient
+			if len(args) == 1 || len(evalArgs.policyFiles) == 0 {
+				if client, err = kubeClient(kubeconfig, configContext); err != nil {
+					return fmt.Errorf("failed to create k8s client: %w", err)
+				}
+			}
+
+			workloadNamespace := handlers.HandleNamespace(namespace, defaultNamespace)
+			workloadLabels := configlabels.Instance{}
+			for _, l := range evalArgs.workload {
+				kv := strings.SplitN(l, "=", 2)
+				if len(kv) != 2 {
+					return fmt.Errorf("invalid label %q, expecting <name>=<value>", l)
+				}
+				workloadLabels[kv[0]] = kv[1]
+			}
+			if len(args) == 1 {
+				podName, podNamespace, err := handlers.InferPodInfoFromTypedResource(args[0], workloadNamespace,
+					client.UtilFactory())
+				if err != nil {
+					return err
+				}
+				pod, err := client.CoreV1().Pods(podNamespace).Get(context.TODO(), podName, metav1.GetOptions{})
+				if err != nil {
+					return fmt.Errorf("failed to get pod %s.%s: %v", podName, podNamespace, err)
+				}
+				workloadNamespace, workloadLabels = pod.Namespace, pod.Labels
+				if req.DestinationIP == "" {
+					req.DestinationIP = pod.Status.PodIP
+				}
+			}
+
+			var policies []model.AuthorizationPolicy
+			if len(evalArgs.policyFiles) != 0 {
+				policies, err = readAuthorizationPolicies(evalArgs.policyFiles)
+			} else {
+				policies, err = listAuthorizationPolicies(client)
+			}
+			if err != nil {
+				return err
+			}
+
+			authzPolicies := &model.AuthorizationPolicies{
+				NamespaceToPolicies: map[string][]model.AuthorizationPolicy{},
+				RootNamespace:       evalArgs.rootNamespace,
+			}
+			for _, p := range policies {
+				authzPolicies.NamespaceToPolicies[p.Namespace] = append(authzPolicies.NamespaceToPolicies[p.Namespace], p)
+			}
+			applied := authzPolicies.ListAuthorizationPolicies(workloadNamespace, configlabels.Collection{workloadLabels})
+			result, err := evaluator.New(applied, trustdomain.NewBundle(evaluator.DefaultTrustDomain, nil)).Evaluate(req)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(cmd.OutOrStdout(), result.String())
+			return nil
+		},
+	}
+
+	flags := cmd.PersistentFlags()
+	flags.StringSliceVarP(&evalArgs.policyFiles, "file", "f", nil,
+		"YAML files with the AuthorizationPolicy to evaluate, instead of the policies in the cluster")
+	flags.StringSliceVarP(&evalArgs.workload, "labels", "l", nil,
+		"Labels of the workload when no pod is given, e.g. app=httpbin,version=v1")
+	flags.StringVar(&evalArgs.rootNamespace, "root-namespace", "istio-system",
+		"The mesh root namespace, policies in it apply to all workloads")
+	flags.StringVar(&evalArgs.request.SourcePrincipal, "source-principal", "",
+		"Peer identity of the request, e.g. cluster.local/ns/default/sa/sleep")
+	flags.StringVar(&evalArgs.request.SourceNamespace, "source-namespace", "",
+		"Namespace of the source workload, used when --source-principal is not set")
+	flags.StringVar(&evalArgs.request.SourceIP, "source-ip", "", "IP address of the downstream connection")
+	flags.StringVar(&evalArgs.request.RemoteIP, "remote-ip", "",
+		"Original client IP address, defaults to --source-ip")
+	flags.StringVar(&evalArgs.request.DestinationIP, "destination-ip", "",
+		"IP address of the workload, defaults to the pod IP")
+	flags.UintVar(&evalArgs.port, "port", 0, "Port of the workload")
+	flags.StringVar(&evalArgs.request.SNI, "sni", "", "Server name of the TLS connection")
+	flags.BoolVar(&evalArgs.request.TCP, "tcp", false, "Evaluate a plain TCP connection instead of a HTTP request")
+	flags.StringVar(&evalArgs.request.Host, "host", "", "Host of the request")
+	flags.StringVar(&evalArgs.request.Method, "method", "GET", "Method of the request")
+	flags.StringVar(&evalArgs.request.Path, "path", "/", "Path of the request")
+	flags.StringArrayVarP(&evalArgs.headers, "header", "H", nil, "Header of the request in the format <name>=<value>")
+	flags.StringVar(&evalArgs.request.RequestPrincipal, "request-principal", "",
+		"JWT principal of the request in the format <iss>/<sub>, defaults to the iss and sub claims")
+	flags.StringArrayVar(&evalArgs.claims, "claim", nil,
+		"JWT claim in the format <name>=<value>, repeated claims are collected into a list")
+	flags.StringVar(&evalArgs.claimsFile, "claims-file", "", "JSON file with the JWT claims, e.g. the decoded JWT payload")
+	flags.StringVar(&evalArgs.request.Presenter, "presenter", "", "Authorized presenter (azp) of the JWT")
+	return cmd
+}
+
+func (a *authzEvalArgs) buildRequest() (*evaluator.Request, error) {
+	req := a.request
+	req.DestinationPort = uint32(a.port)
+	req.Headers = map[string]string{}
+	for _, h := range a.headers {
+		kv := strings.SplitN(h, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid header %q, expecting <name>=<value>", h)
+		}
+		req.Headers[kv[0]] = kv[1]
+	}
+	req.Claims = map[string]interface{}{}
+	if a.claimsFile != "" {
+		data, err := ioutil.ReadFile(a.claimsFile)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &req.Claims); err != nil {
+			return nil, fmt.Errorf("failed to parse claims from %s: %v", a.claimsFile, err)
+		}
+	}
+	for _, c := range a.claims {
+		kv := strings.SplitN(c, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid claim %q, expecting <name>=<value>", c)
+		}
+		// Repeated claims are collected into a list, e.g. for the groups claim.
+		switch v := req.Claims[kv[0]].(type) {
+		case nil:
+			req.Claims[kv[0]] = kv[1]
+		case string:
+			req.Claims[kv[0]] = []string{v, kv[1]}
+		case []string:
+			req.Claims[kv[0]] = append(v, kv[1])
+		default:
+			return nil, fmt.Errorf("claim %q is already set from %s", kv[0], a.claimsFile)
+		}
+	}
+	if aud, ok := req.Claims["aud"]; ok && len(req.Audiences) == 0 {
+		switch v := aud.(type) {
+		case string:
+			req.Audiences = []string{v}
+		case []string:
+			req.Audiences = v
+		case []interface{}:
+			// Lists decoded from the claims file.
+			for _, a := range v {
+				s, ok := a.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid aud claim %v, expecting a string or a list of strings", aud)
+				}
+				req.Audiences = append(req.Audiences, s)
+			}
+		default:
+			return nil, fmt.Errorf("invalid aud claim %v, expecting a string or a list of strings", aud)
+		}
+	}
+	return &req, nil
+}
+
+// readAuthorizationPolicies reads the AuthorizationPolicy from the given YAML files, other kinds are ignored.
+func readAuthorizationPolicies(files []string) ([]model.AuthorizationPolicy, error) {
+	var policies []model.AuthorizationPolicy
+	for _, f := range files {
+		data, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		configs, _, err := crd.ParseInputs(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", f, err)
+		}
+		for _, c := range configs {
+			if c.GroupVersionKind != gvk.AuthorizationPolicy {
+				continue
+			}
+			ns := c.Namespace
+			if ns == "" {
+				ns = handlers.HandleNamespace(namespace, defaultNamespace)
+			}
+			policies = append(policies, model.AuthorizationPolicy{
+				Name:      c.Name,
+				Namespace: ns,
+				Spec:      c.Spec.(*authpb.AuthorizationPolicy),
+			})
+		}
+	}
+	return policies, nil
+}
+
+func listAuthorizationPolicies(client kube.ExtendedClient) ([]model.AuthorizationPolicy, error) {
+	list, err := client.Istio().SecurityV1beta1().AuthorizationPolicies(metav1.NamespaceAll).
+		List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list AuthorizationPolicy: %v", err)
+	}
+	policies := make([]model.AuthorizationPolicy, 0, len(list.Items))
+	for i := range list.Items {
+		item := &list.Items[i]
+		policies = append(policies, model.AuthorizationPolicy{
+			Name:      item.Name,
+			Namespace: item.Namespace,
+			Spec:      &item.Spec,
+		})
+	}
+	return policies, nil
+}
+
 func getConfigDumpFromFile(filename string) (*configdump.Wrapper, error) {
 	file, err := os.Open(filename)
 	if err != nil {
@@ -149,6 +408,7 @@ func AuthZ() *cobra.Command {
 	}
 
 	cmd.AddCommand(checkCmd)
+	cmd.AddCommand(authzEvalCmd())
 	cmd.Long += "\n\n" + ExperimentalMsg
 	return cmd
 }
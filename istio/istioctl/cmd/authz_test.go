@@ -13,3 +13,96 @@
 // limitations under the License.
 
 package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAuthzEval(t *testing.T) {
+	policies := "testdata/authz/policies.yaml"
+	cases := []execTestCase{
+		{
+			args:           strings.Split("x authz eval", " "),
+			expectedString: "eval requires a pod name or policy files",
+			wantException:  true,
+		},
+		{
+			args: strings.Split("x authz eval -f "+policies+" -n foo -l app=httpbin "+
+				"--source-principal cluster.local/ns/foo/sa/sleep --path /ip", " "),
+			expectedOutput: "ALLOW: allowed by policy foo/allow-sleep rule 0\n",
+		},
+		{
+			args: strings.Split("x authz eval -f "+policies+" -n foo -l app=httpbin "+
+				"--source-principal cluster.local/ns/foo/sa/sleep --path /admin/users", " "),
+			expectedOutput: "DENY: denied by policy foo/deny-admin rule 0\n",
+		},
+		{
+			args: strings.Split("x authz eval -f "+policies+" -n foo -l app=httpbin "+
+				"--source-namespace bar --method POST", " "),
+			expectedOutput: "DENY: denied as no rule in the 1 ALLOW policies matched\n",
+		},
+		{
+			args: strings.Split("x authz eval -f "+policies+" -n foo -l app=httpbin --method POST "+
+				"--claim iss=https://issuer.example.com --claim sub=alice --claim groups=dev --claim groups=admin", " "),
+			expectedOutput: "ALLOW: allowed by policy foo/allow-sleep rule 1\n",
+		},
+		{
+			args: strings.Split("x authz eval -f "+policies+" -n bar -l app=productpage "+
+				"--host www.example.com --path /admin", " "),
+			expectedOutput: "CUSTOM: delegated to extension provider \"opa\" by policy istio-system/ext-authz rule 0\n" +
+				"ALLOW: allowed as no ALLOW policy is applied to the workload\n",
+		},
+		{
+			args:           strings.Split("x authz eval -f "+policies+" -n foo -H invalid", " "),
+			expectedString: "invalid header",
+			wantException:  true,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d %s", i, strings.Join(c.args, " ")), func(t *testing.T) {
+			verifyExecTestOutput(t, c)
+		})
+	}
+}
+
+func TestAuthzEvalBuildRequestAudiences(t *testing.T) {
+	cases := []struct {
+		name    string
+		claims  string
+		want    []string
+		wantErr bool
+	}{
+		{name: "string", claims: `{"aud": "foo"}`, want: []string{"foo"}},
+		{name: "list", claims: `{"aud": ["foo", "bar"]}`, want: []string{"foo", "bar"}},
+		{name: "invalid list", claims: `{"aud": ["foo", 1]}`, wantErr: true},
+		{name: "invalid", claims: `{"aud": 1}`, wantErr: true},
+		{name: "none", claims: `{"sub": "alice"}`},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			claimsFile := filepath.Join(t.TempDir(), "claims.json")
+			if err := ioutil.WriteFile(claimsFile, []byte(c.claims), 0644); err != nil {
+				t.Fatal(err)
+			}
+			req, err := (&authzEvalArgs{claimsFile: claimsFile}).buildRequest()
+			if c.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got audiences %v", req.Audiences)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(req.Audiences, c.want) {
+				t.Fatalf("got audiences %v, want %v", req.Audiences, c.want)
+			}
+		})
+	}
+}
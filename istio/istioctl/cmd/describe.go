@@ -28,15 +28,20 @@ import (
 	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	rbac_http_filter "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/rbac/v3"
 	http_conn "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	gogoproto "github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
 	"github.com/golang/protobuf/ptypes"
 	structpb "github.com/golang/protobuf/ptypes/struct"
 	multierror "github.com/hashicorp/go-multierror"
 	"github.com/spf13/cobra"
 	v1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8s_labels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 
+	"istio.io/api/annotation"
+	meshconfig "istio.io/api/mesh/v1alpha1"
 	"istio.io/api/networking/v1alpha3"
 	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
@@ -44,6 +49,8 @@ import (
 	"istio.io/istio/istioctl/pkg/util/configdump"
 	"istio.io/istio/istioctl/pkg/util/handlers"
 	istio_envoy_configdump "istio.io/istio/istioctl/pkg/writer/envoy/configdump"
+	"istio.io/istio/pilot/pkg/config/kube/crdclient"
+	"istio.io/istio/pilot/pkg/config/memory"
 	"istio.io/istio/pilot/pkg/model"
 	pilot_v1alpha3 "istio.io/istio/pilot/pkg/networking/core/v1alpha3"
 	"istio.io/istio/pilot/pkg/networking/util"
@@ -53,7 +60,10 @@ import (
 	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/config/protocol"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/kube/inject"
 	"istio.io/pkg/log"
@@ -137,6 +147,12 @@ the configuration objects that affect that pod.`,
 				return err
 			}
 
+			if isMeshed(pod) && !ignoreUnmeshed {
+				if err := printEffectiveProxyConfig(writer, kubeClient, pod, opts.Revision); err != nil {
+					fmt.Fprintf(writer, "WARNING: could not resolve the effective proxy configuration: %v\n", err)
+				}
+			}
+
 			podsLabels := []k8s_labels.Set{k8s_labels.Set(pod.ObjectMeta.Labels)}
 			fmt.Fprintf(writer, "--------------------\n")
 			err = describePodServices(writer, kubeClient, configClient, pod, matchingServices, podsLabels)
@@ -469,6 +485,97 @@ func printPod(writer io.Writer, pod *v1.Pod) {
 	}
 }
 
+// printEffectiveProxyConfig prints the proxy configuration of the pod, resolved from the mesh config, the ProxyConfig
+// resources selecting the pod and its proxy.istio.io/config annotation.
+func printEffectiveProxyConfig(writer io.Writer, kubeClient kube.ExtendedClient, pod *v1.Pod, revision string) error {
+	cmName := defaultMeshConfigMapName
+	if revision != "" {
+		cmName = fmt.Sprintf("%s-%s", defaultMeshConfigMapName, revision)
+	}
+	cm, err := kubeClient.CoreV1().ConfigMaps(istioNamespace).Get(context.TODO(), cmName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	meshConfig, err := mesh.ApplyMeshConfigDefaults(cm.Data[configMapKey])
+	if err != nil {
+		return err
+	}
+
+	var proxyConfigs []config.Config
+	list, err := kubeClient.Dynamic().Resource(collections.IstioNetworkingV1Beta1Proxyconfigs.Resource().GroupVersionResource()).
+		Namespace(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil && !kerrors.IsNotFound(err) {
+		return err
+	}
+	if list != nil {
+		for i := range list.Items {
+			if cfg := crdclient.TranslateObject(&list.Items[i], gvk.ProxyConfig, ""); cfg != nil {
+				proxyConfigs = append(proxyConfigs, *cfg)
+			}
+		}
+	}
+
+	pc, err := effectiveProxyConfig(pod, meshConfig, proxyConfigs)
+	if err != nil {
+		return err
+	}
+	printProxyConfig(writer, pc)
+	return nil
+}
+
+func effectiveProxyConfig(pod *v1.Pod, meshConfig *meshconfig.MeshConfig, proxyConfigs []config.Config) (*meshconfig.ProxyConfig, error) {
+	store := model.MakeIstioStore(memory.MakeSkipValidation(collections.Pilot, true))
+	for _, cfg := range proxyConfigs {
+		if _, err := store.Create(cfg); err != nil {
+			return nil, err
+		}
+	}
+	pcs, err := model.GetProxyConfigs(store, meshConfig)
+	if err != nil {
+		return nil, err
+	}
+	mc := gogoproto.Clone(meshConfig).(*meshconfig.MeshConfig)
+	mc.DefaultConfig = pcs.EffectiveProxyConfig(pod.Namespace, pod.Labels, meshConfig)
+	if pca, f := pod.Annotations[annotation.ProxyConfig.Name]; f {
+		if mc, err = mesh.ApplyProxyConfig(pca, *mc); err != nil {
+			return nil, err
+		}
+	}
+	return mc.DefaultConfig, nil
+}
+
+func printProxyConfig(writer io.Writer, pc *meshconfig.ProxyConfig) {
+	fmt.Fprintf(writer, "Effective ProxyConfig:\n")
+	fmt.Fprintf(writer, "   Concurrency: %d\n", pc.GetConcurrency().GetValue())
+	for _, d := range []struct {
+		name     string
+		duration *types.Duration
+	}{
+		{"Drain Duration", pc.GetDrainDuration()},
+		{"Parent Shutdown Duration", pc.GetParentShutdownDuration()},
+		{"Termination Drain Duration", pc.GetTerminationDrainDuration()},
+	} {
+		if d.duration != nil {
+			dur, _ := types.DurationFromProto(d.duration)
+			fmt.Fprintf(writer, "   %s: %v\n", d.name, dur)
+		}
+	}
+	if pc.GetTracing() != nil {
+		fmt.Fprintf(writer, "   Tracing Sampling: %v%%\n", pc.GetTracing().GetSampling())
+	}
+	if m := pc.GetProxyStatsMatcher(); m != nil {
+		if len(m.InclusionPrefixes) > 0 {
+			fmt.Fprintf(writer, "   Stats Inclusion Prefixes: %s\n", strings.Join(m.InclusionPrefixes, ", "))
+		}
+		if len(m.InclusionSuffixes) > 0 {
+			fmt.Fprintf(writer, "   Stats Inclusion Suffixes: %s\n", strings.Join(m.InclusionSuffixes, ", "))
+		}
+		if len(m.InclusionRegexps) > 0 {
+			fmt.Fprintf(writer, "   Stats Inclusion Regexps: %s\n", strings.Join(m.InclusionRegexps, ", "))
+		}
+	}
+}
+
 func kname(meta metav1.ObjectMeta) string {
 	ns := handlers.HandleNamespace(namespace, defaultNamespace)
 	if meta.Namespace == ns {
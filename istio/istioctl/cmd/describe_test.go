@@ -20,11 +20,17 @@ import (
 	"strings"
 	"testing"
 
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/fake"
 
 	"istio.io/istio/pilot/test/util"
+	"istio.io/istio/pkg/config"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/schema/gvk"
 )
 
 // execAndK8sConfigTestCase lets a test case hold some Envoy, Istio, and Kubernetes configuration
@@ -129,3 +135,38 @@ func mockInterfaceFactoryGenerator(k8sConfigs []runtime.Object) func(kubeconfig
 
 	return outFactory
 }
+
+func TestEffectiveProxyConfig(t *testing.T) {
+	concurrency := int32(4)
+	meshConfig := mesh.DefaultMeshConfig()
+	pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:        "ratings-v1",
+		Namespace:   "default",
+		Labels:      map[string]string{"app": "ratings"},
+		Annotations: map[string]string{"proxy.istio.io/config": "terminationDrainDuration: 30s"},
+	}}
+	proxyConfigs := []config.Config{{
+		Meta: config.Meta{GroupVersionKind: gvk.ProxyConfig, Name: "ratings", Namespace: "default"},
+		Spec: &networkingv1beta1.ProxyConfigSpec{
+			Selector:          &networkingv1beta1.WorkloadSelector{MatchLabels: map[string]string{"app": "ratings"}},
+			Concurrency:       &concurrency,
+			ProxyStatsMatcher: &networkingv1beta1.ProxyStatsMatcher{InclusionPrefixes: []string{"cluster.outbound"}},
+		},
+	}}
+
+	pc, err := effectiveProxyConfig(pod, &meshConfig, proxyConfigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	printProxyConfig(&out, pc)
+	for _, want := range []string{
+		"Concurrency: 4\n",
+		"Termination Drain Duration: 30s\n",
+		"Stats Inclusion Prefixes: cluster.outbound\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/pkg/kube/secretcontroller"
+)
+
+// remoteClusterObjectKinds are the kinds of objects counted in the informer caches of the remote clusters.
+var remoteClusterObjectKinds = []string{"Services", "Endpoints", "Pods", "Nodes"}
+
+// istiodClusterStatus is the status of a remote cluster, as reported by an Istiod instance.
+type istiodClusterStatus struct {
+	istiod string
+	secretcontroller.ClusterStatus
+}
+
+func remoteClustersCommand() *cobra.Command {
+	var opts clioptions.ControlPlaneOptions
+
+	remoteClustersCmd := &cobra.Command{
+		Use:   "remote-clusters",
+		Short: "Lists the remote clusters each Istiod is connected to",
+		Long: `Lists the remote clusters each Istiod instance reads from the cluster-access secrets, with the state of the
+connection, the last time the informers of the cluster synced, the number of objects in their caches, and the last
+error. A cluster is failed if its kubeconfig is invalid, its API server is unreachable, or its informers did not sync
+within PILOT_REMOTE_CLUSTER_TIMEOUT.`,
+		Example: `  # List the remote clusters of all Istiod instances
+  istioctl x remote-clusters
+
+  # List the remote clusters of the Istiod instances of a revision
+  istioctl x remote-clusters --revision canary`,
+		Args: cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			kubeClient, err := kubeClientWithRevision(kubeconfig, configContext, opts.Revision)
+			if err != nil {
+				return fmt.Errorf("failed to create k8s client: %v", err)
+			}
+			responses, err := kubeClient.AllDiscoveryDo(context.TODO(), istioNamespace, "/debug/clusterz")
+			if err != nil {
+				return fmt.Errorf("unable to query Istiod for remote clusters: %v", err)
+			}
+
+			var statuses []istiodClusterStatus
+			for istiod, response := range responses {
+				var s []secretcontroller.ClusterStatus
+				if err := json.Unmarshal(response, &s); err != nil {
+					// Istiod instances running without multicluster, or without the endpoint, respond with a message.
+					fmt.Fprintf(c.ErrOrStderr(), "%s: %s\n", istiod, strings.TrimSpace(string(response)))
+					continue
+				}
+				for _, status := range s {
+					statuses = append(statuses, istiodClusterStatus{istiod: istiod, ClusterStatus: status})
+				}
+			}
+			writeRemoteClusters(c.OutOrStdout(), statuses, time.Now())
+			return nil
+		},
+	}
+
+	opts.AttachControlPlaneFlags(remoteClustersCmd)
+	return remoteClustersCmd
+}
+
+// writeRemoteClusters writes a table of the remote clusters, sorted by Istiod and cluster ID.
+func writeRemoteClusters(w io.Writer, statuses []istiodClusterStatus, now time.Time) {
+	if len(statuses) == 0 {
+		fmt.Fprintln(w, "No remote clusters found")
+		return
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if statuses[i].istiod != statuses[j].istiod {
+			return statuses[i].istiod < statuses[j].istiod
+		}
+		return statuses[i].ID < statuses[j].ID
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+	fmt.Fprintf(tw, "ISTIOD\tCLUSTER\tSECRET\tSTATUS\tLAST SYNC\t%s\tERROR\n", strings.ToUpper(strings.Join(remoteClusterObjectKinds, "\t")))
+	for _, s := range statuses {
+		lastSync := "-"
+		if s.LastSyncTime != nil {
+			lastSync = now.Sub(*s.LastSyncTime).Round(time.Second).String() + " ago"
+		}
+		counts := make([]string, 0, len(remoteClusterObjectKinds))
+		for _, kind := range remoteClusterObjectKinds {
+			if count, f := s.Objects[kind]; f {
+				counts = append(counts, strconv.Itoa(count))
+			} else {
+				counts = append(counts, "-")
+			}
+		}
+		errMsg := s.Error
+		if errMsg == "" {
+			errMsg = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.istiod, s.ID, s.SecretName, s.State, lastSync, strings.Join(counts, "\t"), errMsg)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,65 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"istio.io/istio/pkg/kube/secretcontroller"
+)
+
+func TestWriteRemoteClusters(t *testing.T) {
+	now := time.Date(2021, 3, 1, 12, 0, 0, 0, time.UTC)
+	lastSync := now.Add(-90 * time.Second)
+	statuses := []istiodClusterStatus{
+		{
+			istiod: "istiod-b",
+			ClusterStatus: secretcontroller.ClusterStatus{
+				ID:         "cluster2",
+				SecretName: "istio-system/istio-remote-secret-cluster2",
+				State:      secretcontroller.Failed,
+				Error:      "informers not synced after 30s",
+			},
+		},
+		{
+			istiod: "istiod-a",
+			ClusterStatus: secretcontroller.ClusterStatus{
+				ID:           "cluster2",
+				SecretName:   "istio-system/istio-remote-secret-cluster2",
+				State:        secretcontroller.Synced,
+				LastSyncTime: &lastSync,
+				Objects:      map[string]int{"Services": 12, "Endpoints": 10, "Pods": 30, "Nodes": 3},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	writeRemoteClusters(&out, statuses, now)
+	want := `ISTIOD   CLUSTER  SECRET                                    STATUS LAST SYNC SERVICES ENDPOINTS PODS NODES ERROR
+istiod-a cluster2 istio-system/istio-remote-secret-cluster2 synced 1m30s ago 12       10        30   3     -
+istiod-b cluster2 istio-system/istio-remote-secret-cluster2 failed -         -        -         -    -     informers not synced after 30s
+`
+	if out.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	writeRemoteClusters(&out, nil, now)
+	if want := "No remote clusters found\n"; out.String() != want {
+		t.Fatalf("got %q, want %q", out.String(), want)
+	}
+}
@@ -226,6 +226,9 @@ debug and diagnose their Istio mesh.
 	experimentalCmd.AddCommand(configCmd())
 	experimentalCmd.AddCommand(workloadCommands())
 	experimentalCmd.AddCommand(revisionCommand())
+	experimentalCmd.AddCommand(upgradeCommand())
+	experimentalCmd.AddCommand(sidecarCommand())
+	experimentalCmd.AddCommand(remoteClustersCommand())
 
 	analyzeCmd := Analyze()
 	hideInheritedFlags(analyzeCmd, "istioNamespace")
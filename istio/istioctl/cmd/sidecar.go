@@ -0,0 +1,347 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+
+	adminapi "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
+	envoy_corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	xdsapi "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/ghodss/yaml"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	networking "istio.io/api/networking/v1alpha3"
+	clientnetworking "istio.io/client-go/pkg/apis/networking/v1alpha3"
+	"istio.io/istio/istioctl/pkg/clioptions"
+	"istio.io/istio/istioctl/pkg/multixds"
+	"istio.io/istio/istioctl/pkg/sidecar"
+	"istio.io/istio/istioctl/pkg/util/configdump"
+	"istio.io/istio/istioctl/pkg/util/handlers"
+	pilotxds "istio.io/istio/pilot/pkg/xds"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/kube"
+)
+
+func sidecarCommand() *cobra.Command {
+	sidecarCmd := &cobra.Command{
+		Use:   "sidecar",
+		Short: "Commands to assist in configuring Sidecar resources",
+		Example: `  # Recommend Sidecar resources for the workloads of a namespace
+  istioctl x sidecar recommend -n default`,
+	}
+	sidecarCmd.AddCommand(sidecarRecommendCommand())
+	return sidecarCmd
+}
+
+func sidecarRecommendCommand() *cobra.Command {
+	var opts clioptions.ControlPlaneOptions
+	var centralOpts clioptions.CentralControlPlaneOptions
+	var dryRun bool
+
+	recommendCmd := &cobra.Command{
+		Use:   "recommend [<type>/]<name>[.<namespace>]",
+		Short: "Recommends Sidecar resources limiting the egress of workloads to the services they use",
+		Long: `Recommends a Sidecar resource per workload, importing only the services the workload sent requests or opened
+connections to, as observed in the config dump and stats of the Envoy of its pods. Services only used before the
+stats were last reset, or not used yet, are not imported, so the workloads must have served representative traffic.
+Workloads with no observed outbound traffic are skipped with a warning, and gateways are never considered.
+
+The estimated size of the clusters, listeners and routes of the workload with and without the recommended Sidecar
+is computed by Istiod. The default output is serialized YAML, which can be piped into 'kubectl apply -f -' to send
+the Sidecars to the API Server.`,
+		Example: `  # Recommend Sidecar resources for the workloads of the default namespace
+  istioctl x sidecar recommend -n default
+
+  # Recommend a Sidecar resource for the workload of a pod
+  istioctl x sidecar recommend productpage-v1-7f44c4d57c-p9wdw.default
+
+  # Show the changes the recommended Sidecar resources would make to the existing ones
+  istioctl x sidecar recommend -n default --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(c *cobra.Command, args []string) error {
+			kubeClient, err := kubeClientWithRevision(kubeconfig, configContext, opts.Revision)
+			if err != nil {
+				return fmt.Errorf("failed to create k8s client: %v", err)
+			}
+
+			ns := handlers.HandleNamespace(namespace, defaultNamespace)
+			var pods []v1.Pod
+			if len(args) > 0 {
+				podName, podNamespace, err := handlers.InferPodInfoFromTypedResource(args[0], ns, kubeClient.UtilFactory())
+				if err != nil {
+					return err
+				}
+				pod, err := kubeClient.Kube().CoreV1().Pods(podNamespace).Get(context.TODO(), podName, metav1.GetOptions{})
+				if err != nil {
+					return err
+				}
+				if isGatewayPod(pod) {
+					return fmt.Errorf("%s.%s is a gateway, Sidecar resources do not apply to gateways", podName, podNamespace)
+				}
+				pods = []v1.Pod{*pod}
+				ns = podNamespace
+			} else {
+				podList, err := kubeClient.Kube().CoreV1().Pods(ns).List(context.TODO(), metav1.ListOptions{})
+				if err != nil {
+					return err
+				}
+				pods = podList.Items
+			}
+
+			workloads, err := observeWorkloads(kubeClient, pods)
+			if err != nil {
+				return err
+			}
+			if len(workloads) == 0 {
+				return fmt.Errorf("no running pods with a sidecar found in namespace %s", ns)
+			}
+			recommended := 0
+			for _, w := range workloads {
+				if len(w.UsedHosts()) == 0 {
+					// A Sidecar importing no service would cut all the egress of the workload.
+					fmt.Fprintf(c.ErrOrStderr(), "Warning: skipping workload %s.%s, no outbound traffic was observed in its %d pod(s). "+
+						"Send representative traffic to it before recommending a Sidecar.\n", w.Name, w.Namespace, len(w.Pods))
+					continue
+				}
+				if recommended > 0 {
+					fmt.Fprintln(c.OutOrStdout(), "---")
+				}
+				recommended++
+				if err := recommendSidecar(c.OutOrStdout(), kubeClient, &centralOpts, w, dryRun); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+
+	recommendCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false,
+		"Show the diff between the Sidecar resources currently applying to the workloads and the recommended resources, "+
+			"instead of the recommended resources")
+	opts.AttachControlPlaneFlags(recommendCmd)
+	centralOpts.AttachControlPlaneFlags(recommendCmd)
+	return recommendCmd
+}
+
+// observeWorkloads gathers the usage of outbound services of the running pods with a sidecar, grouped by workload.
+// Gateways are skipped.
+func observeWorkloads(kubeClient kube.ExtendedClient, pods []v1.Pod) ([]*sidecar.Workload, error) {
+	workloads := map[string]*sidecar.Workload{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase != v1.PodRunning || !hasProxyContainer(pod) || isGatewayPod(pod) {
+			continue
+		}
+		configDump, err := kubeClient.EnvoyDo(context.TODO(), pod.Name, pod.Namespace, "GET", "config_dump", nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute command on %s.%s sidecar: %v", pod.Name, pod.Namespace, err)
+		}
+		stats, err := kubeClient.EnvoyDo(context.TODO(), pod.Name, pod.Namespace, "GET",
+			"stats?usedonly&filter="+url.QueryEscape(sidecar.StatsFilter), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute command on %s.%s sidecar: %v", pod.Name, pod.Namespace, err)
+		}
+
+		deployMeta, _ := kube.GetDeployMetaFromPod(pod)
+		w, f := workloads[deployMeta.Name]
+		if !f {
+			w = sidecar.NewWorkload(deployMeta.Name, pod.Namespace)
+			workloads[deployMeta.Name] = w
+		}
+		if err := w.AddPod(pod.Name, pod.Labels, configDump, stats); err != nil {
+			return nil, err
+		}
+	}
+
+	out := make([]*sidecar.Workload, 0, len(workloads))
+	for _, w := range workloads {
+		out = append(out, w)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func hasProxyContainer(pod *v1.Pod) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == proxyContainerName {
+			return true
+		}
+	}
+	return false
+}
+
+// isGatewayPod returns true if the proxy of the pod runs as a gateway rather than a sidecar.
+func isGatewayPod(pod *v1.Pod) bool {
+	for _, c := range pod.Spec.Containers {
+		if c.Name != proxyContainerName {
+			continue
+		}
+		for _, arg := range c.Args {
+			if arg == "router" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// recommendSidecar writes the Sidecar recommended for the workload, or its diff with the Sidecars applying to it.
+func recommendSidecar(writer io.Writer, kubeClient kube.ExtendedClient, centralOpts *clioptions.CentralControlPlaneOptions,
+	w *sidecar.Workload, dryRun bool) error {
+	sidecars, err := kubeClient.Istio().NetworkingV1alpha3().Sidecars(w.Namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	// The Sidecars selecting the workload apply to it, otherwise the namespace-wide ones do.
+	var selecting, namespaceWide []*clientnetworking.Sidecar
+	for i := range sidecars.Items {
+		sc := &sidecars.Items[i]
+		if w.SelectedBy(&sc.Spec) {
+			selecting = append(selecting, sc)
+		} else if sc.Spec.GetWorkloadSelector() == nil {
+			namespaceWide = append(namespaceWide, sc)
+		}
+	}
+	// The recommended Sidecar replaces the one selecting the workload, rather than conflicting with it.
+	name := w.Name
+	if len(selecting) == 1 {
+		name = selecting[0].Name
+	}
+	recommended, err := sidecarYAML(name, w.Namespace, w.Sidecar())
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(writer, "# Workload %s.%s, observed in %d pod(s)\n", w.Name, w.Namespace, len(w.Pods))
+	for _, h := range w.UsedHosts() {
+		usage := w.Hosts[h]
+		fmt.Fprintf(writer, "#   %s: %d requests, %d connections\n", h, usage.Requests, usage.Connections)
+	}
+	fmt.Fprintf(writer, "#   %d of %d outbound services used\n", len(w.UsedHosts()), len(w.Hosts))
+	current, estimated, err := estimateSidecarConfigSize(kubeClient, centralOpts, w.Pods[0]+"."+w.Namespace, w.EgressHosts())
+	if err != nil {
+		fmt.Fprintf(writer, "#   config size could not be estimated: %v\n", err)
+	} else {
+		fmt.Fprintf(writer, "#   estimated config size: %d bytes, %d bytes with the Sidecar (%d bytes saved)\n",
+			current, estimated, current-estimated)
+	}
+	if len(selecting) > 1 {
+		fmt.Fprintf(writer, "#   warning: %d Sidecars select the workload, they should be replaced by the recommended one\n",
+			len(selecting))
+	}
+
+	if !dryRun {
+		_, err := writer.Write(recommended)
+		return err
+	}
+
+	if len(selecting) == 0 {
+		selecting = namespaceWide
+	}
+	if len(selecting) == 0 {
+		return writeSidecarDiff(writer, "No Sidecar", nil, fmt.Sprintf("Recommended Sidecar %s.%s", name, w.Namespace),
+			recommended)
+	}
+	for _, sc := range selecting {
+		existing, err := sidecarYAML(sc.Name, sc.Namespace, &sc.Spec)
+		if err != nil {
+			return err
+		}
+		if err := writeSidecarDiff(writer, fmt.Sprintf("Existing Sidecar %s.%s", sc.Name, sc.Namespace), existing,
+			fmt.Sprintf("Recommended Sidecar %s.%s", name, w.Namespace), recommended); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSidecarDiff writes the diff between a Sidecar currently applying to the workload and the recommended one.
+func writeSidecarDiff(writer io.Writer, from string, existing []byte, to string, recommended []byte) error {
+	text, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		FromFile: from,
+		A:        difflib.SplitLines(string(existing)),
+		ToFile:   to,
+		B:        difflib.SplitLines(string(recommended)),
+		Context:  3,
+	})
+	if err != nil {
+		return err
+	}
+	if text == "" {
+		text = "# The existing Sidecar matches the recommended one\n"
+	}
+	_, err = fmt.Fprint(writer, text)
+	return err
+}
+
+func sidecarYAML(name, ns string, spec *networking.Sidecar) ([]byte, error) {
+	iSpec, err := unstructureIstioType(spec)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(map[string]interface{}{
+		"apiVersion": collections.IstioNetworkingV1Alpha3Sidecars.Resource().APIVersion(),
+		"kind":       collections.IstioNetworkingV1Alpha3Sidecars.Resource().Kind(),
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": ns,
+		},
+		"spec": iSpec,
+	})
+}
+
+// estimateSidecarConfigSize returns the size of the configuration Istiod generates for the proxy, and the size it
+// would generate if the proxy was selected by a Sidecar with the egress hosts.
+func estimateSidecarConfigSize(kubeClient kube.ExtendedClient, centralOpts *clioptions.CentralControlPlaneOptions,
+	proxyID string, hosts []string) (int, int, error) {
+	current, err := istiodConfigSize(kubeClient, centralOpts, pilotxds.TypeDebugConfigDump, []string{proxyID})
+	if err != nil {
+		return 0, 0, err
+	}
+	estimated, err := istiodConfigSize(kubeClient, centralOpts, pilotxds.TypeDebugSidecarScope, append([]string{proxyID}, hosts...))
+	if err != nil {
+		return 0, 0, err
+	}
+	return current, estimated, nil
+}
+
+func istiodConfigSize(kubeClient kube.ExtendedClient, centralOpts *clioptions.CentralControlPlaneOptions,
+	typeURL string, resourceNames []string) (int, error) {
+	xdsRequest := xdsapi.DiscoveryRequest{
+		ResourceNames: resourceNames,
+		Node: &envoy_corev3.Node{
+			Id: "debug~0.0.0.0~istioctl~cluster.local",
+		},
+		TypeUrl: typeURL,
+	}
+	xdsResponses, err := multixds.FirstRequestAndProcessXds(&xdsRequest, centralOpts, istioNamespace, kubeClient)
+	if err != nil {
+		return 0, err
+	}
+	for _, resp := range xdsResponses {
+		if len(resp.Resources) > 0 {
+			return sidecar.ConfigSize(&configdump.Wrapper{ConfigDump: &adminapi.ConfigDump{Configs: resp.Resources}})
+		}
+	}
+	return 0, fmt.Errorf("unable to find the config dump of %s in Istiod responses", resourceNames[0])
+}
@@ -0,0 +1,69 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestSidecarRecommend(t *testing.T) {
+	cases := []execTestCase{
+		{
+			args:           strings.Split("x sidecar", " "),
+			expectedString: "Commands to assist in configuring Sidecar resources",
+		},
+		{
+			args:           strings.Split("x sidecar recommend --help", " "),
+			expectedString: "--dry-run",
+		},
+		{
+			args:           strings.Split("x sidecar recommend a.default b.default", " "),
+			expectedString: "accepts at most 1 arg(s), received 2",
+			wantException:  true,
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %d %s", i, strings.Join(c.args, " ")), func(t *testing.T) {
+			verifyExecTestOutput(t, c)
+		})
+	}
+}
+
+func TestIsGatewayPod(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{"sidecar", []string{"proxy", "sidecar", "--domain", "default.svc.cluster.local"}, false},
+		{"gateway", []string{"proxy", "router", "--domain", "istio-system.svc.cluster.local"}, true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &v1.Pod{Spec: v1.PodSpec{Containers: []v1.Container{
+				{Name: "app"},
+				{Name: proxyContainerName, Args: tt.args},
+			}}}
+			if got := isGatewayPod(pod); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
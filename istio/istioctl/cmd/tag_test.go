@@ -382,7 +382,7 @@ func TestSetTagErrors(t *testing.T) {
 			mockClient := kube.MockClient{
 				Interface: client,
 			}
-			err := setTag(context.Background(), mockClient, tc.tag, tc.revision, false, &out)
+			err := setTag(context.Background(), mockClient, tc.tag, tc.revision, false, false, &out)
 			if tc.error == "" && err != nil {
 				t.Fatalf("expected no error, got %v", err)
 			}
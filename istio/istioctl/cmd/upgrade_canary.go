@@ -0,0 +1,797 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/api/label"
+	"istio.io/istio/galley/pkg/config/analysis/analyzers"
+	"istio.io/istio/galley/pkg/config/analysis/diag"
+	"istio.io/istio/galley/pkg/config/analysis/local"
+	cfgKube "istio.io/istio/galley/pkg/config/source/kube"
+	"istio.io/istio/operator/cmd/mesh"
+	"istio.io/istio/pilot/pkg/xds"
+	labelutil "istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/resource"
+	"istio.io/istio/pkg/config/schema"
+	"istio.io/istio/pkg/kube"
+)
+
+const (
+	canaryStateConfigMapPrefix = "istio-canary-upgrade-"
+	canaryStateKey             = "state"
+	canaryTagPrefix            = "tag:"
+	injectionLabelName         = "istio-injection"
+	restartedAtAnnotation      = "kubectl.kubernetes.io/restartedAt"
+
+	canaryWaveHelpStr = `A comma separated list of namespaces and revision tags (prefixed with "tag:") moved to the new
+revision together. May be repeated; waves are processed in the order given.`
+)
+
+// canaryPhase is the step of the canary upgrade that was last reached.
+type canaryPhase string
+
+const (
+	canaryPhaseInstalling canaryPhase = "Installing"
+	canaryPhaseWaves      canaryPhase = "MovingWaves"
+	canaryPhaseComplete   canaryPhase = "Complete"
+	canaryPhaseRolledBack canaryPhase = "RolledBack"
+)
+
+type canaryUpgradeArgs struct {
+	// revision is the new control plane revision being rolled out.
+	revision string
+	// inFilenames are IstioOperator files used to install the new revision.
+	inFilenames []string
+	// set are IstioOperator overrides used to install the new revision.
+	set []string
+	// waves are the raw --wave flag values.
+	waves []string
+	// skipInstall skips installing the revision, for when it was installed separately.
+	skipInstall bool
+	// skipAnalysis disables the analyzer gate run after each wave.
+	skipAnalysis bool
+	// rollback reverts every wave moved so far and stops the upgrade.
+	rollback bool
+	// pauseAfterWave stops after moving the next wave, so the upgrade can be resumed later.
+	pauseAfterWave bool
+	// readinessTimeout bounds how long to wait for workloads to restart and proxies to sync.
+	readinessTimeout time.Duration
+}
+
+// canaryWave is a set of namespaces and revision tags moved to the new revision together.
+type canaryWave struct {
+	Namespaces []string `json:"namespaces,omitempty"`
+	Tags       []string `json:"tags,omitempty"`
+}
+
+// namespaceInjectionLabels records the injection labels of a namespace before it was moved.
+type namespaceInjectionLabels struct {
+	Revision  string `json:"revision,omitempty"`
+	Injection string `json:"injection,omitempty"`
+}
+
+// canaryUpgradeState is persisted in a ConfigMap in the Istio namespace so an interrupted upgrade can be
+// resumed or rolled back.
+type canaryUpgradeState struct {
+	Revision       string                              `json:"revision"`
+	Phase          canaryPhase                         `json:"phase"`
+	Waves          []canaryWave                        `json:"waves"`
+	CompletedWaves int                                 `json:"completedWaves"`
+	Namespaces     map[string]namespaceInjectionLabels `json:"namespaces,omitempty"`
+	Tags           map[string]string                   `json:"tags,omitempty"`
+	LastUpdated    time.Time                           `json:"lastUpdated"`
+}
+
+func upgradeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "Command group used to orchestrate control plane upgrades",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return fmt.Errorf("unknown subcommand %q", args[0])
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.HelpFunc()(cmd, args)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(upgradeCanaryCommand())
+	return cmd
+}
+
+func upgradeCanaryCommand() *cobra.Command {
+	cArgs := &canaryUpgradeArgs{}
+	cmd := &cobra.Command{
+		Use:   "canary",
+		Short: "Perform a revision based canary upgrade of the control plane",
+		Long: `Installs a new control plane revision and moves namespaces and revision tags to it in waves.
+
+After each wave the workloads in the affected namespaces are restarted, and the wave is only considered complete
+once every restarted proxy is synced with the new revision and the analyzers report no errors for the affected
+namespaces. Progress is stored in the "istio-canary-upgrade-<revision>" ConfigMap in the Istio namespace: running
+the command again for the same revision resumes from the last completed wave, and --rollback reverts every
+wave moved so far.
+`,
+		Example: `  # Install revision 1-10-0 and move the "prod" tag, then the "team-a" and "team-b" namespaces
+  istioctl x upgrade canary --revision 1-10-0 -f iop.yaml --wave tag:prod --wave team-a,team-b
+
+  # Move only the next wave and stop, the upgrade is resumed by running the same command again
+  istioctl x upgrade canary --revision 1-10-0 --wave tag:prod --wave team-a,team-b --pause-after-wave
+
+  # Revert every namespace and tag moved to revision 1-10-0
+  istioctl x upgrade canary --revision 1-10-0 --rollback
+`,
+		Args: cobra.ExactArgs(0),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if cArgs.revision == "" || !labelutil.IsDNS1123Label(cArgs.revision) {
+				return fmt.Errorf("invalid revision specified: %q", cArgs.revision)
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := kubeClientWithRevision(kubeconfig, configContext, cArgs.revision)
+			if err != nil {
+				return fmt.Errorf("failed to create Kubernetes client: %v", err)
+			}
+			ctx := context.Background()
+			if cArgs.rollback {
+				return rollbackCanaryUpgrade(ctx, client, cArgs, cmd.OutOrStdout())
+			}
+			return runCanaryUpgrade(ctx, client, cArgs, cmd.OutOrStdout())
+		},
+	}
+
+	cmd.PersistentFlags().StringVarP(&cArgs.revision, "revision", "r", "", "Control plane revision to upgrade to")
+	cmd.PersistentFlags().StringSliceVarP(&cArgs.inFilenames, "filename", "f", nil,
+		"Path to IstioOperator files used to install the new revision")
+	cmd.PersistentFlags().StringArrayVarP(&cArgs.set, "set", "s", nil,
+		"Override an IstioOperator value used to install the new revision, e.g. to choose a profile")
+	cmd.PersistentFlags().StringArrayVar(&cArgs.waves, "wave", nil, canaryWaveHelpStr)
+	cmd.PersistentFlags().BoolVar(&cArgs.skipInstall, "skip-install", false,
+		"Do not install the revision, it must already be running")
+	cmd.PersistentFlags().BoolVar(&cArgs.skipAnalysis, "skip-analysis", false,
+		"Do not gate waves on analyzer results")
+	cmd.PersistentFlags().BoolVar(&cArgs.rollback, "rollback", false,
+		"Revert every namespace and revision tag moved to the revision")
+	cmd.PersistentFlags().BoolVar(&cArgs.pauseAfterWave, "pause-after-wave", false,
+		"Stop after moving a single wave")
+	cmd.PersistentFlags().StringVarP(&manifestsPath, "manifests", "d", "", mesh.ManifestsFlagHelpStr)
+	cmd.PersistentFlags().DurationVar(&cArgs.readinessTimeout, "readiness-timeout", 5*time.Minute,
+		"Maximum time to wait for the workloads of a wave to restart and sync with the new revision")
+	_ = cmd.MarkPersistentFlagRequired("revision")
+
+	return cmd
+}
+
+// parseCanaryWaves converts --wave flag values into waves.
+func parseCanaryWaves(values []string) ([]canaryWave, error) {
+	seen := map[string]bool{}
+	waves := make([]canaryWave, 0, len(values))
+	for _, v := range values {
+		var wave canaryWave
+		for _, item := range strings.Split(v, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			if seen[item] {
+				return nil, fmt.Errorf("%q appears in more than one wave", item)
+			}
+			seen[item] = true
+			if strings.HasPrefix(item, canaryTagPrefix) {
+				tag := strings.TrimPrefix(item, canaryTagPrefix)
+				if !labelutil.IsDNS1123Label(tag) {
+					return nil, fmt.Errorf("invalid revision tag %q", tag)
+				}
+				wave.Tags = append(wave.Tags, tag)
+				continue
+			}
+			if !labelutil.IsDNS1123Label(item) {
+				return nil, fmt.Errorf("invalid namespace %q", item)
+			}
+			wave.Namespaces = append(wave.Namespaces, item)
+		}
+		if len(wave.Namespaces) == 0 && len(wave.Tags) == 0 {
+			return nil, fmt.Errorf("wave %q is empty", v)
+		}
+		waves = append(waves, wave)
+	}
+	return waves, nil
+}
+
+func canaryStateConfigMapName(revision string) string {
+	return canaryStateConfigMapPrefix + revision
+}
+
+// loadCanaryState returns the persisted state of the upgrade to revision, or nil if none was started.
+func loadCanaryState(ctx context.Context, client kubernetes.Interface, revision string) (*canaryUpgradeState, error) {
+	cm, err := client.CoreV1().ConfigMaps(istioNamespace).Get(ctx, canaryStateConfigMapName(revision), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read canary upgrade state: %v", err)
+	}
+	state := &canaryUpgradeState{}
+	if err := json.Unmarshal([]byte(cm.Data[canaryStateKey]), state); err != nil {
+		return nil, fmt.Errorf("failed to parse canary upgrade state in ConfigMap %s/%s: %v", cm.Namespace, cm.Name, err)
+	}
+	return state, nil
+}
+
+// saveCanaryState persists the state of the upgrade, creating the ConfigMap if needed.
+func saveCanaryState(ctx context.Context, client kubernetes.Interface, state *canaryUpgradeState) error {
+	state.LastUpdated = time.Now().UTC()
+	by, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      canaryStateConfigMapName(state.Revision),
+			Namespace: istioNamespace,
+			Labels:    map[string]string{label.IoIstioRev.Name: state.Revision},
+		},
+		Data: map[string]string{canaryStateKey: string(by)},
+	}
+	cms := client.CoreV1().ConfigMaps(istioNamespace)
+	if _, err := cms.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		if !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to update canary upgrade state: %v", err)
+		}
+		if _, err := cms.Create(ctx, cm, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create canary upgrade state: %v", err)
+		}
+	}
+	return nil
+}
+
+// runCanaryUpgrade installs the revision if needed and moves the remaining waves to it.
+func runCanaryUpgrade(ctx context.Context, client kube.ExtendedClient, cArgs *canaryUpgradeArgs, w io.Writer) error {
+	state, err := loadCanaryState(ctx, client.Kube(), cArgs.revision)
+	if err != nil {
+		return err
+	}
+	switch {
+	case state == nil:
+		waves, err := parseCanaryWaves(cArgs.waves)
+		if err != nil {
+			return err
+		}
+		state = &canaryUpgradeState{
+			Revision:   cArgs.revision,
+			Phase:      canaryPhaseInstalling,
+			Waves:      waves,
+			Namespaces: map[string]namespaceInjectionLabels{},
+			Tags:       map[string]string{},
+		}
+		if err := saveCanaryState(ctx, client.Kube(), state); err != nil {
+			return err
+		}
+	case state.Phase == canaryPhaseComplete:
+		fmt.Fprintf(w, "Canary upgrade to revision %q is already complete\n", state.Revision)
+		return nil
+	case state.Phase == canaryPhaseRolledBack:
+		return fmt.Errorf("canary upgrade to revision %q was rolled back, delete ConfigMap %s/%s to start over",
+			state.Revision, istioNamespace, canaryStateConfigMapName(state.Revision))
+	default:
+		if len(cArgs.waves) > 0 {
+			waves, err := parseCanaryWaves(cArgs.waves)
+			if err != nil {
+				return err
+			}
+			if !reflect.DeepEqual(waves, state.Waves) {
+				return fmt.Errorf("the --wave flags do not match the waves of the canary upgrade to revision %q in progress, "+
+					"run the command without --wave to resume it, or use --rollback", state.Revision)
+			}
+		}
+		fmt.Fprintf(w, "Resuming canary upgrade to revision %q at wave %d of %d\n",
+			state.Revision, state.CompletedWaves+1, len(state.Waves))
+	}
+
+	if state.Phase == canaryPhaseInstalling {
+		if !cArgs.skipInstall {
+			if err := installCanaryRevision(cArgs, w); err != nil {
+				return err
+			}
+		}
+		state.Phase = canaryPhaseWaves
+		if err := saveCanaryState(ctx, client.Kube(), state); err != nil {
+			return err
+		}
+	}
+
+	for state.CompletedWaves < len(state.Waves) {
+		i := state.CompletedWaves
+		fmt.Fprintf(w, "Moving wave %d of %d to revision %q\n", i+1, len(state.Waves), state.Revision)
+		if err := moveCanaryWave(ctx, client, state, state.Waves[i], w); err != nil {
+			return fmt.Errorf("wave %d failed, fix the issue and run the command again to resume or use --rollback: %v", i+1, err)
+		}
+		if err := gateCanaryWave(ctx, client, cArgs, state.Waves[i], w); err != nil {
+			return fmt.Errorf("wave %d failed, fix the issue and run the command again to resume or use --rollback: %v", i+1, err)
+		}
+		state.CompletedWaves++
+		if err := saveCanaryState(ctx, client.Kube(), state); err != nil {
+			return err
+		}
+		if cArgs.pauseAfterWave && state.CompletedWaves < len(state.Waves) {
+			fmt.Fprintf(w, "Pausing after wave %d, run the command again to continue\n", state.CompletedWaves)
+			return nil
+		}
+	}
+
+	state.Phase = canaryPhaseComplete
+	if err := saveCanaryState(ctx, client.Kube(), state); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Canary upgrade to revision %q complete\n", state.Revision)
+	return nil
+}
+
+// installCanaryRevision installs the new control plane revision with `istioctl install`.
+func installCanaryRevision(cArgs *canaryUpgradeArgs, w io.Writer) error {
+	args := []string{"--revision", cArgs.revision, "--skip-confirmation", "--readiness-timeout", cArgs.readinessTimeout.String()}
+	for _, f := range cArgs.inFilenames {
+		args = append(args, "--filename", f)
+	}
+	for _, s := range cArgs.set {
+		args = append(args, "--set", s)
+	}
+	if manifestsPath != "" {
+		args = append(args, "--manifests", manifestsPath)
+	}
+	if kubeconfig != "" {
+		args = append(args, "--kubeconfig", kubeconfig)
+	}
+	if configContext != "" {
+		args = append(args, "--context", configContext)
+	}
+	installCmd := mesh.InstallCmd(loggingOptions)
+	installCmd.SetArgs(args)
+	installCmd.SetOut(w)
+	if err := installCmd.Execute(); err != nil {
+		return fmt.Errorf("failed to install revision %q: %v", cArgs.revision, err)
+	}
+	return nil
+}
+
+// moveCanaryWave points the namespaces and tags of the wave at the new revision and restarts the affected workloads.
+// The previous labels and tag revisions are recorded in the state before anything is changed.
+func moveCanaryWave(ctx context.Context, client kube.ExtendedClient, state *canaryUpgradeState, wave canaryWave, w io.Writer) error {
+	for _, ns := range wave.Namespaces {
+		if _, ok := state.Namespaces[ns]; !ok {
+			prev, err := namespaceInjection(ctx, client.Kube(), ns)
+			if err != nil {
+				return err
+			}
+			state.Namespaces[ns] = prev
+		}
+	}
+	for _, tag := range wave.Tags {
+		if _, ok := state.Tags[tag]; ok {
+			continue
+		}
+		whs, err := getWebhooksWithTag(ctx, client.Kube(), tag)
+		if err != nil {
+			return err
+		}
+		if len(whs) == 0 {
+			return fmt.Errorf("revision tag %q does not exist", tag)
+		}
+		prev, err := getWebhookRevision(whs[0])
+		if err != nil {
+			return err
+		}
+		state.Tags[tag] = prev
+	}
+	if err := saveCanaryState(ctx, client.Kube(), state); err != nil {
+		return err
+	}
+
+	for _, ns := range wave.Namespaces {
+		if err := setNamespaceInjection(ctx, client.Kube(), ns, namespaceInjectionLabels{Revision: state.Revision}); err != nil {
+			return err
+		}
+	}
+	for _, tag := range wave.Tags {
+		if err := setTag(ctx, client, tag, state.Revision, false, true, w); err != nil {
+			return err
+		}
+	}
+
+	namespaces, err := canaryWaveNamespaces(ctx, client.Kube(), wave)
+	if err != nil {
+		return err
+	}
+	return restartWorkloads(ctx, client.Kube(), namespaces, w)
+}
+
+// canaryWaveNamespaces returns the namespaces of the wave, including namespaces injected through its tags.
+func canaryWaveNamespaces(ctx context.Context, client kubernetes.Interface, wave canaryWave) ([]string, error) {
+	namespaces := append([]string{}, wave.Namespaces...)
+	for _, tag := range wave.Tags {
+		tagged, err := getNamespacesWithTag(ctx, client, tag)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve namespaces dependent on tag %q: %v", tag, err)
+		}
+		namespaces = append(namespaces, tagged...)
+	}
+	return namespaces, nil
+}
+
+func namespaceInjection(ctx context.Context, client kubernetes.Interface, ns string) (namespaceInjectionLabels, error) {
+	namespace, err := client.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+	if err != nil {
+		return namespaceInjectionLabels{}, fmt.Errorf("failed to get namespace %q: %v", ns, err)
+	}
+	return namespaceInjectionLabels{
+		Revision:  namespace.Labels[label.IoIstioRev.Name],
+		Injection: namespace.Labels[injectionLabelName],
+	}, nil
+}
+
+// setNamespaceInjection replaces the injection labels of the namespace. Empty values remove the label.
+func setNamespaceInjection(ctx context.Context, client kubernetes.Interface, ns string, injection namespaceInjectionLabels) error {
+	patchValue := func(v string) interface{} {
+		if v == "" {
+			return nil
+		}
+		return v
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{
+				label.IoIstioRev.Name: patchValue(injection.Revision),
+				injectionLabelName:    patchValue(injection.Injection),
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := client.CoreV1().Namespaces().Patch(ctx, ns, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return fmt.Errorf("failed to relabel namespace %q: %v", ns, err)
+	}
+	return nil
+}
+
+// restartWorkloads triggers a rolling restart of the deployments, statefulsets and daemonsets in the namespaces,
+// the same way `kubectl rollout restart` does.
+func restartWorkloads(ctx context.Context, client kubernetes.Interface, namespaces []string, w io.Writer) error {
+	patch := []byte(fmt.Sprintf(`{"spec":{"template":{"metadata":{"annotations":{%q:%q}}}}}`,
+		restartedAtAnnotation, time.Now().Format(time.RFC3339)))
+	for _, ns := range namespaces {
+		deployments, err := client.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, d := range deployments.Items {
+			if _, err := client.AppsV1().Deployments(ns).Patch(ctx, d.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				return fmt.Errorf("failed to restart deployment %s/%s: %v", ns, d.Name, err)
+			}
+			fmt.Fprintf(w, "  restarted deployment %s/%s\n", ns, d.Name)
+		}
+		statefulSets, err := client.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, s := range statefulSets.Items {
+			if _, err := client.AppsV1().StatefulSets(ns).Patch(ctx, s.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				return fmt.Errorf("failed to restart statefulset %s/%s: %v", ns, s.Name, err)
+			}
+			fmt.Fprintf(w, "  restarted statefulset %s/%s\n", ns, s.Name)
+		}
+		daemonSets, err := client.AppsV1().DaemonSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, d := range daemonSets.Items {
+			if _, err := client.AppsV1().DaemonSets(ns).Patch(ctx, d.Name, types.StrategicMergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				return fmt.Errorf("failed to restart daemonset %s/%s: %v", ns, d.Name, err)
+			}
+			fmt.Fprintf(w, "  restarted daemonset %s/%s\n", ns, d.Name)
+		}
+	}
+	return nil
+}
+
+// gateCanaryWave waits until the workloads of the wave are rolled out and synced with the new revision,
+// then runs the analyzers on the affected namespaces.
+func gateCanaryWave(ctx context.Context, client kube.ExtendedClient, cArgs *canaryUpgradeArgs, wave canaryWave, w io.Writer) error {
+	namespaces, err := canaryWaveNamespaces(ctx, client.Kube(), wave)
+	if err != nil {
+		return err
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, cArgs.readinessTimeout)
+	defer cancel()
+
+	var lastErr error
+	for {
+		lastErr = checkWaveRolledOut(waitCtx, client.Kube(), namespaces)
+		if lastErr == nil {
+			lastErr = checkWaveSynced(waitCtx, client, namespaces)
+		}
+		if lastErr == nil {
+			break
+		}
+		select {
+		case <-waitCtx.Done():
+			return fmt.Errorf("timed out waiting for wave to become ready: %v", lastErr)
+		case <-time.After(pollInterval):
+		}
+	}
+	fmt.Fprintf(w, "  all proxies in %s are synced with revision %q\n", strings.Join(namespaces, ","), cArgs.revision)
+
+	if cArgs.skipAnalysis {
+		return nil
+	}
+	for _, ns := range namespaces {
+		if err := analyzeCanaryNamespace(ns, w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWaveRolledOut returns an error if any deployment, statefulset or daemonset in the namespaces still has pods
+// from before the restart. Statefulsets and daemonsets with the OnDelete update strategy are not restarted by the
+// template change and are skipped.
+func checkWaveRolledOut(ctx context.Context, client kubernetes.Interface, namespaces []string) error {
+	for _, ns := range namespaces {
+		deployments, err := client.AppsV1().Deployments(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, d := range deployments.Items {
+			replicas := int32(1)
+			if d.Spec.Replicas != nil {
+				replicas = *d.Spec.Replicas
+			}
+			if d.Status.ObservedGeneration < d.Generation || d.Status.UpdatedReplicas < replicas ||
+				d.Status.Replicas > d.Status.UpdatedReplicas || d.Status.AvailableReplicas < d.Status.UpdatedReplicas {
+				return fmt.Errorf("deployment %s/%s is not rolled out", ns, d.Name)
+			}
+		}
+		statefulSets, err := client.AppsV1().StatefulSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, s := range statefulSets.Items {
+			if s.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+				continue
+			}
+			replicas := int32(1)
+			if s.Spec.Replicas != nil {
+				replicas = *s.Spec.Replicas
+			}
+			if s.Status.ObservedGeneration < s.Generation || s.Status.ReadyReplicas < replicas {
+				return fmt.Errorf("statefulset %s/%s is not rolled out", ns, s.Name)
+			}
+			if ru := s.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.Partition != nil && *ru.Partition > 0 {
+				if s.Status.UpdatedReplicas < replicas-*ru.Partition {
+					return fmt.Errorf("statefulset %s/%s is not rolled out", ns, s.Name)
+				}
+			} else if s.Status.UpdateRevision != s.Status.CurrentRevision {
+				return fmt.Errorf("statefulset %s/%s is not rolled out", ns, s.Name)
+			}
+		}
+		daemonSets, err := client.AppsV1().DaemonSets(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return err
+		}
+		for _, d := range daemonSets.Items {
+			if d.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+				continue
+			}
+			if d.Status.ObservedGeneration < d.Generation || d.Status.UpdatedNumberScheduled < d.Status.DesiredNumberScheduled ||
+				d.Status.NumberAvailable < d.Status.DesiredNumberScheduled {
+				return fmt.Errorf("daemonset %s/%s is not rolled out", ns, d.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// checkWaveSynced returns an error if a proxy in the namespaces is not connected to the new revision or
+// has not acknowledged the latest push.
+func checkWaveSynced(ctx context.Context, client kube.ExtendedClient, namespaces []string) error {
+	expected, err := injectedProxyIDs(ctx, client.Kube(), namespaces)
+	if err != nil {
+		return err
+	}
+	statuses, err := client.AllDiscoveryDo(ctx, istioNamespace, "/debug/syncz")
+	if err != nil {
+		return err
+	}
+	return canaryProxiesSynced(statuses, namespaces, expected)
+}
+
+// injectedProxyIDs returns the proxy IDs of the running pods with a sidecar in the namespaces.
+func injectedProxyIDs(ctx context.Context, client kubernetes.Interface, namespaces []string) (map[string]bool, error) {
+	ids := map[string]bool{}
+	for _, ns := range namespaces {
+		pods, err := client.CoreV1().Pods(ns).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, pod := range pods.Items {
+			if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+			for _, c := range pod.Spec.Containers {
+				if c.Name == proxyContainerName {
+					ids[pod.Name+"."+pod.Namespace] = true
+					break
+				}
+			}
+		}
+	}
+	return ids, nil
+}
+
+// canaryProxiesSynced checks the syncz responses of the new revision's istiod instances. Every proxy of the
+// namespaces connected to the new revision must be synced, and every expected proxy must be connected.
+// Namespaces without proxies are synced.
+func canaryProxiesSynced(statuses map[string][]byte, namespaces []string, expected map[string]bool) error {
+	inWave := map[string]bool{}
+	for _, ns := range namespaces {
+		inWave[ns] = true
+	}
+	connected := map[string]bool{}
+	for pilot, status := range statuses {
+		var ss []xds.SyncStatus
+		if err := json.Unmarshal(status, &ss); err != nil {
+			return fmt.Errorf("failed to parse sync status from %s: %v", pilot, err)
+		}
+		for _, s := range ss {
+			parts := strings.Split(s.ProxyID, ".")
+			ns := parts[len(parts)-1]
+			if !inWave[ns] {
+				continue
+			}
+			connected[s.ProxyID] = true
+			if s.ClusterSent != s.ClusterAcked || s.ListenerSent != s.ListenerAcked ||
+				s.RouteSent != s.RouteAcked || s.EndpointSent != s.EndpointAcked {
+				return fmt.Errorf("proxy %s is not synced with %s", s.ProxyID, pilot)
+			}
+		}
+	}
+	ids := make([]string, 0, len(expected))
+	for id := range expected {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		if !connected[id] {
+			return fmt.Errorf("proxy %s is not connected to the new revision", id)
+		}
+	}
+	return nil
+}
+
+// analyzeCanaryNamespace runs the analyzers against the live cluster and fails on any error level message.
+func analyzeCanaryNamespace(ns string, w io.Writer) error {
+	restConfig, err := kube.BuildClientCmd(kubeconfig, configContext).ClientConfig()
+	if err != nil {
+		return err
+	}
+	sa := local.NewSourceAnalyzer(schema.MustGet(), analyzers.AllCombined(),
+		resource.Namespace(ns), resource.Namespace(istioNamespace), nil, true, analysisTimeout)
+	sa.AddRunningKubeSource(cfgKube.NewInterfaces(restConfig))
+	cancel := make(chan struct{})
+	result, err := sa.Analyze(cancel)
+	if err != nil {
+		return fmt.Errorf("failed to analyze namespace %q: %v", ns, err)
+	}
+	var errs []string
+	for _, m := range result.Messages {
+		if m.Type.Level().IsWorseThanOrEqualTo(diag.Error) {
+			errs = append(errs, m.String())
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("analysis of namespace %q found errors:\n%s", ns, strings.Join(errs, "\n"))
+	}
+	fmt.Fprintf(w, "  analysis of namespace %q found no errors\n", ns)
+	return nil
+}
+
+// rollbackCanaryUpgrade restores the namespaces and tags moved to the revision, most recent wave first.
+func rollbackCanaryUpgrade(ctx context.Context, client kube.ExtendedClient, cArgs *canaryUpgradeArgs, w io.Writer) error {
+	state, err := loadCanaryState(ctx, client.Kube(), cArgs.revision)
+	if err != nil {
+		return err
+	}
+	if state == nil {
+		return fmt.Errorf("no canary upgrade to revision %q found", cArgs.revision)
+	}
+	if state.Phase == canaryPhaseRolledBack {
+		fmt.Fprintf(w, "Canary upgrade to revision %q is already rolled back\n", state.Revision)
+		return nil
+	}
+
+	for i := len(state.Waves) - 1; i >= 0; i-- {
+		wave := state.Waves[i]
+		var namespaces []string
+		for _, ns := range wave.Namespaces {
+			prev, ok := state.Namespaces[ns]
+			if !ok {
+				continue
+			}
+			if err := setNamespaceInjection(ctx, client.Kube(), ns, prev); err != nil {
+				return err
+			}
+			delete(state.Namespaces, ns)
+			namespaces = append(namespaces, ns)
+		}
+		for _, tag := range wave.Tags {
+			prev, ok := state.Tags[tag]
+			if !ok {
+				continue
+			}
+			if err := setTag(ctx, client, tag, prev, false, true, w); err != nil {
+				return err
+			}
+			delete(state.Tags, tag)
+			tagged, err := getNamespacesWithTag(ctx, client.Kube(), tag)
+			if err != nil {
+				return err
+			}
+			namespaces = append(namespaces, tagged...)
+		}
+		if len(namespaces) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "Rolling back wave %d of %d\n", i+1, len(state.Waves))
+		if err := restartWorkloads(ctx, client.Kube(), namespaces, w); err != nil {
+			return err
+		}
+		if i < state.CompletedWaves {
+			state.CompletedWaves = i
+		}
+		if err := saveCanaryState(ctx, client.Kube(), state); err != nil {
+			return err
+		}
+	}
+
+	state.Phase = canaryPhaseRolledBack
+	state.CompletedWaves = 0
+	if err := saveCanaryState(ctx, client.Kube(), state); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Canary upgrade to revision %q rolled back. The revision is still installed, remove it with "+
+		"'istioctl x uninstall --revision %s'\n", state.Revision, state.Revision)
+	return nil
+}
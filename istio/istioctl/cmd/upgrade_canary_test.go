@@ -0,0 +1,309 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/api/label"
+	"istio.io/istio/pilot/pkg/xds"
+	"istio.io/istio/pkg/kube"
+)
+
+func TestParseCanaryWaves(t *testing.T) {
+	tcs := []struct {
+		name  string
+		waves []string
+		want  []canaryWave
+		error string
+	}{
+		{
+			name:  "namespaces and tags",
+			waves: []string{"tag:prod", "team-a, team-b,tag:canary"},
+			want: []canaryWave{
+				{Tags: []string{"prod"}},
+				{Namespaces: []string{"team-a", "team-b"}, Tags: []string{"canary"}},
+			},
+		},
+		{
+			name:  "duplicate namespace",
+			waves: []string{"team-a", "team-a"},
+			error: "appears in more than one wave",
+		},
+		{
+			name:  "empty wave",
+			waves: []string{" , "},
+			error: "is empty",
+		},
+		{
+			name:  "invalid tag",
+			waves: []string{"tag:Prod_1"},
+			error: "invalid revision tag",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseCanaryWaves(tc.waves)
+			if tc.error != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.error) {
+					t.Fatalf("expected error containing %q, got %v", tc.error, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("got %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+// setIstioNamespace sets the Istio namespace for the test, and restores it after.
+func setIstioNamespace(t *testing.T, ns string) {
+	prev := istioNamespace
+	istioNamespace = ns
+	t.Cleanup(func() {
+		istioNamespace = prev
+	})
+}
+
+func TestCanaryStatePersistence(t *testing.T) {
+	setIstioNamespace(t, "istio-system")
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+
+	state, err := loadCanaryState(ctx, client, "1-10-0")
+	if err != nil || state != nil {
+		t.Fatalf("expected no state, got %v, %v", state, err)
+	}
+
+	want := &canaryUpgradeState{
+		Revision:       "1-10-0",
+		Phase:          canaryPhaseWaves,
+		Waves:          []canaryWave{{Namespaces: []string{"team-a"}}, {Tags: []string{"prod"}}},
+		CompletedWaves: 1,
+		Namespaces:     map[string]namespaceInjectionLabels{"team-a": {Injection: "enabled"}},
+		Tags:           map[string]string{"prod": "1-9-0"},
+	}
+	if err := saveCanaryState(ctx, client, want); err != nil {
+		t.Fatal(err)
+	}
+	// Saving again must update the existing ConfigMap.
+	want.CompletedWaves = 2
+	if err := saveCanaryState(ctx, client, want); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadCanaryState(ctx, client, "1-10-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got.LastUpdated = want.LastUpdated
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
+
+func TestSetNamespaceInjection(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "team-a",
+			Labels: map[string]string{injectionLabelName: "enabled", "team": "a"},
+		},
+	})
+
+	prev, err := namespaceInjection(ctx, client, "team-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev != (namespaceInjectionLabels{Injection: "enabled"}) {
+		t.Fatalf("unexpected previous labels %+v", prev)
+	}
+
+	if err := setNamespaceInjection(ctx, client, "team-a", namespaceInjectionLabels{Revision: "1-10-0"}); err != nil {
+		t.Fatal(err)
+	}
+	ns, _ := client.CoreV1().Namespaces().Get(ctx, "team-a", metav1.GetOptions{})
+	want := map[string]string{label.IoIstioRev.Name: "1-10-0", "team": "a"}
+	if !reflect.DeepEqual(ns.Labels, want) {
+		t.Fatalf("got labels %v, want %v", ns.Labels, want)
+	}
+
+	if err := setNamespaceInjection(ctx, client, "team-a", prev); err != nil {
+		t.Fatal(err)
+	}
+	ns, _ = client.CoreV1().Namespaces().Get(ctx, "team-a", metav1.GetOptions{})
+	want = map[string]string{injectionLabelName: "enabled", "team": "a"}
+	if !reflect.DeepEqual(ns.Labels, want) {
+		t.Fatalf("got labels %v, want %v", ns.Labels, want)
+	}
+}
+
+func TestRestartWorkloads(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-a"}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "team-a"}},
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "team-b"}},
+	)
+	var out bytes.Buffer
+	if err := restartWorkloads(ctx, client, []string{"team-a"}, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	d, _ := client.AppsV1().Deployments("team-a").Get(ctx, "app", metav1.GetOptions{})
+	if _, ok := d.Spec.Template.Annotations[restartedAtAnnotation]; !ok {
+		t.Fatalf("deployment was not restarted")
+	}
+	s, _ := client.AppsV1().StatefulSets("team-a").Get(ctx, "db", metav1.GetOptions{})
+	if _, ok := s.Spec.Template.Annotations[restartedAtAnnotation]; !ok {
+		t.Fatalf("statefulset was not restarted")
+	}
+	o, _ := client.AppsV1().Deployments("team-b").Get(ctx, "other", metav1.GetOptions{})
+	if _, ok := o.Spec.Template.Annotations[restartedAtAnnotation]; ok {
+		t.Fatalf("deployment outside of the wave was restarted")
+	}
+}
+
+func TestCanaryProxiesSynced(t *testing.T) {
+	synced := xds.SyncStatus{
+		ProxyID:     "app-1.team-a",
+		ClusterSent: "1", ClusterAcked: "1",
+		ListenerSent: "1", ListenerAcked: "1",
+	}
+	stale := synced
+	stale.ProxyID = "app-2.team-a"
+	stale.ListenerAcked = "0"
+	other := stale
+	other.ProxyID = "app.team-b"
+
+	toSyncz := func(ss ...xds.SyncStatus) map[string][]byte {
+		by, _ := json.Marshal(ss)
+		return map[string][]byte{"istiod-1-10-0": by}
+	}
+
+	expected := map[string]bool{"app-1.team-a": true}
+
+	if err := canaryProxiesSynced(toSyncz(synced, other), []string{"team-a"}, expected); err != nil {
+		t.Fatalf("expected wave to be synced, got %v", err)
+	}
+	if err := canaryProxiesSynced(toSyncz(synced, stale), []string{"team-a"}, expected); err == nil {
+		t.Fatalf("expected stale proxy to fail the wave")
+	}
+	if err := canaryProxiesSynced(toSyncz(other), []string{"team-a"}, expected); err == nil {
+		t.Fatalf("expected proxy not connected to the new revision to fail the wave")
+	}
+	if err := canaryProxiesSynced(toSyncz(other), []string{"team-a"}, nil); err != nil {
+		t.Fatalf("expected namespace without proxies to be synced, got %v", err)
+	}
+}
+
+func TestInjectedProxyIDs(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase, containers ...string) *corev1.Pod {
+		p := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "team-a"},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+		for _, c := range containers {
+			p.Spec.Containers = append(p.Spec.Containers, corev1.Container{Name: c})
+		}
+		return p
+	}
+	client := fake.NewSimpleClientset(
+		pod("app-1", corev1.PodRunning, "app", proxyContainerName),
+		pod("app-2", corev1.PodPending, "app", proxyContainerName),
+		pod("job", corev1.PodRunning, "job"),
+	)
+	got, err := injectedProxyIDs(context.Background(), client, []string{"team-a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"app-1.team-a": true}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestCheckWaveRolledOut(t *testing.T) {
+	replicas := int32(2)
+	rolledOut := []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "team-a"},
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status:     appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "team-a"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: &replicas},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 2, UpdatedReplicas: 2, CurrentRevision: "db-2", UpdateRevision: "db-2"},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "team-a"},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 3},
+		},
+	}
+	ctx := context.Background()
+	if err := checkWaveRolledOut(ctx, fake.NewSimpleClientset(rolledOut...), []string{"team-a"}); err != nil {
+		t.Fatalf("expected wave to be rolled out, got %v", err)
+	}
+
+	statefulSet := rolledOut[1].DeepCopyObject().(*appsv1.StatefulSet)
+	statefulSet.Status.CurrentRevision = "db-1"
+	client := fake.NewSimpleClientset(rolledOut[0], statefulSet, rolledOut[2])
+	if err := checkWaveRolledOut(ctx, client, []string{"team-a"}); err == nil || !strings.Contains(err.Error(), "statefulset") {
+		t.Fatalf("expected statefulset not rolled out, got %v", err)
+	}
+
+	daemonSet := rolledOut[2].DeepCopyObject().(*appsv1.DaemonSet)
+	daemonSet.Status.UpdatedNumberScheduled = 1
+	client = fake.NewSimpleClientset(rolledOut[0], rolledOut[1], daemonSet)
+	if err := checkWaveRolledOut(ctx, client, []string{"team-a"}); err == nil || !strings.Contains(err.Error(), "daemonset") {
+		t.Fatalf("expected daemonset not rolled out, got %v", err)
+	}
+}
+
+func TestResumeCanaryUpgradeWithOtherWaves(t *testing.T) {
+	setIstioNamespace(t, "istio-system")
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	state := &canaryUpgradeState{
+		Revision: "1-10-0",
+		Phase:    canaryPhaseWaves,
+		Waves:    []canaryWave{{Namespaces: []string{"team-a"}}},
+	}
+	if err := saveCanaryState(ctx, client, state); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	cArgs := &canaryUpgradeArgs{revision: "1-10-0", waves: []string{"team-b"}}
+	err := runCanaryUpgrade(ctx, kube.MockClient{Interface: client}, cArgs, &out)
+	if err == nil || !strings.Contains(err.Error(), "do not match") {
+		t.Fatalf("expected resuming with other waves to fail, got %v", err)
+	}
+}
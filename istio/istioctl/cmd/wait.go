@@ -19,8 +19,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -44,6 +47,7 @@ var (
 	timeout      time.Duration
 	generation   string
 	verbose      bool
+	report       bool
 	targetSchema collection.Schema
 	clientGetter func(string, string) (dynamic.Interface, error)
 )
@@ -62,6 +66,9 @@ func waitCmd() *cobra.Command {
 
   # Wait until 99% of the proxies receive the distribution, timing out after 5 minutes
   istioctl experimental wait --for=distribution --threshold=.99 --timeout=300 virtualservice bookinfo.default
+
+  # Wait for the distribution, then print how long it took to reach the proxies and which ones are lagging
+  istioctl experimental wait --report virtualservice bookinfo.default
 `,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			printVerbosef(cmd, "kubeconfig %s", kubeconfig)
@@ -102,6 +109,9 @@ func waitCmd() *cobra.Command {
 				} else if float32(present)/float32(present+notpresent) >= threshold {
 					_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Resource %s present on %d out of %d sidecars\n",
 						targetResource, present, present+notpresent)
+					if report {
+						return printDistributionReport(cmd.OutOrStdout(), targetResource, opts)
+					}
 					return nil
 				}
 				select {
@@ -117,6 +127,11 @@ func waitCmd() *cobra.Command {
 					printVerbosef(cmd, "timeout")
 					// I think this means the timeout has happened:
 					t.Stop()
+					if report {
+						if err := printDistributionReport(cmd.OutOrStdout(), targetResource, opts); err != nil {
+							printVerbosef(cmd, "unable to report distribution latency: %v", err)
+						}
+					}
 					return fmt.Errorf("timeout expired before resource %s became effective on all sidecars",
 						targetResource)
 				}
@@ -139,6 +154,9 @@ func waitCmd() *cobra.Command {
 	cmd.PersistentFlags().StringVar(&generation, "generation", "",
 		"Wait for a specific generation of config to become current, rather than using whatever is latest in "+
 			"Kubernetes")
+	cmd.PersistentFlags().BoolVar(&report, "report", false,
+		"Once done waiting, print the percentiles of the time the resource took to reach the sidecars, and the "+
+			"sidecars that have not received it")
 	cmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "enables verbose output")
 	_ = cmd.PersistentFlags().MarkHidden("verbose")
 	opts.AttachControlPlaneFlags(cmd)
@@ -210,6 +228,84 @@ func poll(cmd *cobra.Command, acceptedVersions []string, targetResource string,
 	return present, notpresent, nil
 }
 
+// printDistributionReport prints the distribution latency of the target resource reported by all the Istiod
+// instances.
+func printDistributionReport(w io.Writer, targetResource string, opts clioptions.ControlPlaneOptions) error {
+	kubeClient, err := kubeClientWithRevision(kubeconfig, configContext, opts.Revision)
+	if err != nil {
+		return err
+	}
+	path := fmt.Sprintf("/debug/distribution_latency?resource=%s", targetResource)
+	pilotResponses, err := kubeClient.AllDiscoveryDo(context.TODO(), istioNamespace, path)
+	if err != nil {
+		return fmt.Errorf("unable to query pilot for distribution latency "+
+			"(are you using pilot version >= 1.10 with config distribution tracking on): %s", err)
+	}
+	var latencies []xds.DistributionLatency
+	for _, response := range pilotResponses {
+		var l []xds.DistributionLatency
+		if err := json.Unmarshal(response, &l); err != nil {
+			return err
+		}
+		latencies = append(latencies, l...)
+	}
+	writeDistributionReport(w, targetResource, latencies)
+	return nil
+}
+
+// writeDistributionReport merges the latencies of the latest generation of the resource reported by the Istiod
+// instances, and writes their percentiles and stragglers.
+func writeDistributionReport(w io.Writer, targetResource string, latencies []xds.DistributionLatency) {
+	latest := int64(-1)
+	for _, l := range latencies {
+		if g, err := strconv.ParseInt(l.Generation, 10, 64); err == nil && g > latest {
+			latest = g
+		}
+	}
+	var acked []time.Duration
+	var stragglers []string
+	for _, l := range latencies {
+		if l.Generation != strconv.FormatInt(latest, 10) {
+			continue
+		}
+		acked = append(acked, l.Latencies...)
+		stragglers = append(stragglers, l.Stragglers...)
+	}
+	if latest < 0 || len(acked)+len(stragglers) == 0 {
+		_, _ = fmt.Fprintf(w, "No distribution latency recorded for %s\n", targetResource)
+		return
+	}
+	sort.Slice(acked, func(i, j int) bool {
+		return acked[i] < acked[j]
+	})
+	sort.Strings(stragglers)
+	_, _ = fmt.Fprintf(w, "Distribution latency of %s generation %d, acknowledged by %d out of %d sidecars:\n",
+		targetResource, latest, len(acked), len(acked)+len(stragglers))
+	if len(acked) > 0 {
+		tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)
+		for _, p := range []int{50, 90, 99} {
+			_, _ = fmt.Fprintf(tw, "  p%d:\t%v\n", p, percentile(acked, p))
+		}
+		_, _ = fmt.Fprintf(tw, "  max:\t%v\n", acked[len(acked)-1])
+		_ = tw.Flush()
+	}
+	if len(stragglers) > 0 {
+		_, _ = fmt.Fprintf(w, "Sidecars that have not acknowledged it:\n")
+		for _, s := range stragglers {
+			_, _ = fmt.Fprintf(w, "  %s\n", s)
+		}
+	}
+}
+
+// percentile returns the nearest-rank percentile of the sorted durations.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
 func init() {
 	clientGetter = func(kubeconfig, context string) (dynamic.Interface, error) {
 		config, err := kube.DefaultRestConfig(kubeconfig, context)
@@ -15,12 +15,14 @@
 package cmd
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -83,6 +85,13 @@ func TestWaitCmd(t *testing.T) {
 			args:             strings.Split("x wait --timeout 2s --revision canary virtualservice foo.default", " "),
 			wantException:    false,
 		},
+		{
+			execClientConfig: cannedResponseMap,
+			args:             strings.Split("x wait --generation=1 --report virtualservice foo.default", " "),
+			wantException:    false,
+			expectedOutput: "Resource VirtualService/default/foo present on 3 out of 3 sidecars\n" +
+				"No distribution latency recorded for VirtualService/default/foo\n",
+		},
 	}
 
 	for i, c := range cases {
@@ -93,6 +102,49 @@ func TestWaitCmd(t *testing.T) {
 	}
 }
 
+func TestWriteDistributionReport(t *testing.T) {
+	latencies := []xds.DistributionLatency{
+		{
+			// an Istiod that has not seen the latest generation yet
+			Resource:   "VirtualService/default/foo",
+			Generation: "1",
+			Latencies:  []time.Duration{time.Hour},
+		},
+		{
+			Resource:   "VirtualService/default/foo",
+			Generation: "2",
+			Latencies:  []time.Duration{3 * time.Second, time.Second},
+			Stragglers: []string{"sidecar~b"},
+		},
+		{
+			Resource:   "VirtualService/default/foo",
+			Generation: "2",
+			Latencies:  []time.Duration{2 * time.Second},
+			Stragglers: []string{"sidecar~a"},
+		},
+	}
+	var out bytes.Buffer
+	writeDistributionReport(&out, "VirtualService/default/foo", latencies)
+	want := `Distribution latency of VirtualService/default/foo generation 2, acknowledged by 3 out of 5 sidecars:
+  p50: 2s
+  p90: 3s
+  p99: 3s
+  max: 3s
+Sidecars that have not acknowledged it:
+  sidecar~a
+  sidecar~b
+`
+	if out.String() != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	writeDistributionReport(&out, "VirtualService/default/foo", nil)
+	if want := "No distribution latency recorded for VirtualService/default/foo\n"; out.String() != want {
+		t.Fatalf("got %q, want %q", out.String(), want)
+	}
+}
+
 func setupK8Sfake() *fake.FakeDynamicClient {
 	client := fake.NewSimpleDynamicClient(runtime.NewScheme())
 	clientGetter = func(_, _ string) (dynamic.Interface, error) {
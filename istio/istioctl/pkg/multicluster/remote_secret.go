@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"sort"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -98,6 +99,15 @@ func NewCreateRemoteSecretCommand() *cobra.Command {
 
   # Create a secret access a remote cluster with an auth plugin
   istioctl --kubeconfig=c0.yaml x create-remote-secret --name c0 --auth-type=plugin --auth-plugin-name=gcp \
+    | kubectl --kubeconfig=c1.yaml apply -f -
+
+  # Create a secret to access a remote cluster with a token rotated in a file mounted in Istiod
+  istioctl --kubeconfig=c0.yaml x create-remote-secret --name c0 --auth-type=token-file \
+    --token-file=/var/run/secrets/remote/c0/token | kubectl --kubeconfig=c1.yaml apply -f -
+
+  # Create a secret to access a remote cluster with an exec credential plugin run by Istiod
+  istioctl --kubeconfig=c0.yaml x create-remote-secret --name c0 --auth-type=exec \
+    --exec-command=/usr/local/bin/aws-iam-authenticator --exec-arg=token --exec-arg=-i --exec-arg=c0 \
     | kubectl --kubeconfig=c1.yaml apply -f -`,
 		Args: cobra.NoArgs,
 		RunE: func(c *cobra.Command, args []string) error {
@@ -182,6 +192,34 @@ func createPluginKubeconfig(caData []byte, clusterName, server string, authProvi
 	return c
 }
 
+// createRotatingCredentialKubeconfig creates a kubeconfig whose credentials are refreshed by Istiod, read from a
+// token file or returned by an exec credential plugin.
+func createRotatingCredentialKubeconfig(caData []byte, clusterName, server string, authInfo *api.AuthInfo) *api.Config {
+	c := createBaseKubeconfig(caData, clusterName, server)
+	c.AuthInfos[c.CurrentContext] = authInfo
+	return c
+}
+
+func createRemoteSecretFromRotatingCredential(
+	tokenSecret *v1.Secret,
+	server, clusterName, secName string,
+	authInfo *api.AuthInfo,
+) (*v1.Secret, error) {
+	caData, ok := tokenSecret.Data[v1.ServiceAccountRootCAKey]
+	if !ok {
+		return nil, errMissingRootCAKey
+	}
+
+	// Create a Kubeconfig to access the remote cluster with credentials refreshed by Istio.
+	kubeconfig := createRotatingCredentialKubeconfig(caData, clusterName, server, authInfo)
+	if err := clientcmd.Validate(*kubeconfig); err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %v", err)
+	}
+
+	// Encode the Kubeconfig in a secret that can be loaded by Istio to dynamically discover and access the remote cluster.
+	return createRemoteServiceAccountSecret(kubeconfig, clusterName, secName)
+}
+
 func createRemoteSecretFromPlugin(
 	tokenSecret *v1.Secret,
 	server, clusterName, secName string,
@@ -442,6 +480,12 @@ const (
 	// User a custom custom authentication plugin for the remote kubernetes cluster.
 	RemoteSecretAuthTypePlugin RemoteSecretAuthType = "plugin"
 
+	// Use a token read from a file of the Istiod filesystem, re-read when the token is rotated.
+	RemoteSecretAuthTypeTokenFile RemoteSecretAuthType = "token-file"
+
+	// Use an exec credential plugin run by Istiod, run again when the credentials expire.
+	RemoteSecretAuthTypeExec RemoteSecretAuthType = "exec"
+
 	// Secret generated from remote cluster
 	SecretTypeRemote SecretType = "remote"
 
@@ -469,6 +513,13 @@ type RemoteSecretOptions struct {
 	// Authenticator plugin configuration
 	AuthPluginName   string
 	AuthPluginConfig map[string]string
+	// Path of the token file in the Istiod filesystem
+	TokenFile string
+	// Exec credential plugin configuration
+	ExecCommand    string
+	ExecArgs       []string
+	ExecEnv        map[string]string
+	ExecAPIVersion string
 
 	// Type of the generated secret
 	Type SecretType
@@ -502,7 +553,9 @@ func (o *RemoteSecretOptions) addFlags(flagset *pflag.FlagSet) {
 	flagset.StringVar(&o.SecretName, "secret-name", "",
 		"The name of the specific secret to use from the service-account. Needed when there are multiple secrets in the service account.")
 	var supportedAuthType []string
-	for _, at := range []RemoteSecretAuthType{RemoteSecretAuthTypeBearerToken, RemoteSecretAuthTypePlugin} {
+	for _, at := range []RemoteSecretAuthType{
+		RemoteSecretAuthTypeBearerToken, RemoteSecretAuthTypePlugin, RemoteSecretAuthTypeTokenFile, RemoteSecretAuthTypeExec,
+	} {
 		supportedAuthType = append(supportedAuthType, string(at))
 	}
 	var supportedSecretType []string
@@ -518,6 +571,22 @@ func (o *RemoteSecretOptions) addFlags(flagset *pflag.FlagSet) {
 	flagset.StringToString("auth-plugin-config", o.AuthPluginConfig,
 		fmt.Sprintf("Authenticator plug-in configuration. --auth-type=%v must be set with this option",
 			RemoteSecretAuthTypePlugin))
+	flagset.StringVar(&o.TokenFile, "token-file", "",
+		fmt.Sprintf("Path of the token file in the Istiod filesystem, re-read when the token is rotated. It must be in a "+
+			"directory listed in the PILOT_REMOTE_CLUSTER_CREDENTIAL_DIRS of Istiod. "+
+			"--auth-type=%v must be set with this option", RemoteSecretAuthTypeTokenFile))
+	flagset.StringVar(&o.ExecCommand, "exec-command", "",
+		fmt.Sprintf("Command run by Istiod to get credentials, which must be listed in its PILOT_REMOTE_CLUSTER_EXEC_ALLOWLIST. "+
+			"--auth-type=%v must be set with this option", RemoteSecretAuthTypeExec))
+	flagset.StringSliceVar(&o.ExecArgs, "exec-arg", nil,
+		fmt.Sprintf("Argument of the exec credential command, can be repeated. --auth-type=%v must be set with this option",
+			RemoteSecretAuthTypeExec))
+	flagset.StringToStringVar(&o.ExecEnv, "exec-env", nil,
+		fmt.Sprintf("Environment variables of the exec credential command. --auth-type=%v must be set with this option",
+			RemoteSecretAuthTypeExec))
+	flagset.StringVar(&o.ExecAPIVersion, "exec-api-version", "client.authentication.k8s.io/v1beta1",
+		fmt.Sprintf("API version of the ExecCredential returned by the exec credential command. "+
+			"--auth-type=%v must be set with this option", RemoteSecretAuthTypeExec))
 	flagset.Var(&o.Type, "type",
 		fmt.Sprintf("Type of the generated secret. supported values = %v", supportedSecretType))
 	flagset.StringVarP(&o.ManifestsPath, "manifests", "d", "", mesh.ManifestsFlagHelpStr)
@@ -585,6 +654,18 @@ func createRemoteSecret(opt RemoteSecretOptions, client kube.ExtendedClient, env
 		}
 		remoteSecret, err = createRemoteSecretFromPlugin(tokenSecret, server, opt.ClusterName, secretName,
 			authProviderConfig)
+	case RemoteSecretAuthTypeTokenFile:
+		if opt.TokenFile == "" {
+			return nil, fmt.Errorf("--token-file is required with --auth-type=%v", RemoteSecretAuthTypeTokenFile)
+		}
+		remoteSecret, err = createRemoteSecretFromRotatingCredential(tokenSecret, server, opt.ClusterName, secretName,
+			&api.AuthInfo{TokenFile: opt.TokenFile})
+	case RemoteSecretAuthTypeExec:
+		if opt.ExecCommand == "" {
+			return nil, fmt.Errorf("--exec-command is required with --auth-type=%v", RemoteSecretAuthTypeExec)
+		}
+		remoteSecret, err = createRemoteSecretFromRotatingCredential(tokenSecret, server, opt.ClusterName, secretName,
+			&api.AuthInfo{Exec: execConfig(opt)})
 	default:
 		err = fmt.Errorf("unsupported authentication type: %v", opt.AuthType)
 	}
@@ -596,6 +677,23 @@ func createRemoteSecret(opt RemoteSecretOptions, client kube.ExtendedClient, env
 	return remoteSecret, nil
 }
 
+func execConfig(opt RemoteSecretOptions) *api.ExecConfig {
+	exec := &api.ExecConfig{
+		Command:    opt.ExecCommand,
+		Args:       opt.ExecArgs,
+		APIVersion: opt.ExecAPIVersion,
+	}
+	names := make([]string, 0, len(opt.ExecEnv))
+	for name := range opt.ExecEnv {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		exec.Env = append(exec.Env, api.ExecEnvVar{Name: name, Value: opt.ExecEnv[name]})
+	}
+	return exec
+}
+
 // CreateRemoteSecret creates a remote secret with credentials of the specified service account.
 // This is useful for providing a cluster access to a remote apiserver.
 func CreateRemoteSecret(opt RemoteSecretOptions, env Environment) (string, error) {
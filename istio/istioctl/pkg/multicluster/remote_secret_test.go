@@ -777,6 +777,109 @@ users:
 	}
 }
 
+func TestCreateRemoteSecretFromRotatingCredential(t *testing.T) {
+	fakeClusterName := "fake-clusterName-0"
+	kubeconfig := func(user string) string {
+		return strings.ReplaceAll(`apiVersion: v1
+clusters:
+- cluster:
+    certificate-authority-data: Y2FEYXRh
+    server: https://1.2.3.4
+  name: {cluster}
+contexts:
+- context:
+    cluster: {cluster}
+    user: {cluster}
+  name: {cluster}
+current-context: {cluster}
+kind: Config
+preferences: {}
+users:
+- name: {cluster}
+  user:
+`+user, "{cluster}", fakeClusterName)
+	}
+
+	cases := []struct {
+		name       string
+		in         *v1.Secret
+		opt        RemoteSecretOptions
+		want       string
+		wantErrStr string
+	}{
+		{
+			name: "error on missing caData",
+			in:   makeSecret("", "", "token"),
+			opt: RemoteSecretOptions{
+				AuthType:  RemoteSecretAuthTypeTokenFile,
+				TokenFile: "/var/run/secrets/remote/token",
+			},
+			wantErrStr: errMissingRootCAKey.Error(),
+		},
+		{
+			name: "token file",
+			in:   makeSecret("", "caData", "token"),
+			opt: RemoteSecretOptions{
+				AuthType:  RemoteSecretAuthTypeTokenFile,
+				TokenFile: "/var/run/secrets/remote/token",
+			},
+			want: kubeconfig(`    tokenFile: /var/run/secrets/remote/token
+`),
+		},
+		{
+			name: "exec",
+			in:   makeSecret("", "caData", ""),
+			opt: RemoteSecretOptions{
+				AuthType:       RemoteSecretAuthTypeExec,
+				ExecCommand:    "/usr/local/bin/aws-iam-authenticator",
+				ExecArgs:       []string{"token", "-i", "c0"},
+				ExecEnv:        map[string]string{"B": "b", "A": "a"},
+				ExecAPIVersion: "client.authentication.k8s.io/v1beta1",
+			},
+			want: kubeconfig(`    exec:
+      apiVersion: client.authentication.k8s.io/v1beta1
+      args:
+      - token
+      - -i
+      - c0
+      command: /usr/local/bin/aws-iam-authenticator
+      env:
+      - name: A
+        value: a
+      - name: B
+        value: b
+      provideClusterInfo: false
+`),
+		},
+	}
+
+	for i := range cases {
+		c := &cases[i]
+		secName := remoteSecretNameFromClusterName(fakeClusterName)
+		t.Run(fmt.Sprintf("[%v] %v", i, c.name), func(tt *testing.T) {
+			authInfo := &api.AuthInfo{TokenFile: c.opt.TokenFile}
+			if c.opt.AuthType == RemoteSecretAuthTypeExec {
+				authInfo = &api.AuthInfo{Exec: execConfig(c.opt)}
+			}
+			got, err := createRemoteSecretFromRotatingCredential(c.in, "https://1.2.3.4", fakeClusterName, secName, authInfo)
+			if c.wantErrStr != "" {
+				if err == nil {
+					tt.Fatalf("wanted error including %q but none", c.wantErrStr)
+				} else if !strings.Contains(err.Error(), c.wantErrStr) {
+					tt.Fatalf("wanted error including %q but %v", c.wantErrStr, err)
+				}
+				return
+			}
+			if err != nil {
+				tt.Fatalf("wanted non-error but got %q", err)
+			}
+			if diff := cmp.Diff(string(got.Data[fakeClusterName]), c.want); diff != "" {
+				tt.Fatalf("got %v\nwant %v\ndiff %v", string(got.Data[fakeClusterName]), c.want, diff)
+			}
+		})
+	}
+}
+
 func TestRemoteSecretOptions(t *testing.T) {
 	g := NewWithT(t)
 
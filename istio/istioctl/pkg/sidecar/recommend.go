@@ -0,0 +1,248 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sidecar recommends Sidecar resources limiting the egress of workloads to the services they use.
+package sidecar
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/istioctl/pkg/util/configdump"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
+)
+
+const (
+	requestsStat    = "upstream_rq_total"
+	connectionsStat = "upstream_cx_total"
+)
+
+// StatsFilter is the filter of the Envoy stats needed to compute the usage of outbound clusters.
+var StatsFilter = `^cluster\.outbound\|.*\.(` + requestsStat + `|` + connectionsStat + `)$`
+
+// perPodLabels are the labels differing between the pods of a workload, they are not used in its selector.
+var perPodLabels = map[string]bool{
+	"pod-template-hash":                  true,
+	"controller-revision-hash":           true,
+	"statefulset.kubernetes.io/pod-name": true,
+}
+
+// HostUsage is the observed usage of an outbound service.
+type HostUsage struct {
+	// Requests is the number of HTTP requests sent to the service.
+	Requests uint64
+	// Connections is the number of connections opened to the service.
+	Connections uint64
+}
+
+// Workload is the observed usage of the outbound services by the pods of a workload.
+type Workload struct {
+	Name      string
+	Namespace string
+	// Selector holds the labels shared by all pods of the workload.
+	Selector map[string]string
+	// Pods are the names of the pods of the workload.
+	Pods  []string
+	Hosts map[host.Name]*HostUsage
+}
+
+// NewWorkload creates a workload with no observed usage.
+func NewWorkload(name, namespace string) *Workload {
+	return &Workload{
+		Name:      name,
+		Namespace: namespace,
+		Hosts:     map[host.Name]*HostUsage{},
+	}
+}
+
+// AddPod records the usage of the outbound services observed in the config dump and Envoy stats of a pod of
+// the workload.
+func (w *Workload) AddPod(name string, podLabels map[string]string, configDump, stats []byte) error {
+	clusters, err := outboundClusters(configDump)
+	if err != nil {
+		return fmt.Errorf("failed to parse the config dump of %s.%s: %v", name, w.Namespace, err)
+	}
+	for _, h := range clusters {
+		if _, f := w.Hosts[h]; !f {
+			w.Hosts[h] = &HostUsage{}
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(stats))
+	for scanner.Scan() {
+		clusterName, stat, value, ok := parseClusterStat(scanner.Text())
+		if !ok {
+			continue
+		}
+		usage, f := w.Hosts[clusters[clusterName]]
+		if !f {
+			continue
+		}
+		switch stat {
+		case requestsStat:
+			usage.Requests += value
+		case connectionsStat:
+			usage.Connections += value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to parse the stats of %s.%s: %v", name, w.Namespace, err)
+	}
+
+	w.Pods = append(w.Pods, name)
+	w.addSelector(podLabels)
+	return nil
+}
+
+func (w *Workload) addSelector(podLabels map[string]string) {
+	if w.Selector == nil {
+		w.Selector = map[string]string{}
+		for k, v := range podLabels {
+			if !perPodLabels[k] {
+				w.Selector[k] = v
+			}
+		}
+		return
+	}
+	for k, v := range w.Selector {
+		if podLabels[k] != v {
+			delete(w.Selector, k)
+		}
+	}
+}
+
+// UsedHosts returns the outbound services the workload sent requests or opened connections to, sorted by name.
+func (w *Workload) UsedHosts() []host.Name {
+	out := make([]host.Name, 0, len(w.Hosts))
+	for h, usage := range w.Hosts {
+		if usage.Requests > 0 || usage.Connections > 0 {
+			out = append(out, h)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
+
+// EgressHosts returns the egress hosts of a Sidecar importing the services used by the workload. A workload with no
+// observed usage has none: no Sidecar is recommended for it, as one importing no service would cut all its egress.
+func (w *Workload) EgressHosts() []string {
+	used := w.UsedHosts()
+	if len(used) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(used))
+	for _, h := range used {
+		out = append(out, egressHost(h))
+	}
+	return out
+}
+
+// Sidecar returns the spec of a Sidecar limiting the egress of the workload to the services it uses.
+func (w *Workload) Sidecar() *networking.Sidecar {
+	return &networking.Sidecar{
+		WorkloadSelector: &networking.WorkloadSelector{Labels: w.Selector},
+		Egress: []*networking.IstioEgressListener{{
+			Hosts: w.EgressHosts(),
+		}},
+	}
+}
+
+// SelectedBy returns true if the Sidecar selects all the pods of the workload, with a workload selector matching the
+// labels shared by the pods. Sidecars without a workload selector apply to the workloads of their namespace no other
+// Sidecar selects, so they are not considered.
+func (w *Workload) SelectedBy(sc *networking.Sidecar) bool {
+	selector := sc.GetWorkloadSelector().GetLabels()
+	return len(selector) > 0 && labels.Instance(selector).SubsetOf(w.Selector)
+}
+
+// egressHost returns the Sidecar egress host importing the service. Kubernetes services are only imported from
+// their namespace, other services are imported from any namespace as the one they are defined in is unknown.
+func egressHost(h host.Name) string {
+	parts := strings.Split(string(h), ".")
+	if len(parts) > 3 && parts[2] == "svc" {
+		return parts[1] + "/" + string(h)
+	}
+	return "*/" + string(h)
+}
+
+// outboundClusters returns the hosts of the outbound clusters of the config dump, keyed by cluster name.
+func outboundClusters(configDump []byte) (map[string]host.Name, error) {
+	dump := &configdump.Wrapper{}
+	if err := dump.UnmarshalJSON(configDump); err != nil {
+		return nil, err
+	}
+	clusterDump, err := dump.GetDynamicClusterDump(false)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]host.Name)
+	for _, dc := range clusterDump.DynamicActiveClusters {
+		c := &cluster.Cluster{}
+		if err := ptypes.UnmarshalAny(dc.Cluster, c); err != nil {
+			return nil, err
+		}
+		direction, _, hostname, _ := model.ParseSubsetKey(c.Name)
+		if direction != model.TrafficDirectionOutbound || hostname == "" {
+			continue
+		}
+		out[c.Name] = hostname
+	}
+	return out, nil
+}
+
+// parseClusterStat parses a line of the Envoy stats, of the form cluster.<cluster name>.<stat>: <value>.
+func parseClusterStat(line string) (clusterName, stat string, value uint64, ok bool) {
+	parts := strings.SplitN(line, ": ", 2)
+	if len(parts) != 2 || !strings.HasPrefix(parts[0], "cluster.") {
+		return "", "", 0, false
+	}
+	value, err := strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 64)
+	if err != nil {
+		return "", "", 0, false
+	}
+	name := strings.TrimPrefix(parts[0], "cluster.")
+	i := strings.LastIndex(name, ".")
+	if i < 0 {
+		return "", "", 0, false
+	}
+	return name[:i], name[i+1:], value, true
+}
+
+// ConfigSize returns the size in bytes of the clusters, listeners and routes of a config dump.
+func ConfigSize(dump *configdump.Wrapper) (int, error) {
+	clusters, err := dump.GetDynamicClusterDump(true)
+	if err != nil {
+		return 0, err
+	}
+	listeners, err := dump.GetDynamicListenerDump(true)
+	if err != nil {
+		return 0, err
+	}
+	routes, err := dump.GetDynamicRouteDump(true)
+	if err != nil {
+		return 0, err
+	}
+	return proto.Size(clusters) + proto.Size(listeners) + proto.Size(routes), nil
+}
@@ -0,0 +1,154 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sidecar
+
+import (
+	"reflect"
+	"testing"
+
+	adminapi "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	"github.com/golang/protobuf/ptypes/any"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/istioctl/pkg/util/configdump"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config/host"
+)
+
+func configDump(t *testing.T, clusterNames ...string) []byte {
+	t.Helper()
+	clusters := &adminapi.ClustersConfigDump{}
+	for _, name := range clusterNames {
+		clusters.DynamicActiveClusters = append(clusters.DynamicActiveClusters, &adminapi.ClustersConfigDump_DynamicCluster{
+			Cluster: util.MessageToAny(&cluster.Cluster{Name: name}),
+		})
+	}
+	dump := &configdump.Wrapper{ConfigDump: &adminapi.ConfigDump{Configs: []*any.Any{util.MessageToAny(clusters)}}}
+	out, err := dump.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestWorkload(t *testing.T) {
+	dump := configDump(t,
+		"BlackHoleCluster",
+		"inbound|9080||",
+		"outbound|9080||reviews.default.svc.cluster.local",
+		"outbound|9080|v1|reviews.default.svc.cluster.local",
+		"outbound|9080||ratings.other.svc.cluster.local",
+		"outbound|443||api.example.com",
+		"outbound|80||unused.default.svc.cluster.local",
+	)
+
+	w := NewWorkload("productpage", "default")
+	if err := w.AddPod("productpage-1", map[string]string{"app": "productpage", "pod-template-hash": "1"}, dump, []byte(`
+cluster.outbound|9080||reviews.default.svc.cluster.local.upstream_rq_total: 3
+cluster.outbound|9080|v1|reviews.default.svc.cluster.local.upstream_rq_total: 2
+cluster.outbound|9080||ratings.other.svc.cluster.local.upstream_rq_total: 0
+cluster.outbound|80||unused.default.svc.cluster.local.upstream_cx_total: 0
+cluster.inbound|9080||.upstream_rq_total: 10
+`)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddPod("productpage-2", map[string]string{"app": "productpage", "pod-template-hash": "2"}, dump, []byte(`
+cluster.outbound|9080||reviews.default.svc.cluster.local.upstream_rq_total: 1
+cluster.outbound|443||api.example.com.upstream_cx_total: 4
+`)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := w.Hosts["reviews.default.svc.cluster.local"], (&HostUsage{Requests: 6}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got reviews usage %v, want %v", got, want)
+	}
+	if got, want := w.Hosts["api.example.com"], (&HostUsage{Connections: 4}); !reflect.DeepEqual(got, want) {
+		t.Errorf("got api.example.com usage %v, want %v", got, want)
+	}
+	if got, want := w.UsedHosts(), []host.Name{"api.example.com", "reviews.default.svc.cluster.local"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got used hosts %v, want %v", got, want)
+	}
+
+	sidecar := w.Sidecar()
+	if got, want := sidecar.Egress[0].Hosts, []string{"*/api.example.com", "default/reviews.default.svc.cluster.local"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got egress hosts %v, want %v", got, want)
+	}
+	if got, want := sidecar.WorkloadSelector.Labels, map[string]string{"app": "productpage"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got selector %v, want %v", got, want)
+	}
+}
+
+func TestWorkloadNoUsage(t *testing.T) {
+	w := NewWorkload("ratings", "default")
+	if err := w.AddPod("ratings-1", map[string]string{"app": "ratings"},
+		configDump(t, "outbound|9080||reviews.default.svc.cluster.local"), nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.EgressHosts(); len(got) != 0 {
+		t.Errorf("got egress hosts %v, want none", got)
+	}
+}
+
+func TestWorkloadSelectedBy(t *testing.T) {
+	w := NewWorkload("productpage", "default")
+	w.addSelector(map[string]string{"app": "productpage", "version": "v1", "pod-template-hash": "abc"})
+	w.addSelector(map[string]string{"app": "productpage", "version": "v1", "pod-template-hash": "def"})
+	cases := []struct {
+		name     string
+		selector map[string]string
+		want     bool
+	}{
+		{"matching selector", map[string]string{"app": "productpage"}, true},
+		{"selector of the pods", map[string]string{"app": "productpage", "version": "v1"}, true},
+		{"selector of another workload", map[string]string{"app": "reviews"}, false},
+		{"selector of a single pod", map[string]string{"app": "productpage", "pod-template-hash": "abc"}, false},
+		{"namespace-wide", nil, false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &networking.Sidecar{}
+			if tt.selector != nil {
+				sc.WorkloadSelector = &networking.WorkloadSelector{Labels: tt.selector}
+			}
+			if got := w.SelectedBy(sc); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseClusterStat(t *testing.T) {
+	cases := []struct {
+		line    string
+		cluster string
+		stat    string
+		value   uint64
+		ok      bool
+	}{
+		{"cluster.outbound|80||a.b.svc.cluster.local.upstream_rq_total: 5", "outbound|80||a.b.svc.cluster.local", "upstream_rq_total", 5, true},
+		{"cluster.outbound|80||a.b.svc.cluster.local.upstream_rq_total: No recorded values", "", "", 0, false},
+		{"http.inbound_0.0.0.0_80.downstream_rq_total: 5", "", "", 0, false},
+		{"", "", "", 0, false},
+	}
+	for _, tt := range cases {
+		t.Run(tt.line, func(t *testing.T) {
+			c, s, v, ok := parseClusterStat(tt.line)
+			if c != tt.cluster || s != tt.stat || v != tt.value || ok != tt.ok {
+				t.Errorf("got %q %q %v %v, want %q %q %v %v", c, s, v, ok, tt.cluster, tt.stat, tt.value, tt.ok)
+			}
+		})
+	}
+}
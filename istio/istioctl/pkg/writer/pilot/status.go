@@ -67,7 +67,10 @@ func (s *StatusWriter) PrintAll(statuses map[string][]byte) error {
 			return err
 		}
 	}
-	return w.Flush()
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return nacksPrintln(s.Writer, fullStatus)
 }
 
 // PrintSingle takes a slice of Pilot syncz responses and outputs them using a tabwriter filtering for a specific pod
@@ -76,14 +79,19 @@ func (s *StatusWriter) PrintSingle(statuses map[string][]byte, proxyName string)
 	if err != nil {
 		return err
 	}
+	var matched []*writerStatus
 	for _, status := range fullStatus {
 		if strings.Contains(status.ProxyID, proxyName) {
 			if err := statusPrintln(w, status); err != nil {
 				return err
 			}
+			matched = append(matched, status)
 		}
 	}
-	return w.Flush()
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return nacksPrintln(s.Writer, matched)
 }
 
 func (s *StatusWriter) setupStatusPrint(statuses map[string][]byte) (*tabwriter.Writer, []*writerStatus, error) {
@@ -108,10 +116,10 @@ func (s *StatusWriter) setupStatusPrint(statuses map[string][]byte) (*tabwriter.
 }
 
 func statusPrintln(w io.Writer, status *writerStatus) error {
-	clusterSynced := xdsStatus(status.ClusterSent, status.ClusterAcked)
-	listenerSynced := xdsStatus(status.ListenerSent, status.ListenerAcked)
-	routeSynced := xdsStatus(status.RouteSent, status.RouteAcked)
-	endpointSynced := xdsStatus(status.EndpointSent, status.EndpointAcked)
+	clusterSynced := xdsStatus(status.ClusterSent, status.ClusterAcked, status.Nacks["CDS"])
+	listenerSynced := xdsStatus(status.ListenerSent, status.ListenerAcked, status.Nacks["LDS"])
+	routeSynced := xdsStatus(status.RouteSent, status.RouteAcked, status.Nacks["RDS"])
+	endpointSynced := xdsStatus(status.EndpointSent, status.EndpointAcked, status.Nacks["EDS"])
 	version := status.IstioVersion
 	if version == "" {
 		// If we can't find an Istio version (talking to a 1.1 pilot), fallback to the proxy version
@@ -124,13 +132,16 @@ func statusPrintln(w io.Writer, status *writerStatus) error {
 	return nil
 }
 
-func xdsStatus(sent, acked string) string {
+func xdsStatus(sent, acked string, nack *xds.NackStatus) string {
 	if sent == "" {
 		return "NOT SENT"
 	}
 	if sent == acked {
 		return "SYNCED"
 	}
+	if nack != nil && nack.Active && nack.Nonce == sent {
+		return "NACKED"
+	}
 	// acked will be empty string when there is never Acknowledged
 	if acked == "" {
 		return "STALE (Never Acknowledged)"
@@ -139,6 +150,38 @@ func xdsStatus(sent, acked string) string {
 	return "STALE"
 }
 
+// nacksPrintln prints the reason of the messages currently rejected by the proxies, if any.
+func nacksPrintln(w io.Writer, statuses []*writerStatus) error {
+	header := false
+	for _, status := range statuses {
+		types := make([]string, 0, len(status.Nacks))
+		for t, nack := range status.Nacks {
+			if nack.Active {
+				types = append(types, t)
+			}
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			if !header {
+				if _, err := fmt.Fprintln(w, "\nRejected configuration:"); err != nil {
+					return err
+				}
+				header = true
+			}
+			nack := status.Nacks[t]
+			if _, err := fmt.Fprintf(w, "%v %v: %v\n", status.ProxyID, t, nack.Message); err != nil {
+				return err
+			}
+			if len(nack.Configs) > 0 {
+				if _, err := fmt.Fprintf(w, "    caused by changes to: %v\n", strings.Join(nack.Configs, ", ")); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
 // PrintAll takes a slice of Istiod syncz responses and outputs them using a tabwriter
 func (s *XdsStatusWriter) PrintAll(statuses map[string]*xdsapi.DiscoveryResponse) error {
 	w, fullStatus, err := s.setupStatusPrint(statuses)
@@ -206,13 +249,13 @@ func getSyncStatus(configs []*xdsstatus.PerXdsConfig) (cds, lds, eds, rds string
 	for _, config := range configs {
 		switch val := config.PerXdsConfig.(type) {
 		case *xdsstatus.PerXdsConfig_ListenerConfig:
-			lds = config.Status.String()
+			lds = configStatus(config.Status)
 		case *xdsstatus.PerXdsConfig_ClusterConfig:
-			cds = config.Status.String()
+			cds = configStatus(config.Status)
 		case *xdsstatus.PerXdsConfig_RouteConfig:
-			rds = config.Status.String()
+			rds = configStatus(config.Status)
 		case *xdsstatus.PerXdsConfig_EndpointConfig:
-			eds = config.Status.String()
+			eds = configStatus(config.Status)
 		case *xdsstatus.PerXdsConfig_ScopedRouteConfig:
 			// ignore; Istiod doesn't send these
 		default:
@@ -221,3 +264,11 @@ func getSyncStatus(configs []*xdsstatus.PerXdsConfig) (cds, lds, eds, rds string
 	}
 	return
 }
+
+func configStatus(status xdsstatus.ConfigStatus) string {
+	if status == xdsstatus.ConfigStatus_ERROR {
+		// Istiod reports the config rejected by the proxy as an error
+		return "NACKED"
+	}
+	return status.String()
+}
@@ -117,6 +117,14 @@ func TestStatusWriter_PrintSingle(t *testing.T) {
 			filterPod: "proxy2",
 			want:      "testdata/singleStatus.txt",
 		},
+		{
+			name: "prints the reason of rejected configuration",
+			input: map[string][]xds.SyncStatus{
+				"istiod2": statusInputNacked(),
+			},
+			filterPod: "proxy2",
+			want:      "testdata/singleStatusNacked.txt",
+		},
 		{
 			name: "fallback to proxy version",
 			input: map[string][]xds.SyncStatus{
@@ -225,3 +233,32 @@ func statusInputProxyVersion() []xds.SyncStatus {
 		},
 	}
 }
+
+func statusInputNacked() []xds.SyncStatus {
+	return []xds.SyncStatus{
+		{
+			ProxyID:       "proxy2",
+			IstioVersion:  "1.1",
+			ClusterSent:   preDefinedNonce,
+			ClusterAcked:  preDefinedNonce,
+			ListenerSent:  preDefinedNonce,
+			ListenerAcked: preDefinedNonce,
+			EndpointSent:  preDefinedNonce,
+			EndpointAcked: preDefinedNonce,
+			RouteSent:     preDefinedNonce,
+			RouteAcked:    newNonce(),
+			Nacks: map[string]*xds.NackStatus{
+				"RDS": {
+					Nonce:   preDefinedNonce,
+					Message: "invalid route",
+					Configs: []string{"VirtualService/default/foo"},
+					Active:  true,
+				},
+				"LDS": {
+					Nonce:   newNonce(),
+					Message: "no longer rejected",
+				},
+			},
+		},
+	}
+}
@@ -32,6 +32,7 @@ import (
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/config/validation"
+	"istio.io/istio/pkg/util/gogoprotomarshal"
 	"istio.io/pkg/log"
 )
 
@@ -90,6 +91,22 @@ func ConstructProxyConfig(meshConfigFile, serviceCluster, proxyConfigEnv string,
 	return applyAnnotations(proxyConfig, annotations), nil
 }
 
+// PinnedProxyConfig returns the fields set by the proxy.istio.io/config annotation of the pod. They take precedence
+// over the ProxyConfig resources istiod pushes to the running proxy.
+func PinnedProxyConfig() *meshconfig.ProxyConfig {
+	pc := &meshconfig.ProxyConfig{}
+	annotations, err := readPodAnnotations()
+	if err != nil {
+		return pc
+	}
+	if v := annotations[annotation.ProxyConfig.Name]; v != "" {
+		if err := gogoprotomarshal.ApplyYAML(v, pc); err != nil {
+			log.Warnf("failed to parse proxy config annotation: %v", err)
+		}
+	}
+	return pc
+}
+
 // determineConcurrencyOption determines the correct setting for --concurrency based on CPU requests/limits
 func determineConcurrencyOption() *types.Int32Value {
 	// If limit is set, us that
@@ -40,8 +40,10 @@ import (
 	"istio.io/istio/pkg/jwt"
 	"istio.io/istio/pkg/security"
 	"istio.io/istio/pkg/util/gogoprotomarshal"
+	"istio.io/istio/security/pkg/credentialfetcher"
 	stsserver "istio.io/istio/security/pkg/stsservice/server"
 	"istio.io/istio/security/pkg/stsservice/tokenmanager"
+	"istio.io/istio/security/pkg/stsservice/tokenmanager/oauth"
 	cleaniptables "istio.io/istio/tools/istio-clean-iptables/pkg/cmd"
 	iptables "istio.io/istio/tools/istio-iptables/pkg/cmd"
 	"istio.io/pkg/collateral"
@@ -127,9 +129,40 @@ var (
 	eccSigAlgEnv        = env.RegisterStringVar("ECC_SIGNATURE_ALGORITHM", "", "The type of ECC signature algorithm to use when generating private keys").Get()
 	fileMountedCertsEnv = env.RegisterBoolVar("FILE_MOUNTED_CERTS", false, "").Get()
 	credFetcherTypeEnv  = env.RegisterStringVar("CREDENTIAL_FETCHER_TYPE", "",
-		"The type of the credential fetcher. Currently supported types include GoogleComputeEngine").Get()
+		"The type of the credential fetcher. Currently supported types include GoogleComputeEngine, "+
+			"TokenFile and InstanceMetadata").Get()
 	credIdentityProvider = env.RegisterStringVar("CREDENTIAL_IDENTITY_PROVIDER", "GoogleComputeEngine",
 		"The identity provider for credential. Currently default supported identity provider is GoogleComputeEngine").Get()
+	credTokenFileEnv = env.RegisterStringVar("CREDENTIAL_TOKEN_FILE", "",
+		"The token file read by the TokenFile credential fetcher. The token is reloaded when the file changes.").Get()
+	credMetadataURLEnv = env.RegisterStringVar("CREDENTIAL_METADATA_URL", "",
+		"The URL of the local metadata endpoint the InstanceMetadata credential fetcher gets the token from. "+
+			"{{audience}} in the URL is replaced by CREDENTIAL_METADATA_AUDIENCE.").Get()
+	credMetadataHeadersEnv = env.RegisterStringVar("CREDENTIAL_METADATA_HEADERS", "",
+		"Comma separated list of 'Name: value' headers sent to CREDENTIAL_METADATA_URL.").Get()
+	credMetadataAudienceEnv = env.RegisterStringVar("CREDENTIAL_METADATA_AUDIENCE", "",
+		"The audience of the token requested from CREDENTIAL_METADATA_URL. Defaults to the trust domain.").Get()
+	credMetadataTokenFieldEnv = env.RegisterStringVar("CREDENTIAL_METADATA_TOKEN_FIELD", "",
+		"The field of the JSON response of CREDENTIAL_METADATA_URL holding the token. "+
+			"If unset, the response body is the token.").Get()
+	stsTokenExchangeEndpoint = env.RegisterStringVar("STS_TOKEN_EXCHANGE_ENDPOINT", "",
+		"The token endpoint of the Security Token Service used by the OAuthTokenExchange token manager plugin.").Get()
+	stsTokenExchangeAudience = env.RegisterStringVar("STS_TOKEN_EXCHANGE_AUDIENCE", "",
+		"The audience requested from STS_TOKEN_EXCHANGE_ENDPOINT, if the STS request does not specify one.").Get()
+	stsTokenExchangeScope = env.RegisterStringVar("STS_TOKEN_EXCHANGE_SCOPE", "",
+		"The scope requested from STS_TOKEN_EXCHANGE_ENDPOINT, if the STS request does not specify one.").Get()
+	stsTokenExchangeSubjectTokenType = env.RegisterStringVar("STS_TOKEN_EXCHANGE_SUBJECT_TOKEN_TYPE", "",
+		"The type of the workload token sent to STS_TOKEN_EXCHANGE_ENDPOINT. "+
+			"Defaults to urn:ietf:params:oauth:token-type:jwt.").Get()
+	stsTokenExchangeClientAuth = env.RegisterStringVar("STS_TOKEN_EXCHANGE_CLIENT_AUTH", "",
+		"How the agent authenticates to STS_TOKEN_EXCHANGE_ENDPOINT: none, client_secret_basic or "+
+			"client_secret_post. Defaults to client_secret_basic if STS_TOKEN_EXCHANGE_CLIENT_ID is set.").Get()
+	stsTokenExchangeClientID = env.RegisterStringVar("STS_TOKEN_EXCHANGE_CLIENT_ID", "",
+		"The client ID used to authenticate to STS_TOKEN_EXCHANGE_ENDPOINT.").Get()
+	stsTokenExchangeClientSecretFile = env.RegisterStringVar("STS_TOKEN_EXCHANGE_CLIENT_SECRET_FILE", "",
+		"The file holding the client secret used to authenticate to STS_TOKEN_EXCHANGE_ENDPOINT.").Get()
+	stsTokenExchangeCACertFile = env.RegisterStringVar("STS_TOKEN_EXCHANGE_CA_CERT_FILE", "",
+		"The CA certificates verifying STS_TOKEN_EXCHANGE_ENDPOINT. If unset, the system roots are used.").Get()
 	proxyXDSViaAgent = env.RegisterBoolVar("PROXY_XDS_VIA_AGENT", true,
 		"If set to true, envoy will proxy XDS calls via the agent instead of directly connecting to istiod. This option "+
 			"will be removed once the feature is stabilized.").Get()
@@ -237,8 +270,18 @@ var (
 				SecretTTL:                      secretTTLEnv,
 				SecretRotationGracePeriodRatio: secretRotationGracePeriodRatioEnv,
 			}
+			credMetadataHeaders, err := credentialfetcher.ParseHeaders(credMetadataHeadersEnv)
+			if err != nil {
+				return fmt.Errorf("invalid CREDENTIAL_METADATA_HEADERS: %v", err)
+			}
 			secOpts, err := secopt.SetupSecurityOptions(proxyConfig, sop, jwtPolicy.Get(),
-				credFetcherTypeEnv, credIdentityProvider)
+				credFetcherTypeEnv, credIdentityProvider, credentialfetcher.Config{
+					TokenFile:          credTokenFileEnv,
+					MetadataURL:        credMetadataURLEnv,
+					MetadataHeaders:    credMetadataHeaders,
+					MetadataAudience:   credMetadataAudienceEnv,
+					MetadataTokenField: credMetadataTokenFieldEnv,
+				})
 			if err != nil {
 				return err
 			}
@@ -246,7 +289,20 @@ var (
 			if stsPort > 0 || xdsAuthProvider.Get() != "" {
 				// tokenManager is gcp token manager when using the default token manager plugin.
 				tokenManager = tokenmanager.CreateTokenManager(tokenManagerPlugin,
-					tokenmanager.Config{CredFetcher: secOpts.CredFetcher, TrustDomain: secOpts.TrustDomain})
+					tokenmanager.Config{
+						CredFetcher: secOpts.CredFetcher,
+						TrustDomain: secOpts.TrustDomain,
+						OAuth: oauth.Config{
+							Endpoint:         stsTokenExchangeEndpoint,
+							Audience:         stsTokenExchangeAudience,
+							Scope:            stsTokenExchangeScope,
+							SubjectTokenType: stsTokenExchangeSubjectTokenType,
+							ClientAuth:       stsTokenExchangeClientAuth,
+							ClientID:         stsTokenExchangeClientID,
+							ClientSecretFile: stsTokenExchangeClientSecretFile,
+							CACertFile:       stsTokenExchangeCACertFile,
+						},
+					})
 			}
 			secOpts.TokenManager = tokenManager
 
@@ -284,6 +340,7 @@ var (
 				agentConfig.ProxyNamespace = podNamespace
 				agentConfig.ProxyDomain = role.DNSDomain
 			}
+			agentConfig.PinnedProxyConfig = config.PinnedProxyConfig()
 			sa := istio_agent.NewAgent(&proxyConfig, agentConfig, secOpts)
 
 			var pilotSAN []string
@@ -293,26 +350,11 @@ var (
 			}
 			log.Infof("Pilot SAN: %v", pilotSAN)
 
-			// Start in process SDS.
-			if err := sa.Start(); err != nil {
-				log.Fatala("Failed to start in-process SDS", err)
-			}
-
 			// If we are using a custom template file (for control plane proxy, for example), configure this.
 			if templateFile != "" && proxyConfig.CustomConfigFile == "" {
 				proxyConfig.ProxyBootstrapTemplatePath = templateFile
 			}
 
-			ctx, cancel := context.WithCancel(context.Background())
-			defer cancel()
-
-			// If a status port was provided, start handling status probes.
-			if proxyConfig.StatusPort > 0 {
-				if err := initStatusServer(ctx, proxyIPv6, proxyConfig); err != nil {
-					return err
-				}
-			}
-
 			provCert := sa.FindRootCAForXDS()
 			if provCert == "" {
 				// Envoy only supports load from file. If we want to use system certs, use best guess
@@ -343,6 +385,25 @@ var (
 			}
 
 			agent := envoy.NewAgent(envoyProxy, drainDuration)
+			// Hot restart Envoy with a new bootstrap when the ProxyConfig resources pushed by istiod change it.
+			agentConfig.ProxyConfigUpdated = func(pc *meshconfig.ProxyConfig) {
+				agent.Restart(pc)
+			}
+
+			// Start in process SDS.
+			if err := sa.Start(); err != nil {
+				log.Fatala("Failed to start in-process SDS", err)
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// If a status port was provided, start handling status probes.
+			if proxyConfig.StatusPort > 0 {
+				if err := initStatusServer(ctx, proxyIPv6, proxyConfig); err != nil {
+					return err
+				}
+			}
 
 			// Watcher is also kicking envoy start.
 			watcher := envoy.NewWatcher(agent.Restart)
@@ -411,7 +472,7 @@ func init() {
 	proxyCmd.PersistentFlags().IntVar(&stsPort, "stsPort", 0,
 		"HTTP Port on which to serve Security Token Service (STS). If zero, STS service will not be provided.")
 	proxyCmd.PersistentFlags().StringVar(&tokenManagerPlugin, "tokenManagerPlugin", tokenmanager.GoogleTokenExchange,
-		"Token provider specific plugin name.")
+		"Token provider specific plugin name, "+tokenmanager.GoogleTokenExchange+" or "+tokenmanager.OAuthTokenExchange+".")
 	// Flags for proxy configuration
 	proxyCmd.PersistentFlags().StringVar(&serviceCluster, "serviceCluster", constants.ServiceClusterName, "Service cluster")
 	// Log levels are provided by the library https://github.com/gabime/spdlog, used by Envoy.
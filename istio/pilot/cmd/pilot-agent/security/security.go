@@ -29,7 +29,7 @@ import (
 )
 
 func SetupSecurityOptions(proxyConfig meshconfig.ProxyConfig, secOpt security.Options, jwtPolicy,
-	credFetcherTypeEnv, credIdentityProvider string) (security.Options, error) {
+	credFetcherTypeEnv, credIdentityProvider string, credFetcherConfig credentialfetcher.Config) (security.Options, error) {
 	var jwtPath string
 	if jwtPolicy == jwt.PolicyThirdParty {
 		log.Info("JWT policy is third-party-jwt")
@@ -49,11 +49,10 @@ func SetupSecurityOptions(proxyConfig meshconfig.ProxyConfig, secOpt security.Op
 		o.CAEndpoint = proxyConfig.DiscoveryAddress
 	}
 
-	// TODO (liminw): CredFetcher is a general interface. In 1.7, we limit the use on GCE only because
-	// GCE is the only supported plugin at the moment.
-	if credFetcherTypeEnv == security.GCE {
+	if credFetcherTypeEnv != "" {
 		o.CredIdentityProvider = credIdentityProvider
-		credFetcher, err := credentialfetcher.NewCredFetcher(credFetcherTypeEnv, o.TrustDomain, jwtPath, o.CredIdentityProvider)
+		credFetcher, err := credentialfetcher.NewCredFetcher(credFetcherTypeEnv, o.TrustDomain, jwtPath,
+			o.CredIdentityProvider, credFetcherConfig)
 		if err != nil {
 			return security.Options{}, fmt.Errorf("failed to create credential fetcher: %v", err)
 		}
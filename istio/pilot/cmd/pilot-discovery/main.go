@@ -146,6 +146,14 @@ func init() {
 		"HTTP address to use for pilot's self-monitoring information")
 	discoveryCmd.PersistentFlags().BoolVar(&serverArgs.ServerOptions.EnableProfiling, "profile", true,
 		"Enable profiling via web interface host:port/debug/pprof")
+	discoveryCmd.PersistentFlags().Float64Var(&serverArgs.ServerOptions.XDSRateLimitPerIdentity, "xdsRateLimitPerIdentity",
+		serverArgs.ServerOptions.XDSRateLimitPerIdentity,
+		"The rate, in connections per second, at which each authenticated identity can open XDS streams. "+
+			"0 disables the limit. If not set, uses ${PILOT_XDS_RATE_LIMIT_PER_IDENTITY} environment variable")
+	discoveryCmd.PersistentFlags().Float64Var(&serverArgs.ServerOptions.XDSRateLimitPerIP, "xdsRateLimitPerIP",
+		serverArgs.ServerOptions.XDSRateLimitPerIP,
+		"The rate, in connections per second, at which each source IP can open XDS streams. "+
+			"0 disables the limit. If not set, uses ${PILOT_XDS_RATE_LIMIT_PER_IP} environment variable")
 
 	// Use TLS certificates if provided.
 	discoveryCmd.PersistentFlags().StringVar(&serverArgs.ServerOptions.TLSOptions.CaCertFile, "caCertFile", "",
@@ -155,6 +163,24 @@ func init() {
 	discoveryCmd.PersistentFlags().StringVar(&serverArgs.ServerOptions.TLSOptions.KeyFile, "tlsKeyFile", "",
 		"File containing the x509 private key matching --tlsCertFile")
 
+	// CA server options
+	discoveryCmd.PersistentFlags().StringVar(&serverArgs.CAServerOptions.WorkloadKeyPolicy, "workloadKeyPolicy",
+		serverArgs.CAServerOptions.WorkloadKeyPolicy,
+		"Comma separated key algorithms allowed in the workload CSRs, in order of preference, e.g. \"ECDSA-P256,RSA-3072\". "+
+			"If not set, uses ${WORKLOAD_KEY_POLICY} environment variable")
+	discoveryCmd.PersistentFlags().StringVar(&serverArgs.CAServerOptions.CAKeyPolicy, "caKeyPolicy",
+		serverArgs.CAServerOptions.CAKeyPolicy,
+		"Comma separated key algorithms allowed in the CA certificate chain, in the format of --workloadKeyPolicy. "+
+			"Istiod fails to start if its CA certificates don't comply. If not set, uses ${CA_KEY_POLICY} environment variable")
+	discoveryCmd.PersistentFlags().Float64Var(&serverArgs.CAServerOptions.RateLimitPerIdentity, "caRateLimitPerIdentity",
+		serverArgs.CAServerOptions.RateLimitPerIdentity,
+		"The rate, in requests per second, at which each authenticated identity can send CSRs. 0 disables the limit. "+
+			"If not set, uses ${PILOT_CA_RATE_LIMIT_PER_IDENTITY} environment variable")
+	discoveryCmd.PersistentFlags().Float64Var(&serverArgs.CAServerOptions.RateLimitPerIP, "caRateLimitPerIP",
+		serverArgs.CAServerOptions.RateLimitPerIP,
+		"The rate, in requests per second, at which each source IP can send CSRs. 0 disables the limit. "+
+			"If not set, uses ${PILOT_CA_RATE_LIMIT_PER_IP} environment variable")
+
 	discoveryCmd.PersistentFlags().Float32Var(&serverArgs.RegistryOptions.KubeOptions.KubernetesAPIQPS, "kubernetesApiQPS", 80.0,
 		"Maximum QPS when communicating with the kubernetes API")
 
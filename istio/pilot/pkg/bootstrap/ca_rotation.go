@@ -0,0 +1,193 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/utils/clock"
+
+	"istio.io/istio/pilot/pkg/status"
+	"istio.io/pkg/log"
+)
+
+const (
+	// caRotationReportLabel labels the ConfigMaps in which each istiod reports its plugged-in CA rotation progress.
+	caRotationReportLabel = "internal.istio.io/ca-rotation-report"
+	// caRotationReportKey is the data key of the report in the ConfigMap.
+	caRotationReportKey = "report"
+)
+
+// caRotationReport is the plugged-in CA rotation progress of an istiod replica.
+type caRotationReport struct {
+	// Roots is the SHA-256 digest of the roots the replica publishes.
+	Roots string `json:"roots"`
+	// Unconfirmed are the proxies connected to the replica which have not confirmed the roots.
+	Unconfirmed []string `json:"unconfirmed,omitempty"`
+	// Total is the number of proxies connected to the replica.
+	Total int `json:"total"`
+	// Time is when the report was written. Reports which are not refreshed belong to terminated replicas.
+	Time time.Time `json:"time"`
+}
+
+// caRotationCoordinator confirms the roots of a plugged-in CA rotation across the istiod replicas. Each replica
+// keeps its rotation state in memory and only sees the proxies connected to it, so it reports the roots it
+// publishes and the proxies which have not confirmed them in a ConfigMap. Signing switches to the new bundle
+// once the proxies of all the replicas confirmed the same roots.
+type caRotationCoordinator struct {
+	client   v1.CoreV1Interface
+	cm       *corev1.ConfigMap
+	interval time.Duration
+	clock    clock.Clock
+
+	// localRootsConfirmed returns the proxies connected to this replica which have not confirmed the roots
+	// published at or after since, and the number of connected proxies.
+	localRootsConfirmed func(since time.Time) ([]string, int)
+
+	mutex sync.Mutex
+	roots string
+	// since is when this replica started to publish the roots.
+	since time.Time
+}
+
+func newCARotationCoordinator(client v1.CoreV1Interface, namespace, podName string, interval time.Duration,
+	localRootsConfirmed func(since time.Time) ([]string, int)) *caRotationCoordinator {
+	return &caRotationCoordinator{
+		client: client,
+		cm: &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      podName + "-ca-rotation",
+				Namespace: namespace,
+				Labels:    map[string]string{caRotationReportLabel: "true"},
+			},
+			Data: map[string]string{},
+		},
+		interval:            interval,
+		clock:               clock.RealClock{},
+		localRootsConfirmed: localRootsConfirmed,
+	}
+}
+
+// RootsChanged records the roots this replica publishes.
+func (c *caRotationCoordinator) RootsChanged(roots []byte) {
+	sum := sha256.Sum256(roots)
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.roots = hex.EncodeToString(sum[:])
+	c.since = c.clock.Now()
+}
+
+// RootsConfirmed returns the proxies connected to any replica which have not confirmed the roots published by this
+// replica, and the number of proxies connected to all the replicas. A replica which publishes other roots is
+// reported as unconfirmed, so that signing does not switch before it distributes the new roots.
+func (c *caRotationCoordinator) RootsConfirmed(since time.Time) ([]string, int) {
+	c.mutex.Lock()
+	c.since = since
+	c.mutex.Unlock()
+	own := c.writeReport()
+	unconfirmed, total := own.Unconfirmed, own.Total
+
+	cms, err := c.client.ConfigMaps(c.cm.Namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.Set{caRotationReportLabel: "true"}.AsSelector().String(),
+	})
+	if err != nil {
+		// The other replicas cannot be confirmed, keep waiting for them.
+		log.Errorf("failed to list the CA rotation reports: %v", err)
+		return append(unconfirmed, "istiod replicas: "+err.Error()), total + 1
+	}
+	now := c.clock.Now()
+	for _, cm := range cms.Items {
+		if cm.Name == c.cm.Name {
+			continue
+		}
+		var peer caRotationReport
+		if err := json.Unmarshal([]byte(cm.Data[caRotationReportKey]), &peer); err != nil {
+			log.Warnf("ignoring malformed CA rotation report %s: %v", cm.Name, err)
+			continue
+		}
+		if now.Sub(peer.Time) > 3*c.interval {
+			continue
+		}
+		if peer.Roots != own.Roots {
+			unconfirmed = append(unconfirmed, cm.Name+": publishes other roots")
+			total++
+			continue
+		}
+		for _, p := range peer.Unconfirmed {
+			unconfirmed = append(unconfirmed, cm.Name+"/"+p)
+		}
+		total += peer.Total
+	}
+	return unconfirmed, total
+}
+
+// Run refreshes the report of this replica until stop is closed, and then deletes it.
+func (c *caRotationCoordinator) Run(podName string, stop <-chan struct{}) {
+	if pod, err := c.client.Pods(c.cm.Namespace).Get(context.TODO(), podName, metav1.GetOptions{}); err != nil {
+		log.Warnf("can't identify pod context for the CA rotation report: %v", err)
+	} else {
+		c.mutex.Lock()
+		c.cm.OwnerReferences = []metav1.OwnerReference{
+			*metav1.NewControllerRef(pod, schema.GroupVersionKind{Version: "v1", Kind: "Pod"}),
+		}
+		c.mutex.Unlock()
+	}
+	c.writeReport()
+	t := c.clock.Tick(c.interval)
+	for {
+		select {
+		case <-stop:
+			if err := c.client.ConfigMaps(c.cm.Namespace).Delete(context.Background(), c.cm.Name, metav1.DeleteOptions{}); err != nil {
+				log.Warnf("failed to clean up the CA rotation report: %v", err)
+			}
+			return
+		case <-t:
+			c.writeReport()
+		}
+	}
+}
+
+// writeReport writes the report of this replica, and returns it.
+func (c *caRotationCoordinator) writeReport() caRotationReport {
+	c.mutex.Lock()
+	report := caRotationReport{Roots: c.roots, Time: c.clock.Now()}
+	since := c.since
+	c.mutex.Unlock()
+	report.Unconfirmed, report.Total = c.localRootsConfirmed(since)
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		log.Errorf("failed to marshal the CA rotation report: %v", err)
+		return report
+	}
+	c.mutex.Lock()
+	c.cm.Data[caRotationReportKey] = string(data)
+	cm := c.cm.DeepCopy()
+	c.mutex.Unlock()
+	if _, err := status.CreateOrUpdateConfigMap(context.TODO(), cm, c.client.ConfigMaps(cm.Namespace)); err != nil {
+		log.Errorf("failed to write the CA rotation report: %v", err)
+	}
+	return report
+}
@@ -0,0 +1,70 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+	clock "k8s.io/utils/clock/testing"
+)
+
+func TestCARotationCoordinator(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	fakeClock := clock.NewFakeClock(time.Now())
+	var unconfirmedA, unconfirmedB []string
+	newCoordinator := func(podName string, unconfirmed *[]string) *caRotationCoordinator {
+		c := newCARotationCoordinator(client.CoreV1(), "istio-system", podName, time.Minute, func(time.Time) ([]string, int) {
+			return *unconfirmed, 2
+		})
+		c.clock = fakeClock
+		return c
+	}
+	a := newCoordinator("istiod-a", &unconfirmedA)
+	b := newCoordinator("istiod-b", &unconfirmedB)
+
+	expect := func(wantUnconfirmed []string, wantTotal int) {
+		t.Helper()
+		unconfirmed, total := a.RootsConfirmed(fakeClock.Now())
+		if !reflect.DeepEqual(unconfirmed, wantUnconfirmed) || total != wantTotal {
+			t.Fatalf("got unconfirmed %v of %d, want %v of %d", unconfirmed, total, wantUnconfirmed, wantTotal)
+		}
+	}
+
+	// Without other replicas, only the local proxies are confirmed.
+	a.RootsChanged([]byte("roots"))
+	unconfirmedA = []string{"proxy-a"}
+	expect([]string{"proxy-a"}, 2)
+
+	// The proxies of the other replicas must confirm the roots too.
+	b.RootsChanged([]byte("roots"))
+	unconfirmedA, unconfirmedB = nil, []string{"proxy-b"}
+	b.writeReport()
+	expect([]string{"istiod-b-ca-rotation/proxy-b"}, 4)
+	unconfirmedB = nil
+	b.writeReport()
+	expect(nil, 4)
+
+	// A replica publishing other roots, e.g. as the staged bundle is not yet mounted, is not confirmed.
+	b.RootsChanged([]byte("other roots"))
+	b.writeReport()
+	expect([]string{"istiod-b-ca-rotation: publishes other roots"}, 3)
+
+	// The reports of terminated replicas are ignored.
+	fakeClock.Step(4 * time.Minute)
+	expect(nil, 2)
+}
@@ -87,10 +87,12 @@ func (s *Server) initConfigController(args *PilotArgs) error {
 			ingress.NewController(s.kubeClient, s.environment.Watcher, args.RegistryOptions.KubeOptions))
 
 		s.addTerminatingStartFunc(func(stop <-chan struct{}) error {
-			leaderelection.
-				NewLeaderElection(args.Namespace, args.PodName, leaderelection.IngressController, s.kubeClient.Kube()).
+			election := leaderelection.
+				NewShardedLeaderElection(args.Namespace, args.PodName, leaderelection.IngressController, features.LeaderElectionShards, s.kubeClient.Kube())
+			election.
 				AddRunFunction(func(leaderStop <-chan struct{}) {
 					ingressSyncer := ingress.NewStatusSyncer(s.environment.Watcher, s.kubeClient)
+					ingressSyncer.SetNamespaceFilter(election.Owns)
 					// Start informers again. This fixes the case where informers for namespace do not start,
 					// as we create them only after acquiring the leader lock
 					// Note: stop here should be the overall pilot stop, NOT the leader election stop. We are
@@ -146,6 +148,16 @@ func (s *Server) initK8SConfigStore(args *PilotArgs) error {
 		return err
 	}
 	s.XDSServer.WorkloadEntryController = workloadentry.NewController(configController, args.PodName, args.KeepaliveOptions.MaxServerConnectionAge)
+	if s.XDSServer.WorkloadEntryController != nil && features.LeaderElectionShards > 1 {
+		// Split the periodic cleanup between replicas rather than have each of them clean up all namespaces.
+		election := leaderelection.NewShardedLeaderElection(args.Namespace, args.PodName, leaderelection.WorkloadEntryController,
+			features.LeaderElectionShards, s.kubeClient)
+		s.XDSServer.WorkloadEntryController.SetNamespaceFilter(election.Owns)
+		s.addTerminatingStartFunc(func(stop <-chan struct{}) error {
+			go election.Run(stop)
+			return nil
+		})
+	}
 	return nil
 }
 
@@ -271,8 +283,10 @@ func (s *Server) initStatusController(args *PilotArgs, writeStatus bool) {
 	if writeStatus {
 		s.addTerminatingStartFunc(func(stop <-chan struct{}) error {
 			controller := status.NewController(*s.kubeRestConfig, args.Namespace, s.RWConfigStore)
-			leaderelection.
-				NewLeaderElection(args.Namespace, args.PodName, leaderelection.StatusController, s.kubeClient).
+			election := leaderelection.
+				NewShardedLeaderElection(args.Namespace, args.PodName, leaderelection.StatusController, features.LeaderElectionShards, s.kubeClient)
+			controller.SetNamespaceFilter(election.Owns)
+			election.
 				AddRunFunction(func(stop <-chan struct{}) {
 					s.statusReporter.SetController(controller)
 					controller.Start(stop)
@@ -33,15 +33,21 @@ import (
 
 	"istio.io/api/security/v1beta1"
 	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/security/issuance"
 	securityModel "istio.io/istio/pilot/pkg/security/model"
 	"istio.io/istio/pilot/pkg/serviceregistry/kube/controller"
+	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/jwt"
 	kubelib "istio.io/istio/pkg/kube"
+	"istio.io/istio/pkg/ratelimit"
 	"istio.io/istio/pkg/security"
 	"istio.io/istio/security/pkg/cmd"
 	"istio.io/istio/security/pkg/pki/ca"
 	"istio.io/istio/security/pkg/pki/ra"
+	"istio.io/istio/security/pkg/pki/util"
 	caserver "istio.io/istio/security/pkg/server/ca"
 	"istio.io/istio/security/pkg/server/ca/authenticate"
 	"istio.io/pkg/env"
@@ -56,6 +62,14 @@ type caOptions struct {
 	TrustDomain    string
 	Namespace      string
 	Authenticators []security.Authenticator
+	// PodName is the name of the istiod pod, used to coordinate the plugged-in CA rotation with the other replicas.
+	PodName string
+	// WorkloadKeyPolicy restricts the keys of the workload CSRs.
+	WorkloadKeyPolicy util.KeyPolicy
+	// CAKeyPolicy restricts the keys of the CA certificate chain.
+	CAKeyPolicy util.KeyPolicy
+	// RateLimit limits the CSRs of each caller.
+	RateLimit ratelimit.Config
 }
 
 // Based on istio_ca main - removing creation of Secrets with private keys in all namespaces and install complexity.
@@ -118,6 +132,37 @@ var (
 			"Jitter selects a backoff time in seconds to start root cert rotator, "+
 			"and the back off time is below root cert check interval.")
 
+	pluggedCertRotationCheckInterval = env.RegisterDurationVar("CITADEL_PLUGGED_CERT_ROTATION_CHECK_INTERVAL",
+		time.Minute,
+		"The interval at which a plugged-in CA checks for a new bundle staged in the 'cacerts' secret, "+
+			"and advances its rotation. Setting this interval to zero or a negative value disables "+
+			"the rotation, so a new bundle is only loaded when istiod restarts.")
+
+	pluggedCertOldRootGracePeriod = env.RegisterDurationVar("CITADEL_PLUGGED_CERT_OLD_ROOT_GRACE_PERIOD",
+		0,
+		"How long the old roots of a plugged-in CA stay trusted after signing switched to a new bundle. "+
+			"Defaults to MAX_WORKLOAD_CERT_TTL.")
+
+	pluggedCertRootDistributionTimeout = env.RegisterDurationVar("CITADEL_PLUGGED_CERT_ROOT_DISTRIBUTION_TIMEOUT",
+		time.Hour,
+		"How long a plugged-in CA waits for all proxies to confirm receiving the roots of a new bundle before "+
+			"signing with the new bundle anyway. Proxies which have not confirmed are listed in /debug/carotationz. "+
+			"With several istiod replicas, the proxies connected to all the replicas must confirm the roots. "+
+			"Setting this timeout to zero or a negative value waits until all proxies confirmed.")
+
+	workloadKeyPolicy = env.RegisterStringVar("WORKLOAD_KEY_POLICY", "",
+		"Comma separated key algorithms allowed in the workload CSRs, in order of preference, e.g. "+
+			"\"ECDSA-P256,RSA-3072\". RSA-<size> allows RSA keys of at least the given size, ECDSA-P256 and "+
+			"ECDSA-P384 allow ECDSA keys on the given curve. The policy is sent to the agents, which generate "+
+			"keys of the first allowed algorithm when theirs is not allowed. If empty, any key is allowed. "+
+			"Overridden by the --workloadKeyPolicy flag.")
+
+	caKeyPolicy = env.RegisterStringVar("CA_KEY_POLICY", "",
+		"Comma separated key algorithms allowed in the CA certificate chain, in the format of WORKLOAD_KEY_POLICY. "+
+			"Istiod fails to start when its CA certificates don't comply, does not rotate in a staged plugged-in CA "+
+			"bundle which doesn't comply, and istioctl analyze reports them. If empty, any key is allowed. "+
+			"Overridden by the --caKeyPolicy flag.")
+
 	k8sInCluster = env.RegisterStringVar("KUBERNETES_SERVICE_HOST", "",
 		"Kuberenetes service host, set automatically when running in-cluster")
 
@@ -133,8 +178,23 @@ var (
 
 	// TODO: Likely to be removed and added to mesh config
 	externalCaType = env.RegisterStringVar("EXTERNAL_CA", "",
-		"External CA Integration Type. Permitted Values are ISTIOD_RA_KUBERNETES_API or "+
-			"ISTIOD_RA_ISTIO_API").Get()
+		"External CA Integration Type. Permitted Values are ISTIOD_RA_KUBERNETES_API, "+
+			"ISTIOD_RA_ISTIO_API, ISTIOD_RA_HTTP_API or ISTIOD_RA_ACME_API").Get()
+
+	// TODO: Likely to be removed and added to mesh config
+	externalCaSignURL = env.RegisterStringVar("EXTERNAL_CA_SIGN_URL", "",
+		"URL of the signing API of the external CA with ISTIOD_RA_HTTP_API, "+
+			"or of the directory of the external CA with ISTIOD_RA_ACME_API").Get()
+
+	// TODO: Likely to be removed and added to mesh config
+	externalCaRootURL = env.RegisterStringVar("EXTERNAL_CA_ROOT_URL", "",
+		"URL of the PEM encoded root certificates of the external CA with ISTIOD_RA_HTTP_API. "+
+			"Only used if the root certificates are not mounted in "+ra.DefaultExtCACertDir).Get()
+
+	// TODO: Likely to be removed and added to mesh config
+	externalCaTokenFile = env.RegisterStringVar("EXTERNAL_CA_TOKEN_FILE", "",
+		"File containing the token to authenticate to the external CA with ISTIOD_RA_HTTP_API "+
+			"or ISTIOD_RA_ACME_API").Get()
 
 	// TODO: Likely to be removed and added to mesh config
 	k8sSigner = env.RegisterStringVar("K8S_SIGNER", "",
@@ -219,11 +279,41 @@ func (s *Server) RunCA(grpc *grpc.Server, ca caserver.CertificateAuthority, opts
 		}
 	}
 
+	if s.issuancePolicy != nil {
+		caServer.IssuancePolicy = s.issuancePolicy
+	}
+	caServer.RateLimiter = ratelimit.New("ca", opts.RateLimit)
+	caServer.KeyPolicy = opts.WorkloadKeyPolicy
+
 	caServer.Register(grpc)
 
 	log.Info("Istiod CA has started")
 }
 
+// checkCAKeyPolicy checks that the keys of the CA certificate chain are allowed by the policy.
+func checkCAKeyPolicy(bundle util.KeyCertBundle, policy util.KeyPolicy) error {
+	certBytes, _, certChainBytes, rootCertBytes := bundle.GetAllPem()
+	chain := bytes.Join([][]byte{certBytes, certChainBytes, rootCertBytes}, []byte("\n"))
+	return policy.CheckPemCertificates(chain)
+}
+
+// initCertificateIssuancePolicy applies the CertificateIssuancePolicy resources to the CA server, and keeps the
+// serial numbers they deny in the workload trust bundle.
+func (s *Server) initCertificateIssuancePolicy() {
+	if s.configController == nil {
+		return
+	}
+	if _, f := s.configController.Schemas().FindByGroupVersionKind(gvk.CertificateIssuancePolicy); !f {
+		return
+	}
+	s.issuancePolicy = issuance.NewPolicy(s.configController, func() string {
+		return s.environment.Mesh().GetRootNamespace()
+	})
+	s.configController.RegisterEventHandler(gvk.CertificateIssuancePolicy, func(config.Config, config.Config, model.Event) {
+		s.workloadTrustBundle.UpdateDeniedSerialNumbers(s.issuancePolicy.DeniedSerialNumbers())
+	})
+}
+
 // detectAuthEnv will use the JWT token that is mounted in istiod to set the default audience
 // and trust domain for Istiod, if not explicitly defined.
 // K8S will use the same kind of tokens for the pods, and the value in istiod's own token is
@@ -348,6 +438,7 @@ func (s *Server) loadRemoteCACerts(caOpts *caOptions, dir string) error {
 //   which may contain multiple roots. A 'cert-chain.pem' file has the full cert chain.
 func (s *Server) createIstioCA(client corev1.CoreV1Interface, opts *caOptions) (*ca.IstioCA, error) {
 	var caOpts *ca.IstioCAOptions
+	var coordinator *caRotationCoordinator
 	var err error
 
 	// In pods, this is the optional 'cacerts' Secret.
@@ -380,7 +471,8 @@ func (s *Server) createIstioCA(client corev1.CoreV1Interface, opts *caOptions) (
 			return nil, fmt.Errorf("failed to create a self-signed istiod CA: %v", err)
 		}
 	} else {
-		if err == nil {
+		usePluggedCert := err == nil
+		if usePluggedCert {
 			log.Info("Use local CA certificate")
 		} else {
 			log.Info("Use local self-signed CA certificate")
@@ -396,11 +488,49 @@ func (s *Server) createIstioCA(client corev1.CoreV1Interface, opts *caOptions) (
 		if err != nil {
 			return nil, fmt.Errorf("failed to create an istiod CA: %v", err)
 		}
+		// New bundles staged in the 'cacerts' secret are rotated in without downtime.
+		if usePluggedCert && rootCertFile != "" {
+			rotatorConfig := &ca.PluggedCertRotatorConfig{
+				SigningCertFile:         signingCertFile,
+				SigningKeyFile:          signingKeyFile,
+				CertChainFile:           certChainFile,
+				RootCertFile:            rootCertFile,
+				CheckInterval:           pluggedCertRotationCheckInterval.Get(),
+				OldRootGracePeriod:      pluggedCertOldRootGracePeriod.Get(),
+				RootDistributionTimeout: pluggedCertRootDistributionTimeout.Get(),
+				KeyPolicy:               opts.CAKeyPolicy,
+				RootsChanged:            s.updateCARoots,
+				RootsConfirmed:          s.XDSServer.ProxyConfigUnackedSince,
+			}
+			if client != nil && opts.PodName != "" {
+				// The roots must be confirmed by the proxies connected to all the istiod replicas.
+				coordinator = newCARotationCoordinator(client, opts.Namespace, opts.PodName,
+					rotatorConfig.CheckInterval, s.XDSServer.ProxyConfigUnackedSince)
+				rotatorConfig.RootsChanged = func(roots []byte) {
+					s.updateCARoots(roots)
+					coordinator.RootsChanged(roots)
+				}
+				rotatorConfig.RootsConfirmed = coordinator.RootsConfirmed
+			}
+			caOpts.PluggedCertRotatorConfig = rotatorConfig
+		}
 	}
 	istioCA, err := ca.NewIstioCA(caOpts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create an istiod CA: %v", err)
 	}
+	if rotator := istioCA.GetPluggedCertRotator(); rotator != nil {
+		s.XDSServer.CARotationStatus = func() interface{} {
+			return rotator.Status()
+		}
+		if coordinator != nil {
+			coordinator.RootsChanged(istioCA.GetCAKeyCertBundle().GetRootCertPem())
+			s.addStartFunc(func(stop <-chan struct{}) error {
+				go coordinator.Run(opts.PodName, stop)
+				return nil
+			})
+		}
+	}
 	// TODO: provide an endpoint returning all the roots. SDS can only pull a single root in current impl.
 	// ca.go saves or uses the secret, but also writes to the configmap "istio-security", under caTLSRootCert
 	// rootCertRotatorChan channel accepts signals to stop root cert rotator for
@@ -428,6 +558,10 @@ func (s *Server) createIstioRA(client kubelib.Client,
 		VerifyAppendCA: true,
 		K8sClient:      client.CertificatesV1beta1(),
 		TrustDomain:    opts.TrustDomain,
+
+		ExternalCASignURL:   externalCaSignURL,
+		ExternalCARootURL:   externalCaRootURL,
+		ExternalCATokenFile: externalCaTokenFile,
 	}
 	return ra.NewIstioRA(raOpts)
 }
@@ -0,0 +1,91 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/security/pkg/k8s"
+	"istio.io/pkg/log"
+)
+
+const (
+	// jwksConfigMapName is the ConfigMap istiod persists the JWKS to.
+	jwksConfigMapName = "istio-jwks-cache"
+	// jwksConfigMapKey is the data key of the persisted JWKS in the ConfigMap.
+	jwksConfigMapKey = "jwks.json"
+)
+
+// configMapJwksStore persists the JWKS to a ConfigMap.
+type configMapJwksStore struct {
+	client    corev1.ConfigMapsGetter
+	namespace string
+	name      string
+}
+
+var _ model.JwksStore = &configMapJwksStore{}
+
+func (s *configMapJwksStore) Load() ([]model.PersistedJwks, error) {
+	cm, err := s.client.ConfigMaps(s.namespace).Get(context.TODO(), s.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return model.UnmarshalPersistedJwks([]byte(cm.Data[jwksConfigMapKey]))
+}
+
+func (s *configMapJwksStore) Save(jwks []model.PersistedJwks) error {
+	data, err := model.MarshalPersistedJwks(jwks)
+	if err != nil {
+		return err
+	}
+	return k8s.InsertDataToConfigMap(s.client, metav1.ObjectMeta{Name: s.name, Namespace: s.namespace},
+		map[string]string{jwksConfigMapKey: string(data)})
+}
+
+// initJwksPersistence restores the JWKS persisted by a previous istiod, and persists the JWKS fetched from now on.
+func (s *Server) initJwksPersistence(args *PilotArgs) error {
+	var store model.JwksStore
+	switch features.JwksPersistence {
+	case "":
+		return nil
+	case "file":
+		store = model.NewFileJwksStore(features.JwksPersistenceFile)
+	case "configmap":
+		if s.kubeClient == nil {
+			log.Warnf("JWKS persistence to a ConfigMap requires a Kubernetes cluster, disabling it")
+			return nil
+		}
+		store = &configMapJwksStore{client: s.kubeClient.CoreV1(), namespace: args.Namespace, name: jwksConfigMapName}
+	default:
+		return fmt.Errorf("unsupported JWKS persistence %q", features.JwksPersistence)
+	}
+	if err := model.GetJwtKeyResolver().SetStore(store); err != nil {
+		// Keep going without the persisted keys, they are fetched from the JWKS URI as usual.
+		log.Errorf("failed to restore the persisted JWKS: %v", err)
+		return nil
+	}
+	log.Infof("JWKS persistence to %s is enabled", features.JwksPersistence)
+	return nil
+}
@@ -0,0 +1,53 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/pilot/pkg/model"
+)
+
+func TestConfigMapJwksStore(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := &configMapJwksStore{client: client.CoreV1(), namespace: "istio-system", name: jwksConfigMapName}
+
+	jwks, err := store.Load()
+	if err != nil || len(jwks) != 0 {
+		t.Fatalf("Load() from a missing ConfigMap returns %v, %v", jwks, err)
+	}
+
+	want := []model.PersistedJwks{
+		{Issuer: "https://issuer.example.com", JwksURI: "https://issuer.example.com/jwks", Jwks: `{"keys": []}`, FetchedTime: time.Unix(1000, 0).UTC()},
+	}
+	for i := 0; i < 2; i++ {
+		// The second save updates the existing ConfigMap.
+		want[0].FetchedTime = want[0].FetchedTime.Add(time.Minute)
+		if err := store.Save(want); err != nil {
+			t.Fatal(err)
+		}
+		got, err := store.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Load() returns %+v, want %+v", got, want)
+		}
+	}
+}
@@ -62,6 +62,7 @@ type PilotArgs struct {
 	KeepaliveOptions   *keepalive.Options
 	ShutdownDuration   time.Duration
 	JwtRule            string
+	CAServerOptions    CAServerOptions
 }
 
 // DiscoveryServerOptions contains options for create a new discovery server instance.
@@ -93,6 +94,27 @@ type DiscoveryServerOptions struct {
 	// The listening address for secured gRPC. If the port in the address is empty or "0" (as in "127.0.0.1:" or "[::1]:0")
 	// a port number is automatically chosen.
 	SecureGRPCAddr string
+
+	// XDSRateLimitPerIdentity is the rate, in connections per second, at which each authenticated identity can open
+	// XDS streams. 0 disables the limit.
+	XDSRateLimitPerIdentity float64
+
+	// XDSRateLimitPerIP is the rate, in connections per second, at which each source IP can open XDS streams.
+	// 0 disables the limit.
+	XDSRateLimitPerIP float64
+}
+
+// CAServerOptions contains the options of the Istio CA server.
+type CAServerOptions struct {
+	// WorkloadKeyPolicy restricts the keys of the workload CSRs, e.g. "ECDSA-P256,RSA-3072".
+	WorkloadKeyPolicy string
+	// CAKeyPolicy restricts the keys of the CA certificate chain, in the format of WorkloadKeyPolicy.
+	CAKeyPolicy string
+	// RateLimitPerIdentity is the rate, in requests per second, at which each authenticated identity can send CSRs.
+	// 0 disables the limit.
+	RateLimitPerIdentity float64
+	// RateLimitPerIP is the rate, in requests per second, at which each source IP can send CSRs. 0 disables the limit.
+	RateLimitPerIP float64
 }
 
 type InjectionOptions struct {
@@ -146,6 +168,12 @@ func (p *PilotArgs) applyDefaults() {
 	p.PodName = podNameVar.Get()
 	p.Revision = RevisionVar.Get()
 	p.JwtRule = jwtRuleVar.Get()
+	p.CAServerOptions.WorkloadKeyPolicy = workloadKeyPolicy.Get()
+	p.CAServerOptions.CAKeyPolicy = caKeyPolicy.Get()
+	p.CAServerOptions.RateLimitPerIdentity = features.CARateLimitPerIdentity
+	p.CAServerOptions.RateLimitPerIP = features.CARateLimitPerIP
+	p.ServerOptions.XDSRateLimitPerIdentity = features.XDSRateLimitPerIdentity
+	p.ServerOptions.XDSRateLimitPerIP = features.XDSRateLimitPerIP
 	p.KeepaliveOptions = keepalive.DefaultOption()
 	p.RegistryOptions.DistributionTrackingEnabled = features.EnableDistributionTracking
 	p.RegistryOptions.DistributionCacheRetention = features.DistributionHistoryRetention
@@ -19,6 +19,7 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -51,6 +52,7 @@ import (
 	modelstatus "istio.io/istio/pilot/pkg/model/status"
 	"istio.io/istio/pilot/pkg/networking/plugin"
 	kubesecrets "istio.io/istio/pilot/pkg/secrets/kube"
+	"istio.io/istio/pilot/pkg/security/issuance"
 	"istio.io/istio/pilot/pkg/serviceregistry"
 	"istio.io/istio/pilot/pkg/serviceregistry/aggregate"
 	kubecontroller "istio.io/istio/pilot/pkg/serviceregistry/kube/controller"
@@ -67,11 +69,13 @@ import (
 	istiokeepalive "istio.io/istio/pkg/keepalive"
 	kubelib "istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/kube/inject"
+	"istio.io/istio/pkg/ratelimit"
 	"istio.io/istio/pkg/security"
 	"istio.io/istio/pkg/spiffe"
 	"istio.io/istio/security/pkg/k8s/chiron"
 	"istio.io/istio/security/pkg/pki/ca"
 	"istio.io/istio/security/pkg/pki/ra"
+	"istio.io/istio/security/pkg/pki/util"
 	"istio.io/istio/security/pkg/server/ca/authenticate"
 	"istio.io/istio/security/pkg/server/ca/authenticate/kubeauth"
 	"istio.io/pkg/ctrlz"
@@ -163,6 +167,8 @@ type Server struct {
 
 	// TrustAnchors for workload to workload mTLS
 	workloadTrustBundle *tb.TrustBundle
+	// issuancePolicy applies the CertificateIssuancePolicy resources to the certificates issued by the CA.
+	issuancePolicy *issuance.Policy
 	// path to the caBundle that signs the DNS certs. This should be agnostic to provider.
 	caBundlePath string
 	certMu       sync.RWMutex
@@ -212,6 +218,8 @@ func NewServer(args *PilotArgs) (*Server, error) {
 	// Initialize workload Trust Bundle before XDS Server
 	e.TrustBundle = s.workloadTrustBundle
 	s.XDSServer = xds.NewDiscoveryServer(e, args.Plugins, args.PodName)
+	s.XDSServer.RateLimiter = ratelimit.New("xds",
+		rateLimitConfig(args.Namespace, args.ServerOptions.XDSRateLimitPerIdentity, args.ServerOptions.XDSRateLimitPerIP))
 
 	if args.ShutdownDuration == 0 {
 		s.shutdownDuration = 10 * time.Second // If not specified set to 10 seconds.
@@ -244,19 +252,32 @@ func NewServer(args *PilotArgs) (*Server, error) {
 
 	s.initMeshNetworks(args, s.fileWatcher)
 	s.initMeshHandlers()
+	if err := s.initJwksPersistence(args); err != nil {
+		return nil, fmt.Errorf("error initializing JWKS persistence: %v", err)
+	}
 	s.initWorkloadTrustBundle()
 
 	// Options based on the current 'defaults' in istio.
 	caOpts := &caOptions{
 		TrustDomain:    s.environment.Mesh().TrustDomain,
 		Namespace:      args.Namespace,
+		PodName:        args.PodName,
 		ExternalCAType: ra.CaExternalType(externalCaType),
+		RateLimit: rateLimitConfig(args.Namespace,
+			args.CAServerOptions.RateLimitPerIdentity, args.CAServerOptions.RateLimitPerIP),
 	}
 
 	if caOpts.ExternalCAType == ra.ExtCAK8s {
 		// Older environment variable preserved for backward compatibility
 		caOpts.ExternalCASigner = k8sSigner
 	}
+	var err error
+	if caOpts.WorkloadKeyPolicy, err = util.ParseKeyPolicy(args.CAServerOptions.WorkloadKeyPolicy); err != nil {
+		return nil, fmt.Errorf("invalid workload key policy: %v", err)
+	}
+	if caOpts.CAKeyPolicy, err = util.ParseKeyPolicy(args.CAServerOptions.CAKeyPolicy); err != nil {
+		return nil, fmt.Errorf("invalid CA key policy: %v", err)
+	}
 
 	// CA signing certificate must be created first if needed.
 	if err := s.maybeCreateCA(caOpts); err != nil {
@@ -304,6 +325,7 @@ func NewServer(args *PilotArgs) (*Server, error) {
 	}
 	// This should be called only after controllers are initialized.
 	s.initRegistryEventHandlers()
+	s.initCertificateIssuancePolicy()
 
 	s.initDiscoveryService(args)
 
@@ -720,12 +742,28 @@ func (s *Server) initSecureDiscoveryService(args *PilotArgs) error {
 		ClientCAs:      s.peerCertVerifier.GetGeneralCertPool(),
 		VerifyPeerCertificate: func(rawCerts [][]byte, verifiedChains [][]*x509.Certificate) error {
 			err := s.peerCertVerifier.VerifyPeerCert(rawCerts, verifiedChains)
+			if err == nil && len(rawCerts) > 0 {
+				// The certificates denied by the issuance policies are no longer trusted.
+				if cert, perr := x509.ParseCertificate(rawCerts[0]); perr == nil && s.workloadTrustBundle.IsDenied(cert) {
+					err = fmt.Errorf("certificate with serial number %s is denied", cert.SerialNumber.Text(16))
+				}
+			}
 			if err != nil {
 				log.Infof("Could not verify certificate: %v", err)
 			}
 			return err
 		},
 	}
+	if features.SpiffeBundleEndpoints != "" {
+		// The roots of the SPIFFE bundle endpoints rotate, so the client CAs are resolved on each handshake.
+		cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			c := cfg.Clone()
+			c.GetConfigForClient = nil
+			c.ClientCAs = s.peerCertVerifier.GetGeneralCertPool()
+			c.NextProtos = []string{"h2"}
+			return c, nil
+		}
+	}
 
 	tlsCreds := credentials.NewTLS(cfg)
 
@@ -918,6 +956,10 @@ func (s *Server) initRegistryEventHandlers() {
 				Resource().GroupVersionKind() {
 				continue
 			}
+			// This resource type only affects the CA, see initCertificateIssuancePolicy.
+			if schema.Resource().GroupVersionKind() == gvk.CertificateIssuancePolicy {
+				continue
+			}
 
 			s.configController.RegisterEventHandler(schema.Resource().GroupVersionKind(), configHandler)
 		}
@@ -1078,14 +1120,7 @@ func (s *Server) setPeerCertVerifier(tlsOptions TLSOptions) error {
 		}
 	}
 
-	if features.SpiffeBundleEndpoints != "" {
-		certMap, err := spiffe.RetrieveSpiffeBundleRootCertsFromStringInput(
-			features.SpiffeBundleEndpoints, []*x509.Certificate{})
-		if err != nil {
-			return err
-		}
-		s.peerCertVerifier.AddMappings(certMap)
-	}
+	// The roots of the SPIFFE bundle endpoints are added by the workload trust bundle, as they are polled.
 
 	return nil
 }
@@ -1140,6 +1175,9 @@ func (s *Server) maybeCreateCA(caOpts *caOptions) error {
 		if s.CA, err = s.createIstioCA(corev1, caOpts); err != nil {
 			return fmt.Errorf("failed to create CA: %v", err)
 		}
+		if err := checkCAKeyPolicy(s.CA.GetCAKeyCertBundle(), caOpts.CAKeyPolicy); err != nil {
+			return fmt.Errorf("the CA certificate chain does not comply with the CA key policy: %v", err)
+		}
 		if caOpts.ExternalCAType != "" {
 			if s.RA, err = s.createIstioRA(s.kubeClient, caOpts); err != nil {
 				return fmt.Errorf("failed to create RA: %v", err)
@@ -1211,6 +1249,9 @@ func (s *Server) initWorkloadTrustBundle() {
 			Full:   true,
 			Reason: []model.TriggerReason{model.GlobalUpdate},
 		}
+		if s.peerCertVerifier != nil {
+			updateSpiffeMappings(s.peerCertVerifier, s.workloadTrustBundle.GetSpiffeBundles(), spiffe.GetTrustDomain())
+		}
 		s.XDSServer.ConfigUpdate(pushReq)
 	})
 	// MeshConfig: Add initial roots
@@ -1245,5 +1286,47 @@ func (s *Server) initWorkloadTrustBundle() {
 			log.Errorf("fatal: unable to add RA root as trustAnchor")
 		}
 	}
+
+	// SPIFFE bundle endpoints: keep the roots of federated trust domains up to date
+	if features.SpiffeBundleEndpoints != "" {
+		endpoints, err := spiffe.ParseSpiffeBundleEndpoints(features.SpiffeBundleEndpoints)
+		if err != nil {
+			log.Errorf("unable to parse SPIFFE bundle endpoints: %v", err)
+		} else {
+			if _, f := endpoints[spiffe.GetTrustDomain()]; f {
+				log.Warnf("the SPIFFE bundle of the local trust domain %s is not used to verify the peers of istiod, "+
+					"which trusts its own roots for it", spiffe.GetTrustDomain())
+			}
+			s.addStartFunc(func(stop <-chan struct{}) error {
+				return s.workloadTrustBundle.AddSpiffeBundleEndpoints(endpoints, nil, stop)
+			})
+		}
+	}
 	log.Infof("done initializing workload trustBundle")
 }
+
+// updateSpiffeMappings replaces the mappings of the federated trust domains of the verifier with the roots of their
+// SPIFFE bundles. The mapping of the local trust domain holds the roots of istiod, and is never replaced by a bundle.
+func updateSpiffeMappings(verifier *spiffe.PeerCertVerifier, bundles map[string][]*x509.Certificate, localTrustDomain string) {
+	for trustDomain, certs := range bundles {
+		if trustDomain == localTrustDomain {
+			continue
+		}
+		verifier.ReplaceMapping(trustDomain, certs)
+	}
+}
+
+// updateCARoots publishes the roots of the Istio CA through the workload trust bundle.
+func (s *Server) updateCARoots(roots []byte) {
+	var rootCerts []string
+	for block, rest := pem.Decode(roots); block != nil; block, rest = pem.Decode(rest) {
+		rootCerts = append(rootCerts, string(pem.EncodeToMemory(block)))
+	}
+	err := s.workloadTrustBundle.UpdateTrustAnchor(&tb.TrustAnchorUpdate{
+		TrustAnchorConfig: tb.TrustAnchorConfig{Certs: rootCerts},
+		Source:            tb.SourceIstioCA,
+	})
+	if err != nil {
+		log.Errorf("unable to update CA roots in the workload trust bundle: %v", err)
+	}
+}
@@ -16,6 +16,7 @@ package bootstrap
 import (
 	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -28,7 +29,9 @@ import (
 	"istio.io/istio/pilot/pkg/serviceregistry"
 	kubecontroller "istio.io/istio/pilot/pkg/serviceregistry/kube/controller"
 	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/spiffe"
 	"istio.io/istio/pkg/testcerts"
+	"istio.io/istio/security/pkg/pki/util"
 	"istio.io/pkg/filewatcher"
 )
 
@@ -365,3 +368,103 @@ func checkCert(t *testing.T, s *Server, cert, key []byte) bool {
 	}
 	return bytes.Equal(actual.Certificate[0], expected.Certificate[0])
 }
+
+func TestUpdateSpiffeMappings(t *testing.T) {
+	g := NewWithT(t)
+	genRoot := func() ([]byte, []byte, *x509.Certificate) {
+		certPEM, keyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+			Org:          "Root CA",
+			TTL:          time.Hour,
+			IsCA:         true,
+			IsSelfSigned: true,
+			RSAKeySize:   2048,
+		})
+		g.Expect(err).To(BeNil())
+		cert, err := util.ParsePemEncodedCertificate(certPEM)
+		g.Expect(err).To(BeNil())
+		return certPEM, keyPEM, cert
+	}
+	genWorkload := func(root *x509.Certificate, rootKeyPEM []byte, id string) [][]byte {
+		key, err := util.ParsePemEncodedKey(rootKeyPEM)
+		g.Expect(err).To(BeNil())
+		certPEM, _, err := util.GenCertKeyFromOptions(util.CertOptions{
+			Host:       id,
+			TTL:        time.Hour,
+			SignerCert: root,
+			SignerPriv: key,
+			RSAKeySize: 2048,
+		})
+		g.Expect(err).To(BeNil())
+		cert, err := util.ParsePemEncodedCertificate(certPEM)
+		g.Expect(err).To(BeNil())
+		return [][]byte{cert.Raw}
+	}
+
+	localRootPEM, localRootKey, localRoot := genRoot()
+	_, remoteRootKey, remoteRoot := genRoot()
+	verifier := spiffe.NewPeerCertVerifier()
+	g.Expect(verifier.AddMappingFromPEM("cluster.local", localRootPEM)).To(BeNil())
+	localWorkload := genWorkload(localRoot, localRootKey, "spiffe://cluster.local/ns/foo/sa/bar")
+	remoteWorkload := genWorkload(remoteRoot, remoteRootKey, "spiffe://remote.domain/ns/foo/sa/bar")
+
+	// A bundle of the local trust domain does not replace the roots of istiod.
+	updateSpiffeMappings(verifier, map[string][]*x509.Certificate{
+		"cluster.local": {remoteRoot},
+		"remote.domain": {remoteRoot},
+	}, "cluster.local")
+	g.Expect(verifier.VerifyPeerCert(localWorkload, nil)).To(BeNil())
+	g.Expect(verifier.VerifyPeerCert(remoteWorkload, nil)).To(BeNil())
+
+	// The roots of federated trust domains are replaced.
+	updateSpiffeMappings(verifier, map[string][]*x509.Certificate{"remote.domain": {localRoot}}, "cluster.local")
+	g.Expect(verifier.VerifyPeerCert(remoteWorkload, nil)).NotTo(BeNil())
+	g.Expect(verifier.VerifyPeerCert(localWorkload, nil)).To(BeNil())
+}
+
+func TestNewServerKeyPolicy(t *testing.T) {
+	// Other tests disable the CA server.
+	enableCAServer := features.EnableCAServer
+	features.EnableCAServer = true
+	t.Cleanup(func() { features.EnableCAServer = enableCAServer })
+
+	cases := []struct {
+		name        string
+		options     CAServerOptions
+		expectedErr string
+	}{
+		{
+			name:        "invalid workload key policy",
+			options:     CAServerOptions{WorkloadKeyPolicy: "DSA-1024"},
+			expectedErr: "invalid workload key policy",
+		},
+		{
+			// The in-memory root CA has an RSA key.
+			name:        "CA key not allowed",
+			options:     CAServerOptions{CAKeyPolicy: "ECDSA-P256"},
+			expectedErr: "does not comply with the CA key policy",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			args := NewPilotArgs(func(p *PilotArgs) {
+				p.Namespace = "istio-system"
+				p.ServerOptions = DiscoveryServerOptions{
+					// Dynamically assign all ports.
+					HTTPAddr:       ":0",
+					MonitoringAddr: ":0",
+					GRPCAddr:       ":0",
+				}
+				p.RegistryOptions = RegistryOptions{
+					FileDir: t.TempDir(),
+				}
+				p.ShutdownDuration = 1 * time.Millisecond
+				p.CAServerOptions = c.options
+			})
+
+			g := NewWithT(t)
+			_, err := NewServer(args)
+			g.Expect(err).To(MatchError(ContainSubstring(c.expectedErr)))
+		})
+	}
+}
@@ -104,6 +104,9 @@ func (s *Server) initKubeRegistry(args *PilotArgs) (err error) {
 		mc.InitSecretController(stop)
 		return nil
 	})
+	s.XDSServer.RemoteClusterStatus = func() interface{} {
+		return mc.ClusterStatuses()
+	}
 
 	s.multicluster = mc
 	return
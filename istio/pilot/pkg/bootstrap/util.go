@@ -15,8 +15,12 @@
 package bootstrap
 
 import (
+	"strings"
+
+	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/serviceregistry"
+	"istio.io/istio/pkg/ratelimit"
 	"istio.io/pkg/ledger"
 )
 
@@ -38,3 +42,20 @@ func buildLedger(ca RegistryOptions) ledger.Ledger {
 	}
 	return result
 }
+
+// rateLimitConfig returns the per caller rate limits of an istiod service. The istiod identities in its namespace
+// are never limited, as well as the identities allowlisted with PILOT_RATE_LIMIT_ALLOWLIST.
+func rateLimitConfig(namespace string, perIdentity, perIP float64) ratelimit.Config {
+	allowlist := []string{"*/ns/" + namespace + "/sa/istiod*"}
+	for _, id := range strings.Split(features.RateLimitAllowlist, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			allowlist = append(allowlist, id)
+		}
+	}
+	return ratelimit.Config{
+		IdentityQPS: perIdentity,
+		IPQPS:       perIP,
+		Burst:       features.RateLimitBurst,
+		Allowlist:   allowlist,
+	}
+}
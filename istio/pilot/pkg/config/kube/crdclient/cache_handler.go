@@ -53,7 +53,11 @@ func (h *cacheHandler) onEvent(old interface{}, curr interface{}, event model.Ev
 		scope.Warnf("New Object can not be converted to runtime Object %v, is type %T", curr, curr)
 		return nil
 	}
-	currConfig := *TranslateObject(currItem, h.schema.Resource().GroupVersionKind(), h.client.domainSuffix)
+	currCfg := TranslateObject(currItem, h.schema.Resource().GroupVersionKind(), h.client.domainSuffix)
+	if currCfg == nil {
+		return nil
+	}
+	currConfig := *currCfg
 
 	var oldConfig config.Config
 	if old != nil {
@@ -62,7 +66,9 @@ func (h *cacheHandler) onEvent(old interface{}, curr interface{}, event model.Ev
 			log.Warnf("Old Object can not be converted to runtime Object %v, is type %T", old, old)
 			return nil
 		}
-		oldConfig = *TranslateObject(oldItem, h.schema.Resource().GroupVersionKind(), h.client.domainSuffix)
+		if oldCfg := TranslateObject(oldItem, h.schema.Resource().GroupVersionKind(), h.client.domainSuffix); oldCfg != nil {
+			oldConfig = *oldCfg
+		}
 	}
 
 	// TODO we may consider passing a pointer to handlers instead of the value. While spec is a pointer, the meta will be copied
@@ -33,10 +33,12 @@ import (
 	crd "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	klabels "k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/json"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/informers"
 
 	//  import GKE cluster authentication plugin
@@ -83,6 +85,9 @@ type Client struct {
 
 	// The gateway-api client we will use to access objects
 	gatewayAPIClient gatewayapiclient.Interface
+
+	// The dynamic client we will use to access kinds without a generated client
+	dynamicClient dynamic.Interface
 }
 
 var _ model.ConfigStoreCache = &Client{}
@@ -150,6 +155,7 @@ func NewForSchemas(client kube.Client, revision, domainSuffix string, schemas co
 		kinds:            map[config.GroupVersionKind]*cacheHandler{},
 		istioClient:      client.Istio(),
 		gatewayAPIClient: client.GatewayAPI(),
+		dynamicClient:    client.Dynamic(),
 	}
 	known := knownCRDs(client.Ext())
 	for _, s := range out.schemas.All() {
@@ -158,7 +164,9 @@ func NewForSchemas(client kube.Client, revision, domainSuffix string, schemas co
 		if _, f := known[name]; f {
 			var i informers.GenericInformer
 			var err error
-			if s.Resource().Group() == "networking.x-k8s.io" {
+			if usesDynamicClient(s.Resource().GroupVersionKind()) {
+				i = client.DynamicInformer().ForResource(s.Resource().GroupVersionResource())
+			} else if s.Resource().Group() == "networking.x-k8s.io" {
 				i, err = client.GatewayAPIInformer().ForResource(s.Resource().GroupVersionResource())
 			} else {
 				i, err = client.IstioInformer().ForResource(s.Resource().GroupVersionResource())
@@ -196,7 +204,7 @@ func (cl *Client) Get(typ config.GroupVersionKind, name, namespace string) *conf
 	}
 
 	cfg := TranslateObject(obj, typ, cl.domainSuffix)
-	if !cl.objectInRevision(cfg) {
+	if cfg == nil || !cl.objectInRevision(cfg) {
 		return nil
 	}
 	return cfg
@@ -208,7 +216,13 @@ func (cl *Client) Create(cfg config.Config) (string, error) {
 		return "", fmt.Errorf("nil spec for %v/%v", cfg.Name, cfg.Namespace)
 	}
 
-	meta, err := create(cl.istioClient, cl.gatewayAPIClient, cfg, getObjectMetadata(cfg))
+	var meta metav1.Object
+	var err error
+	if usesDynamicClient(cfg.GroupVersionKind) {
+		meta, err = createDynamic(cl.dynamicClient, cfg, getObjectMetadata(cfg))
+	} else {
+		meta, err = create(cl.istioClient, cl.gatewayAPIClient, cfg, getObjectMetadata(cfg))
+	}
 	if err != nil {
 		return "", err
 	}
@@ -221,7 +235,13 @@ func (cl *Client) Update(cfg config.Config) (string, error) {
 		return "", fmt.Errorf("nil spec for %v/%v", cfg.Name, cfg.Namespace)
 	}
 
-	meta, err := update(cl.istioClient, cl.gatewayAPIClient, cfg, getObjectMetadata(cfg))
+	var meta metav1.Object
+	var err error
+	if usesDynamicClient(cfg.GroupVersionKind) {
+		meta, err = updateDynamic(cl.dynamicClient, cfg, getObjectMetadata(cfg))
+	} else {
+		meta, err = update(cl.istioClient, cl.gatewayAPIClient, cfg, getObjectMetadata(cfg))
+	}
 	if err != nil {
 		return "", err
 	}
@@ -233,7 +253,13 @@ func (cl *Client) UpdateStatus(cfg config.Config) (string, error) {
 		return "", fmt.Errorf("nil status for %v/%v on updateStatus()", cfg.Name, cfg.Namespace)
 	}
 
-	meta, err := updateStatus(cl.istioClient, cl.gatewayAPIClient, cfg, getObjectMetadata(cfg))
+	var meta metav1.Object
+	var err error
+	if usesDynamicClient(cfg.GroupVersionKind) {
+		meta, err = updateStatusDynamic(cl.dynamicClient, cfg, getObjectMetadata(cfg))
+	} else {
+		meta, err = updateStatus(cl.istioClient, cl.gatewayAPIClient, cfg, getObjectMetadata(cfg))
+	}
 	if err != nil {
 		return "", err
 	}
@@ -245,7 +271,13 @@ func (cl *Client) UpdateStatus(cfg config.Config) (string, error) {
 func (cl *Client) Patch(orig config.Config, patchFn config.PatchFunc) (string, error) {
 	modified, patchType := patchFn(orig.DeepCopy())
 
-	meta, err := patch(cl.istioClient, cl.gatewayAPIClient, orig, getObjectMetadata(orig), modified, getObjectMetadata(modified), patchType)
+	var meta metav1.Object
+	var err error
+	if usesDynamicClient(orig.GroupVersionKind) {
+		meta, err = patchDynamic(cl.dynamicClient, orig, getObjectMetadata(orig), modified, getObjectMetadata(modified), patchType)
+	} else {
+		meta, err = patch(cl.istioClient, cl.gatewayAPIClient, orig, getObjectMetadata(orig), modified, getObjectMetadata(modified), patchType)
+	}
 	if err != nil {
 		return "", err
 	}
@@ -255,6 +287,9 @@ func (cl *Client) Patch(orig config.Config, patchFn config.PatchFunc) (string, e
 // Delete implements store interface
 // `resourceVersion` must be matched before deletion is carried out. If not possible, a 409 Conflict status will be
 func (cl *Client) Delete(typ config.GroupVersionKind, name, namespace string, resourceVersion *string) error {
+	if usesDynamicClient(typ) {
+		return deleteDynamic(cl.dynamicClient, typ, name, namespace, resourceVersion)
+	}
 	return delete(cl.istioClient, cl.gatewayAPIClient, typ, name, namespace, resourceVersion)
 }
 
@@ -272,7 +307,7 @@ func (cl *Client) List(kind config.GroupVersionKind, namespace string) ([]config
 	out := make([]config.Config, 0, len(list))
 	for _, item := range list {
 		cfg := TranslateObject(item, kind, cl.domainSuffix)
-		if cl.objectInRevision(cfg) {
+		if cfg != nil && cl.objectInRevision(cfg) {
 			out = append(out, *cfg)
 		}
 	}
@@ -317,12 +352,38 @@ func knownCRDs(crdClient apiextensionsclient.Interface) map[string]struct{} {
 }
 
 func TranslateObject(r runtime.Object, gvk config.GroupVersionKind, domainSuffix string) *config.Config {
-	translateFunc, f := translationMap[gvk]
-	if !f {
-		scope.Errorf("unknown type %v", gvk)
-		return nil
+	var c *config.Config
+	if _, f := translationMap[gvk]; !f {
+		if _, ok := r.(*unstructured.Unstructured); !ok {
+			// Typed objects of kinds without a generated client, such as those in istio.io/istio/pkg/config/apis,
+			// share the translation of unstructured objects.
+			obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(r)
+			if err != nil {
+				scope.Errorf("failed to convert %v: %v", gvk, err)
+				return nil
+			}
+			r = &unstructured.Unstructured{Object: obj}
+		}
+	}
+	if u, ok := r.(*unstructured.Unstructured); ok {
+		s, f := collections.PilotServiceApi.FindByGroupVersionKind(gvk)
+		if !f {
+			scope.Errorf("unknown type %v", gvk)
+			return nil
+		}
+		var err error
+		if c, err = translateUnstructured(u, s); err != nil {
+			scope.Errorf("failed to translate %v %s/%s: %v", gvk, u.GetNamespace(), u.GetName(), err)
+			return nil
+		}
+	} else {
+		translateFunc, f := translationMap[gvk]
+		if !f {
+			scope.Errorf("unknown type %v", gvk)
+			return nil
+		}
+		c = translateFunc(r)
 	}
-	c := translateFunc(r)
 	c.Domain = domainSuffix
 	return c
 }
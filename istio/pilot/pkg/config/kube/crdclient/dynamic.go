@@ -0,0 +1,186 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package crdclient
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/collection"
+	"istio.io/istio/pkg/config/schema/collections"
+)
+
+// Kinds defined in istio.io/istio/pkg/config/apis have no generated client, so the code generation skips them.
+// They are accessed through the dynamic client instead; as there are only a handful of such objects per cluster,
+// the cost of converting unstructured objects on each Get/List call is acceptable.
+
+// usesDynamicClient returns true if the kind has no generated client.
+func usesDynamicClient(gvk config.GroupVersionKind) bool {
+	_, f := translationMap[gvk]
+	return !f
+}
+
+func dynamicResource(dc dynamic.Interface, gvk config.GroupVersionKind, namespace string) (dynamic.ResourceInterface, error) {
+	s, f := collections.PilotServiceApi.FindByGroupVersionKind(gvk)
+	if !f {
+		return nil, fmt.Errorf("unsupported type: %v", gvk)
+	}
+	ri := dc.Resource(s.Resource().GroupVersionResource())
+	if s.Resource().IsClusterScoped() {
+		return ri, nil
+	}
+	return ri.Namespace(namespace), nil
+}
+
+func toUnstructured(cfg config.Config, objMeta metav1.ObjectMeta) (*unstructured.Unstructured, error) {
+	meta, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&objMeta)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cfg.Spec)
+	if err != nil {
+		return nil, err
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"metadata": meta,
+		"spec":     spec,
+	}}
+	if cfg.Status != nil {
+		status, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cfg.Status)
+		if err != nil {
+			return nil, err
+		}
+		obj.Object["status"] = status
+	}
+	obj.SetAPIVersion(cfg.GroupVersionKind.Group + "/" + cfg.GroupVersionKind.Version)
+	obj.SetKind(cfg.GroupVersionKind.Kind)
+	return obj, nil
+}
+
+func translateUnstructured(obj *unstructured.Unstructured, s collection.Schema) (*config.Config, error) {
+	spec, err := s.Resource().NewInstance()
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := obj.Object["spec"].(map[string]interface{}); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, spec); err != nil {
+			return nil, err
+		}
+	}
+	status, err := s.Resource().Status()
+	if err != nil {
+		return nil, err
+	}
+	if raw, ok := obj.Object["status"].(map[string]interface{}); ok {
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, status); err != nil {
+			return nil, err
+		}
+	}
+	return &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind:  s.Resource().GroupVersionKind(),
+			Name:              obj.GetName(),
+			Namespace:         obj.GetNamespace(),
+			Labels:            obj.GetLabels(),
+			Annotations:       obj.GetAnnotations(),
+			ResourceVersion:   obj.GetResourceVersion(),
+			CreationTimestamp: obj.GetCreationTimestamp().Time,
+			OwnerReferences:   obj.GetOwnerReferences(),
+			UID:               string(obj.GetUID()),
+			Generation:        obj.GetGeneration(),
+		},
+		Spec:   spec,
+		Status: status,
+	}, nil
+}
+
+func createDynamic(dc dynamic.Interface, cfg config.Config, objMeta metav1.ObjectMeta) (metav1.Object, error) {
+	ri, err := dynamicResource(dc, cfg.GroupVersionKind, cfg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := toUnstructured(cfg, objMeta)
+	if err != nil {
+		return nil, err
+	}
+	return ri.Create(context.TODO(), obj, metav1.CreateOptions{})
+}
+
+func updateDynamic(dc dynamic.Interface, cfg config.Config, objMeta metav1.ObjectMeta) (metav1.Object, error) {
+	ri, err := dynamicResource(dc, cfg.GroupVersionKind, cfg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := toUnstructured(cfg, objMeta)
+	if err != nil {
+		return nil, err
+	}
+	return ri.Update(context.TODO(), obj, metav1.UpdateOptions{})
+}
+
+func updateStatusDynamic(dc dynamic.Interface, cfg config.Config, objMeta metav1.ObjectMeta) (metav1.Object, error) {
+	ri, err := dynamicResource(dc, cfg.GroupVersionKind, cfg.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := toUnstructured(cfg, objMeta)
+	if err != nil {
+		return nil, err
+	}
+	return ri.UpdateStatus(context.TODO(), obj, metav1.UpdateOptions{})
+}
+
+func patchDynamic(dc dynamic.Interface, orig config.Config, origMeta metav1.ObjectMeta, mod config.Config, modMeta metav1.ObjectMeta,
+	typ types.PatchType) (metav1.Object, error) {
+	if orig.GroupVersionKind != mod.GroupVersionKind {
+		return nil, fmt.Errorf("gvk mismatch: %v, modified: %v", orig.GroupVersionKind, mod.GroupVersionKind)
+	}
+	ri, err := dynamicResource(dc, orig.GroupVersionKind, orig.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	oldRes, err := toUnstructured(orig, origMeta)
+	if err != nil {
+		return nil, err
+	}
+	modRes, err := toUnstructured(mod, modMeta)
+	if err != nil {
+		return nil, err
+	}
+	patchBytes, err := genPatchBytes(oldRes, modRes, typ)
+	if err != nil {
+		return nil, err
+	}
+	return ri.Patch(context.TODO(), orig.Name, typ, patchBytes, metav1.PatchOptions{FieldManager: "pilot-discovery"})
+}
+
+func deleteDynamic(dc dynamic.Interface, typ config.GroupVersionKind, name, namespace string, resourceVersion *string) error {
+	ri, err := dynamicResource(dc, typ, namespace)
+	if err != nil {
+		return err
+	}
+	var deleteOptions metav1.DeleteOptions
+	if resourceVersion != nil {
+		deleteOptions.Preconditions = &metav1.Preconditions{ResourceVersion: resourceVersion}
+	}
+	return ri.Delete(context.TODO(), name, deleteOptions)
+}
@@ -123,6 +123,11 @@ func main() {
 	// Prepare to generate types for mock schema and all Istio schemas
 	typeList := []ConfigData{}
 	for _, s := range collections.PilotServiceApi.All() {
+		if _, f := clientGoImport[s.Resource().ProtoPackage()]; !f {
+			// Types without a generated client are served by the dynamic client, see dynamic.go
+			log.Printf("Skipping type %s without a generated client\n", s.VariableName())
+			continue
+		}
 		typeList = append(typeList, MakeConfigData(s))
 	}
 	var buffer bytes.Buffer
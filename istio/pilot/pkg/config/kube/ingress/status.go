@@ -56,6 +56,9 @@ type StatusSyncer struct {
 	serviceLister      listerv1.ServiceLister
 	nodeLister         listerv1.NodeLister
 	ingressClassLister listerv1beta1.IngressClassLister
+
+	// ownsNamespace, if set, limits the Ingresses updated to the namespaces it returns true for.
+	ownsNamespace func(namespace string) bool
 }
 
 // Run the syncer until stopCh is closed
@@ -64,6 +67,12 @@ func (s *StatusSyncer) Run(stopCh <-chan struct{}) {
 	go s.runUpdateStatus(stopCh)
 }
 
+// SetNamespaceFilter limits the Ingresses updated by the syncer to the namespaces f returns true for. This is used
+// to split the status updates between replicas.
+func (s *StatusSyncer) SetNamespaceFilter(f func(namespace string) bool) {
+	s.ownsNamespace = f
+}
+
 // NewStatusSyncer creates a new instance
 func NewStatusSyncer(meshHolder mesh.Holder, client kubelib.Client) *StatusSyncer {
 	// as in controller, ingressClassListener can be nil since not supported in k8s version <1.18
@@ -128,6 +137,9 @@ func (s *StatusSyncer) updateStatus(status []coreV1.LoadBalancerIngress) error {
 		return err
 	}
 	for _, currIng := range l {
+		if s.ownsNamespace != nil && !s.ownsNamespace(currIng.Namespace) {
+			continue
+		}
 		shouldTarget, err := s.shouldTargetIngress(currIng)
 		if err != nil {
 			log.Warnf("error determining whether should target ingress for status update: %v", err)
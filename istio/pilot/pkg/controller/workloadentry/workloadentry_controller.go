@@ -152,6 +152,9 @@ type Controller struct {
 
 	// healthCondition is a fifo queue used for updating health check status
 	healthCondition cache.Queue
+
+	// ownsNamespace, if set, limits the periodic cleanup to the namespaces it returns true for.
+	ownsNamespace func(namespace string) bool
 }
 
 type HealthStatus = v1alpha1.IstioCondition
@@ -178,6 +181,18 @@ func NewController(store model.ConfigStoreCache, instanceID string, maxConnAge t
 	return nil
 }
 
+// SetNamespaceFilter limits the periodic cleanup of WorkloadEntries to the namespaces f returns true for, so that
+// the cleanup is split between replicas. By default, every replica cleans up all namespaces.
+// Registration is unaffected, as it is done by the replica the workload is connected to.
+func (c *Controller) SetNamespaceFilter(f func(namespace string) bool) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.ownsNamespace = f
+}
+
 func (c *Controller) Run(stop <-chan struct{}) {
 	if c == nil {
 		return
@@ -471,8 +486,14 @@ func (c *Controller) periodicWorkloadEntryCleanup(stopCh <-chan struct{}) {
 				log.Warnf("error listing WorkloadEntry for cleanup: %v", err)
 				continue
 			}
+			c.mutex.Lock()
+			ownsNamespace := c.ownsNamespace
+			c.mutex.Unlock()
 			for _, wle := range wles {
 				wle := wle
+				if ownsNamespace != nil && !ownsNamespace(wle.Namespace) {
+					continue
+				}
 				if c.shouldCleanupEntry(wle) {
 					c.cleanupQueue.Push(func() error {
 						c.cleanupEntry(wle)
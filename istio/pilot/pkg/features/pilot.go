@@ -114,6 +114,39 @@ var (
 		"EnableMysqlFilter enables injection of `envoy.filters.network.mysql_proxy` in the filter chain.",
 	).Get()
 
+	// EnableKafkaFilter enables injection of `envoy.filters.network.kafka_broker` in the filter chain.
+	// Pilot injects this filter if the service port name is `kafka`.
+	EnableKafkaFilter = env.RegisterBoolVar(
+		"PILOT_ENABLE_KAFKA_FILTER",
+		false,
+		"EnableKafkaFilter enables injection of `envoy.filters.network.kafka_broker` in the filter chain.",
+	).Get()
+
+	// EnablePostgresFilter enables injection of `envoy.filters.network.postgres_proxy` in the filter chain.
+	// Pilot injects this filter if the service port name is `postgres`.
+	EnablePostgresFilter = env.RegisterBoolVar(
+		"PILOT_ENABLE_POSTGRES_FILTER",
+		false,
+		"EnablePostgresFilter enables injection of `envoy.filters.network.postgres_proxy` in the filter chain.",
+	).Get()
+
+	// DynamicForwardProxyHostTTL is how long hosts resolved by the dynamic forward proxy DNS cache stay cached without
+	// being used.
+	DynamicForwardProxyHostTTL = env.RegisterDurationVar(
+		"PILOT_DYNAMIC_FORWARD_PROXY_HOST_TTL",
+		5*time.Minute,
+		"The time after which unused hosts are removed from the DNS cache of the dynamic forward proxy "+
+			"used for ServiceEntries with wildcard hosts and DNS resolution.",
+	).Get()
+
+	// DynamicForwardProxyMaxHosts is the maximum number of hosts in the dynamic forward proxy DNS cache.
+	DynamicForwardProxyMaxHosts = env.RegisterIntVar(
+		"PILOT_DYNAMIC_FORWARD_PROXY_MAX_HOSTS",
+		1024,
+		"The maximum number of hosts in the DNS cache of the dynamic forward proxy used for ServiceEntries with "+
+			"wildcard hosts and DNS resolution.",
+	).Get()
+
 	// EnableRedisFilter enables injection of `envoy.filters.network.redis_proxy` in the filter chain.
 	// Pilot injects this outbound filter if the service port name is `redis`.
 	EnableRedisFilter = env.RegisterBoolVar(
@@ -255,6 +288,14 @@ var (
 			"See https://godoc.org/k8s.io/client-go/rest#Config Burst",
 	).Get()
 
+	LeaderElectionShards = env.RegisterIntVar(
+		"PILOT_LEADER_ELECTION_SHARDS",
+		1,
+		"The number of shards the status, WorkloadEntry cleanup and ingress status controllers are partitioned "+
+			"into. Each shard holds a set of namespaces and is processed by a single istiod replica, with the "+
+			"shards spread between the live replicas. The default of 1 elects a single leader.",
+	).Get()
+
 	// IstiodServiceCustomHost allow user to bring a custom address for istiod server
 	// for examples: istiod.mycompany.com
 	IstiodServiceCustomHost = env.RegisterStringVar("ISTIOD_CUSTOM_HOST", "",
@@ -322,8 +363,9 @@ var (
 
 	SpiffeBundleEndpoints = env.RegisterStringVar("SPIFFE_BUNDLE_ENDPOINTS", "",
 		"The SPIFFE bundle trust domain to endpoint mappings. Istiod retrieves the root certificate from each SPIFFE "+
-			"bundle endpoint and uses it to verify client certifiates from that trust domain. The endpoint must be "+
-			"compliant to the SPIFFE Bundle Endpoint standard. For details, please refer to "+
+			"bundle endpoint, refreshing it according to the refresh hint of the bundle, and uses it to verify client "+
+			"certifiates from that trust domain. The roots are also added to the workload trust bundle. "+
+			"The endpoint must be compliant to the SPIFFE Bundle Endpoint standard. For details, please refer to "+
 			"https://github.com/spiffe/spiffe/blob/master/standards/SPIFFE_Trust_Domain_and_Bundle.md . "+
 			"No need to configure this for root certificates issued via Istiod or web-PKI based root certificates. "+
 			"Use || between <trustdomain, endpoint> tuples. Use | as delimiter between trust domain and endpoint in "+
@@ -411,6 +453,75 @@ var (
 		"The interval for istiod to fetch the jwks_uri for the jwks public key.",
 	).Get()
 
+	XDSRateLimitPerIdentity = env.RegisterFloatVar(
+		"PILOT_XDS_RATE_LIMIT_PER_IDENTITY",
+		0,
+		"The rate, in connections per second, at which each authenticated identity can open XDS streams. 0 disables the limit. "+
+			"Overridden by the --xdsRateLimitPerIdentity flag.",
+	).Get()
+
+	XDSRateLimitPerIP = env.RegisterFloatVar(
+		"PILOT_XDS_RATE_LIMIT_PER_IP",
+		0,
+		"The rate, in connections per second, at which each source IP can open XDS streams. 0 disables the limit. "+
+			"Overridden by the --xdsRateLimitPerIP flag.",
+	).Get()
+
+	CARateLimitPerIdentity = env.RegisterFloatVar(
+		"PILOT_CA_RATE_LIMIT_PER_IDENTITY",
+		0,
+		"The rate, in requests per second, at which each authenticated identity can send CSRs. 0 disables the limit. "+
+			"Overridden by the --caRateLimitPerIdentity flag.",
+	).Get()
+
+	CARateLimitPerIP = env.RegisterFloatVar(
+		"PILOT_CA_RATE_LIMIT_PER_IP",
+		0,
+		"The rate, in requests per second, at which each source IP can send CSRs. 0 disables the limit. "+
+			"Overridden by the --caRateLimitPerIP flag.",
+	).Get()
+
+	RateLimitBurst = env.RegisterIntVar(
+		"PILOT_RATE_LIMIT_BURST",
+		10,
+		"The number of XDS connections or CSRs a caller can make at once before the per caller rate limits apply.",
+	).Get()
+
+	RateLimitAllowlist = env.RegisterStringVar(
+		"PILOT_RATE_LIMIT_ALLOWLIST",
+		"",
+		"Comma separated identities not subject to the per caller rate limits, e.g. cluster.local/ns/foo/sa/bar. "+
+			"A \"*\" matches any characters. The istiod identities in the istiod namespace are always allowed.",
+	).Get()
+
+	JwksPersistence = env.RegisterStringVar(
+		"PILOT_JWKS_PERSISTENCE",
+		"",
+		"Where istiod persists the last successfully fetched JWKS of each issuer, so they can be restored "+
+			"after a restart when the JWKS URI is unavailable. One of \"configmap\" (the istio-jwks-cache ConfigMap "+
+			"in the istiod namespace), \"file\" (PILOT_JWKS_PERSISTENCE_FILE) or empty to disable persistence.",
+	).Get()
+
+	JwksPersistenceFile = env.RegisterStringVar(
+		"PILOT_JWKS_PERSISTENCE_FILE",
+		"/var/lib/istio/jwks/jwks.json",
+		"The file istiod persists the JWKS to when PILOT_JWKS_PERSISTENCE is \"file\".",
+	).Get()
+
+	JwksFetchProxy = env.RegisterStringVar(
+		"PILOT_JWKS_FETCH_PROXY",
+		"",
+		"The URL of the HTTP proxy istiod uses to fetch JWKS and OpenID discovery documents. If empty, the "+
+			"HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables are used.",
+	).Get()
+
+	JwksExtraCABundles = env.RegisterStringVar(
+		"PILOT_JWKS_EXTRA_CA_BUNDLES",
+		"",
+		"Comma separated paths of PEM CA bundles istiod trusts when fetching JWKS over https, in addition to "+
+			"the system roots and /cacerts/extra.pem.",
+	).Get()
+
 	EnableInboundPassthrough = env.RegisterBoolVar(
 		"PILOT_ENABLE_INBOUND_PASSTHROUGH",
 		true,
@@ -421,6 +532,23 @@ var (
 			"Regardless of this setting, the configuration can be overridden with the Sidecar.Ingress.DefaultEndpoint configuration.",
 	).Get()
 
+	RemoteClusterTimeout = env.RegisterDurationVar(
+		"PILOT_REMOTE_CLUSTER_TIMEOUT",
+		30*time.Second,
+		"The time after which a remote cluster whose informers did not sync is reported as failed. "+
+			"Istiod keeps waiting for the informers to sync, and reports the cluster as synced once they do.",
+	).Get()
+
+	LocalityFailoverPriority = env.RegisterStringVar(
+		"PILOT_LOCALITY_FAILOVER_PRIORITY",
+		"",
+		"Comma separated list of the labels used, in order, to prioritize the endpoints on failover, before their locality. "+
+			"For example, with topology.istio.io/network,topology.istio.io/cluster traffic stays in the network, then the "+
+			"cluster, of the proxy before crossing, even within a region. Overridden by the "+
+			"networking.istio.io/failoverPriority annotation of a DestinationRule. Like locality failover, it only "+
+			"applies to destinations whose DestinationRule enables outlier detection. This setting is experimental.",
+	).Get()
+
 	StripHostPort = env.RegisterBoolVar("ISTIO_GATEWAY_STRIP_HOST_PORT", false,
 		"If enabled, Gateway will remove any port from host/authority header "+
 			"before any processing of request by HTTP filters or routing.").Get()
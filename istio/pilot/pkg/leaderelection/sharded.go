@@ -0,0 +1,303 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelection
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	klabels "k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"istio.io/pkg/log"
+)
+
+const (
+	// WorkloadEntryController is the lock for the periodic cleanup of auto-registered WorkloadEntries. It is only
+	// used when the work is sharded; otherwise every replica cleans up.
+	WorkloadEntryController = "istio-workloadentry-leader"
+
+	// memberLabel is set on the ConfigMaps that record the live members of a sharded election, with the election
+	// ID as value.
+	memberLabel = "istio.io/leader-election"
+	// renewTimeAnnotation records the last time a member renewed its membership.
+	renewTimeAnnotation = "istio.io/leader-election-renew-time"
+)
+
+// ShardedLeaderElection partitions work between replicas. Each of the shards is guarded by its own leader
+// election lock, and replicas only contend for the shards assigned to them. Replicas announce themselves with a
+// membership ConfigMap that is renewed periodically, and the shards are spread round-robin between the live
+// members, so that they move as replicas come and go.
+//
+// With a single shard, this is equivalent to a LeaderElection on the election ID.
+type ShardedLeaderElection struct {
+	namespace  string
+	name       string
+	electionID string
+	shards     int
+	client     kubernetes.Interface
+	ttl        time.Duration
+	runFns     []func(stop <-chan struct{})
+
+	mu sync.Mutex
+	// elections holds the stop channels of the elections of the shards this replica contends for.
+	elections map[int]chan struct{}
+	// owned holds the shards this replica is the leader of.
+	owned map[int]struct{}
+	// leaderStop is closed once this replica is no longer the leader of any shard.
+	leaderStop chan struct{}
+}
+
+// NewShardedLeaderElection returns an election of the given number of shards.
+func NewShardedLeaderElection(namespace, name, electionID string, shards int, client kubernetes.Interface) *ShardedLeaderElection {
+	if name == "" {
+		name = "unknown"
+	}
+	if shards < 1 {
+		shards = 1
+	}
+	return &ShardedLeaderElection{
+		namespace:  namespace,
+		name:       name,
+		electionID: electionID,
+		shards:     shards,
+		client:     client,
+		// Default to a 30s ttl. Overridable for tests
+		ttl:       time.Second * 30,
+		elections: map[int]chan struct{}{},
+		owned:     map[int]struct{}{},
+	}
+}
+
+// AddRunFunction registers a function to run while we are the leader of at least one shard. These will be run
+// asynchronously. To avoid running when not a leader, functions should respect the stop channel.
+func (s *ShardedLeaderElection) AddRunFunction(f func(stop <-chan struct{})) *ShardedLeaderElection {
+	s.runFns = append(s.runFns, f)
+	return s
+}
+
+// Owns returns true if we are the leader of the shard of the namespace.
+func (s *ShardedLeaderElection) Owns(namespace string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, f := s.owned[ShardForNamespace(namespace, s.shards)]
+	return f
+}
+
+// OwnedShards returns the shards we are the leader of.
+func (s *ShardedLeaderElection) OwnedShards() []int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]int, 0, len(s.owned))
+	for shard := range s.owned {
+		out = append(out, shard)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// Run will start the elections of the shards assigned to this replica, rebalancing them until stop is closed.
+func (s *ShardedLeaderElection) Run(stop <-chan struct{}) {
+	if s.shards == 1 {
+		s.contend([]int{0})
+		<-stop
+		s.contend(nil)
+		return
+	}
+	ticker := time.NewTicker(s.ttl / 4)
+	defer ticker.Stop()
+	for {
+		s.rebalance()
+		select {
+		case <-stop:
+			s.contend(nil)
+			s.leave()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rebalance renews our membership and contends for the shards assigned to us given the live members.
+func (s *ShardedLeaderElection) rebalance() {
+	if err := s.heartbeat(); err != nil {
+		log.Warnf("failed to renew membership of %v: %v", s.electionID, err)
+	}
+	members, err := s.members()
+	if err != nil {
+		// Keep the current shards until the members can be listed again.
+		log.Warnf("failed to list members of %v: %v", s.electionID, err)
+		return
+	}
+	s.contend(AssignShards(members, s.shards)[s.name])
+}
+
+// contend starts the elections of the given shards, and stops those of any other shard.
+func (s *ShardedLeaderElection) contend(shards []int) {
+	assigned := map[int]struct{}{}
+	for _, shard := range shards {
+		assigned[shard] = struct{}{}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for shard, stop := range s.elections {
+		if _, f := assigned[shard]; !f {
+			log.Infof("leaving shard %d of %v", shard, s.electionID)
+			close(stop)
+			delete(s.elections, shard)
+		}
+	}
+	for shard := range assigned {
+		if _, f := s.elections[shard]; f {
+			continue
+		}
+		shard := shard
+		stop := make(chan struct{})
+		s.elections[shard] = stop
+		le := NewLeaderElection(s.namespace, s.name, s.lockName(shard), s.client)
+		le.ttl = s.ttl
+		le.AddRunFunction(func(leaderStop <-chan struct{}) {
+			s.acquired(shard)
+			<-leaderStop
+			s.released(shard)
+		})
+		go le.Run(stop)
+	}
+}
+
+func (s *ShardedLeaderElection) acquired(shard int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	log.Infof("acquired shard %d of %v", shard, s.electionID)
+	s.owned[shard] = struct{}{}
+	if s.leaderStop == nil {
+		s.leaderStop = make(chan struct{})
+		for _, f := range s.runFns {
+			go f(s.leaderStop)
+		}
+	}
+}
+
+func (s *ShardedLeaderElection) released(shard int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.owned, shard)
+	if len(s.owned) == 0 && s.leaderStop != nil {
+		close(s.leaderStop)
+		s.leaderStop = nil
+	}
+}
+
+func (s *ShardedLeaderElection) lockName(shard int) string {
+	if s.shards == 1 {
+		// Use the same lock as an unsharded election, so that replicas of older versions are excluded.
+		return s.electionID
+	}
+	return fmt.Sprintf("%s-%d", s.electionID, shard)
+}
+
+func (s *ShardedLeaderElection) memberName() string {
+	return fmt.Sprintf("%s-member-%s", s.electionID, s.name)
+}
+
+// heartbeat creates or renews the membership ConfigMap of this replica.
+func (s *ShardedLeaderElection) heartbeat() error {
+	now := time.Now().Format(time.RFC3339Nano)
+	cms := s.client.CoreV1().ConfigMaps(s.namespace)
+	cm, err := cms.Get(context.TODO(), s.memberName(), metaV1.GetOptions{})
+	if errors.IsNotFound(err) {
+		_, err = cms.Create(context.TODO(), &v1.ConfigMap{
+			ObjectMeta: metaV1.ObjectMeta{
+				Name:        s.memberName(),
+				Namespace:   s.namespace,
+				Labels:      map[string]string{memberLabel: s.electionID},
+				Annotations: map[string]string{renewTimeAnnotation: now},
+			},
+			Data: map[string]string{"member": s.name},
+		}, metaV1.CreateOptions{})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+	if cm.Annotations == nil {
+		cm.Annotations = map[string]string{}
+	}
+	cm.Annotations[renewTimeAnnotation] = now
+	_, err = cms.Update(context.TODO(), cm, metaV1.UpdateOptions{})
+	return err
+}
+
+// members returns the sorted names of the replicas that renewed their membership within the ttl, including ours.
+func (s *ShardedLeaderElection) members() ([]string, error) {
+	cms, err := s.client.CoreV1().ConfigMaps(s.namespace).List(context.TODO(), metaV1.ListOptions{
+		LabelSelector: klabels.SelectorFromSet(map[string]string{memberLabel: s.electionID}).String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	members := []string{s.name}
+	for _, cm := range cms.Items {
+		name := cm.Data["member"]
+		if name == "" || name == s.name {
+			continue
+		}
+		renewed, err := time.Parse(time.RFC3339Nano, cm.Annotations[renewTimeAnnotation])
+		if err != nil || time.Since(renewed) > s.ttl {
+			continue
+		}
+		members = append(members, name)
+	}
+	sort.Strings(members)
+	return members, nil
+}
+
+// leave removes our membership, so the other replicas take over our shards without waiting for it to expire.
+func (s *ShardedLeaderElection) leave() {
+	err := s.client.CoreV1().ConfigMaps(s.namespace).Delete(context.TODO(), s.memberName(), metaV1.DeleteOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		log.Warnf("failed to remove membership of %v: %v", s.electionID, err)
+	}
+}
+
+// ShardForNamespace returns the shard the namespace belongs to.
+func ShardForNamespace(namespace string, shards int) int {
+	if shards <= 1 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(namespace))
+	return int(h.Sum32() % uint32(shards))
+}
+
+// AssignShards spreads the shards round-robin between the sorted members.
+func AssignShards(members []string, shards int) map[string][]int {
+	out := make(map[string][]int, len(members))
+	if len(members) == 0 {
+		return out
+	}
+	for shard := 0; shard < shards; shard++ {
+		member := members[shard%len(members)]
+		out[member] = append(out[member], shard)
+	}
+	return out
+}
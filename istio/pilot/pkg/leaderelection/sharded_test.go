@@ -0,0 +1,141 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leaderelection
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/atomic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"istio.io/istio/pkg/test/util/retry"
+)
+
+func TestAssignShards(t *testing.T) {
+	cases := []struct {
+		name    string
+		members []string
+		shards  int
+		want    map[string][]int
+	}{
+		{"no members", nil, 4, map[string][]int{}},
+		{"single member", []string{"a"}, 3, map[string][]int{"a": {0, 1, 2}}},
+		{"even", []string{"a", "b"}, 4, map[string][]int{"a": {0, 2}, "b": {1, 3}}},
+		{"more members than shards", []string{"a", "b", "c"}, 2, map[string][]int{"a": {0}, "b": {1}}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := AssignShards(tt.members, tt.shards); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShardForNamespace(t *testing.T) {
+	if got := ShardForNamespace("default", 1); got != 0 {
+		t.Fatalf("expected a single shard, got %v", got)
+	}
+	seen := map[int]bool{}
+	for i := 0; i < 100; i++ {
+		ns := fmt.Sprintf("ns-%d", i)
+		shard := ShardForNamespace(ns, 4)
+		if shard < 0 || shard >= 4 {
+			t.Fatalf("shard %v of %v out of range", shard, ns)
+		}
+		if again := ShardForNamespace(ns, 4); again != shard {
+			t.Fatalf("shard of %v is not stable: %v and %v", ns, shard, again)
+		}
+		seen[shard] = true
+	}
+	if len(seen) != 4 {
+		t.Fatalf("expected namespaces in all shards, got %v", seen)
+	}
+}
+
+func createShardedElection(name string, shards int, client kubernetes.Interface) (*ShardedLeaderElection, *atomic.Bool, chan struct{}) {
+	s := NewShardedLeaderElection("ns", name, testLock, shards, client)
+	s.ttl = time.Second
+	running := atomic.NewBool(false)
+	s.AddRunFunction(func(stop <-chan struct{}) {
+		running.Store(true)
+		<-stop
+		running.Store(false)
+	})
+	stop := make(chan struct{})
+	go s.Run(stop)
+	return s, running, stop
+}
+
+func expectShards(t *testing.T, s *ShardedLeaderElection, expected ...int) {
+	t.Helper()
+	retry.UntilSuccessOrFail(t, func() error {
+		if got := s.OwnedShards(); !reflect.DeepEqual(got, expected) {
+			return fmt.Errorf("%v owns shards %v, want %v", s.name, got, expected)
+		}
+		return nil
+	}, retry.Timeout(time.Second*15))
+}
+
+func TestShardedLeaderElection(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	pod1, running1, stop1 := createShardedElection("pod1", 4, client)
+	expectShards(t, pod1, 0, 1, 2, 3)
+	if !running1.Load() {
+		t.Fatalf("expected run functions to run once a shard is owned")
+	}
+
+	// A new replica takes over half of the shards
+	pod2, running2, stop2 := createShardedElection("pod2", 4, client)
+	expectShards(t, pod1, 0, 2)
+	expectShards(t, pod2, 1, 3)
+	if !running2.Load() {
+		t.Fatalf("expected run functions to run once a shard is owned")
+	}
+	for i := 0; i < 10; i++ {
+		ns := fmt.Sprintf("ns-%d", i)
+		if pod1.Owns(ns) == pod2.Owns(ns) {
+			t.Fatalf("expected namespace %v to be owned by a single replica", ns)
+		}
+	}
+
+	// Once the first replica leaves, the second takes over all shards
+	close(stop1)
+	expectShards(t, pod2, 0, 1, 2, 3)
+	retry.UntilSuccessOrFail(t, func() error {
+		if running1.Load() {
+			return fmt.Errorf("run functions of a stopped replica still running")
+		}
+		return nil
+	}, retry.Timeout(time.Second*5))
+	close(stop2)
+}
+
+func TestShardedLeaderElectionSingleShard(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	pod1, running1, stop1 := createShardedElection("pod1", 1, client)
+	expectShards(t, pod1, 0)
+	if !pod1.Owns("any") || !running1.Load() {
+		t.Fatalf("expected the leader to own all namespaces")
+	}
+	// A single shard shares the lock of an unsharded election
+	_, stop := createElection(t, "pod2", false, client)
+	close(stop)
+	close(stop1)
+}
@@ -250,6 +250,19 @@ type Proxy struct {
 	WatchedResources map[string]*WatchedResource
 }
 
+// NackDetail describes a message rejected by a client.
+type NackDetail struct {
+	// Nonce of the rejected message.
+	Nonce string
+	// Message is the error detail reported by the client.
+	Message string
+	// Time the rejection was received.
+	Time time.Time
+	// ConfigsUpdated holds the configs updated since the client last accepted a message. It is empty if the
+	// rejected message was not generated following a config change, for example on the initial request.
+	ConfigsUpdated map[ConfigKey]struct{}
+}
+
 // WatchedResource tracks an active DiscoveryRequest subscription.
 type WatchedResource struct {
 	// TypeUrl is copied from the DiscoveryRequest.TypeUrl that initiated watching this resource.
@@ -281,6 +294,15 @@ type WatchedResource struct {
 	// NonceNacked is the last nacked message. This is reset following a successful ACK
 	NonceNacked string
 
+	// LastNack describes the last message rejected by the client. Unlike NonceNacked, it is kept after a
+	// successful ACK.
+	LastNack *NackDetail
+
+	// ConfigsUpdatedSent holds the configs updated by the push of the last sent message, along with those of
+	// the previous messages if they were rejected. It is used to attribute a rejection to the configs that likely
+	// caused it.
+	ConfigsUpdatedSent map[ConfigKey]struct{}
+
 	// LastSent tracks the time of the generated push, to determine the time it takes the client to ack.
 	LastSent time.Time
 
@@ -527,6 +549,10 @@ type NodeMetadata struct {
 	// UnprivilegedPod is used to determine whether a Gateway Pod can open ports < 1024
 	UnprivilegedPod string `json:"UNPRIVILEGED_POD,omitempty"`
 
+	// OutboundUDPPorts is the list of outbound UDP ports redirected to the proxy, from the
+	// traffic.sidecar.istio.io/includeOutboundUDPPorts annotation. Outbound UDP listeners are only built for these ports.
+	OutboundUDPPorts StringList `json:"traffic.sidecar.istio.io/includeOutboundUDPPorts,omitempty"`
+
 	// Contains a copy of the raw metadata. This is needed to lookup arbitrary values.
 	// If a value is known ahead of time it should be added to the struct rather than reading from here,
 	Raw map[string]interface{} `json:"-"`
@@ -697,6 +723,33 @@ func (node *Proxy) SetSidecarScope(ps *PushContext) {
 	node.PrevSidecarScope = sidecarScope
 }
 
+// WithSidecarScope returns a copy of the proxy using the given SidecarScope, to generate the configuration the
+// proxy would get with it without affecting the configuration pushed to the proxy.
+func (node *Proxy) WithSidecarScope(sidecarScope *SidecarScope) *Proxy {
+	node.RLock()
+	defer node.RUnlock()
+	return &Proxy{
+		Type:                 node.Type,
+		IPAddresses:          node.IPAddresses,
+		ID:                   node.ID,
+		Locality:             node.Locality,
+		DNSDomain:            node.DNSDomain,
+		ConfigNamespace:      node.ConfigNamespace,
+		Metadata:             node.Metadata,
+		SidecarScope:         sidecarScope,
+		PrevSidecarScope:     node.SidecarScope,
+		MergedGateway:        node.MergedGateway,
+		ServiceInstances:     node.ServiceInstances,
+		IstioVersion:         node.IstioVersion,
+		VerifiedIdentity:     node.VerifiedIdentity,
+		ipv6Support:          node.ipv6Support,
+		ipv4Support:          node.ipv4Support,
+		GlobalUnicastIP:      node.GlobalUnicastIP,
+		XdsResourceGenerator: node.XdsResourceGenerator,
+		WatchedResources:     node.WatchedResources,
+	}
+}
+
 // SetGatewaysForProxy merges the Gateway objects associated with this
 // proxy and caches the merged object in the proxy Node. This is a convenience hack so that
 // callers can simply call push.MergedGateways(node) instead of having to
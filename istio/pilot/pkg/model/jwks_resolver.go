@@ -91,6 +91,9 @@ var (
 
 	// JwtPubKeyRefreshInterval is the running interval of JWT pubKey refresh job.
 	JwtPubKeyRefreshInterval = features.PilotJwtPubKeyRefreshInterval
+
+	// jwksFetchProxy is the URL of the HTTP proxy used to fetch the JWKS, empty to use the proxy environment variables.
+	jwksFetchProxy = features.JwksFetchProxy
 )
 
 // jwtPubKeyEntry is a single cached entry for jwt public key.
@@ -102,6 +105,13 @@ type jwtPubKeyEntry struct {
 
 	// Cached item's last used time, which is set in GetPublicKey.
 	lastUsedTime time.Time
+
+	// The error of the last failed fetch, cleared on a successful fetch.
+	lastError     string
+	lastErrorTime time.Time
+
+	// Whether the pubKey was restored from the JwksStore and not fetched since.
+	restored bool
 }
 
 // jwtKey is a key in the JwksResolver keyEntries map.
@@ -142,6 +152,12 @@ type JwksResolver struct {
 
 	// How many times refresh job failed to fetch the public key from network, used in unit test.
 	refreshJobFetchFailedCount uint64
+
+	// store persists the fetched public keys, nil if persistence is disabled.
+	store JwksStore
+	// persistMutex serializes the writes to the store, lastPersisted is the last content written.
+	persistMutex  sync.Mutex
+	lastPersisted string
 }
 
 func init() {
@@ -150,12 +166,18 @@ func init() {
 
 // newJwksResolver creates new instance of JwksResolver.
 func newJwksResolver(evictionDuration, refreshDefaultInterval, refreshIntervalOnFailure, retryInterval time.Duration) *JwksResolver {
+	caBundlePaths := []string{jwksExtraRootCABundlePath}
+	for _, p := range strings.Split(features.JwksExtraCABundles, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			caBundlePaths = append(caBundlePaths, p)
+		}
+	}
 	return newJwksResolverWithCABundlePaths(
 		evictionDuration,
 		refreshDefaultInterval,
 		refreshIntervalOnFailure,
 		retryInterval,
-		[]string{jwksExtraRootCABundlePath},
+		caBundlePaths,
 	)
 }
 
@@ -174,6 +196,15 @@ func newJwksResolverWithCABundlePaths(
 	retryInterval time.Duration,
 	caBundlePaths []string,
 ) *JwksResolver {
+	proxy := http.ProxyFromEnvironment
+	if jwksFetchProxy != "" {
+		proxyURL, err := url.Parse(jwksFetchProxy)
+		if err != nil {
+			log.Errorf("Failed to parse the JWKS fetch proxy %q, using the proxy environment variables: %v", jwksFetchProxy, err)
+		} else {
+			proxy = http.ProxyURL(proxyURL)
+		}
+	}
 	ret := &JwksResolver{
 		evictionDuration:         evictionDuration,
 		refreshInterval:          refreshDefaultInterval,
@@ -183,7 +214,7 @@ func newJwksResolverWithCABundlePaths(
 		httpClient: &http.Client{
 			Timeout: jwksHTTPTimeOutInSec * time.Second,
 			Transport: &http.Transport{
-				Proxy:             http.ProxyFromEnvironment,
+				Proxy:             proxy,
 				DisableKeepAlives: true,
 				TLSClientConfig:   &tls.Config{InsecureSkipVerify: true},
 			},
@@ -206,7 +237,7 @@ func newJwksResolverWithCABundlePaths(
 		ret.secureHTTPClient = &http.Client{
 			Timeout: jwksHTTPTimeOutInSec * time.Second,
 			Transport: &http.Transport{
-				Proxy:             http.ProxyFromEnvironment,
+				Proxy:             proxy,
 				DisableKeepAlives: true,
 				TLSClientConfig: &tls.Config{
 					RootCAs: caCertPool,
@@ -256,11 +287,20 @@ func (r *JwksResolver) GetPublicKey(issuer string, jwksURI string) (string, erro
 		pubKey = string(resp)
 	}
 
-	r.keyEntries.Store(key, jwtPubKeyEntry{
+	e := jwtPubKeyEntry{
 		pubKey:            pubKey,
 		lastRefreshedTime: now,
 		lastUsedTime:      now,
-	})
+	}
+	if err != nil {
+		e.lastError = err.Error()
+		e.lastErrorTime = now
+	}
+	r.keyEntries.Store(key, e)
+	if err == nil {
+		// Persist in the background to not block the push.
+		go r.persist()
+	}
 
 	return pubKey, err
 }
@@ -414,6 +454,7 @@ func (r *JwksResolver) refresh() bool {
 					hasErrors = true
 					log.Errorf("Failed to resolve Jwks from issuer %q: %v", k.issuer, err)
 					atomic.AddUint64(&r.refreshJobFetchFailedCount, 1)
+					r.recordRefreshError(k, err, now)
 					return
 				}
 			}
@@ -423,6 +464,7 @@ func (r *JwksResolver) refresh() bool {
 				hasErrors = true
 				log.Errorf("Failed to refresh JWT public key from %q: %v", jwksURI, err)
 				atomic.AddUint64(&r.refreshJobFetchFailedCount, 1)
+				r.recordRefreshError(k, err, now)
 				return
 			}
 			newPubKey := string(resp)
@@ -449,6 +491,8 @@ func (r *JwksResolver) refresh() bool {
 	// Wait for all go routine to complete.
 	wg.Wait()
 
+	r.persist()
+
 	if hasChange {
 		atomic.AddUint64(&r.refreshJobKeyChangedCount, 1)
 		// Push public key changes to sidecars.
@@ -459,6 +503,131 @@ func (r *JwksResolver) refresh() bool {
 	return hasErrors
 }
 
+// recordRefreshError records the failed refresh of a key, keeping the cached public key.
+func (r *JwksResolver) recordRefreshError(k jwtKey, err error, now time.Time) {
+	if val, found := r.keyEntries.Load(k); found {
+		e := val.(jwtPubKeyEntry)
+		e.lastError = err.Error()
+		e.lastErrorTime = now
+		r.keyEntries.Store(k, e)
+	}
+}
+
+// SetStore enables persisting the fetched public keys to the store, and restores the public keys
+// persisted by a previous istiod. The restored keys are refreshed in the background and are used
+// until then, which keeps the JWT policies working during an outage of the JWKS URI.
+func (r *JwksResolver) SetStore(store JwksStore) error {
+	persisted, err := store.Load()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	restored := 0
+	for _, p := range persisted {
+		if p.Jwks == "" || now.Sub(p.FetchedTime) >= r.evictionDuration {
+			continue
+		}
+		key := jwtKey{issuer: p.Issuer, jwksURI: p.JwksURI}
+		if _, found := r.keyEntries.Load(key); found {
+			continue
+		}
+		r.keyEntries.Store(key, jwtPubKeyEntry{
+			pubKey:            p.Jwks,
+			lastRefreshedTime: p.FetchedTime,
+			lastUsedTime:      now,
+			restored:          true,
+		})
+		restored++
+	}
+	r.persistMutex.Lock()
+	r.store = store
+	r.persistMutex.Unlock()
+	if restored > 0 {
+		log.Infof("Restored %d persisted JWT public keys", restored)
+		go r.refresh()
+	}
+	return nil
+}
+
+// persist saves the fetched public keys to the store if they, or the time they were last refreshed, changed
+// since the last save.
+func (r *JwksResolver) persist() {
+	r.persistMutex.Lock()
+	defer r.persistMutex.Unlock()
+	if r.store == nil {
+		return
+	}
+
+	var jwks []PersistedJwks
+	r.keyEntries.Range(func(key interface{}, value interface{}) bool {
+		k := key.(jwtKey)
+		e := value.(jwtPubKeyEntry)
+		if e.pubKey != "" {
+			jwks = append(jwks, PersistedJwks{Issuer: k.issuer, JwksURI: k.jwksURI, Jwks: e.pubKey, FetchedTime: e.lastRefreshedTime})
+		}
+		return true
+	})
+	sort.Slice(jwks, func(i, j int) bool {
+		if jwks[i].Issuer != jwks[j].Issuer {
+			return jwks[i].Issuer < jwks[j].Issuer
+		}
+		return jwks[i].JwksURI < jwks[j].JwksURI
+	})
+
+	// The fetched time is compared too, so that a key which did not change is not dropped as stale on restore.
+	var content strings.Builder
+	for _, j := range jwks {
+		content.WriteString(j.Issuer + "\n" + j.JwksURI + "\n" + j.Jwks + "\n" + j.FetchedTime.String() + "\n")
+	}
+	if content.String() == r.lastPersisted {
+		return
+	}
+	if err := r.store.Save(jwks); err != nil {
+		log.Errorf("Failed to persist JWT public keys: %v", err)
+		return
+	}
+	r.lastPersisted = content.String()
+}
+
+// JwksStatus is the status of the public key of an issuer, as shown by the /debug/jwksz endpoint.
+type JwksStatus struct {
+	Issuer            string    `json:"issuer"`
+	JwksURI           string    `json:"jwks_uri,omitempty"`
+	HasKey            bool      `json:"has_key"`
+	Restored          bool      `json:"restored"`
+	LastRefreshedTime time.Time `json:"last_refreshed_time"`
+	LastUsedTime      time.Time `json:"last_used_time"`
+	LastError         string    `json:"last_error,omitempty"`
+	LastErrorTime     time.Time `json:"last_error_time"`
+}
+
+// Status returns the status of the cached public keys, sorted by issuer.
+func (r *JwksResolver) Status() []JwksStatus {
+	status := []JwksStatus{}
+	r.keyEntries.Range(func(key interface{}, value interface{}) bool {
+		k := key.(jwtKey)
+		e := value.(jwtPubKeyEntry)
+		status = append(status, JwksStatus{
+			Issuer:            k.issuer,
+			JwksURI:           k.jwksURI,
+			HasKey:            e.pubKey != "",
+			Restored:          e.restored,
+			LastRefreshedTime: e.lastRefreshedTime,
+			LastUsedTime:      e.lastUsedTime,
+			LastError:         e.lastError,
+			LastErrorTime:     e.lastErrorTime,
+		})
+		return true
+	})
+	sort.Slice(status, func(i, j int) bool {
+		if status[i].Issuer != status[j].Issuer {
+			return status[i].Issuer < status[j].Issuer
+		}
+		return status[i].JwksURI < status[j].JwksURI
+	})
+	return status
+}
+
 // Close will shut down the refresher job.
 // TODO: may need to figure out the right place to call this function.
 // (right now calls it from initDiscoveryService in pkg/bootstrap/server.go).
@@ -16,6 +16,9 @@ package model
 
 import (
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -571,6 +574,170 @@ func verifyKeyLastRefreshedTime(t *testing.T, r *JwksResolver, ms *test.MockOpen
 	}
 }
 
+func TestJwksPersistence(t *testing.T) {
+	store := NewFileJwksStore(filepath.Join(t.TempDir(), "jwks", "jwks.json"))
+
+	r := newJwksResolver(JwtPubKeyEvictionDuration, JwtPubKeyRefreshInterval, JwtPubKeyRefreshIntervalOnFailure, testRetryInterval)
+	defer r.Close()
+	if err := r.SetStore(store); err != nil {
+		t.Fatal(err)
+	}
+	ms := startMockServer(t)
+	mockCertURL := ms.URL + "/oauth2/v3/certs"
+	if _, err := r.GetPublicKey("testIssuer", mockCertURL); err != nil {
+		t.Fatal(err)
+	}
+	retry.UntilSuccessOrFail(t, func() error {
+		persisted, err := store.Load()
+		if err != nil {
+			return err
+		}
+		if len(persisted) != 1 || persisted[0].Issuer != "testIssuer" || persisted[0].JwksURI != mockCertURL ||
+			persisted[0].Jwks != test.JwtPubKey1 {
+			return fmt.Errorf("unexpected persisted JWKS %+v", persisted)
+		}
+		return nil
+	}, retry.Delay(time.Millisecond))
+
+	// A restarted resolver uses the persisted key while the JWKS URI is unavailable.
+	_ = ms.Stop()
+	restarted := newJwksResolver(JwtPubKeyEvictionDuration, JwtPubKeyRefreshInterval, JwtPubKeyRefreshIntervalOnFailure, testRetryInterval)
+	defer restarted.Close()
+	if err := restarted.SetStore(store); err != nil {
+		t.Fatal(err)
+	}
+	pk, err := restarted.GetPublicKey("testIssuer", mockCertURL)
+	if err != nil {
+		t.Fatalf("GetPublicKey() returns error: %v", err)
+	}
+	if pk != test.JwtPubKey1 {
+		t.Errorf("GetPublicKey() returns %s, want the persisted %s", pk, test.JwtPubKey1)
+	}
+
+	// The failed refresh of the restored key is reported in the status, and the key is kept.
+	retry.UntilSuccessOrFail(t, func() error {
+		status := restarted.Status()
+		if len(status) != 1 {
+			return fmt.Errorf("unexpected status %+v", status)
+		}
+		if !status[0].HasKey || !status[0].Restored || status[0].LastError == "" {
+			return fmt.Errorf("unexpected status %+v", status[0])
+		}
+		return nil
+	}, retry.Delay(time.Millisecond))
+}
+
+func TestJwksPersistenceUnchangedKey(t *testing.T) {
+	store := NewFileJwksStore(filepath.Join(t.TempDir(), "jwks.json"))
+	ms := startMockServer(t)
+	mockCertURL := ms.URL + "/oauth2/v3/certs"
+	key := jwtKey{issuer: "testIssuer", jwksURI: mockCertURL}
+
+	// The key was first fetched more than the eviction duration ago, and has not changed since.
+	r := newJwksResolver(JwtPubKeyEvictionDuration, JwtPubKeyRefreshInterval, JwtPubKeyRefreshIntervalOnFailure, testRetryInterval)
+	defer r.Close()
+	if err := r.SetStore(store); err != nil {
+		t.Fatal(err)
+	}
+	r.keyEntries.Store(key, jwtPubKeyEntry{
+		pubKey:            test.JwtPubKey1,
+		lastRefreshedTime: time.Now().Add(-JwtPubKeyEvictionDuration - time.Hour),
+		lastUsedTime:      time.Now(),
+	})
+	r.persist()
+	// Simulate the refresh right before the key expires, which succeeds without changing the key.
+	r.keyEntries.Store(key, jwtPubKeyEntry{
+		pubKey:            test.JwtPubKey1,
+		lastRefreshedTime: time.Now().Add(-JwtPubKeyEvictionDuration + time.Hour),
+		lastUsedTime:      time.Now(),
+	})
+	r.refresh()
+
+	// A restarted resolver keeps the key while the JWKS URI is unavailable.
+	_ = ms.Stop()
+	restarted := newJwksResolver(JwtPubKeyEvictionDuration, JwtPubKeyRefreshInterval, JwtPubKeyRefreshIntervalOnFailure, testRetryInterval)
+	defer restarted.Close()
+	if err := restarted.SetStore(store); err != nil {
+		t.Fatal(err)
+	}
+	restarted.refresh()
+	pk, err := restarted.GetPublicKey("testIssuer", mockCertURL)
+	if err != nil {
+		t.Fatalf("GetPublicKey() returns error: %v", err)
+	}
+	if pk != test.JwtPubKey1 {
+		t.Errorf("GetPublicKey() returns %s, want the persisted %s", pk, test.JwtPubKey1)
+	}
+}
+
+func TestJwksPersistenceSkipsExpired(t *testing.T) {
+	store := NewFileJwksStore(filepath.Join(t.TempDir(), "jwks.json"))
+	if err := store.Save([]PersistedJwks{
+		{Issuer: "expired", Jwks: test.JwtPubKey1, FetchedTime: time.Now().Add(-2 * time.Hour)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	r := newJwksResolver(time.Hour, JwtPubKeyRefreshInterval, JwtPubKeyRefreshIntervalOnFailure, testRetryInterval)
+	defer r.Close()
+	if err := r.SetStore(store); err != nil {
+		t.Fatal(err)
+	}
+	if status := r.Status(); len(status) != 0 {
+		t.Errorf("expected the expired key not to be restored, got %+v", status)
+	}
+}
+
+func TestJwksFetchProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// A forward proxy receives the absolute URL of the upstream request.
+		proxied = append(proxied, req.URL.String())
+		_, _ = w.Write([]byte(test.JwtPubKey1))
+	}))
+	defer proxy.Close()
+
+	jwksFetchProxy = proxy.URL
+	defer func() { jwksFetchProxy = "" }()
+	r := newJwksResolver(JwtPubKeyEvictionDuration, JwtPubKeyRefreshInterval, JwtPubKeyRefreshIntervalOnFailure, testRetryInterval)
+	defer r.Close()
+
+	pk, err := r.GetPublicKey("testIssuer", "http://jwks.example.com/certs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pk != test.JwtPubKey1 {
+		t.Errorf("GetPublicKey() returns %s, want %s", pk, test.JwtPubKey1)
+	}
+	if len(proxied) != 1 || proxied[0] != "http://jwks.example.com/certs" {
+		t.Errorf("expected the JWKS to be fetched through the proxy, got %v", proxied)
+	}
+}
+
+func TestJwksStatus(t *testing.T) {
+	r := newJwksResolver(JwtPubKeyEvictionDuration, JwtPubKeyRefreshInterval, JwtPubKeyRefreshIntervalOnFailure, testRetryInterval)
+	defer r.Close()
+
+	ms := startMockServer(t)
+	defer ms.Stop()
+	if _, err := r.GetPublicKey("b-issuer", ms.URL+"/oauth2/v3/certs"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GetPublicKey("a-issuer", "http://xyz"); err == nil {
+		t.Fatal("expected error fetching from an invalid URL")
+	}
+
+	status := r.Status()
+	if len(status) != 2 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	if status[0].Issuer != "a-issuer" || status[0].HasKey || status[0].LastError == "" || status[0].LastErrorTime.IsZero() {
+		t.Errorf("unexpected status of the failed fetch %+v", status[0])
+	}
+	if status[1].Issuer != "b-issuer" || !status[1].HasKey || status[1].LastError != "" || status[1].Restored {
+		t.Errorf("unexpected status of the successful fetch %+v", status[1])
+	}
+}
+
 func TestCompareJWKSResponse(t *testing.T) {
 	type args struct {
 		oldKeyString string
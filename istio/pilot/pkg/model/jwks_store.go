@@ -0,0 +1,95 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// PersistedJwks is the last successfully fetched JWKS of an issuer.
+type PersistedJwks struct {
+	Issuer      string    `json:"issuer"`
+	JwksURI     string    `json:"jwks_uri,omitempty"`
+	Jwks        string    `json:"jwks"`
+	FetchedTime time.Time `json:"fetched_time"`
+}
+
+// JwksStore persists the JWKS fetched by the JwksResolver, so they can be restored after a restart
+// when the JWKS URI is unavailable.
+type JwksStore interface {
+	// Load returns the persisted JWKS, or nothing if none was persisted yet.
+	Load() ([]PersistedJwks, error)
+	// Save replaces the persisted JWKS.
+	Save([]PersistedJwks) error
+}
+
+// MarshalPersistedJwks encodes the JWKS for a JwksStore.
+func MarshalPersistedJwks(jwks []PersistedJwks) ([]byte, error) {
+	return json.MarshalIndent(jwks, "", "  ")
+}
+
+// UnmarshalPersistedJwks decodes the JWKS encoded by MarshalPersistedJwks.
+func UnmarshalPersistedJwks(data []byte) ([]PersistedJwks, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var jwks []PersistedJwks
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, err
+	}
+	return jwks, nil
+}
+
+type fileJwksStore struct {
+	path string
+}
+
+var _ JwksStore = &fileJwksStore{}
+
+// NewFileJwksStore returns a JwksStore persisting the JWKS to the given file.
+func NewFileJwksStore(path string) JwksStore {
+	return &fileJwksStore{path: path}
+}
+
+func (s *fileJwksStore) Load() ([]PersistedJwks, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return UnmarshalPersistedJwks(data)
+}
+
+func (s *fileJwksStore) Save(jwks []PersistedJwks) error {
+	data, err := MarshalPersistedJwks(jwks)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return err
+	}
+	// Write to a temporary file first so a crash never leaves a partially written file.
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
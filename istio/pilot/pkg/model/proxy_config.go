@@ -0,0 +1,131 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	"istio.io/istio/pkg/config"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/schema/gvk"
+)
+
+// ProxyConfigs organizes ProxyConfig resources by namespace.
+type ProxyConfigs struct {
+	// Maps from namespace to the ProxyConfig resources, sorted by creation time.
+	namespaceToProxyConfigs map[string][]config.Config
+
+	// The name of the root namespace. ProxyConfigs without a selector in the root namespace apply to all
+	// workloads in the mesh.
+	rootNamespace string
+}
+
+// GetProxyConfigs returns the ProxyConfigs in the given store.
+func GetProxyConfigs(store IstioConfigStore, mesh *meshconfig.MeshConfig) (*ProxyConfigs, error) {
+	proxyConfigs := &ProxyConfigs{
+		namespaceToProxyConfigs: map[string][]config.Config{},
+		rootNamespace:           mesh.GetRootNamespace(),
+	}
+	configs, err := store.List(gvk.ProxyConfig, NamespaceAll)
+	if err != nil {
+		return nil, err
+	}
+	sortConfigByCreationTime(configs)
+	for _, cfg := range configs {
+		proxyConfigs.namespaceToProxyConfigs[cfg.Namespace] = append(proxyConfigs.namespaceToProxyConfigs[cfg.Namespace], cfg)
+	}
+	return proxyConfigs, nil
+}
+
+// EffectiveProxyConfig returns the proxy configuration of a workload with the given labels in the namespace. It is
+// the mesh-wide default overridden by the ProxyConfig resources of the root namespace, the namespace and the
+// workload, in that order. When several resources apply at the same level the oldest one is used.
+func (p *ProxyConfigs) EffectiveProxyConfig(namespace string, workload labels.Instance, mc *meshconfig.MeshConfig) *meshconfig.ProxyConfig {
+	pc := &meshconfig.ProxyConfig{}
+	if mc.GetDefaultConfig() != nil {
+		pc = proto.Clone(mc.GetDefaultConfig()).(*meshconfig.ProxyConfig)
+	}
+	p.apply(pc, namespace, workload)
+	return pc
+}
+
+// apply overrides pc with the ProxyConfig resources of the root namespace, the namespace and the workload.
+func (p *ProxyConfigs) apply(pc *meshconfig.ProxyConfig, namespace string, workload labels.Instance) {
+	if p == nil {
+		return
+	}
+
+	if namespace != p.rootNamespace {
+		if spec := p.namespaceDefault(p.rootNamespace); spec != nil {
+			applyProxyConfigSpec(pc, spec)
+		}
+	}
+	if spec := p.namespaceDefault(namespace); spec != nil {
+		applyProxyConfigSpec(pc, spec)
+	}
+	for _, cfg := range p.namespaceToProxyConfigs[namespace] {
+		spec := cfg.Spec.(*networkingv1beta1.ProxyConfigSpec)
+		if spec.Selector == nil || len(spec.Selector.MatchLabels) == 0 {
+			continue
+		}
+		if labels.Instance(spec.Selector.MatchLabels).SubsetOf(workload) {
+			applyProxyConfigSpec(pc, spec)
+			break
+		}
+	}
+}
+
+// namespaceDefault returns the oldest ProxyConfig without a selector in the namespace.
+func (p *ProxyConfigs) namespaceDefault(namespace string) *networkingv1beta1.ProxyConfigSpec {
+	for _, cfg := range p.namespaceToProxyConfigs[namespace] {
+		spec := cfg.Spec.(*networkingv1beta1.ProxyConfigSpec)
+		if spec.Selector == nil || len(spec.Selector.MatchLabels) == 0 {
+			return spec
+		}
+	}
+	return nil
+}
+
+// applyProxyConfigSpec overrides the fields of pc that are set in spec.
+func applyProxyConfigSpec(pc *meshconfig.ProxyConfig, spec *networkingv1beta1.ProxyConfigSpec) {
+	if spec.Concurrency != nil {
+		pc.Concurrency = &types.Int32Value{Value: *spec.Concurrency}
+	}
+	if spec.DrainDuration != nil {
+		pc.DrainDuration = types.DurationProto(spec.DrainDuration.Duration)
+	}
+	if spec.ParentShutdownDuration != nil {
+		pc.ParentShutdownDuration = types.DurationProto(spec.ParentShutdownDuration.Duration)
+	}
+	if spec.TerminationDrainDuration != nil {
+		pc.TerminationDrainDuration = types.DurationProto(spec.TerminationDrainDuration.Duration)
+	}
+	if spec.Tracing != nil && spec.Tracing.Sampling != nil {
+		if pc.Tracing == nil {
+			pc.Tracing = &meshconfig.Tracing{}
+		}
+		pc.Tracing.Sampling = *spec.Tracing.Sampling
+	}
+	if m := spec.ProxyStatsMatcher; m != nil {
+		pc.ProxyStatsMatcher = &meshconfig.ProxyConfig_ProxyStatsMatcher{
+			InclusionPrefixes: m.InclusionPrefixes,
+			InclusionSuffixes: m.InclusionSuffixes,
+			InclusionRegexps:  m.InclusionRegexps,
+		}
+	}
+}
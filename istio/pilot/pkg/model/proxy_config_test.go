@@ -0,0 +1,149 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/config/schema/gvk"
+)
+
+func TestEffectiveProxyConfig(t *testing.T) {
+	concurrency := func(c int32) *int32 { return &c }
+	sampling := 10.0
+	now := time.Now()
+	proxyConfigs := []config.Config{
+		newProxyConfig("root", "istio-system", now, &networkingv1beta1.ProxyConfigSpec{
+			Concurrency:   concurrency(1),
+			DrainDuration: &metav1.Duration{Duration: 10 * time.Second},
+		}),
+		newProxyConfig("ns", "foo", now, &networkingv1beta1.ProxyConfigSpec{
+			Concurrency: concurrency(2),
+			Tracing:     &networkingv1beta1.ProxyTracing{Sampling: &sampling},
+		}),
+		newProxyConfig("ns-newer", "foo", now.Add(time.Second), &networkingv1beta1.ProxyConfigSpec{
+			Concurrency: concurrency(20),
+		}),
+		newProxyConfig("workload", "foo", now, &networkingv1beta1.ProxyConfigSpec{
+			Selector:    &networkingv1beta1.WorkloadSelector{MatchLabels: map[string]string{"app": "ratings"}},
+			Concurrency: concurrency(3),
+			ProxyStatsMatcher: &networkingv1beta1.ProxyStatsMatcher{
+				InclusionPrefixes: []string{"cluster.outbound"},
+			},
+		}),
+		newProxyConfig("workload-newer", "foo", now.Add(time.Second), &networkingv1beta1.ProxyConfigSpec{
+			Selector:    &networkingv1beta1.WorkloadSelector{MatchLabels: map[string]string{"app": "ratings"}},
+			Concurrency: concurrency(30),
+		}),
+	}
+
+	m := mesh.DefaultMeshConfig()
+	store := model.MakeIstioStore(memory.Make(collections.Pilot))
+	for _, pc := range proxyConfigs {
+		if _, err := store.Create(pc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pcs, err := model.GetProxyConfigs(store, &m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name        string
+		namespace   string
+		labels      labels.Instance
+		concurrency int32
+		sampling    float64
+		drain       time.Duration
+		statsPrefix []string
+	}{
+		{
+			name:        "root namespace only",
+			namespace:   "bar",
+			labels:      labels.Instance{"app": "ratings"},
+			concurrency: 1,
+			sampling:    m.DefaultConfig.Tracing.GetSampling(),
+			drain:       10 * time.Second,
+		},
+		{
+			name:        "namespace overrides root namespace",
+			namespace:   "foo",
+			labels:      labels.Instance{"app": "reviews"},
+			concurrency: 2,
+			sampling:    10,
+			drain:       10 * time.Second,
+		},
+		{
+			name:        "workload overrides namespace",
+			namespace:   "foo",
+			labels:      labels.Instance{"app": "ratings", "version": "v1"},
+			concurrency: 3,
+			sampling:    10,
+			drain:       10 * time.Second,
+			statsPrefix: []string{"cluster.outbound"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pcs.EffectiveProxyConfig(tt.namespace, tt.labels, &m)
+			if got.GetConcurrency().GetValue() != tt.concurrency {
+				t.Errorf("got concurrency %v, want %v", got.GetConcurrency().GetValue(), tt.concurrency)
+			}
+			if got.GetTracing().GetSampling() != tt.sampling {
+				t.Errorf("got sampling %v, want %v", got.GetTracing().GetSampling(), tt.sampling)
+			}
+			if drain, _ := types.DurationFromProto(got.GetDrainDuration()); drain != tt.drain {
+				t.Errorf("got drain duration %v, want %v", drain, tt.drain)
+			}
+			if prefixes := got.GetProxyStatsMatcher().GetInclusionPrefixes(); len(prefixes) != len(tt.statsPrefix) {
+				t.Errorf("got stats inclusion prefixes %v, want %v", prefixes, tt.statsPrefix)
+			}
+		})
+	}
+
+	// Resolving must not modify the mesh config.
+	if defaults := mesh.DefaultProxyConfig(); m.DefaultConfig.GetConcurrency().GetValue() != defaults.GetConcurrency().GetValue() {
+		t.Fatalf("mesh default config was modified")
+	}
+	var nilConfigs *model.ProxyConfigs
+	if got := nilConfigs.EffectiveProxyConfig("foo", nil, &meshconfig.MeshConfig{}); got == nil {
+		t.Fatalf("expected an empty proxy config")
+	}
+}
+
+func newProxyConfig(name, ns string, created time.Time, spec *networkingv1beta1.ProxyConfigSpec) config.Config {
+	return config.Config{
+		Meta: config.Meta{
+			GroupVersionKind:  gvk.ProxyConfig,
+			Name:              name,
+			Namespace:         ns,
+			CreationTimestamp: created,
+		},
+		Spec: spec,
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/util/sets"
 	"istio.io/istio/pkg/config"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
@@ -176,6 +177,12 @@ type PushContext struct {
 	// envoy filters for each namespace including global config namespace
 	envoyFiltersByNamespace map[string][]*EnvoyFilterWrapper
 
+	// local rate limits for each namespace, sorted by creation time
+	localRateLimitsByNamespace map[string][]config.Config
+
+	// ProxyConfigs stores the ProxyConfig resources of the mesh, resolved per proxy by the PCDS generator.
+	ProxyConfigs *ProxyConfigs `json:"-"`
+
 	// AuthnPolicies contains Authn policies by namespace.
 	AuthnPolicies *AuthenticationPolicies `json:"-"`
 
@@ -450,6 +457,13 @@ var (
 		"Number of conflicting wildcard http listeners with current wildcard tcp listener.",
 	)
 
+	// ProxyStatusConflictOutboundListenerUDPOverUDP metric tracks number of
+	// UDP listeners that conflicted with existing UDP listeners on same port
+	ProxyStatusConflictOutboundListenerUDPOverUDP = monitoring.NewGauge(
+		"pilot_conflict_outbound_listener_udp_over_current_udp",
+		"Number of conflicting udp listeners with current udp listener.",
+	)
+
 	// ProxyStatusConflictInboundListener tracks cases of multiple inbound
 	// listeners - 2 services selecting the same port of the pod.
 	ProxyStatusConflictInboundListener = monitoring.NewGauge(
@@ -509,6 +523,7 @@ var (
 		ProxyStatusConflictOutboundListenerTCPOverHTTP,
 		ProxyStatusConflictOutboundListenerTCPOverTCP,
 		ProxyStatusConflictOutboundListenerHTTPOverTCP,
+		ProxyStatusConflictOutboundListenerUDPOverUDP,
 		ProxyStatusConflictInboundListener,
 		DuplicatedClusters,
 		ProxyStatusClusterNoInstances,
@@ -528,14 +543,15 @@ func init() {
 func NewPushContext() *PushContext {
 	// TODO: detect push in progress, don't update status if set
 	return &PushContext{
-		ServiceIndex:            newServiceIndex(),
-		virtualServiceIndex:     newVirtualServiceIndex(),
-		destinationRuleIndex:    newDestinationRuleIndex(),
-		sidecarsByNamespace:     map[string][]*SidecarScope{},
-		envoyFiltersByNamespace: map[string][]*EnvoyFilterWrapper{},
-		gatewayIndex:            newGatewayIndex(),
-		ProxyStatus:             map[string]map[string]ProxyPushStatus{},
-		ServiceAccounts:         map[host.Name]map[int][]string{},
+		ServiceIndex:               newServiceIndex(),
+		virtualServiceIndex:        newVirtualServiceIndex(),
+		destinationRuleIndex:       newDestinationRuleIndex(),
+		sidecarsByNamespace:        map[string][]*SidecarScope{},
+		envoyFiltersByNamespace:    map[string][]*EnvoyFilterWrapper{},
+		localRateLimitsByNamespace: map[string][]config.Config{},
+		gatewayIndex:               newGatewayIndex(),
+		ProxyStatus:                map[string]map[string]ProxyPushStatus{},
+		ServiceAccounts:            map[host.Name]map[int][]string{},
 	}
 }
 
@@ -908,8 +924,11 @@ func (ps *PushContext) getExportedDestinationRuleFromNamespace(owningNamespace s
 }
 
 // IsClusterLocal indicates whether the endpoints for the service should only be accessible to clients
-// within the cluster.
+// within the cluster, as set by the service itself or by the MeshConfig.
 func (ps *PushContext) IsClusterLocal(service *Service) bool {
+	if service.Attributes.ClusterLocal {
+		return true
+	}
 	_, ok := MostSpecificHostMatch(service.Hostname, nil, ps.clusterLocalHosts)
 	return ok
 }
@@ -1007,6 +1026,14 @@ func (ps *PushContext) createNewContext(env *Environment) error {
 		return err
 	}
 
+	if err := ps.initLocalRateLimits(env); err != nil {
+		return err
+	}
+
+	if err := ps.initProxyConfigs(env); err != nil {
+		return err
+	}
+
 	if err := ps.initGateways(env); err != nil {
 		return err
 	}
@@ -1023,7 +1050,7 @@ func (ps *PushContext) updateContext(
 	oldPushContext *PushContext,
 	pushReq *PushRequest) error {
 	var servicesChanged, virtualServicesChanged, destinationRulesChanged, gatewayChanged,
-		authnChanged, authzChanged, envoyFiltersChanged, sidecarsChanged bool
+		authnChanged, authzChanged, envoyFiltersChanged, localRateLimitsChanged, proxyConfigsChanged, sidecarsChanged bool
 
 	for conf := range pushReq.ConfigsUpdated {
 		switch conf.Kind {
@@ -1039,6 +1066,10 @@ func (ps *PushContext) updateContext(
 			sidecarsChanged = true
 		case gvk.EnvoyFilter:
 			envoyFiltersChanged = true
+		case gvk.LocalRateLimit:
+			localRateLimitsChanged = true
+		case gvk.ProxyConfig:
+			proxyConfigsChanged = true
 		case gvk.AuthorizationPolicy:
 			authzChanged = true
 		case gvk.RequestAuthentication,
@@ -1102,6 +1133,22 @@ func (ps *PushContext) updateContext(
 		ps.envoyFiltersByNamespace = oldPushContext.envoyFiltersByNamespace
 	}
 
+	if localRateLimitsChanged {
+		if err := ps.initLocalRateLimits(env); err != nil {
+			return err
+		}
+	} else {
+		ps.localRateLimitsByNamespace = oldPushContext.localRateLimitsByNamespace
+	}
+
+	if proxyConfigsChanged {
+		if err := ps.initProxyConfigs(env); err != nil {
+			return err
+		}
+	} else {
+		ps.ProxyConfigs = oldPushContext.ProxyConfigs
+	}
+
 	if gatewayChanged {
 		if err := ps.initGateways(env); err != nil {
 			return err
@@ -1199,7 +1246,8 @@ func (ps *PushContext) initServiceAccounts(env *Environment, services []*Service
 			ps.ServiceAccounts[svc.Hostname] = map[int][]string{}
 		}
 		for _, port := range svc.Ports {
-			if port.Protocol == protocol.UDP {
+			// UDP ports share the instances of a port of another protocol with the same value, if any.
+			if _, f := svc.Ports.GetByPort(port.Port); f && port.Protocol == protocol.UDP {
 				continue
 			}
 			ps.ServiceAccounts[svc.Hostname][port.Port] = env.GetIstioServiceAccounts(svc, []int{port.Port})
@@ -1657,6 +1705,52 @@ func (ps *PushContext) EnvoyFilters(proxy *Proxy) *EnvoyFilterWrapper {
 	return out
 }
 
+// pre computes local rate limits per namespace
+func (ps *PushContext) initLocalRateLimits(env *Environment) error {
+	localRateLimits, err := env.List(gvk.LocalRateLimit, NamespaceAll)
+	if err != nil {
+		return err
+	}
+
+	sortConfigByCreationTime(localRateLimits)
+
+	ps.localRateLimitsByNamespace = make(map[string][]config.Config)
+	for _, localRateLimit := range localRateLimits {
+		ps.localRateLimitsByNamespace[localRateLimit.Namespace] = append(ps.localRateLimitsByNamespace[localRateLimit.Namespace], localRateLimit)
+	}
+	return nil
+}
+
+// LocalRateLimits returns the LocalRateLimit resources of the proxy's namespace selecting it, oldest first.
+func (ps *PushContext) LocalRateLimits(proxy *Proxy) []config.Config {
+	if proxy == nil {
+		return nil
+	}
+	var workloadLabels labels.Collection
+	// This should never happen except in tests.
+	if proxy.Metadata != nil && len(proxy.Metadata.Labels) > 0 {
+		workloadLabels = labels.Collection{proxy.Metadata.Labels}
+	}
+	var out []config.Config
+	for _, cfg := range ps.localRateLimitsByNamespace[proxy.ConfigNamespace] {
+		selector := cfg.Spec.(*networkingv1beta1.LocalRateLimitSpec).Selector
+		if selector == nil || workloadLabels.IsSupersetOf(selector.MatchLabels) {
+			out = append(out, cfg)
+		}
+	}
+	return out
+}
+
+// pre computes the ProxyConfig resources per namespace
+func (ps *PushContext) initProxyConfigs(env *Environment) error {
+	proxyConfigs, err := GetProxyConfigs(env, env.Mesh())
+	if err != nil {
+		return err
+	}
+	ps.ProxyConfigs = proxyConfigs
+	return nil
+}
+
 // pre computes gateways per namespace
 func (ps *PushContext) initGateways(env *Environment) error {
 	gatewayConfigs, err := env.List(gvk.Gateway, NamespaceAll)
@@ -564,7 +564,8 @@ func TestInitPushContext(t *testing.T) {
 	diff := cmp.Diff(old, newPush,
 		// Allow looking into exported fields for parts of push context
 		cmp.AllowUnexported(PushContext{}, exportToDefaults{}, serviceIndex{}, virtualServiceIndex{},
-			destinationRuleIndex{}, gatewayIndex{}, processedDestRules{}, IstioEgressListenerWrapper{}, SidecarScope{}, AuthenticationPolicies{}),
+			destinationRuleIndex{}, gatewayIndex{}, processedDestRules{}, IstioEgressListenerWrapper{}, SidecarScope{}, AuthenticationPolicies{},
+			ProxyConfigs{}),
 		// These are not feasible/worth comparing
 		cmpopts.IgnoreTypes(sync.RWMutex{}, localServiceDiscovery{}, FakeStore{}, atomic.Bool{}, sync.Mutex{}),
 		cmpopts.IgnoreInterfaces(struct{ mesh.Holder }{}),
@@ -1529,10 +1530,11 @@ func TestServiceWithExportTo(t *testing.T) {
 
 func TestIsClusterLocal(t *testing.T) {
 	cases := []struct {
-		name     string
-		m        meshconfig.MeshConfig
-		host     string
-		expected bool
+		name         string
+		m            meshconfig.MeshConfig
+		host         string
+		clusterLocal bool
+		expected     bool
 	}{
 		{
 			name:     "local by default",
@@ -1622,6 +1624,13 @@ func TestIsClusterLocal(t *testing.T) {
 			host:     "s.ns3.svc.cluster.local",
 			expected: false,
 		},
+		{
+			name:         "local service",
+			m:            mesh.DefaultMeshConfig(),
+			host:         "s.ns1.svc.cluster.local",
+			clusterLocal: true,
+			expected:     true,
+		},
 	}
 
 	for _, c := range cases {
@@ -1635,7 +1644,8 @@ func TestIsClusterLocal(t *testing.T) {
 			push.initClusterLocalHosts(env)
 
 			svc := &Service{
-				Hostname: host.Name(c.host),
+				Hostname:   host.Name(c.host),
+				Attributes: ServiceAttributes{ClusterLocal: c.clusterLocal},
 			}
 			clusterLocal := push.IsClusterLocal(svc)
 			g.Expect(clusterLocal).To(Equal(c.expected))
@@ -117,6 +117,9 @@ const (
 	DNSLB
 	// Passthrough implies that the proxy should forward traffic to the destination IP requested by the caller
 	Passthrough
+	// DynamicDNSLB implies that the proxy will resolve the DNS address of each host requested by the caller, for
+	// services with wildcard hostnames and no endpoints
+	DynamicDNSLB
 )
 
 // String converts Resolution in to String.
@@ -128,6 +131,8 @@ func (resolution Resolution) String() string {
 		return "DNS"
 	case Passthrough:
 		return "Passthrough"
+	case DynamicDNSLB:
+		return "DynamicDNS"
 	default:
 		return fmt.Sprintf("%d", int(resolution))
 	}
@@ -445,6 +450,10 @@ type ServiceAttributes struct {
 	// Applicable to both Kubernetes and ServiceEntries.
 	LabelSelectors map[string]string
 
+	// ClusterLocal is true if the endpoints of the service must only be reached by clients in the same cluster,
+	// in addition to the cluster-local hosts of the MeshConfig.
+	ClusterLocal bool
+
 	// For Kubernetes platform
 
 	// ClusterExternalAddresses is a mapping between a cluster name and the external
@@ -553,6 +562,16 @@ func (ports PortList) GetByPort(num int) (*Port, bool) {
 	return nil, false
 }
 
+// GetByPortAndProtocol retrieves a port declaration by port value and protocol
+func (ports PortList) GetByPortAndProtocol(num int, p protocol.Instance) (*Port, bool) {
+	for _, port := range ports {
+		if port.Port == num && port.Protocol == p {
+			return port, true
+		}
+	}
+	return nil, false
+}
+
 // External predicate checks whether the service is external
 func (s *Service) External() bool {
 	return s.MeshExternal
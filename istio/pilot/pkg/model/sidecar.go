@@ -47,6 +47,7 @@ var (
 		gvk.EnvoyFilter:           {},
 		gvk.AuthorizationPolicy:   {},
 		gvk.RequestAuthentication: {},
+		gvk.ProxyConfig:           {},
 	}
 )
 
@@ -91,6 +92,11 @@ type SidecarScope struct {
 	// listeners from the proxy service instances
 	HasCustomIngressListeners bool
 
+	// HasDynamicDNSServices is a convenience variable that if set to true
+	// indicates that one or more of the services are resolved dynamically,
+	// requiring the dynamic forward proxy filters.
+	HasDynamicDNSServices bool
+
 	// Union of services imported across all egress listeners for use by CDS code.
 	services           []*Service
 	servicesByHostname map[host.Name]*Service
@@ -211,6 +217,9 @@ func DefaultSidecarScopeForNamespace(ps *PushContext, configNamespace string) *S
 	// that these services need
 	for _, s := range out.services {
 		out.servicesByHostname[s.Hostname] = s
+		if s.Resolution == DynamicDNSLB {
+			out.HasDynamicDNSServices = true
+		}
 		if dr := ps.DestinationRule(&dummyNode, s); dr != nil {
 			out.destinationRules[s.Hostname] = dr
 		}
@@ -384,6 +393,9 @@ func ConvertToSidecarScope(ps *PushContext, sidecarConfig *config.Config, config
 	out.destinationRules = make(map[host.Name]*config.Config)
 	for _, s := range out.services {
 		out.servicesByHostname[s.Hostname] = s
+		if s.Resolution == DynamicDNSLB {
+			out.HasDynamicDNSServices = true
+		}
 		dr := ps.DestinationRule(&dummyNode, s)
 		if dr != nil {
 			out.destinationRules[s.Hostname] = dr
@@ -145,7 +145,8 @@ func (configgen *ConfigGeneratorImpl) buildOutboundClusters(cb *ClusterBuilder,
 	}
 	for _, service := range services {
 		for _, port := range service.Ports {
-			if port.Protocol == protocol.UDP {
+			// UDP ports share the clusters of a port of another protocol with the same value, if any.
+			if _, f := service.Ports.GetByPort(port.Port); f && port.Protocol == protocol.UDP {
 				continue
 			}
 			lbEndpoints := cb.buildLocalityLbEndpoints(networkView, service, port.Port, nil)
@@ -196,6 +197,7 @@ func (p clusterPatcher) insertedClusters() []*cluster.Cluster {
 
 // SniDnat clusters do not have any TLS setting, as they simply forward traffic to upstream
 // All SniDnat clusters are internal services in the mesh.
+// Cluster-local services have no SniDnat cluster, so they are not exposed to other networks.
 func (configgen *ConfigGeneratorImpl) buildOutboundSniDnatClusters(proxy *model.Proxy, push *model.PushContext,
 	cp clusterPatcher) []*cluster.Cluster {
 	clusters := make([]*cluster.Cluster, 0)
@@ -204,7 +206,7 @@ func (configgen *ConfigGeneratorImpl) buildOutboundSniDnatClusters(proxy *model.
 	networkView := model.GetNetworkView(proxy)
 
 	for _, service := range push.Services(proxy) {
-		if service.MeshExternal {
+		if service.MeshExternal || push.IsClusterLocal(service) {
 			continue
 		}
 		for _, port := range service.Ports {
@@ -388,7 +390,7 @@ func convertResolution(proxy *model.Proxy, service *model.Service) cluster.Clust
 	switch service.Resolution {
 	case model.ClientSideLB:
 		return cluster.Cluster_EDS
-	case model.DNSLB:
+	case model.DNSLB, model.DynamicDNSLB:
 		return cluster.Cluster_STRICT_DNS
 	case model.Passthrough:
 		// Gateways cannot use passthrough clusters. So fallback to EDS
@@ -604,7 +606,7 @@ func applyTrafficPolicy(opts buildClusterOpts) {
 		applyOutlierDetection(opts.cluster, outlierDetection)
 		applyLoadBalancer(opts.cluster, loadBalancer, opts.port, opts.proxy, opts.mesh)
 	}
-	if opts.cluster.GetType() == cluster.Cluster_ORIGINAL_DST {
+	if opts.cluster.GetType() == cluster.Cluster_ORIGINAL_DST || isDynamicForwardProxyCluster(opts.cluster) {
 		opts.cluster.LbPolicy = cluster.Cluster_CLUSTER_PROVIDED
 	}
 
@@ -614,6 +616,9 @@ func applyTrafficPolicy(opts buildClusterOpts) {
 		tls, mtlsCtxType = buildAutoMtlsSettings(tls, opts.serviceAccounts, opts.istioMtlsSni, opts.proxy,
 			autoMTLSEnabled, opts.meshExternal, opts.serviceMTLSMode)
 		applyUpstreamTLSSettings(&opts, tls, mtlsCtxType)
+		if isDynamicForwardProxyCluster(opts.cluster) {
+			applyDynamicForwardProxyTLS(opts.cluster, tls)
+		}
 	}
 }
 
@@ -835,7 +840,7 @@ func applyLocalityLBSetting(locality *core.Locality, cluster *cluster.Cluster, l
 	// Failover should only be applied with outlier detection, or traffic will never failover.
 	enabledFailover := cluster.OutlierDetection != nil
 	if cluster.LoadAssignment != nil {
-		loadbalancer.ApplyLocalityLBSetting(locality, cluster.LoadAssignment, localityLB, enabledFailover)
+		loadbalancer.ApplyLocalityLBSetting(locality, cluster.LoadAssignment, localityLB, enabledFailover, nil)
 	}
 }
 
@@ -111,6 +111,9 @@ func (cb *ClusterBuilder) applyDestinationRule(c *cluster.Cluster, clusterMode C
 			}
 		}
 		clusterType := c.GetType()
+		if isDynamicForwardProxyCluster(c) {
+			clusterType = cluster.Cluster_STRICT_DNS
+		}
 
 		if isPassthrough {
 			clusterType = cluster.Cluster_ORIGINAL_DST
@@ -208,6 +211,11 @@ func (cb *ClusterBuilder) buildDefaultCluster(name string, discoveryType cluster
 	}
 	switch discoveryType {
 	case cluster.Cluster_STRICT_DNS:
+		if service != nil && service.Resolution == model.DynamicDNSLB {
+			// The cluster resolves each requested host, there are no endpoints to load.
+			c.ClusterDiscoveryType = buildDynamicForwardProxyClusterType(cb.push.Mesh)
+			break
+		}
 		c.DnsLookupFamily = cluster.Cluster_V4_ONLY
 		dnsRate := gogo.DurationToProtoDuration(cb.push.Mesh.DnsRefreshRate)
 		c.DnsRefreshRate = dnsRate
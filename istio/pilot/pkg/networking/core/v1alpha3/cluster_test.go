@@ -1359,6 +1359,41 @@ func TestGatewayLocalityLB(t *testing.T) {
 	}
 }
 
+func TestBuildSniDnatClustersClusterLocal(t *testing.T) {
+	port := &model.Port{Name: "default", Port: 8080, Protocol: protocol.HTTP}
+	service := func(hostname string, clusterLocal bool) *model.Service {
+		return &model.Service{
+			Hostname:    host.Name(hostname),
+			Address:     "1.1.1.1",
+			ClusterVIPs: make(map[string]string),
+			Ports:       model.PortList{port},
+			Resolution:  model.ClientSideLB,
+			Attributes:  model.ServiceAttributes{Namespace: TestServiceNamespace, ClusterLocal: clusterLocal},
+		}
+	}
+	cg := NewConfigGenTest(t, TestOptions{
+		Services: []*model.Service{service("global.example.org", false), service("local.example.org", true)},
+	})
+	clusters := cg.Clusters(cg.SetupProxy(&model.Proxy{
+		Type:        model.Router,
+		IPAddresses: []string{"6.6.6.6"},
+		DNSDomain:   "default.example.org",
+		Metadata:    &model.NodeMetadata{RouterMode: string(model.SniDnatRouter)},
+	}))
+	clusterMap := xdstest.ExtractClusters(clusters)
+
+	sniDnatCluster := func(hostname string) string {
+		return model.BuildDNSSrvSubsetKey(model.TrafficDirectionOutbound, "", host.Name(hostname), 8080)
+	}
+	if _, f := clusterMap[sniDnatCluster("global.example.org")]; !f {
+		t.Errorf("expected an SNI-DNAT cluster for the service, got %v", xdstest.MapKeys(clusterMap))
+	}
+	// A cluster-local service is not exposed to other networks through the gateway.
+	if _, f := clusterMap[sniDnatCluster("local.example.org")]; f {
+		t.Errorf("expected no SNI-DNAT cluster for the cluster-local service, got %v", xdstest.MapKeys(clusterMap))
+	}
+}
+
 func TestFindServiceInstanceForIngressListener(t *testing.T) {
 	servicePort := &model.Port{
 		Name:     "default",
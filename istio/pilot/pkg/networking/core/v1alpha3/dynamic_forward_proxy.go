@@ -0,0 +1,130 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3
+
+import (
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	dfpcluster "github.com/envoyproxy/go-control-plane/envoy/extensions/clusters/dynamic_forward_proxy/v3"
+	dfpcommon "github.com/envoyproxy/go-control-plane/envoy/extensions/common/dynamic_forward_proxy/v3"
+	dfphttp "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/dynamic_forward_proxy/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	snidfp "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/sni_dynamic_forward_proxy/v3alpha"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/wrappers"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/util/gogo"
+)
+
+const (
+	// DynamicForwardProxyClusterType is the name of the Envoy dynamic forward proxy cluster type.
+	DynamicForwardProxyClusterType = "envoy.clusters.dynamic_forward_proxy"
+	// DynamicForwardProxyFilterName is the name of the Envoy dynamic forward proxy HTTP filter.
+	DynamicForwardProxyFilterName = "envoy.filters.http.dynamic_forward_proxy"
+	// SniDynamicForwardProxyFilterName is the name of the Envoy SNI dynamic forward proxy network filter.
+	SniDynamicForwardProxyFilterName = "envoy.filters.network.sni_dynamic_forward_proxy"
+
+	// dynamicForwardProxyDNSCacheName is the name of the DNS cache shared by all the dynamic forward proxy clusters
+	// and filters of a proxy. Envoy requires all the users of a cache to configure it identically.
+	dynamicForwardProxyDNSCacheName = "dynamic_forward_proxy_cache_config"
+)
+
+// buildDynamicForwardProxyDNSCache builds the DNS cache resolving the hosts of dynamically resolved services.
+func buildDynamicForwardProxyDNSCache(mesh *meshconfig.MeshConfig) *dfpcommon.DnsCacheConfig {
+	return &dfpcommon.DnsCacheConfig{
+		Name:            dynamicForwardProxyDNSCacheName,
+		DnsLookupFamily: cluster.Cluster_V4_ONLY,
+		DnsRefreshRate:  gogo.DurationToProtoDuration(mesh.DnsRefreshRate),
+		HostTtl:         ptypes.DurationProto(features.DynamicForwardProxyHostTTL),
+		MaxHosts:        &wrappers.UInt32Value{Value: uint32(features.DynamicForwardProxyMaxHosts)},
+	}
+}
+
+// buildDynamicForwardProxyClusterType builds the discovery type of the clusters of dynamically resolved services,
+// which resolve the host requested by the HTTP or SNI dynamic forward proxy filters.
+func buildDynamicForwardProxyClusterType(mesh *meshconfig.MeshConfig) *cluster.Cluster_ClusterType {
+	return &cluster.Cluster_ClusterType{
+		ClusterType: &cluster.Cluster_CustomClusterType{
+			Name: DynamicForwardProxyClusterType,
+			TypedConfig: util.MessageToAny(&dfpcluster.ClusterConfig{
+				DnsCacheConfig: buildDynamicForwardProxyDNSCache(mesh),
+			}),
+		},
+	}
+}
+
+// isDynamicForwardProxyCluster returns true if the cluster is a dynamic forward proxy cluster.
+func isDynamicForwardProxyCluster(c *cluster.Cluster) bool {
+	return c.GetClusterType().GetName() == DynamicForwardProxyClusterType
+}
+
+// applyDynamicForwardProxyTLS configures a dynamic forward proxy cluster originating TLS to validate the requested
+// host, unless the TLS settings override the SNI or the subject alternative names to verify.
+func applyDynamicForwardProxyTLS(c *cluster.Cluster, tls *networking.ClientTLSSettings) {
+	if c.TransportSocket == nil ||
+		(tls.GetMode() != networking.ClientTLSSettings_SIMPLE && tls.GetMode() != networking.ClientTLSSettings_MUTUAL) {
+		return
+	}
+	autoSni := tls.Sni == ""
+	autoSanValidation := len(tls.SubjectAltNames) == 0
+	if autoSni || autoSanValidation {
+		c.UpstreamHttpProtocolOptions = &core.UpstreamHttpProtocolOptions{
+			AutoSni:           autoSni,
+			AutoSanValidation: autoSanValidation,
+		}
+	}
+}
+
+// buildDynamicForwardProxyHTTPFilter builds the HTTP filter resolving the host of the requests routed to dynamic
+// forward proxy clusters.
+func buildDynamicForwardProxyHTTPFilter(mesh *meshconfig.MeshConfig) *hcm.HttpFilter {
+	return &hcm.HttpFilter{
+		Name: DynamicForwardProxyFilterName,
+		ConfigType: &hcm.HttpFilter_TypedConfig{
+			TypedConfig: util.MessageToAny(&dfphttp.FilterConfig{DnsCacheConfig: buildDynamicForwardProxyDNSCache(mesh)}),
+		},
+	}
+}
+
+// buildSniDynamicForwardProxyFilter builds the network filter resolving the SNI of the TLS connections forwarded to
+// dynamic forward proxy clusters, connecting to the given port of the resolved host.
+func buildSniDynamicForwardProxyFilter(mesh *meshconfig.MeshConfig, port uint32) *listener.Filter {
+	return &listener.Filter{
+		Name: SniDynamicForwardProxyFilterName,
+		ConfigType: &listener.Filter_TypedConfig{
+			TypedConfig: util.MessageToAny(&snidfp.FilterConfig{
+				DnsCacheConfig: buildDynamicForwardProxyDNSCache(mesh),
+				PortSpecifier:  &snidfp.FilterConfig_PortValue{PortValue: port},
+			}),
+		},
+	}
+}
+
+// maybeAddSniDynamicForwardProxyFilter prepends the SNI dynamic forward proxy filter to the network filters of TLS
+// connections forwarded to the cluster of a dynamically resolved service.
+func maybeAddSniDynamicForwardProxyFilter(push *model.PushContext, service *model.Service, listenPort *model.Port,
+	clusterName string, filters []*listener.Filter) []*listener.Filter {
+	if service == nil || service.Resolution != model.DynamicDNSLB || !listenPort.Protocol.IsTLS() {
+		return filters
+	}
+	_, _, _, port := model.ParseSubsetKey(clusterName)
+	return append([]*listener.Filter{buildSniDynamicForwardProxyFilter(push.Mesh, uint32(port))}, filters...)
+}
@@ -0,0 +1,246 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3_test
+
+import (
+	"testing"
+
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3"
+	"istio.io/istio/pilot/pkg/simulation"
+	"istio.io/istio/pilot/pkg/xds"
+	"istio.io/istio/pilot/test/xdstest"
+)
+
+const wildcardServiceEntry = `
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: wildcard
+spec:
+  hosts:
+  - "*.example.com"
+  ports:
+  - number: 80
+    name: http
+    protocol: HTTP
+  - number: 443
+    name: tls
+    protocol: TLS
+  resolution: DNS
+---
+`
+
+func TestDynamicForwardProxy(t *testing.T) {
+	runSimulationTest(t, nil, xds.FakeOptions{}, simulationTest{
+		config: wildcardServiceEntry,
+		calls: []simulation.Expect{
+			{
+				Name: "http",
+				Call: simulation.Call{
+					Port:       80,
+					HostHeader: "foo.example.com",
+					Protocol:   simulation.HTTP,
+				},
+				Result: simulation.Result{
+					ListenerMatched:    "0.0.0.0_80",
+					RouteConfigMatched: "80",
+					VirtualHostMatched: "*.example.com:80",
+					ClusterMatched:     "outbound|80||*.example.com",
+				},
+			},
+			{
+				Name: "tls",
+				Call: simulation.Call{
+					Port:     443,
+					Sni:      "foo.example.com",
+					Protocol: simulation.HTTP,
+					TLS:      simulation.TLS,
+				},
+				Result: simulation.Result{
+					ListenerMatched: "0.0.0.0_443",
+					ClusterMatched:  "outbound|443||*.example.com",
+				},
+			},
+		},
+	})
+}
+
+func TestDynamicForwardProxyConfig(t *testing.T) {
+	cases := []struct {
+		name              string
+		config            string
+		autoSni           bool
+		autoSanValidation bool
+	}{
+		{
+			name:   "no tls origination",
+			config: wildcardServiceEntry,
+		},
+		{
+			name: "simple tls",
+			config: wildcardServiceEntry + `
+apiVersion: networking.istio.io/v1alpha3
+kind: DestinationRule
+metadata:
+  name: wildcard
+spec:
+  host: "*.example.com"
+  trafficPolicy:
+    tls:
+      mode: SIMPLE
+      caCertificates: /etc/certs/ca.pem
+`,
+			autoSni:           true,
+			autoSanValidation: true,
+		},
+		{
+			name: "simple tls with sni and subject alt names",
+			config: wildcardServiceEntry + `
+apiVersion: networking.istio.io/v1alpha3
+kind: DestinationRule
+metadata:
+  name: wildcard
+spec:
+  host: "*.example.com"
+  trafficPolicy:
+    tls:
+      mode: SIMPLE
+      caCertificates: /etc/certs/ca.pem
+      sni: foo.example.com
+      subjectAltNames:
+      - foo.example.com
+`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{ConfigString: tt.config})
+			proxy := s.SetupProxy(&model.Proxy{})
+
+			c := xdstest.ExtractCluster("outbound|80||*.example.com", s.Clusters(proxy))
+			if c == nil {
+				t.Fatalf("missing cluster outbound|80||*.example.com")
+			}
+			if got := c.GetClusterType().GetName(); got != v1alpha3.DynamicForwardProxyClusterType {
+				t.Errorf("expected cluster type %v, got %v", v1alpha3.DynamicForwardProxyClusterType, got)
+			}
+			if c.LbPolicy != cluster.Cluster_CLUSTER_PROVIDED {
+				t.Errorf("expected lb policy CLUSTER_PROVIDED, got %v", c.LbPolicy)
+			}
+			if c.LoadAssignment != nil {
+				t.Errorf("expected no load assignment, got %v", c.LoadAssignment)
+			}
+			opts := c.GetUpstreamHttpProtocolOptions()
+			if opts.GetAutoSni() != tt.autoSni || opts.GetAutoSanValidation() != tt.autoSanValidation {
+				t.Errorf("expected auto sni %v and auto san validation %v, got %v", tt.autoSni, tt.autoSanValidation, opts)
+			}
+
+			listeners := s.Listeners(proxy)
+			hcm := xdstest.ExtractHTTPConnectionManager(t, xdstest.ExtractListener("0.0.0.0_80", listeners).FilterChains[0])
+			filters := hcm.HttpFilters
+			if len(filters) < 2 || filters[len(filters)-2].Name != v1alpha3.DynamicForwardProxyFilterName {
+				t.Errorf("expected the dynamic forward proxy filter before the router, got %v", filters)
+			}
+
+			var tlsChain *listener.FilterChain
+			for _, fc := range xdstest.ExtractListener("0.0.0.0_443", listeners).FilterChains {
+				if len(fc.GetFilterChainMatch().GetServerNames()) > 0 {
+					tlsChain = fc
+				}
+			}
+			if tlsChain == nil || tlsChain.Filters[0].Name != v1alpha3.SniDynamicForwardProxyFilterName {
+				t.Errorf("expected the sni dynamic forward proxy filter, got %v", tlsChain)
+			}
+		})
+	}
+}
+
+func TestDynamicForwardProxyNotConfigured(t *testing.T) {
+	s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{ConfigString: `
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: wildcard
+spec:
+  hosts:
+  - "*.example.com"
+  ports:
+  - number: 80
+    name: http
+    protocol: HTTP
+  resolution: DNS
+  endpoints:
+  - address: 1.1.1.1
+`})
+	proxy := s.SetupProxy(&model.Proxy{})
+	c := xdstest.ExtractCluster("outbound|80||*.example.com", s.Clusters(proxy))
+	if c.GetType() != cluster.Cluster_STRICT_DNS {
+		t.Errorf("expected a STRICT_DNS cluster for a wildcard host with endpoints, got %v", c.ClusterDiscoveryType)
+	}
+	hcm := xdstest.ExtractHTTPConnectionManager(t, xdstest.ExtractListener("0.0.0.0_80", s.Listeners(proxy)).FilterChains[0])
+	for _, f := range hcm.HttpFilters {
+		if f.Name == v1alpha3.DynamicForwardProxyFilterName {
+			t.Errorf("unexpected dynamic forward proxy filter")
+		}
+	}
+}
+
+func TestGatewayDynamicForwardProxy(t *testing.T) {
+	runGatewayTest(t, simulationTest{
+		name: "egress gateway",
+		config: createGateway("gateway", "", `
+port:
+  name: http
+  number: 80
+  protocol: HTTP
+hosts:
+- "*.example.com"
+`) + wildcardServiceEntry + `
+apiVersion: networking.istio.io/v1alpha3
+kind: VirtualService
+metadata:
+  name: wildcard
+spec:
+  hosts:
+  - "*.example.com"
+  gateways:
+  - gateway
+  http:
+  - route:
+    - destination:
+        host: "*.example.com"
+        port:
+          number: 443
+`,
+		calls: []simulation.Expect{{
+			Name: "http",
+			Call: simulation.Call{
+				Port:       80,
+				HostHeader: "foo.example.com",
+				Protocol:   simulation.HTTP,
+				CallMode:   simulation.CallModeGateway,
+			},
+			Result: simulation.Result{
+				ListenerMatched:    "0.0.0.0_80",
+				RouteConfigMatched: "http.80",
+				ClusterMatched:     "outbound|443||*.example.com",
+			},
+		}},
+	})
+}
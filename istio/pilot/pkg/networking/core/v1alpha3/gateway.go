@@ -96,6 +96,14 @@ func (configgen *ConfigGeneratorImpl) buildGatewayListeners(builder *ListenerBui
 		}
 
 		p := protocol.Parse(port.Protocol)
+		if p == protocol.UDP {
+			// Merging ensures a single UDP server per port.
+			if l := buildGatewayUDPListener(builder.node, builder.push, actualWildcard, port.Number, servers[0],
+				mergedGateway.GatewayNameForServer[servers[0]]); l != nil {
+				listeners = append(listeners, l)
+			}
+			continue
+		}
 		listenerProtocol := istionetworking.ModelProtocolToListenerProtocol(p, core.TrafficDirection_OUTBOUND)
 		filterChains := make([]istionetworking.FilterChain, 0)
 		if p.IsHTTP() {
@@ -359,6 +367,7 @@ func (configgen *ConfigGeneratorImpl) buildGatewayHTTPRouteConfig(node *model.Pr
 	}
 
 	util.SortVirtualHosts(virtualHosts)
+	applyLocalRateLimits(push.LocalRateLimits(node), uint32(port), virtualHosts)
 
 	routeCfg := &route.RouteConfiguration{
 		// Retain the routeName as its used by EnvoyFilter patching logic
@@ -373,6 +373,13 @@ func (configgen *ConfigGeneratorImpl) buildSidecarInboundListeners(
 			bind = getSidecarInboundBindIP(node)
 		}
 
+		if listenPort.Protocol == protocol.UDP {
+			if l := configgen.buildSidecarInboundUDPListener(node, bind, ingressListener); l != nil {
+				listeners = append(listeners, l)
+			}
+			continue
+		}
+
 		instance := configgen.findOrCreateServiceInstance(node.ServiceInstances, ingressListener,
 			sidecarScope.Name, sidecarScope.Namespace)
 
@@ -459,9 +466,10 @@ func (configgen *ConfigGeneratorImpl) buildSidecarThriftListenerOptsForPortOrUDS
 	// clusters and these are static endpoint clusters used only for sidecar (proxy -> app)
 	clusterName := model.BuildInboundSubsetKey(int(pluginParams.ServiceInstance.Endpoint.EndpointPort))
 
+	thriftProtocol, thriftTransport := thriftProtocolAndTransport(pluginParams.ServiceInstance.ServicePort)
 	thriftOpts := &thriftListenerOpts{
-		transport:   thrift.TransportType_AUTO_TRANSPORT,
-		protocol:    thrift.ProtocolType_AUTO_PROTOCOL,
+		transport:   thriftTransport,
+		protocol:    thriftProtocol,
 		routeConfig: configgen.buildSidecarThriftRouteConfig(clusterName, pluginParams.Push.Mesh.ThriftConfig.RateLimitUrl),
 	}
 
@@ -659,6 +667,9 @@ type outboundListenerEntry struct {
 }
 
 func protocolName(p protocol.Instance) string {
+	if p == protocol.UDP {
+		return "UDP"
+	}
 	switch istionetworking.ModelProtocolToListenerProtocol(p, core.TrafficDirection_OUTBOUND) {
 	case istionetworking.ListenerProtocolHTTP:
 		return "HTTP"
@@ -926,7 +937,7 @@ func (configgen *ConfigGeneratorImpl) buildSidecarOutboundListeners(node *model.
 		configgen.appendListenerFallthroughRouteForCompleteListener(listener, node, push)
 	}
 	removeListenerFilterTimeout(tcpListeners)
-	return tcpListeners
+	return append(tcpListeners, configgen.buildSidecarOutboundUDPListeners(node, push)...)
 }
 
 func (configgen *ConfigGeneratorImpl) buildHTTPProxy(node *model.Proxy,
@@ -1075,7 +1086,7 @@ func (configgen *ConfigGeneratorImpl) buildSidecarOutboundHTTPListenerOptsForPor
 
 func (configgen *ConfigGeneratorImpl) buildSidecarOutboundThriftListenerOptsForPortOrUDS(listenerMapKey *string,
 	currentListenerEntry **outboundListenerEntry, listenerOpts *buildListenerOpts,
-	listenerMap map[string]*outboundListenerEntry, actualWildcard string) (bool, []*filterChainOpts) {
+	listenerMap map[string]*outboundListenerEntry, virtualServices []config.Config, actualWildcard string) (bool, []*filterChainOpts) {
 	// first identify the bind if its not set. Then construct the key
 	// used to lookup the listener in the conflict map.
 	if len(listenerOpts.bind) == 0 { // no user specified bind. Use 0.0.0.0:Port
@@ -1110,11 +1121,12 @@ func (configgen *ConfigGeneratorImpl) buildSidecarOutboundThriftListenerOptsForP
 	}
 
 	// No conflicts. Add a thrift filter chain option to the listenerOpts
-	clusterName := model.BuildSubsetKey(model.TrafficDirectionOutbound, "", listenerOpts.service.Hostname, listenerOpts.port.Port)
+	thriftProtocol, thriftTransport := thriftProtocolAndTransport(listenerOpts.port)
 	thriftOpts := &thriftListenerOpts{
-		protocol:    thrift.ProtocolType_AUTO_PROTOCOL,
-		transport:   thrift.TransportType_AUTO_TRANSPORT,
-		routeConfig: configgen.buildSidecarThriftRouteConfig(clusterName, listenerOpts.push.Mesh.ThriftConfig.RateLimitUrl),
+		protocol:  thriftProtocol,
+		transport: thriftTransport,
+		routeConfig: configgen.buildSidecarOutboundThriftRouteConfig(listenerOpts.proxy, listenerOpts.push,
+			listenerOpts.service, listenerOpts.port, virtualServices),
 	}
 
 	return true, []*filterChainOpts{{
@@ -1311,7 +1323,7 @@ func (configgen *ConfigGeneratorImpl) buildSidecarOutboundListenerForPortOrUDS(n
 			// Hard code the service IP for outbound thrift service listeners. HTTP services
 			// use RDS but the Thrift stack has no such dynamic configuration option.
 			if ret, opts = configgen.buildSidecarOutboundThriftListenerOptsForPortOrUDS(&listenerMapKey,
-				&currentListenerEntry, &listenerOpts, listenerMap, actualWildcard); !ret {
+				&currentListenerEntry, &listenerOpts, listenerMap, virtualServices, actualWildcard); !ret {
 				return
 			}
 
@@ -1603,7 +1615,21 @@ func buildHTTPConnectionManager(listenerOpts buildListenerOpts, httpOpts *httpLi
 		filters = append(filters, xdsfilters.Alpn)
 	}
 
-	filters = append(filters, xdsfilters.Cors, xdsfilters.Fault, xdsfilters.Router)
+	// enforce the local rate limits configured on the routes of inbound and gateway listeners.
+	if (listenerOpts.class == ListenerClassSidecarInbound || listenerOpts.class == ListenerClassGateway) &&
+		len(listenerOpts.push.LocalRateLimits(listenerOpts.proxy)) > 0 {
+		filters = append(filters, buildLocalRateLimitFilter())
+	}
+
+	filters = append(filters, xdsfilters.Cors, xdsfilters.Fault)
+
+	// resolve the requested host of outbound requests before routing to dynamically resolved services.
+	if (listenerOpts.class == ListenerClassSidecarOutbound || listenerOpts.class == ListenerClassGateway) &&
+		listenerOpts.proxy.SidecarScope != nil && listenerOpts.proxy.SidecarScope.HasDynamicDNSServices {
+		filters = append(filters, buildDynamicForwardProxyHTTPFilter(listenerOpts.push.Mesh))
+	}
+
+	filters = append(filters, xdsfilters.Router)
 
 	if httpOpts.connectionManager == nil {
 		httpOpts.connectionManager = &hcm.HttpConnectionManager{}
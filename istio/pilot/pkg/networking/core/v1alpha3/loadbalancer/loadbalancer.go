@@ -18,6 +18,7 @@ package loadbalancer
 import (
 	"math"
 	"sort"
+	"strings"
 
 	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
@@ -25,8 +26,27 @@ import (
 
 	"istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/labels"
 )
 
+// FailoverPriorityAnnotation is the DestinationRule annotation with the comma separated list of the labels used, in
+// order, to prioritize the endpoints on failover. It overrides PILOT_LOCALITY_FAILOVER_PRIORITY.
+// Like locality failover, it only applies when the traffic policy of the DestinationRule enables outlier detection,
+// otherwise Envoy would never fail over to the lower priorities.
+// This annotation is experimental, an interim until localityLbSetting has a field for it.
+const FailoverPriorityAnnotation = "networking.istio.io/failoverPriority"
+
+// FailoverPriority prioritizes the endpoints by the number of labels they share, in order, with the proxy.
+type FailoverPriority struct {
+	// Labels are the labels compared, in order, e.g. topology.istio.io/network,topology.istio.io/cluster.
+	Labels []string
+	// ProxyLabels are the labels of the proxy.
+	ProxyLabels labels.Instance
+	// EndpointLabels are the labels of the endpoints of the ClusterLoadAssignment, at the same index.
+	EndpointLabels [][]labels.Instance
+}
+
 func GetLocalityLbSetting(
 	mesh *v1alpha3.LocalityLoadBalancerSetting,
 	destrule *v1alpha3.LocalityLoadBalancerSetting,
@@ -56,11 +76,30 @@ func GetLocalityLbSetting(
 	return mesh
 }
 
+// GetFailoverPriority returns the labels used to prioritize the endpoints on failover, from the DestinationRule
+// annotation or else the mesh default.
+func GetFailoverPriority(mesh string, destrule *config.Config) []string {
+	priority := mesh
+	if destrule != nil {
+		if p, f := destrule.Annotations[FailoverPriorityAnnotation]; f {
+			priority = p
+		}
+	}
+	var out []string
+	for _, l := range strings.Split(priority, ",") {
+		if l = strings.TrimSpace(l); l != "" {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
 func ApplyLocalityLBSetting(
 	locality *core.Locality,
 	loadAssignment *endpoint.ClusterLoadAssignment,
 	localityLB *v1alpha3.LocalityLoadBalancerSetting,
 	enableFailover bool,
+	failoverPriority *FailoverPriority,
 ) {
 	if locality == nil || loadAssignment == nil {
 		return
@@ -73,6 +112,10 @@ func ApplyLocalityLBSetting(
 		// Do not apply default failover when locality LB is disabled.
 	} else if enableFailover && (localityLB.Enabled == nil || localityLB.Enabled.Value) {
 		applyLocalityFailover(locality, loadAssignment, localityLB.GetFailover())
+		if failoverPriority != nil && len(failoverPriority.Labels) > 0 &&
+			len(failoverPriority.EndpointLabels) == len(loadAssignment.Endpoints) {
+			applyFailoverPriority(loadAssignment, failoverPriority)
+		}
 	}
 }
 
@@ -140,9 +183,6 @@ func applyLocalityFailover(
 	locality *core.Locality,
 	loadAssignment *endpoint.ClusterLoadAssignment,
 	failover []*v1alpha3.LocalityLoadBalancerSetting_Failover) {
-	// key is priority, value is the index of the LocalityLbEndpoints in ClusterLoadAssignment
-	priorityMap := map[int][]int{}
-
 	// 1. calculate the LocalityLbEndpoints.Priority compared with proxy locality
 	for i, localityEndpoint := range loadAssignment.Endpoints {
 		// if region/zone/subZone all match, the priority is 0.
@@ -163,18 +203,97 @@ func applyLocalityFailover(
 			}
 		}
 		loadAssignment.Endpoints[i].Priority = uint32(priority)
-		priorityMap[priority] = append(priorityMap[priority], i)
 	}
 
 	// since Priorities should range from 0 (highest) to N (lowest) without skipping.
 	// 2. adjust the priorities in order
-	// 2.1 sort all priorities in increasing order.
+	adjustPriorities(loadAssignment)
+}
+
+// set the priority of the endpoints by the failover priority labels they share with the proxy, then their locality.
+func applyFailoverPriority(loadAssignment *endpoint.ClusterLoadAssignment, failoverPriority *FailoverPriority) {
+	// the locality priorities range from 0 to N-1, without skipping.
+	localityPriorities := uint32(0)
+	for _, localityEndpoint := range loadAssignment.Endpoints {
+		if localityEndpoint.Priority >= localityPriorities {
+			localityPriorities = localityEndpoint.Priority + 1
+		}
+	}
+
+	// 1. split the LocalityLbEndpoints by the priority of their endpoints.
+	// The labels take precedence over the locality: endpoints matching more labels have a higher priority,
+	// whatever their locality.
+	out := make([]*endpoint.LocalityLbEndpoints, 0, len(loadAssignment.Endpoints))
+	for i, localityEndpoint := range loadAssignment.Endpoints {
+		// key is the label priority, value is the new LocalityLbEndpoints
+		split := map[int]*endpoint.LocalityLbEndpoints{}
+		var labelPriorities []int
+		for j, lbEndpoint := range localityEndpoint.LbEndpoints {
+			var epLabels labels.Instance
+			if j < len(failoverPriority.EndpointLabels[i]) {
+				epLabels = failoverPriority.EndpointLabels[i][j]
+			}
+			labelPriority := failoverPriority.labelPriority(epLabels)
+			llb, f := split[labelPriority]
+			if !f {
+				llb = &endpoint.LocalityLbEndpoints{
+					Locality:            localityEndpoint.Locality,
+					Proximity:           localityEndpoint.Proximity,
+					Priority:            uint32(labelPriority)*localityPriorities + localityEndpoint.Priority,
+					LoadBalancingWeight: &wrappers.UInt32Value{},
+				}
+				split[labelPriority] = llb
+				labelPriorities = append(labelPriorities, labelPriority)
+			}
+			llb.LbEndpoints = append(llb.LbEndpoints, lbEndpoint)
+			if w := lbEndpoint.GetLoadBalancingWeight(); w != nil {
+				llb.LoadBalancingWeight.Value += w.Value
+			} else {
+				llb.LoadBalancingWeight.Value++
+			}
+		}
+		sort.Ints(labelPriorities)
+		for _, labelPriority := range labelPriorities {
+			out = append(out, split[labelPriority])
+		}
+	}
+	loadAssignment.Endpoints = out
+
+	// 2. adjust the priorities in order
+	adjustPriorities(loadAssignment)
+}
+
+// labelPriority returns the number of the failover priority labels, in order, the endpoint does not share with
+// the proxy. 0 is the highest priority.
+func (p *FailoverPriority) labelPriority(epLabels labels.Instance) int {
+	matched := 0
+	for _, key := range p.Labels {
+		value, f := p.ProxyLabels[key]
+		if !f || epLabels[key] != value {
+			break
+		}
+		matched++
+	}
+	return len(p.Labels) - matched
+}
+
+// adjustPriorities makes the priorities of the LocalityLbEndpoints range from 0 (highest) to N (lowest)
+// without skipping, keeping their order.
+func adjustPriorities(loadAssignment *endpoint.ClusterLoadAssignment) {
+	// key is priority, value is the index of the LocalityLbEndpoints in ClusterLoadAssignment
+	priorityMap := map[int][]int{}
+	for i, localityEndpoint := range loadAssignment.Endpoints {
+		priority := int(localityEndpoint.Priority)
+		priorityMap[priority] = append(priorityMap[priority], i)
+	}
+
+	// 1. sort all priorities in increasing order.
 	priorities := []int{}
 	for priority := range priorityMap {
 		priorities = append(priorities, priority)
 	}
 	sort.Ints(priorities)
-	// 2.2 adjust LocalityLbEndpoints priority
+	// 2. adjust LocalityLbEndpoints priority
 	// if the index and value of priorities array is not equal.
 	for i, priority := range priorities {
 		if i != priority {
@@ -30,6 +30,7 @@ import (
 	"istio.io/istio/pilot/pkg/model"
 	memregistry "istio.io/istio/pilot/pkg/serviceregistry/memory"
 	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/mesh"
 	"istio.io/istio/pkg/config/protocol"
 	"istio.io/istio/pkg/config/schema/collections"
@@ -67,7 +68,7 @@ func TestApplyLocalitySetting(t *testing.T) {
 			t.Run(tt.name, func(t *testing.T) {
 				env := buildEnvForClustersWithDistribute(tt.distribute)
 				cluster := buildFakeCluster()
-				ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh().LocalityLbSetting, true)
+				ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh().LocalityLbSetting, true, nil)
 				weights := make([]int, 0)
 				for _, localityEndpoint := range cluster.LoadAssignment.Endpoints {
 					weights = append(weights, int(localityEndpoint.LoadBalancingWeight.GetValue()))
@@ -83,7 +84,7 @@ func TestApplyLocalitySetting(t *testing.T) {
 		g := NewWithT(t)
 		env := buildEnvForClustersWithFailover()
 		cluster := buildFakeCluster()
-		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh().LocalityLbSetting, true)
+		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh().LocalityLbSetting, true, nil)
 		for _, localityEndpoint := range cluster.LoadAssignment.Endpoints {
 			if localityEndpoint.Locality.Region == locality.Region {
 				if localityEndpoint.Locality.Zone == locality.Zone {
@@ -109,7 +110,7 @@ func TestApplyLocalitySetting(t *testing.T) {
 		g := NewWithT(t)
 		env := buildEnvForClustersWithFailover()
 		cluster := buildSmallCluster()
-		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh().LocalityLbSetting, true)
+		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh().LocalityLbSetting, true, nil)
 		for _, localityEndpoint := range cluster.LoadAssignment.Endpoints {
 			if localityEndpoint.Locality.Region == locality.Region {
 				if localityEndpoint.Locality.Zone == locality.Zone {
@@ -135,7 +136,7 @@ func TestApplyLocalitySetting(t *testing.T) {
 		g := NewWithT(t)
 		env := buildEnvForClustersWithFailover()
 		cluster := buildSmallClusterWithNilLocalities()
-		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh().LocalityLbSetting, true)
+		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh().LocalityLbSetting, true, nil)
 		for _, localityEndpoint := range cluster.LoadAssignment.Endpoints {
 			if localityEndpoint.Locality == nil {
 				g.Expect(localityEndpoint.Priority).To(Equal(uint32(2)))
@@ -164,11 +165,85 @@ func TestApplyLocalitySetting(t *testing.T) {
 		lbsetting := &networking.LocalityLoadBalancerSetting{
 			Enabled: &types.BoolValue{Value: false},
 		}
-		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, lbsetting, true)
+		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, lbsetting, true, nil)
 		for _, localityEndpoint := range cluster.LoadAssignment.Endpoints {
 			g.Expect(localityEndpoint.Priority).To(Equal(uint32(0)))
 		}
 	})
+
+	t.Run("Failover: priority labels", func(t *testing.T) {
+		g := NewWithT(t)
+		env := buildEnvForClustersWithFailover()
+		cluster, endpointLabels := buildClusterWithEndpointLabels()
+		failoverPriority := &FailoverPriority{
+			Labels:         []string{"topology.istio.io/network", "topology.istio.io/cluster"},
+			ProxyLabels:    labels.Instance{"topology.istio.io/network": "n1", "topology.istio.io/cluster": "c1"},
+			EndpointLabels: endpointLabels,
+		}
+		ApplyLocalityLBSetting(locality, cluster.LoadAssignment, env.Mesh().LocalityLbSetting, true, failoverPriority)
+		priorities := map[string]uint32{}
+		for _, localityEndpoint := range cluster.LoadAssignment.Endpoints {
+			g.Expect(localityEndpoint.LoadBalancingWeight.GetValue()).To(Equal(uint32(len(localityEndpoint.LbEndpoints))))
+			for _, lbEndpoint := range localityEndpoint.LbEndpoints {
+				priorities[lbEndpoint.GetEndpoint().Address.GetSocketAddress().Address] = localityEndpoint.Priority
+			}
+		}
+		g.Expect(priorities).To(Equal(map[string]uint32{
+			// same network and cluster, same subzone
+			"1.1.1.1": 0,
+			// same network and cluster, other zone
+			"1.1.1.4": 1,
+			// same network, other cluster, other zone
+			"1.1.1.3": 2,
+			// other network, same subzone
+			"1.1.1.2": 3,
+		}))
+	})
+}
+
+func TestGetFailoverPriority(t *testing.T) {
+	dr := func(annotations map[string]string) *config.Config {
+		return &config.Config{Meta: config.Meta{Annotations: annotations}}
+	}
+	cases := []struct {
+		name     string
+		mesh     string
+		destrule *config.Config
+		want     []string
+	}{
+		{
+			name: "none",
+		},
+		{
+			name: "mesh",
+			mesh: "topology.istio.io/network, topology.istio.io/cluster",
+			want: []string{"topology.istio.io/network", "topology.istio.io/cluster"},
+		},
+		{
+			name:     "destination rule without annotation",
+			mesh:     "topology.istio.io/network",
+			destrule: dr(nil),
+			want:     []string{"topology.istio.io/network"},
+		},
+		{
+			name:     "destination rule overrides mesh",
+			mesh:     "topology.istio.io/network",
+			destrule: dr(map[string]string{FailoverPriorityAnnotation: "topology.istio.io/cluster,rack"}),
+			want:     []string{"topology.istio.io/cluster", "rack"},
+		},
+		{
+			name:     "destination rule disables mesh",
+			mesh:     "topology.istio.io/network",
+			destrule: dr(map[string]string{FailoverPriorityAnnotation: ""}),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetFailoverPriority(tt.mesh, tt.destrule); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
 }
 
 func TestGetLocalityLbSetting(t *testing.T) {
@@ -469,3 +544,52 @@ func buildSmallClusterWithNilLocalities() *cluster.Cluster {
 		},
 	}
 }
+
+// buildClusterWithEndpointLabels returns a cluster with endpoints in two localities, networks and clusters, and the
+// labels of its endpoints.
+func buildClusterWithEndpointLabels() (*cluster.Cluster, [][]labels.Instance) {
+	lbEndpoint := func(address string) *endpoint.LbEndpoint {
+		return &endpoint.LbEndpoint{
+			HostIdentifier: &endpoint.LbEndpoint_Endpoint{
+				Endpoint: &endpoint.Endpoint{
+					Address: &core.Address{
+						Address: &core.Address_SocketAddress{
+							SocketAddress: &core.SocketAddress{Address: address},
+						},
+					},
+				},
+			},
+		}
+	}
+	topology := func(network, clusterID string) labels.Instance {
+		return labels.Instance{"topology.istio.io/network": network, "topology.istio.io/cluster": clusterID}
+	}
+	c := &cluster.Cluster{
+		Name: "outbound|8080||test.example.org",
+		LoadAssignment: &endpoint.ClusterLoadAssignment{
+			ClusterName: "outbound|8080||test.example.org",
+			Endpoints: []*endpoint.LocalityLbEndpoints{
+				{
+					Locality: &core.Locality{
+						Region:  "region1",
+						Zone:    "zone1",
+						SubZone: "subzone1",
+					},
+					LbEndpoints: []*endpoint.LbEndpoint{lbEndpoint("1.1.1.1"), lbEndpoint("1.1.1.2")},
+				},
+				{
+					Locality: &core.Locality{
+						Region:  "region1",
+						Zone:    "zone2",
+						SubZone: "subzone1",
+					},
+					LbEndpoints: []*endpoint.LbEndpoint{lbEndpoint("1.1.1.3"), lbEndpoint("1.1.1.4")},
+				},
+			},
+		},
+	}
+	return c, [][]labels.Instance{
+		{topology("n1", "c1"), topology("n2", "c2")},
+		{topology("n1", "c2"), topology("n1", "c1")},
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3
+
+import (
+	"strconv"
+	"strings"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	ratelimit "github.com/envoyproxy/go-control-plane/envoy/extensions/common/ratelimit/v3"
+	lrl "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
+	hcm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/http_connection_manager/v3"
+	metadata "github.com/envoyproxy/go-control-plane/envoy/type/metadata/v3"
+	xdstype "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
+	"github.com/golang/protobuf/ptypes/wrappers"
+
+	"istio.io/istio/pilot/pkg/networking/util"
+	authn_model "istio.io/istio/pilot/pkg/security/model"
+	"istio.io/istio/pkg/config"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+)
+
+const (
+	// LocalRateLimitFilterName is the name of the Envoy local rate limit HTTP filter.
+	LocalRateLimitFilterName = "envoy.filters.http.local_ratelimit"
+
+	localRateLimitStatPrefix = "http_local_rate_limiter"
+
+	// localRateLimitStage is the rate limit stage of the local rate limit filter and of the route actions generating
+	// its descriptors. Envoy generates the descriptors of the rate limit filters from the actions of the route with
+	// their stage, so a stage other than the default 0 keeps the global rate limit filter from reading them.
+	localRateLimitStage = 10
+
+	// localRateLimitDescriptorKey is the descriptor entry key generated by the header value match rate limit action.
+	localRateLimitDescriptorKey = "header_match"
+
+	// localRateLimitPrincipalDescriptorKey is the descriptor entry key generated from the verified peer principal.
+	localRateLimitPrincipalDescriptorKey = "source_principal"
+
+	// authnSourcePrincipal is the dynamic metadata key of the istio_authn filter holding the principal of the peer
+	// certificate, set only for mTLS connections.
+	authnSourcePrincipal = "source.principal"
+)
+
+// buildLocalRateLimitFilter builds the local rate limit HTTP filter. It is disabled at the listener level and only
+// enforced on the routes configuring a token bucket through their typed per filter config.
+func buildLocalRateLimitFilter() *hcm.HttpFilter {
+	return &hcm.HttpFilter{
+		Name: LocalRateLimitFilterName,
+		ConfigType: &hcm.HttpFilter_TypedConfig{
+			TypedConfig: util.MessageToAny(&lrl.LocalRateLimit{
+				StatPrefix: localRateLimitStatPrefix,
+				Stage:      localRateLimitStage,
+			}),
+		},
+	}
+}
+
+// applyLocalRateLimits limits the routes of the virtual hosts served on the port with the first matching rule of
+// the LocalRateLimit resources, which must be sorted by creation time.
+func applyLocalRateLimits(localRateLimits []config.Config, port uint32, virtualHosts []*route.VirtualHost) {
+	if len(localRateLimits) == 0 {
+		return
+	}
+	// Rules usually match many routes, only build their filter config once.
+	perFilterConfigs := map[*networkingv1beta1.LocalRateLimitRule]*any.Any{}
+	for _, vhost := range virtualHosts {
+		for _, r := range vhost.Routes {
+			rule := matchLocalRateLimitRule(localRateLimits, port, r.Name)
+			if rule == nil {
+				continue
+			}
+			perFilterConfig, f := perFilterConfigs[rule]
+			if !f {
+				perFilterConfig = util.MessageToAny(buildLocalRateLimitPerRouteConfig(rule))
+				perFilterConfigs[rule] = perFilterConfig
+			}
+			if r.TypedPerFilterConfig == nil {
+				r.TypedPerFilterConfig = make(map[string]*any.Any)
+			}
+			r.TypedPerFilterConfig[LocalRateLimitFilterName] = perFilterConfig
+			if action := r.GetRoute(); action != nil && len(rule.Descriptors) > 0 {
+				action.RateLimits = append(action.RateLimits, buildLocalRateLimitActions(rule)...)
+			}
+		}
+	}
+}
+
+// matchLocalRateLimitRule returns the first rule of the LocalRateLimit resources matching the route on the port.
+func matchLocalRateLimitRule(localRateLimits []config.Config, port uint32, routeName string) *networkingv1beta1.LocalRateLimitRule {
+	for _, cfg := range localRateLimits {
+		spec := cfg.Spec.(*networkingv1beta1.LocalRateLimitSpec)
+		for i := range spec.Rules {
+			if localRateLimitRuleMatches(&spec.Rules[i], port, routeName) {
+				return &spec.Rules[i]
+			}
+		}
+	}
+	return nil
+}
+
+func localRateLimitRuleMatches(rule *networkingv1beta1.LocalRateLimitRule, port uint32, routeName string) bool {
+	if rule.Match == nil {
+		return true
+	}
+	if len(rule.Match.Ports) > 0 {
+		found := false
+		for _, p := range rule.Match.Ports {
+			if p == port {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(rule.Match.Routes) > 0 {
+		found := false
+		for _, name := range rule.Match.Routes {
+			// Routes generated from a VirtualService HTTP route with several matches are named <route>.<match>.
+			if routeName == name || strings.HasPrefix(routeName, name+".") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// buildLocalRateLimitPerRouteConfig builds the local rate limit filter config of the routes limited by the rule.
+func buildLocalRateLimitPerRouteConfig(rule *networkingv1beta1.LocalRateLimitRule) *lrl.LocalRateLimit {
+	out := &lrl.LocalRateLimit{
+		StatPrefix:     localRateLimitStatPrefix,
+		TokenBucket:    buildTokenBucket(rule.TokenBucket),
+		FilterEnabled:  fullRuntimeFraction("local_rate_limit_enabled"),
+		FilterEnforced: fullRuntimeFraction("local_rate_limit_enforced"),
+		Stage:          localRateLimitStage,
+	}
+	for i, d := range rule.Descriptors {
+		entry := &ratelimit.RateLimitDescriptor_Entry{
+			Key:   localRateLimitDescriptorKey,
+			Value: localRateLimitDescriptorValue(i),
+		}
+		if d.SourcePrincipal != "" {
+			entry = &ratelimit.RateLimitDescriptor_Entry{
+				Key:   localRateLimitPrincipalDescriptorKey,
+				Value: d.SourcePrincipal,
+			}
+		}
+		out.Descriptors = append(out.Descriptors, &ratelimit.LocalRateLimitDescriptor{
+			Entries:     []*ratelimit.RateLimitDescriptor_Entry{entry},
+			TokenBucket: buildTokenBucket(d.TokenBucket),
+		})
+	}
+	return out
+}
+
+// buildLocalRateLimitActions builds the rate limit actions generating the descriptors of the rule for the requests
+// matching them. They are in the stage of the local rate limit filter, so that only this filter reads them.
+func buildLocalRateLimitActions(rule *networkingv1beta1.LocalRateLimitRule) []*route.RateLimit {
+	out := make([]*route.RateLimit, 0, len(rule.Descriptors))
+	principalAction := false
+	for i, d := range rule.Descriptors {
+		if d.SourcePrincipal != "" {
+			// A single action generates the principal descriptor, the bucket is selected by its value.
+			if !principalAction {
+				out = append(out, buildLocalRateLimitPrincipalAction())
+				principalAction = true
+			}
+			continue
+		}
+		out = append(out, &route.RateLimit{
+			Stage: &wrappers.UInt32Value{Value: localRateLimitStage},
+			Actions: []*route.RateLimit_Action{{
+				ActionSpecifier: &route.RateLimit_Action_HeaderValueMatch_{
+					HeaderValueMatch: &route.RateLimit_Action_HeaderValueMatch{
+						DescriptorValue: localRateLimitDescriptorValue(i),
+						Headers:         []*route.HeaderMatcher{buildLocalRateLimitDescriptorMatcher(d)},
+					},
+				},
+			}},
+		})
+	}
+	return out
+}
+
+// buildLocalRateLimitPrincipalAction builds the action generating a descriptor from the principal of the peer
+// certificate, as verified by the istio_authn filter. Headers such as x-forwarded-client-cert cannot be used since
+// sidecars forward the header sent by plaintext clients. Requests without a verified principal, such as plaintext
+// requests in PERMISSIVE mode, generate no descriptor and only consume the tokens of the rule.
+func buildLocalRateLimitPrincipalAction() *route.RateLimit {
+	return &route.RateLimit{
+		Stage: &wrappers.UInt32Value{Value: localRateLimitStage},
+		Actions: []*route.RateLimit_Action{{
+			ActionSpecifier: &route.RateLimit_Action_Metadata{
+				Metadata: &route.RateLimit_Action_MetaData{
+					DescriptorKey: localRateLimitPrincipalDescriptorKey,
+					MetadataKey: &metadata.MetadataKey{
+						Key:  authn_model.AuthnFilterName,
+						Path: []*metadata.MetadataKey_PathSegment{{
+							Segment: &metadata.MetadataKey_PathSegment_Key{Key: authnSourcePrincipal},
+						}},
+					},
+					Source: route.RateLimit_Action_MetaData_DYNAMIC,
+				},
+			},
+		}},
+	}
+}
+
+func buildLocalRateLimitDescriptorMatcher(d networkingv1beta1.LocalRateLimitDescriptor) *route.HeaderMatcher {
+	if d.Header != nil {
+		return &route.HeaderMatcher{
+			Name:                 d.Header.Name,
+			HeaderMatchSpecifier: &route.HeaderMatcher_ExactMatch{ExactMatch: d.Header.Value},
+		}
+	}
+	return &route.HeaderMatcher{
+		Name:                 ":path",
+		HeaderMatchSpecifier: &route.HeaderMatcher_PrefixMatch{PrefixMatch: d.PathPrefix},
+	}
+}
+
+func localRateLimitDescriptorValue(i int) string {
+	return "descriptor_" + strconv.Itoa(i)
+}
+
+func buildTokenBucket(bucket networkingv1beta1.TokenBucket) *xdstype.TokenBucket {
+	out := &xdstype.TokenBucket{
+		MaxTokens:    bucket.MaxTokens,
+		FillInterval: ptypes.DurationProto(bucket.FillInterval.Duration),
+	}
+	if bucket.TokensPerFill != nil {
+		out.TokensPerFill = &wrappers.UInt32Value{Value: *bucket.TokensPerFill}
+	}
+	return out
+}
+
+func fullRuntimeFraction(runtimeKey string) *core.RuntimeFractionalPercent {
+	return &core.RuntimeFractionalPercent{
+		DefaultValue: &xdstype.FractionalPercent{
+			Numerator:   100,
+			Denominator: xdstype.FractionalPercent_HUNDRED,
+		},
+		RuntimeKey: runtimeKey,
+	}
+}
@@ -0,0 +1,343 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3_test
+
+import (
+	"testing"
+
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	lrl "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/local_ratelimit/v3"
+	"github.com/golang/protobuf/ptypes"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3"
+	"istio.io/istio/pilot/pkg/simulation"
+	"istio.io/istio/pilot/pkg/xds"
+	"istio.io/istio/pilot/test/xdstest"
+)
+
+const localRateLimitServiceEntry = `
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: se
+spec:
+  hosts:
+  - foo.bar
+  endpoints:
+  - address: 1.1.1.1
+  location: MESH_INTERNAL
+  resolution: STATIC
+  ports:
+  - name: http
+    number: 80
+    protocol: HTTP
+  - name: http-admin
+    number: 8080
+    protocol: HTTP
+---
+`
+
+const localRateLimit = `
+apiVersion: networking.istio.io/v1beta1
+kind: LocalRateLimit
+metadata:
+  name: limit
+spec:
+  rules:
+  - match:
+      ports:
+      - 80
+    tokenBucket:
+      maxTokens: 100
+      fillInterval: 1s
+    descriptors:
+    - header:
+        name: x-user-tier
+        value: free
+      tokenBucket:
+        maxTokens: 10
+        fillInterval: 1s
+    - sourcePrincipal: cluster.local/ns/default/sa/sleep
+      tokenBucket:
+        maxTokens: 5
+        fillInterval: 2s
+    - pathPrefix: /admin
+      tokenBucket:
+        maxTokens: 1
+        fillInterval: 1m
+---
+`
+
+func TestLocalRateLimitSimulation(t *testing.T) {
+	runSimulationTest(t, nil, xds.FakeOptions{}, simulationTest{
+		config: localRateLimitServiceEntry + localRateLimit,
+		calls: []simulation.Expect{{
+			Name: "limited port",
+			Call: simulation.Call{
+				Port:     80,
+				Protocol: simulation.HTTP,
+				CallMode: simulation.CallModeInbound,
+			},
+			Result: simulation.Result{
+				VirtualHostMatched: "inbound|http|80",
+				ClusterMatched:     "inbound|80||",
+			},
+		}},
+	})
+}
+
+func TestLocalRateLimitInbound(t *testing.T) {
+	s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{ConfigString: localRateLimitServiceEntry + localRateLimit})
+	listeners := s.Listeners(s.SetupProxy(nil))
+	inbound := xdstest.ExtractListener("virtualInbound", listeners)
+	if inbound == nil {
+		t.Fatalf("missing virtualInbound listener")
+	}
+
+	limited := inboundHTTPRoute(t, inbound, "inbound|http|80")
+	if limited == nil {
+		t.Fatalf("missing inbound route for port 80")
+	}
+	cfg := &lrl.LocalRateLimit{}
+	if err := ptypes.UnmarshalAny(limited.TypedPerFilterConfig[v1alpha3.LocalRateLimitFilterName], cfg); err != nil {
+		t.Fatalf("missing local rate limit config on the limited route: %v", err)
+	}
+	if cfg.TokenBucket.MaxTokens != 100 || len(cfg.Descriptors) != 3 {
+		t.Errorf("unexpected local rate limit config %v", cfg)
+	}
+	if cfg.FilterEnforced.GetDefaultValue().GetNumerator() != 100 {
+		t.Errorf("expected the local rate limit to be enforced, got %v", cfg.FilterEnforced)
+	}
+	rateLimits := limited.GetRoute().RateLimits
+	if len(rateLimits) != 3 {
+		t.Fatalf("expected a rate limit action per descriptor, got %v", rateLimits)
+	}
+	// The actions are in the stage of the local rate limit filter, the global rate limit filter does not read them.
+	for _, rl := range rateLimits {
+		if rl.GetStage().GetValue() == 0 || rl.GetStage().GetValue() != cfg.Stage {
+			t.Errorf("expected the actions in the local rate limit stage %d, got %v", cfg.Stage, rl.GetStage())
+		}
+	}
+	for _, i := range []int{0, 2} {
+		match := rateLimits[i].Actions[0].GetHeaderValueMatch()
+		if match.DescriptorValue != cfg.Descriptors[i].Entries[0].Value {
+			t.Errorf("descriptor %d: action value %v does not match descriptor %v", i, match.DescriptorValue, cfg.Descriptors[i])
+		}
+	}
+	if got := rateLimits[0].Actions[0].GetHeaderValueMatch().GetHeaders()[0].Name; got != "x-user-tier" {
+		t.Errorf("expected a match on header x-user-tier, got %v", got)
+	}
+	if got := rateLimits[2].Actions[0].GetHeaderValueMatch().GetHeaders()[0].Name; got != ":path" {
+		t.Errorf("expected a match on the path, got %v", got)
+	}
+	// The principal is read from the peer certificate verified by the authn filter, never from a request header.
+	principal := rateLimits[1].Actions[0].GetMetadata()
+	if principal.GetMetadataKey().GetKey() != "istio_authn" ||
+		principal.GetMetadataKey().GetPath()[0].GetKey() != "source.principal" ||
+		principal.GetDescriptorKey() != cfg.Descriptors[1].Entries[0].Key {
+		t.Errorf("unexpected principal action %v", rateLimits[1])
+	}
+	if got := cfg.Descriptors[1].Entries[0].Value; got != "cluster.local/ns/default/sa/sleep" {
+		t.Errorf("expected the principal descriptor, got %v", got)
+	}
+
+	unlimited := inboundHTTPRoute(t, inbound, "inbound|http|8080")
+	if unlimited == nil {
+		t.Fatalf("missing inbound route for port 8080")
+	}
+	if _, f := unlimited.TypedPerFilterConfig[v1alpha3.LocalRateLimitFilterName]; f {
+		t.Errorf("unexpected local rate limit config on port 8080")
+	}
+}
+
+func TestLocalRateLimitNotSelected(t *testing.T) {
+	s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{ConfigString: localRateLimitServiceEntry + `
+apiVersion: networking.istio.io/v1beta1
+kind: LocalRateLimit
+metadata:
+  name: limit
+spec:
+  selector:
+    matchLabels:
+      app: other
+  rules:
+  - tokenBucket:
+      maxTokens: 100
+      fillInterval: 1s
+`})
+	proxy := s.SetupProxy(&model.Proxy{Metadata: &model.NodeMetadata{Labels: map[string]string{"app": "foo"}}})
+	inbound := xdstest.ExtractListener("virtualInbound", s.Listeners(proxy))
+	r := inboundHTTPRoute(t, inbound, "inbound|http|80")
+	if r == nil {
+		t.Fatalf("missing inbound route for port 80")
+	}
+	if _, f := r.TypedPerFilterConfig[v1alpha3.LocalRateLimitFilterName]; f {
+		t.Errorf("unexpected local rate limit config on a workload not selected")
+	}
+	for _, fc := range inbound.FilterChains {
+		if len(fc.Filters) == 0 || fc.Filters[len(fc.Filters)-1].Name != "envoy.filters.network.http_connection_manager" {
+			continue
+		}
+		for _, hf := range xdstest.ExtractHTTPConnectionManager(t, fc).HttpFilters {
+			if hf.Name == v1alpha3.LocalRateLimitFilterName {
+				t.Fatalf("unexpected local rate limit filter in filter chain %v", fc.Name)
+			}
+		}
+	}
+}
+
+func TestLocalRateLimitGateway(t *testing.T) {
+	runGatewayTest(t, simulationTest{
+		name: "gateway",
+		config: createGateway("gateway", "", `
+port:
+  name: http
+  number: 80
+  protocol: HTTP
+hosts:
+- "example.com"
+`) + `
+apiVersion: networking.istio.io/v1alpha3
+kind: VirtualService
+metadata:
+  name: vs
+spec:
+  hosts:
+  - "example.com"
+  gateways:
+  - gateway
+  http:
+  - name: limited
+    match:
+    - uri:
+        prefix: /limited
+    route:
+    - destination:
+        host: example.com
+  - name: unlimited
+    route:
+    - destination:
+        host: example.com
+---
+apiVersion: networking.istio.io/v1beta1
+kind: LocalRateLimit
+metadata:
+  name: limit
+spec:
+  selector:
+    matchLabels:
+      istio: ingressgateway
+  rules:
+  - match:
+      routes:
+      - limited
+    tokenBucket:
+      maxTokens: 100
+      fillInterval: 1s
+`,
+		calls: []simulation.Expect{{
+			Name: "limited",
+			Call: simulation.Call{
+				Port:       80,
+				HostHeader: "example.com",
+				Path:       "/limited",
+				Protocol:   simulation.HTTP,
+				CallMode:   simulation.CallModeGateway,
+			},
+			Result: simulation.Result{
+				RouteMatched:       "limited",
+				RouteConfigMatched: "http.80",
+				ClusterMatched:     "outbound|80||example.com",
+			},
+		}},
+	})
+
+	s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{ConfigString: createGateway("gateway", "", `
+port:
+  name: http
+  number: 80
+  protocol: HTTP
+hosts:
+- "example.com"
+`) + `
+apiVersion: networking.istio.io/v1alpha3
+kind: VirtualService
+metadata:
+  name: vs
+spec:
+  hosts:
+  - "example.com"
+  gateways:
+  - gateway
+  http:
+  - name: limited
+    route:
+    - destination:
+        host: example.com
+---
+apiVersion: networking.istio.io/v1beta1
+kind: LocalRateLimit
+metadata:
+  name: limit
+spec:
+  rules:
+  - match:
+      routes:
+      - limited
+    tokenBucket:
+      maxTokens: 100
+      fillInterval: 1s
+`})
+	proxy := s.SetupProxy(&model.Proxy{
+		Metadata: &model.NodeMetadata{Labels: map[string]string{"istio": "ingressgateway"}},
+		Type:     model.Router,
+	})
+	hcm := xdstest.ExtractHTTPConnectionManager(t, xdstest.ExtractListener("0.0.0.0_80", s.Listeners(proxy)).FilterChains[0])
+	found := false
+	for _, f := range hcm.HttpFilters {
+		if f.Name == v1alpha3.LocalRateLimitFilterName {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected the local rate limit filter, got %v", hcm.HttpFilters)
+	}
+	rc := xdstest.ExtractRouteConfigurations(s.Routes(proxy))["http.80"]
+	if rc == nil {
+		t.Fatalf("missing route configuration http.80")
+	}
+	r := rc.VirtualHosts[0].Routes[0]
+	if _, f := r.TypedPerFilterConfig[v1alpha3.LocalRateLimitFilterName]; !f {
+		t.Errorf("expected local rate limit config on route %v", r.Name)
+	}
+}
+
+// inboundHTTPRoute returns the route of the inbound virtual host with the given name.
+func inboundHTTPRoute(t *testing.T, l *listener.Listener, vhostName string) *route.Route {
+	t.Helper()
+	for _, fc := range l.FilterChains {
+		if len(fc.Filters) == 0 || fc.Filters[len(fc.Filters)-1].Name != "envoy.filters.network.http_connection_manager" {
+			continue
+		}
+		for _, vh := range xdstest.ExtractHTTPConnectionManager(t, fc).GetRouteConfig().GetVirtualHosts() {
+			if vh.Name == vhostName && len(vh.Routes) > 0 {
+				return vh.Routes[0]
+			}
+		}
+	}
+	return nil
+}
@@ -18,8 +18,10 @@ import (
 	"time"
 
 	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	kafka "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/kafka_broker/v3"
 	mongo "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/mongo_proxy/v3"
 	mysql "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/mysql_proxy/v3"
+	postgres "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/postgres_proxy/v3alpha"
 	redis "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/redis_proxy/v3"
 	tcp "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/tcp_proxy/v3"
 	thrift "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/v3"
@@ -142,6 +144,16 @@ func buildNetworkFiltersStack(port *model.Port, tcpFilter *listener.Filter, stat
 			filterstack = append(filterstack, buildMySQLFilter(statPrefix))
 		}
 		filterstack = append(filterstack, tcpFilter)
+	case protocol.Kafka:
+		if features.EnableKafkaFilter {
+			filterstack = append(filterstack, buildKafkaFilter(statPrefix))
+		}
+		filterstack = append(filterstack, tcpFilter)
+	case protocol.Postgres:
+		if features.EnablePostgresFilter {
+			filterstack = append(filterstack, buildPostgresFilter(statPrefix))
+		}
+		filterstack = append(filterstack, tcpFilter)
 	case protocol.Thrift:
 		if features.EnableThriftFilter {
 			// Thrift filter has route config, it is a terminating filter, no need append tcp filter.
@@ -171,7 +183,8 @@ func buildOutboundNetworkFilters(node *model.Proxy,
 			statPrefix = util.BuildStatPrefix(push.Mesh.OutboundClusterStatName, routes[0].Destination.Host,
 				routes[0].Destination.Subset, port, service.Attributes)
 		}
-		return buildOutboundNetworkFiltersWithSingleDestination(push, node, statPrefix, clusterName, port)
+		filters := buildOutboundNetworkFiltersWithSingleDestination(push, node, statPrefix, clusterName, port)
+		return maybeAddSniDynamicForwardProxyFilter(push, service, port, clusterName, filters)
 	}
 	return buildOutboundNetworkFiltersWithWeightedClusters(node, routes, push, port, configMeta)
 }
@@ -261,3 +274,31 @@ func buildMySQLFilter(statPrefix string) *listener.Filter {
 
 	return out
 }
+
+// buildKafkaFilter builds an Envoy KafkaBroker filter.
+func buildKafkaFilter(statPrefix string) *listener.Filter {
+	kafkaBroker := &kafka.KafkaBroker{
+		StatPrefix: statPrefix, // Kafka stats are prefixed with kafka.<statPrefix> by Envoy.
+	}
+
+	out := &listener.Filter{
+		Name:       util.KafkaBrokerFilter,
+		ConfigType: &listener.Filter_TypedConfig{TypedConfig: util.MessageToAny(kafkaBroker)},
+	}
+
+	return out
+}
+
+// buildPostgresFilter builds an Envoy PostgresProxy filter.
+func buildPostgresFilter(statPrefix string) *listener.Filter {
+	postgresProxy := &postgres.PostgresProxy{
+		StatPrefix: statPrefix, // Postgres stats are prefixed with postgres.<statPrefix> by Envoy.
+	}
+
+	out := &listener.Filter{
+		Name:       util.PostgresProxyFilter,
+		ConfigType: &listener.Filter_TypedConfig{TypedConfig: util.MessageToAny(postgresProxy)},
+	}
+
+	return out
+}
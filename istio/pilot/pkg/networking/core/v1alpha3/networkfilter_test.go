@@ -15,6 +15,7 @@
 package v1alpha3
 
 import (
+	"reflect"
 	"testing"
 
 	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
@@ -24,7 +25,9 @@ import (
 	"github.com/golang/protobuf/ptypes"
 
 	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/networking/util"
 	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/protocol"
 )
@@ -53,6 +56,40 @@ func TestBuildRedisFilter(t *testing.T) {
 	}
 }
 
+func TestBuildNetworkFiltersStack(t *testing.T) {
+	defaultKafka, defaultPostgres := features.EnableKafkaFilter, features.EnablePostgresFilter
+	defer func() {
+		features.EnableKafkaFilter, features.EnablePostgresFilter = defaultKafka, defaultPostgres
+	}()
+	tcpFilter := &listener.Filter{Name: wellknown.TCPProxy}
+
+	cases := []struct {
+		name     string
+		protocol protocol.Instance
+		enabled  bool
+		want     []string
+	}{
+		{"kafka", protocol.Kafka, true, []string{util.KafkaBrokerFilter, wellknown.TCPProxy}},
+		{"kafka disabled", protocol.Kafka, false, []string{wellknown.TCPProxy}},
+		{"postgres", protocol.Postgres, true, []string{util.PostgresProxyFilter, wellknown.TCPProxy}},
+		{"postgres disabled", protocol.Postgres, false, []string{wellknown.TCPProxy}},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			features.EnableKafkaFilter, features.EnablePostgresFilter = tt.enabled, tt.enabled
+			port := &model.Port{Name: "db", Port: 5432, Protocol: tt.protocol}
+			filters := buildNetworkFiltersStack(port, tcpFilter, "stats", "cluster")
+			got := make([]string, 0, len(filters))
+			for _, f := range filters {
+				got = append(got, f.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("expected filters %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
 func TestInboundNetworkFilterStatPrefix(t *testing.T) {
 	cases := []struct {
 		name               string
@@ -299,9 +299,9 @@ allroutes:
 	return out, nil
 }
 
-// sourceMatchHttp checks if the sourceLabels or the gateways in a match condition match with the
+// SourceMatchHTTP checks if the sourceLabels or the gateways in a match condition match with the
 // labels for the proxy or the gateway name for which we are generating a route
-func sourceMatchHTTP(match *networking.HTTPMatchRequest, proxyLabels labels.Collection, gatewayNames map[string]bool, proxyNamespace string) bool {
+func SourceMatchHTTP(match *networking.HTTPMatchRequest, proxyLabels labels.Collection, gatewayNames map[string]bool, proxyNamespace string) bool {
 	if match == nil {
 		return true
 	}
@@ -330,7 +330,7 @@ func translateRoute(push *model.PushContext, node *model.Proxy, in *networking.H
 	// resolved Traffic to such clusters will blackhole.
 
 	// Match by source labels/gateway names inside the match condition
-	if !sourceMatchHTTP(match, labels.Collection{node.Metadata.Labels}, gatewayNames, node.Metadata.Namespace) {
+	if !SourceMatchHTTP(match, labels.Collection{node.Metadata.Labels}, gatewayNames, node.Metadata.Namespace) {
 		return nil
 	}
 
@@ -595,12 +595,12 @@ func translateRouteMatch(in *networking.HTTPMatchRequest) *route.RouteMatch {
 	}
 
 	for name, stringMatch := range in.Headers {
-		matcher := translateHeaderMatch(name, stringMatch)
+		matcher := TranslateHeaderMatch(name, stringMatch)
 		out.Headers = append(out.Headers, matcher)
 	}
 
 	for name, stringMatch := range in.WithoutHeaders {
-		matcher := translateHeaderMatch(name, stringMatch)
+		matcher := TranslateHeaderMatch(name, stringMatch)
 		matcher.InvertMatch = true
 		out.Headers = append(out.Headers, matcher)
 	}
@@ -630,17 +630,17 @@ func translateRouteMatch(in *networking.HTTPMatchRequest) *route.RouteMatch {
 	out.CaseSensitive = &wrappers.BoolValue{Value: !in.IgnoreUriCase}
 
 	if in.Method != nil {
-		matcher := translateHeaderMatch(HeaderMethod, in.Method)
+		matcher := TranslateHeaderMatch(HeaderMethod, in.Method)
 		out.Headers = append(out.Headers, matcher)
 	}
 
 	if in.Authority != nil {
-		matcher := translateHeaderMatch(HeaderAuthority, in.Authority)
+		matcher := TranslateHeaderMatch(HeaderAuthority, in.Authority)
 		out.Headers = append(out.Headers, matcher)
 	}
 
 	if in.Scheme != nil {
-		matcher := translateHeaderMatch(HeaderScheme, in.Scheme)
+		matcher := TranslateHeaderMatch(HeaderScheme, in.Scheme)
 		out.Headers = append(out.Headers, matcher)
 	}
 
@@ -696,8 +696,8 @@ func isCatchAllHeaderMatch(in *networking.StringMatch) bool {
 	return catchall
 }
 
-// translateHeaderMatch translates to HeaderMatcher
-func translateHeaderMatch(name string, in *networking.StringMatch) *route.HeaderMatcher {
+// TranslateHeaderMatch translates to HeaderMatcher
+func TranslateHeaderMatch(name string, in *networking.StringMatch) *route.HeaderMatcher {
 	out := &route.HeaderMatcher{
 		Name: name,
 	}
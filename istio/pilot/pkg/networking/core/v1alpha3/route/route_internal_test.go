@@ -453,8 +453,8 @@ func TestSourceMatchHTTP(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := sourceMatchHTTP(tt.args.match, tt.args.proxyLabels, tt.args.gatewayNames, tt.args.proxyNamespace); got != tt.want {
-				t.Errorf("sourceMatchHTTP() = %v, want %v", got, tt.want)
+			if got := SourceMatchHTTP(tt.args.match, tt.args.proxyLabels, tt.args.gatewayNames, tt.args.proxyNamespace); got != tt.want {
+				t.Errorf("SourceMatchHTTP() = %v, want %v", got, tt.want)
 			}
 		})
 	}
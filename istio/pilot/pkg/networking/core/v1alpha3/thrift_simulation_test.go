@@ -0,0 +1,226 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3_test
+
+import (
+	"net/http"
+	"testing"
+
+	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/simulation"
+	"istio.io/istio/pilot/pkg/xds"
+)
+
+const thriftServices = `
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: users
+spec:
+  hosts:
+  - users.example.com
+  addresses:
+  - 10.0.0.1
+  ports:
+  - number: 9090
+    name: thrift-framed-compact
+    protocol: THRIFT
+  resolution: STATIC
+  endpoints:
+  - address: 2.2.2.2
+    labels:
+      version: v1
+  - address: 3.3.3.3
+    labels:
+      version: v2
+---
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: admin
+spec:
+  hosts:
+  - admin.example.com
+  addresses:
+  - 10.0.0.2
+  ports:
+  - number: 9090
+    name: thrift
+    protocol: THRIFT
+  resolution: STATIC
+  endpoints:
+  - address: 4.4.4.4
+---
+apiVersion: networking.istio.io/v1alpha3
+kind: DestinationRule
+metadata:
+  name: users
+spec:
+  host: users.example.com
+  subsets:
+  - name: v1
+    labels:
+      version: v1
+  - name: v2
+    labels:
+      version: v2
+---
+apiVersion: networking.istio.io/v1alpha3
+kind: VirtualService
+metadata:
+  name: users
+spec:
+  hosts:
+  - users.example.com
+  http:
+  - match:
+    - uri:
+        exact: getUser
+      headers:
+        x-canary:
+          exact: "true"
+    route:
+    - destination:
+        host: users.example.com
+        subset: v2
+  - match:
+    - uri:
+        prefix: "Admin:"
+    route:
+    - destination:
+        host: admin.example.com
+  - match:
+    - uri:
+        exact: listUsers
+      sourceLabels:
+        app: bar
+    route:
+    - destination:
+        host: users.example.com
+        subset: v2
+  - match:
+    - uri:
+        regex: "list.*"
+    route:
+    - destination:
+        host: users.example.com
+        subset: v2
+  - match:
+    - uri:
+        exact: listUsers
+    route:
+    - destination:
+        host: users.example.com
+        subset: v1
+`
+
+func TestThriftRouting(t *testing.T) {
+	defaultValue := features.EnableThriftFilter
+	features.EnableThriftFilter = true
+	defer func() { features.EnableThriftFilter = defaultValue }()
+
+	proxy := &model.Proxy{Metadata: &model.NodeMetadata{Labels: map[string]string{"app": "foo"}}}
+	call := func(method string, headers http.Header) simulation.Call {
+		return simulation.Call{
+			Address:    "10.0.0.1",
+			Port:       9090,
+			Protocol:   simulation.Thrift,
+			MethodName: method,
+			Headers:    headers,
+			CallMode:   simulation.CallModeOutbound,
+		}
+	}
+	runSimulationTest(t, proxy, xds.FakeOptions{}, simulationTest{
+		config: thriftServices,
+		calls: []simulation.Expect{
+			{
+				Name: "method and header match",
+				Call: call("getUser", http.Header{"x-canary": {"true"}}),
+				Result: simulation.Result{
+					ListenerMatched:    "10.0.0.1_9090",
+					RouteConfigMatched: "outbound|9090||users.example.com",
+					ClusterMatched:     "outbound|9090|v2|users.example.com",
+				},
+			},
+			{
+				Name: "header mismatch falls back to the default route",
+				Call: call("getUser", http.Header{"x-canary": {"false"}}),
+				Result: simulation.Result{
+					ListenerMatched: "10.0.0.1_9090",
+					ClusterMatched:  "outbound|9090||users.example.com",
+				},
+			},
+			{
+				Name: "service name match",
+				Call: call("Admin:deleteUser", nil),
+				Result: simulation.Result{
+					ListenerMatched: "10.0.0.1_9090",
+					ClusterMatched:  "outbound|9090||admin.example.com",
+				},
+			},
+			{
+				// The rules for other source labels and with regex method names are skipped.
+				Name: "method match",
+				Call: call("listUsers", nil),
+				Result: simulation.Result{
+					ListenerMatched: "10.0.0.1_9090",
+					ClusterMatched:  "outbound|9090|v1|users.example.com",
+				},
+			},
+			{
+				Name: "tcp",
+				Call: simulation.Call{Address: "10.0.0.1", Port: 9090, Protocol: simulation.TCP, CallMode: simulation.CallModeOutbound},
+				Result: simulation.Result{
+					ListenerMatched: "10.0.0.1_9090",
+					Error:           simulation.ErrProtocolError,
+				},
+			},
+			{
+				Name: "service without virtual service",
+				Call: simulation.Call{
+					Address:    "10.0.0.2",
+					Port:       9090,
+					Protocol:   simulation.Thrift,
+					MethodName: "getUser",
+					CallMode:   simulation.CallModeOutbound,
+				},
+				Result: simulation.Result{
+					ListenerMatched: "10.0.0.2_9090",
+					ClusterMatched:  "outbound|9090||admin.example.com",
+				},
+			},
+		},
+	})
+}
+
+func TestThriftRoutingDisabled(t *testing.T) {
+	defaultValue := features.EnableThriftFilter
+	features.EnableThriftFilter = false
+	defer func() { features.EnableThriftFilter = defaultValue }()
+
+	proxy := &model.Proxy{Metadata: &model.NodeMetadata{Labels: map[string]string{"app": "foo"}}}
+	runSimulationTest(t, proxy, xds.FakeOptions{}, simulationTest{
+		config: thriftServices,
+		calls: []simulation.Expect{{
+			Name: "tcp proxy ignores virtual service",
+			Call: simulation.Call{Address: "10.0.0.1", Port: 9090, Protocol: simulation.TCP, CallMode: simulation.CallModeOutbound},
+			Result: simulation.Result{
+				ListenerMatched: "10.0.0.1_9090",
+				ClusterMatched:  "outbound|9090||users.example.com",
+			},
+		}},
+	})
+}
@@ -17,35 +17,78 @@ package v1alpha3
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
 	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
 	thrift "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/v3"
+	"github.com/golang/protobuf/ptypes/wrappers"
 
+	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pilot/pkg/model"
+	istio_route "istio.io/istio/pilot/pkg/networking/core/v1alpha3/route"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
 	"istio.io/pkg/log"
 )
 
-// buildDefaultThriftInboundRoute builds a default inbound route.
-func buildDefaultThriftRoute(clusterName, rateLimitClusterName string) *thrift.Route {
-	var rateLimits []*route.RateLimit
-	if rateLimitClusterName != "" {
-		rateLimits = []*route.RateLimit{
-			{
-				Actions: []*route.RateLimit_Action{
-					{
-						ActionSpecifier: &route.RateLimit_Action_SourceCluster_{
-							// Automatically populated
-							SourceCluster: &route.RateLimit_Action_SourceCluster{},
-						},
+// thriftProtocols are the Thrift protocols selectable by a segment of the port name, e.g. thrift-compact.
+var thriftProtocols = map[string]thrift.ProtocolType{
+	"binary":    thrift.ProtocolType_BINARY,
+	"laxbinary": thrift.ProtocolType_LAX_BINARY,
+	"compact":   thrift.ProtocolType_COMPACT,
+	"twitter":   thrift.ProtocolType_TWITTER,
+}
+
+// thriftTransports are the Thrift transports selectable by a segment of the port name, e.g. thrift-framed.
+var thriftTransports = map[string]thrift.TransportType{
+	"framed":   thrift.TransportType_FRAMED,
+	"unframed": thrift.TransportType_UNFRAMED,
+	"header":   thrift.TransportType_HEADER,
+}
+
+// thriftProtocolAndTransport returns the Thrift protocol and transport of a port, selected by the segments of the
+// port name following the thrift prefix, e.g. thrift-framed-compact. They are auto detected if not set.
+func thriftProtocolAndTransport(port *model.Port) (thrift.ProtocolType, thrift.TransportType) {
+	protocol, transport := thrift.ProtocolType_AUTO_PROTOCOL, thrift.TransportType_AUTO_TRANSPORT
+	if port == nil {
+		return protocol, transport
+	}
+	for _, segment := range strings.Split(strings.ToLower(port.Name), "-")[1:] {
+		if p, f := thriftProtocols[segment]; f {
+			protocol = p
+		}
+		if t, f := thriftTransports[segment]; f {
+			transport = t
+		}
+	}
+	return protocol, transport
+}
+
+// buildThriftRateLimits builds the rate limit configurations of the routes, if a rate limit service is configured.
+func buildThriftRateLimits(rateLimitClusterName string) []*route.RateLimit {
+	if rateLimitClusterName == "" {
+		return nil
+	}
+	return []*route.RateLimit{
+		{
+			Actions: []*route.RateLimit_Action{
+				{
+					ActionSpecifier: &route.RateLimit_Action_SourceCluster_{
+						// Automatically populated
+						SourceCluster: &route.RateLimit_Action_SourceCluster{},
 					},
 				},
 			},
-		}
+		},
 	}
+}
 
+// buildDefaultThriftInboundRoute builds a default inbound route.
+func buildDefaultThriftRoute(clusterName, rateLimitClusterName string) *thrift.Route {
 	return &thrift.Route{
 		Match: &thrift.RouteMatch{
 			MatchSpecifier: &thrift.RouteMatch_MethodName{
@@ -60,7 +103,7 @@ func buildDefaultThriftRoute(clusterName, rateLimitClusterName string) *thrift.R
 			ClusterSpecifier: &thrift.RouteAction_Cluster{
 				Cluster: clusterName,
 			},
-			RateLimits: rateLimits,
+			RateLimits: buildThriftRateLimits(rateLimitClusterName),
 		},
 	}
 }
@@ -83,6 +126,140 @@ func (configgen *ConfigGeneratorImpl) buildSidecarThriftRouteConfig(clusterName,
 	}
 }
 
+// buildSidecarOutboundThriftRouteConfig builds the route config of an outbound Thrift listener: the routes
+// translated from the http rules of the VirtualServices for the service, followed by the default route to the
+// service cluster.
+func (configgen *ConfigGeneratorImpl) buildSidecarOutboundThriftRouteConfig(node *model.Proxy, push *model.PushContext,
+	service *model.Service, port *model.Port, virtualServices []config.Config) *thrift.RouteConfiguration {
+	clusterName := model.BuildSubsetKey(model.TrafficDirectionOutbound, "", service.Hostname, port.Port)
+	rlsClusterName, err := thriftRLSClusterNameFromAuthority(push.Mesh.ThriftConfig.RateLimitUrl)
+	if err != nil {
+		rlsClusterName = ""
+	}
+
+	routes := buildThriftRoutesForVirtualServices(node, push, service, port,
+		getConfigsForHost(service.Hostname, virtualServices), buildThriftRateLimits(rlsClusterName))
+	return &thrift.RouteConfiguration{
+		Name:   clusterName,
+		Routes: append(routes, buildDefaultThriftRoute(clusterName, rlsClusterName)),
+	}
+}
+
+// buildThriftRoutesForVirtualServices translates the http rules of the VirtualServices to Thrift routes. A uri
+// exact match selects a method name, and a uri prefix match a service name of multiplexed services. Header matches
+// apply to the headers of the header transport and twitter protocol. Rules without destinations, e.g. redirects,
+// have no Thrift equivalent and are skipped.
+func buildThriftRoutesForVirtualServices(node *model.Proxy, push *model.PushContext, service *model.Service, port *model.Port,
+	virtualServices []config.Config, rateLimits []*route.RateLimit) []*thrift.Route {
+	gateways := map[string]bool{constants.IstioMeshGateway: true}
+	var out []*thrift.Route
+	for _, cfg := range virtualServices {
+		virtualService := cfg.Spec.(*networking.VirtualService)
+		for _, http := range virtualService.Http {
+			if len(http.Route) == 0 {
+				continue
+			}
+			action := buildThriftRouteAction(node, push, http.Route, port, rateLimits)
+			if action == nil {
+				continue
+			}
+			if len(http.Match) == 0 {
+				out = append(out, &thrift.Route{
+					Match: &thrift.RouteMatch{MatchSpecifier: &thrift.RouteMatch_MethodName{MethodName: ""}},
+					Route: action,
+				})
+				continue
+			}
+			for _, match := range http.Match {
+				if match.Port != 0 && int(match.Port) != port.Port {
+					continue
+				}
+				if !istio_route.SourceMatchHTTP(match, labels.Collection{node.Metadata.Labels}, gateways, node.Metadata.Namespace) {
+					continue
+				}
+				routeMatch, err := translateThriftRouteMatch(match)
+				if err != nil {
+					log.Warnf("skipping thrift route of virtual service %s/%s for %s: %v",
+						cfg.Namespace, cfg.Name, service.Hostname, err)
+					continue
+				}
+				out = append(out, &thrift.Route{Match: routeMatch, Route: action})
+			}
+		}
+	}
+	return out
+}
+
+// translateThriftRouteMatch translates an http match to a Thrift route match.
+func translateThriftRouteMatch(in *networking.HTTPMatchRequest) (*thrift.RouteMatch, error) {
+	if in.Method != nil || in.Authority != nil || in.Scheme != nil || len(in.QueryParams) > 0 {
+		return nil, errors.New("only uri and header matches are supported")
+	}
+	out := &thrift.RouteMatch{MatchSpecifier: &thrift.RouteMatch_MethodName{MethodName: ""}}
+	switch m := in.Uri.GetMatchType().(type) {
+	case *networking.StringMatch_Exact:
+		out.MatchSpecifier = &thrift.RouteMatch_MethodName{MethodName: m.Exact}
+	case *networking.StringMatch_Prefix:
+		out.MatchSpecifier = &thrift.RouteMatch_ServiceName{ServiceName: m.Prefix}
+	case *networking.StringMatch_Regex:
+		return nil, errors.New("regex method names are not supported")
+	}
+
+	out.Headers = append(out.Headers, translateThriftHeaderMatches(in.Headers, false)...)
+	out.Headers = append(out.Headers, translateThriftHeaderMatches(in.WithoutHeaders, true)...)
+	return out, nil
+}
+
+// translateThriftHeaderMatches translates header matches, sorted by name for stable route configurations.
+func translateThriftHeaderMatches(headers map[string]*networking.StringMatch, invert bool) []*route.HeaderMatcher {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	out := make([]*route.HeaderMatcher, 0, len(names))
+	for _, name := range names {
+		matcher := istio_route.TranslateHeaderMatch(name, headers[name])
+		matcher.InvertMatch = invert
+		out = append(out, matcher)
+	}
+	return out
+}
+
+// buildThriftRouteAction builds the action forwarding to the destinations of a rule, nil if no destination has a
+// weight.
+func buildThriftRouteAction(node *model.Proxy, push *model.PushContext, destinations []*networking.HTTPRouteDestination,
+	port *model.Port, rateLimits []*route.RateLimit) *thrift.RouteAction {
+	clusterName := func(destination *networking.Destination) string {
+		return istio_route.GetDestinationCluster(destination, push.ServiceForHostname(node, host.Name(destination.Host)), port.Port)
+	}
+	action := &thrift.RouteAction{RateLimits: rateLimits}
+	if len(destinations) == 1 {
+		action.ClusterSpecifier = &thrift.RouteAction_Cluster{Cluster: clusterName(destinations[0].Destination)}
+		return action
+	}
+
+	weighted := &thrift.WeightedCluster{}
+	for _, dst := range destinations {
+		if dst.Weight == 0 {
+			continue
+		}
+		weighted.Clusters = append(weighted.Clusters, &thrift.WeightedCluster_ClusterWeight{
+			Name:   clusterName(dst.Destination),
+			Weight: &wrappers.UInt32Value{Value: uint32(dst.Weight)},
+		})
+	}
+	switch len(weighted.Clusters) {
+	case 0:
+		return nil
+	case 1:
+		action.ClusterSpecifier = &thrift.RouteAction_Cluster{Cluster: weighted.Clusters[0].Name}
+	default:
+		action.ClusterSpecifier = &thrift.RouteAction_WeightedClusters{WeightedClusters: weighted}
+	}
+	return action
+}
+
 // Build a cluster name from an authority (host[:port]) string. If an error is
 // encountered, an empty string is returned as the cluster name.
 func thriftRLSClusterNameFromAuthority(authority string) (string, error) {
@@ -14,7 +14,16 @@
 
 package v1alpha3
 
-import "testing"
+import (
+	"testing"
+
+	thrift "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/v3"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/ptypes/wrappers"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+)
 
 func TestGetClusterNameFromURL(t *testing.T) {
 	cluster, err := thriftRLSClusterNameFromAuthority("")
@@ -29,3 +38,70 @@ func TestGetClusterNameFromURL(t *testing.T) {
 		t.Fatalf("Should return correct cluster name (got %v)", cluster)
 	}
 }
+
+func TestThriftProtocolAndTransport(t *testing.T) {
+	cases := []struct {
+		name      string
+		protocol  thrift.ProtocolType
+		transport thrift.TransportType
+	}{
+		{"thrift", thrift.ProtocolType_AUTO_PROTOCOL, thrift.TransportType_AUTO_TRANSPORT},
+		{"thrift-users", thrift.ProtocolType_AUTO_PROTOCOL, thrift.TransportType_AUTO_TRANSPORT},
+		{"thrift-compact", thrift.ProtocolType_COMPACT, thrift.TransportType_AUTO_TRANSPORT},
+		{"thrift-framed-binary", thrift.ProtocolType_BINARY, thrift.TransportType_FRAMED},
+		{"Thrift-Header-Twitter", thrift.ProtocolType_TWITTER, thrift.TransportType_HEADER},
+		{"framed", thrift.ProtocolType_AUTO_PROTOCOL, thrift.TransportType_AUTO_TRANSPORT},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			protocol, transport := thriftProtocolAndTransport(&model.Port{Name: tc.name, Port: 9090})
+			if protocol != tc.protocol || transport != tc.transport {
+				t.Fatalf("expected %v/%v, got %v/%v", tc.protocol, tc.transport, protocol, transport)
+			}
+		})
+	}
+}
+
+func TestBuildThriftRouteAction(t *testing.T) {
+	port := &model.Port{Name: "thrift", Port: 9090}
+	destination := func(subset string, weight int32) *networking.HTTPRouteDestination {
+		return &networking.HTTPRouteDestination{
+			Destination: &networking.Destination{Host: "users.example.com", Subset: subset},
+			Weight:      weight,
+		}
+	}
+	cases := []struct {
+		name         string
+		destinations []*networking.HTTPRouteDestination
+		want         *thrift.RouteAction
+	}{
+		{
+			name:         "single destination",
+			destinations: []*networking.HTTPRouteDestination{destination("v1", 0)},
+			want:         &thrift.RouteAction{ClusterSpecifier: &thrift.RouteAction_Cluster{Cluster: "outbound|9090|v1|users.example.com"}},
+		},
+		{
+			name:         "weighted destinations",
+			destinations: []*networking.HTTPRouteDestination{destination("v1", 90), destination("v2", 10)},
+			want: &thrift.RouteAction{ClusterSpecifier: &thrift.RouteAction_WeightedClusters{
+				WeightedClusters: &thrift.WeightedCluster{Clusters: []*thrift.WeightedCluster_ClusterWeight{
+					{Name: "outbound|9090|v1|users.example.com", Weight: &wrappers.UInt32Value{Value: 90}},
+					{Name: "outbound|9090|v2|users.example.com", Weight: &wrappers.UInt32Value{Value: 10}},
+				}},
+			}},
+		},
+		{
+			name:         "zero weight destination",
+			destinations: []*networking.HTTPRouteDestination{destination("v1", 0), destination("v2", 100)},
+			want:         &thrift.RouteAction{ClusterSpecifier: &thrift.RouteAction_Cluster{Cluster: "outbound|9090|v2|users.example.com"}},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := buildThriftRouteAction(&model.Proxy{}, model.NewPushContext(), tc.destinations, port, nil)
+			if !proto.Equal(got, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -196,10 +196,11 @@ func buildSidecarOutboundTLSFilterChainOpts(node *model.Proxy, push *model.PushC
 			sniHosts = []string{string(service.Hostname)}
 		}
 
+		networkFilters := buildOutboundNetworkFiltersWithSingleDestination(push, node, statPrefix, clusterName, listenPort)
 		out = append(out, &filterChainOpts{
 			sniHosts:         sniHosts,
 			destinationCIDRs: []string{destinationCIDR},
-			networkFilters:   buildOutboundNetworkFiltersWithSingleDestination(push, node, statPrefix, clusterName, listenPort),
+			networkFilters:   maybeAddSniDynamicForwardProxyFilter(push, service, listenPort, clusterName, networkFilters),
 		})
 	}
 
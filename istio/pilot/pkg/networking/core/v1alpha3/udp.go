@@ -0,0 +1,231 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3
+
+import (
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"time"
+
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
+	udp "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/udp/udp_proxy/v3"
+	"github.com/golang/protobuf/ptypes"
+
+	networking "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/model"
+	istio_route "istio.io/istio/pilot/pkg/networking/core/v1alpha3/route"
+	"istio.io/istio/pilot/pkg/networking/util"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/constants"
+	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/protocol"
+	"istio.io/pkg/log"
+)
+
+// UDPProxyListenerFilterName is the name of the Envoy UDP proxy listener filter.
+const UDPProxyListenerFilterName = "envoy.filters.udp_listener.udp_proxy"
+
+// udpListenerName returns the name of the UDP listener bound to the given address and port. The name differs from
+// the one of TCP listeners, which may be bound to the same address and port.
+func udpListenerName(bind string, port int) string {
+	return bind + "_" + strconv.Itoa(port) + "_udp"
+}
+
+// buildUDPListener builds a listener bound to the given address and port, forwarding all datagrams to a single
+// cluster with the udp_proxy listener filter.
+func buildUDPListener(node *model.Proxy, bind string, port int, statPrefix, clusterName string,
+	direction core.TrafficDirection) *listener.Listener {
+	udpProxy := &udp.UdpProxyConfig{
+		StatPrefix:     statPrefix,
+		RouteSpecifier: &udp.UdpProxyConfig_Cluster{Cluster: clusterName},
+	}
+	idleTimeout, err := time.ParseDuration(node.Metadata.IdleTimeout)
+	if err == nil {
+		udpProxy.IdleTimeout = ptypes.DurationProto(idleTimeout)
+	}
+
+	address := util.BuildAddress(bind, uint32(port))
+	address.GetSocketAddress().Protocol = core.SocketAddress_UDP
+	return &listener.Listener{
+		Name:             udpListenerName(bind, port),
+		Address:          address,
+		TrafficDirection: direction,
+		ListenerFilters: []*listener.ListenerFilter{{
+			Name:       UDPProxyListenerFilterName,
+			ConfigType: &listener.ListenerFilter_TypedConfig{TypedConfig: util.MessageToAny(udpProxy)},
+		}},
+	}
+}
+
+// outboundUDPPorts returns the outbound UDP ports redirected to the proxy.
+func outboundUDPPorts(node *model.Proxy) map[int]bool {
+	ports := make(map[int]bool, len(node.Metadata.OutboundUDPPorts))
+	for _, p := range node.Metadata.OutboundUDPPorts {
+		port, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			log.Debugf("ignoring invalid outbound UDP port %q of proxy %s", p, node.ID)
+			continue
+		}
+		ports[port] = true
+	}
+	return ports
+}
+
+// buildSidecarOutboundUDPListeners builds a listener on localhost for each outbound UDP port redirected to the proxy.
+// Unlike TCP, the original destination of redirected datagrams can not be recovered, so all the services on a
+// port share the same listener, and the first service claiming the port wins.
+func (configgen *ConfigGeneratorImpl) buildSidecarOutboundUDPListeners(node *model.Proxy,
+	push *model.PushContext) []*listener.Listener {
+	udpPorts := outboundUDPPorts(node)
+	if len(udpPorts) == 0 {
+		return nil
+	}
+	_, actualLocalHostAddress := getActualWildcardAndLocalHost(node)
+	gateways := map[string]bool{constants.IstioMeshGateway: true}
+
+	var listeners []*listener.Listener
+	servicesByPort := make(map[int]*model.Service)
+	for _, egressListener := range node.SidecarScope.EgressListeners {
+		virtualServices := egressListener.VirtualServices()
+		for _, service := range egressListener.Services() {
+			for _, port := range service.Ports {
+				if port.Protocol != protocol.UDP || !udpPorts[port.Port] {
+					continue
+				}
+				// An egress listener with a port only exposes services on that port.
+				if l := egressListener.IstioListener; l != nil && l.Port != nil &&
+					(int(l.Port.Number) != port.Port || protocol.Parse(l.Port.Protocol) != protocol.UDP) {
+					continue
+				}
+				if current, f := servicesByPort[port.Port]; f {
+					if current.Hostname != service.Hostname {
+						outboundListenerConflict{
+							metric:          model.ProxyStatusConflictOutboundListenerUDPOverUDP,
+							node:            node,
+							listenerName:    udpListenerName(actualLocalHostAddress, port.Port),
+							currentServices: []*model.Service{current},
+							currentProtocol: protocol.UDP,
+							newHostname:     service.Hostname,
+							newProtocol:     protocol.UDP,
+						}.addMetric(push)
+					}
+					continue
+				}
+				servicesByPort[port.Port] = service
+
+				clusterName := outboundUDPCluster(node, push, service, port, virtualServices, gateways)
+				listeners = append(listeners,
+					buildUDPListener(node, actualLocalHostAddress, port.Port, clusterName, clusterName, core.TrafficDirection_OUTBOUND))
+			}
+		}
+	}
+	return listeners
+}
+
+// outboundUDPCluster returns the cluster receiving the UDP traffic of a service port, selected by the tcp routes of
+// the VirtualServices for the service.
+func outboundUDPCluster(node *model.Proxy, push *model.PushContext, service *model.Service, port *model.Port,
+	configs []config.Config, gateways map[string]bool) string {
+	for _, cfg := range getConfigsForHost(service.Hostname, configs) {
+		virtualService := cfg.Spec.(*networking.VirtualService)
+		for _, tcp := range virtualService.Tcp {
+			if len(tcp.Match) == 0 {
+				return udpRouteCluster(node, push, tcp.Route, port)
+			}
+			for _, match := range tcp.Match {
+				// The destination address of redirected datagrams is lost, so subnet matches can not apply.
+				if len(match.DestinationSubnets) == 0 &&
+					matchTCP(match, labels.Collection{node.Metadata.Labels}, gateways, port.Port, node.Metadata.Namespace) {
+					return udpRouteCluster(node, push, tcp.Route, port)
+				}
+			}
+		}
+	}
+	return model.BuildSubsetKey(model.TrafficDirectionOutbound, "", service.Hostname, port.Port)
+}
+
+// udpRouteCluster returns the cluster of one of the route destinations. The udp_proxy filter of the supported Envoy
+// versions forwards to a single cluster, so traffic is not split by weight: each proxy consistently sends all its
+// datagrams to one destination, picked by hashing the proxy ID with a probability proportional to its weight. The
+// split only approaches the weights across many proxies.
+func udpRouteCluster(node *model.Proxy, push *model.PushContext, routes []*networking.RouteDestination, port *model.Port) string {
+	destination := routes[0].Destination
+	if len(routes) > 1 {
+		var total uint32
+		for _, route := range routes {
+			total += uint32(route.Weight)
+		}
+		if total > 0 {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(node.ID))
+			pick := h.Sum32() % total
+			for _, route := range routes {
+				if pick < uint32(route.Weight) {
+					destination = route.Destination
+					break
+				}
+				pick -= uint32(route.Weight)
+			}
+		}
+	}
+	service := push.ServiceForHostname(node, host.Name(destination.Host))
+	return istio_route.GetDestinationCluster(destination, service, port.Port)
+}
+
+// buildSidecarInboundUDPListener builds the listener of a Sidecar ingress listener with the UDP protocol. Inbound UDP
+// traffic is not captured by iptables, so the listener is always bound to its port, and forwards to the default
+// endpoint of the ingress listener. The UDP ports of the services of the workload get no inbound listener: their
+// traffic reaches the application directly.
+func (configgen *ConfigGeneratorImpl) buildSidecarInboundUDPListener(node *model.Proxy, bind string,
+	ingressListener *networking.IstioIngressListener) *listener.Listener {
+	if ingressListener.DefaultEndpoint == "" || strings.HasPrefix(ingressListener.DefaultEndpoint, model.UnixAddressPrefix) {
+		log.Warnf("buildSidecarInboundUDPListener: skipping UDP ingress listener on port %d of proxy %s without an IP default endpoint",
+			ingressListener.Port.Number, node.ID)
+		return nil
+	}
+	clusterName := model.BuildInboundSubsetKey(int(ingressListener.Port.Number))
+	return buildUDPListener(node, bind, int(ingressListener.Port.Number), clusterName, clusterName, core.TrafficDirection_INBOUND)
+}
+
+// buildGatewayUDPListener builds the listener of a gateway server with the UDP protocol, forwarding to the destination
+// of the first tcp route of the VirtualServices bound to the server.
+func buildGatewayUDPListener(node *model.Proxy, push *model.PushContext, bind string, listenPort uint32,
+	server *networking.Server, gatewayName string) *listener.Listener {
+	port := &model.Port{
+		Name:     server.Port.Name,
+		Port:     int(server.Port.Number),
+		Protocol: protocol.UDP,
+	}
+	gatewayServerHosts := make(map[host.Name]bool, len(server.Hosts))
+	for _, hostname := range server.Hosts {
+		gatewayServerHosts[host.Name(hostname)] = true
+	}
+	for _, v := range push.VirtualServicesForGateway(node, gatewayName) {
+		if len(pickMatchingGatewayHosts(gatewayServerHosts, v)) == 0 {
+			continue
+		}
+		for _, tcp := range v.Spec.(*networking.VirtualService).Tcp {
+			if l4MultiMatch(tcp.Match, server, gatewayName) {
+				clusterName := udpRouteCluster(node, push, tcp.Route, port)
+				return buildUDPListener(node, bind, int(listenPort), clusterName, clusterName, core.TrafficDirection_OUTBOUND)
+			}
+		}
+	}
+	log.Warnf("buildGatewayUDPListener: no virtual service routes UDP port %d of gateway %s", server.Port.Number, gatewayName)
+	return nil
+}
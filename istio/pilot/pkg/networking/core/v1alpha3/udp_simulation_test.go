@@ -0,0 +1,339 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha3_test
+
+import (
+	"testing"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/simulation"
+	"istio.io/istio/pilot/pkg/xds"
+	"istio.io/istio/pilot/test/xdstest"
+)
+
+const syslogServiceEntry = `
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: syslog
+spec:
+  hosts:
+  - syslog.example.com
+  ports:
+  - number: 514
+    name: udp-syslog
+    protocol: UDP
+  - number: 8080
+    name: http
+    protocol: HTTP
+  resolution: DNS
+  endpoints:
+  - address: 2.2.2.2
+    labels:
+      version: v1
+  - address: 3.3.3.3
+    labels:
+      version: v2
+---
+apiVersion: networking.istio.io/v1alpha3
+kind: DestinationRule
+metadata:
+  name: syslog
+spec:
+  host: syslog.example.com
+  subsets:
+  - name: v1
+    labels:
+      version: v1
+  - name: v2
+    labels:
+      version: v2
+---
+`
+
+func udpProxy(ports ...string) *model.Proxy {
+	return &model.Proxy{
+		Metadata: &model.NodeMetadata{
+			Labels:           map[string]string{"app": "foo"},
+			OutboundUDPPorts: ports,
+		},
+	}
+}
+
+func TestOutboundUDP(t *testing.T) {
+	cases := []struct {
+		name  string
+		proxy *model.Proxy
+		simulationTest
+	}{
+		{
+			name:  "captured port",
+			proxy: udpProxy("514"),
+			simulationTest: simulationTest{
+				config: syslogServiceEntry,
+				calls: []simulation.Expect{
+					{
+						Name: "udp",
+						Call: simulation.Call{Port: 514, Protocol: simulation.UDP, CallMode: simulation.CallModeOutbound},
+						Result: simulation.Result{
+							ListenerMatched: "127.0.0.1_514_udp",
+							ClusterMatched:  "outbound|514||syslog.example.com",
+						},
+					},
+					{
+						Name: "tcp is not routed to the udp listener",
+						Call: simulation.Call{Port: 514, Protocol: simulation.TCP, CallMode: simulation.CallModeOutbound},
+						Result: simulation.Result{
+							ListenerMatched: "virtualOutbound",
+							ClusterMatched:  "PassthroughCluster",
+						},
+					},
+				},
+			},
+		},
+		{
+			name:  "port not captured",
+			proxy: udpProxy(),
+			simulationTest: simulationTest{
+				config: syslogServiceEntry,
+				calls: []simulation.Expect{{
+					Name:   "udp",
+					Call:   simulation.Call{Port: 514, Protocol: simulation.UDP, CallMode: simulation.CallModeOutbound},
+					Result: simulation.Result{Error: simulation.ErrNoListener},
+				}},
+			},
+		},
+		{
+			name:  "virtual service subset",
+			proxy: udpProxy("514"),
+			simulationTest: simulationTest{
+				config: syslogServiceEntry + `
+apiVersion: networking.istio.io/v1alpha3
+kind: VirtualService
+metadata:
+  name: syslog
+spec:
+  hosts:
+  - syslog.example.com
+  tcp:
+  - match:
+    - port: 514
+      sourceLabels:
+        app: bar
+    route:
+    - destination:
+        host: syslog.example.com
+        subset: v1
+  - match:
+    - port: 514
+      sourceLabels:
+        app: foo
+    route:
+    - destination:
+        host: syslog.example.com
+        subset: v2
+`,
+				calls: []simulation.Expect{{
+					Name: "udp",
+					Call: simulation.Call{Port: 514, Protocol: simulation.UDP, CallMode: simulation.CallModeOutbound},
+					Result: simulation.Result{
+						ListenerMatched: "127.0.0.1_514_udp",
+						ClusterMatched:  "outbound|514|v2|syslog.example.com",
+					},
+				}},
+			},
+		},
+		{
+			name:  "virtual service weights",
+			proxy: udpProxy("514"),
+			simulationTest: simulationTest{
+				config: syslogServiceEntry + `
+apiVersion: networking.istio.io/v1alpha3
+kind: VirtualService
+metadata:
+  name: syslog
+spec:
+  hosts:
+  - syslog.example.com
+  tcp:
+  - route:
+    - destination:
+        host: syslog.example.com
+        subset: v1
+      weight: 0
+    - destination:
+        host: syslog.example.com
+        subset: v2
+      weight: 100
+`,
+				calls: []simulation.Expect{{
+					Name: "udp",
+					Call: simulation.Call{Port: 514, Protocol: simulation.UDP, CallMode: simulation.CallModeOutbound},
+					Result: simulation.Result{
+						ListenerMatched: "127.0.0.1_514_udp",
+						ClusterMatched:  "outbound|514|v2|syslog.example.com",
+					},
+				}},
+			},
+		},
+		{
+			name:  "conflicting services",
+			proxy: udpProxy("514"),
+			simulationTest: simulationTest{
+				config: syslogServiceEntry + `
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: syslog2
+spec:
+  hosts:
+  - syslog2.example.com
+  ports:
+  - number: 514
+    name: udp-syslog
+    protocol: UDP
+  resolution: DNS
+`,
+				calls: []simulation.Expect{{
+					Name: "udp",
+					Call: simulation.Call{Port: 514, Protocol: simulation.UDP, CallMode: simulation.CallModeOutbound},
+					Result: simulation.Result{
+						ListenerMatched: "127.0.0.1_514_udp",
+						ClusterMatched:  "outbound|514||syslog.example.com",
+					},
+				}},
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			runSimulationTest(t, tt.proxy, xds.FakeOptions{}, tt.simulationTest)
+		})
+	}
+}
+
+const udpSidecar = `
+apiVersion: networking.istio.io/v1alpha3
+kind: Sidecar
+metadata:
+  name: sidecar
+spec:
+  ingress:
+  - port:
+      number: 5353
+      protocol: UDP
+      name: udp-dns
+    defaultEndpoint: 127.0.0.1:53
+  - port:
+      number: 5354
+      protocol: UDP
+      name: udp-dns-uds
+    defaultEndpoint: unix:///var/run/dns.sock
+  - port:
+      number: 8080
+      protocol: HTTP
+      name: http
+    defaultEndpoint: 127.0.0.1:8080
+`
+
+func TestUDPClusters(t *testing.T) {
+	s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{ConfigString: syslogServiceEntry + udpSidecar})
+	clusters := xdstest.ExtractClusters(s.Clusters(s.SetupProxy(udpProxy())))
+	for _, c := range []string{
+		"inbound|5353||",
+		"outbound|514||syslog.example.com",
+		"outbound|514|v1|syslog.example.com",
+		"outbound|514|v2|syslog.example.com",
+		"outbound|8080||syslog.example.com",
+	} {
+		if _, f := clusters[c]; !f {
+			t.Errorf("missing cluster %v, got %v", c, xdstest.MapKeys(clusters))
+		}
+	}
+}
+
+func TestInboundUDP(t *testing.T) {
+	runSimulationTest(t, nil, xds.FakeOptions{}, simulationTest{
+		config: udpSidecar,
+		calls: []simulation.Expect{
+			{
+				Name: "udp",
+				Call: simulation.Call{Address: "1.1.1.1", Port: 5353, Protocol: simulation.UDP},
+				Result: simulation.Result{
+					ListenerMatched: "1.1.1.1_5353_udp",
+					ClusterMatched:  "inbound|5353||",
+				},
+			},
+			{
+				Name:   "unix domain socket endpoint",
+				Call:   simulation.Call{Address: "1.1.1.1", Port: 5354, Protocol: simulation.UDP},
+				Result: simulation.Result{Error: simulation.ErrNoListener},
+			},
+			{
+				Name:   "tcp port",
+				Call:   simulation.Call{Address: "1.1.1.1", Port: 8080, Protocol: simulation.UDP},
+				Result: simulation.Result{Error: simulation.ErrNoListener},
+			},
+		},
+	})
+}
+
+func TestGatewayUDP(t *testing.T) {
+	runGatewayTest(t, simulationTest{
+		name: "udp server",
+		config: createGateway("gateway", "", `
+port:
+  name: udp
+  number: 5353
+  protocol: UDP
+hosts:
+- "*"
+`) + syslogServiceEntry + `
+apiVersion: networking.istio.io/v1alpha3
+kind: VirtualService
+metadata:
+  name: syslog
+spec:
+  hosts:
+  - "*"
+  gateways:
+  - gateway
+  tcp:
+  - match:
+    - port: 5353
+    route:
+    - destination:
+        host: syslog.example.com
+        subset: v1
+        port:
+          number: 514
+`,
+		calls: []simulation.Expect{
+			{
+				Name: "udp",
+				Call: simulation.Call{Port: 5353, Protocol: simulation.UDP, CallMode: simulation.CallModeGateway},
+				Result: simulation.Result{
+					ListenerMatched: "0.0.0.0_5353_udp",
+					ClusterMatched:  "outbound|514|v1|syslog.example.com",
+				},
+			},
+			{
+				Name:   "tcp",
+				Call:   simulation.Call{Port: 5353, Protocol: simulation.TCP, CallMode: simulation.CallModeGateway},
+				Result: simulation.Result{Error: simulation.ErrNoListener},
+			},
+		},
+	})
+}
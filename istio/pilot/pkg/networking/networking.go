@@ -64,7 +64,7 @@ func ModelProtocolToListenerProtocol(p protocol.Instance,
 	case protocol.HTTP, protocol.HTTP2, protocol.GRPC, protocol.GRPCWeb:
 		return ListenerProtocolHTTP
 	case protocol.TCP, protocol.HTTPS, protocol.TLS,
-		protocol.Mongo, protocol.Redis, protocol.MySQL:
+		protocol.Mongo, protocol.Redis, protocol.MySQL, protocol.Kafka, protocol.Postgres:
 		return ListenerProtocolTCP
 	case protocol.Thrift:
 		if features.EnableThriftFilter {
@@ -74,6 +74,12 @@ const (
 	// SniClusterFilter is the name of the sni_cluster envoy filter
 	SniClusterFilter = "envoy.filters.network.sni_cluster"
 
+	// KafkaBrokerFilter is the name of the kafka_broker envoy filter
+	KafkaBrokerFilter = "envoy.filters.network.kafka_broker"
+
+	// PostgresProxyFilter is the name of the postgres_proxy envoy filter
+	PostgresProxyFilter = "envoy.filters.network.postgres_proxy"
+
 	// IstioMetadataKey is the key under which metadata is added to a route or cluster
 	// regarding the virtual service or destination rule used for each
 	IstioMetadataKey = "istio"
@@ -19,6 +19,7 @@ import (
 	"sync"
 	"time"
 
+	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/secrets"
 	"istio.io/istio/pkg/kube"
 	"istio.io/istio/pkg/kube/secretcontroller"
@@ -48,8 +49,10 @@ func NewMulticluster(client kube.Client, localCluster, secretNamespace string, s
 		func(c kube.Client, k string) error { m.addMemberCluster(c, k); return nil },
 		func(c kube.Client, k string) error { m.updateMemberCluster(c, k); return nil },
 		func(k string) error { m.deleteMemberCluster(k); return nil },
+		nil,
 		secretNamespace,
 		time.Millisecond*100,
+		features.RemoteClusterTimeout,
 		stop)
 	m.secretController = sc
 	return m
@@ -0,0 +1,321 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package evaluator evaluates authorization policies against a request description without a
+// running proxy. The policies are translated into the same Envoy RBAC config pushed to the proxy,
+// which is then matched against the request, so the result follows the proxy's enforcement.
+package evaluator
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	rbacpb "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+
+	authzpb "istio.io/api/security/v1beta1"
+	"istio.io/istio/pilot/pkg/model"
+	authzmodel "istio.io/istio/pilot/pkg/security/authz/model"
+	"istio.io/istio/pilot/pkg/security/trustdomain"
+)
+
+// Decision is the result of evaluating the authorization policies.
+type Decision string
+
+const (
+	// Allow means the request is allowed.
+	Allow Decision = "ALLOW"
+	// Deny means the request is denied.
+	Deny Decision = "DENY"
+	// Custom means the request is delegated to an external authorizer.
+	Custom Decision = "CUSTOM"
+)
+
+// DefaultTrustDomain is used to build the source principal when only the source namespace is known.
+const DefaultTrustDomain = "cluster.local"
+
+// Request describes the request to evaluate.
+type Request struct {
+	// SourcePrincipal is the peer identity, e.g. "cluster.local/ns/default/sa/sleep".
+	SourcePrincipal string
+	// SourceNamespace is used to build the source principal if SourcePrincipal is not set.
+	SourceNamespace string
+	// SourceIP is the IP of the downstream connection.
+	SourceIP string
+	// RemoteIP is the original client IP, defaults to SourceIP.
+	RemoteIP string
+	// DestinationIP is the IP of the workload.
+	DestinationIP string
+	// DestinationPort is the port of the workload.
+	DestinationPort uint32
+	// SNI is the server name of the TLS connection.
+	SNI string
+
+	// TCP evaluates the request as a plain TCP connection, the HTTP attributes below are ignored.
+	TCP bool
+
+	Host    string
+	Method  string
+	Path    string
+	Headers map[string]string
+
+	// RequestPrincipal is the JWT principal in the format "<iss>/<sub>", it defaults to the iss
+	// and sub claims in Claims.
+	RequestPrincipal string
+	// Claims of the JWT, values are either strings, lists of strings or nested claims.
+	Claims    map[string]interface{}
+	Audiences []string
+	Presenter string
+}
+
+// Result is the result of evaluating the authorization policies for a request.
+type Result struct {
+	Decision Decision
+	// Policy is the "<namespace>/<name>" of the policy that made the decision, empty if no policy matched.
+	Policy string
+	// Rule is the index of the matched rule in the policy, -1 if no rule matched.
+	Rule int
+	// Provider is the extension provider the request is delegated to for a CUSTOM decision.
+	Provider string
+	// Reason explains the decision.
+	Reason string
+	// Next is the decision of the DENY and ALLOW policies, which is enforced if the CUSTOM
+	// provider allows the request.
+	Next *Result
+}
+
+func (r *Result) String() string {
+	s := fmt.Sprintf("%s: %s", r.Decision, r.Reason)
+	if r.Next != nil {
+		s += "\n" + r.Next.String()
+	}
+	return s
+}
+
+// Evaluator evaluates authorization policies applied to a workload.
+type Evaluator struct {
+	policies          model.AuthorizationPoliciesResult
+	trustDomainBundle trustdomain.Bundle
+}
+
+// New returns an evaluator for the authorization policies applied to a workload, as returned by
+// model.AuthorizationPolicies.ListAuthorizationPolicies.
+func New(policies model.AuthorizationPoliciesResult, trustDomainBundle trustdomain.Bundle) *Evaluator {
+	return &Evaluator{policies: policies, trustDomainBundle: trustDomainBundle}
+}
+
+// Evaluate evaluates the request in the order enforced by the proxy: CUSTOM policies first, then
+// DENY policies and last ALLOW policies. AUDIT policies don't affect the decision and are ignored.
+func (e *Evaluator) Evaluate(req *Request) (*Result, error) {
+	in, err := newInput(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy, rule := e.match(e.policies.Custom, rbacpb.RBAC_DENY, in); policy != nil {
+		provider := policy.Spec.GetProvider().GetName()
+		return &Result{
+			Decision: Custom,
+			Policy:   policyName(policy),
+			Rule:     rule,
+			Provider: provider,
+			Reason: fmt.Sprintf("delegated to extension provider %q by policy %s rule %d",
+				provider, policyName(policy), rule),
+			Next: e.evaluateDenyAllow(in),
+		}, nil
+	}
+	return e.evaluateDenyAllow(in), nil
+}
+
+func (e *Evaluator) evaluateDenyAllow(in *input) *Result {
+	if policy, rule := e.match(e.policies.Deny, rbacpb.RBAC_DENY, in); policy != nil {
+		return &Result{
+			Decision: Deny,
+			Policy:   policyName(policy),
+			Rule:     rule,
+			Reason:   fmt.Sprintf("denied by policy %s rule %d", policyName(policy), rule),
+		}
+	}
+	if len(e.policies.Allow) == 0 {
+		return &Result{
+			Decision: Allow,
+			Rule:     -1,
+			Reason:   "allowed as no ALLOW policy is applied to the workload",
+		}
+	}
+	if policy, rule := e.match(e.policies.Allow, rbacpb.RBAC_ALLOW, in); policy != nil {
+		return &Result{
+			Decision: Allow,
+			Policy:   policyName(policy),
+			Rule:     rule,
+			Reason:   fmt.Sprintf("allowed by policy %s rule %d", policyName(policy), rule),
+		}
+	}
+	return &Result{
+		Decision: Deny,
+		Rule:     -1,
+		Reason:   fmt.Sprintf("denied as no rule in the %d ALLOW policies matched", len(e.policies.Allow)),
+	}
+}
+
+// match returns the first policy and rule matching the request, following the same rule
+// translation as the RBAC filter builder. CUSTOM policies are generated with the DENY action.
+func (e *Evaluator) match(policies []model.AuthorizationPolicy, action rbacpb.RBAC_Action,
+	in *input) (*model.AuthorizationPolicy, int) {
+	for i := range policies {
+		policy := &policies[i]
+		for j, rule := range policy.Spec.GetRules() {
+			if rule == nil {
+				continue
+			}
+			m, err := authzmodel.New(rule)
+			if err != nil {
+				continue
+			}
+			m.MigrateTrustDomain(e.trustDomainBundle)
+			generated, err := m.Generate(in.tcp, action)
+			if err != nil || generated == nil {
+				continue
+			}
+			if in.matchPolicy(generated) {
+				return policy, j
+			}
+		}
+	}
+	return nil, -1
+}
+
+func policyName(policy *model.AuthorizationPolicy) string {
+	return policy.Namespace + "/" + policy.Name
+}
+
+// SortPolicies splits the given policies by action like model.AuthorizationPolicies.ListAuthorizationPolicies,
+// for callers that already selected the policies applied to the workload.
+func SortPolicies(policies []model.AuthorizationPolicy) model.AuthorizationPoliciesResult {
+	ret := model.AuthorizationPoliciesResult{}
+	for _, p := range policies {
+		switch p.Spec.GetAction() {
+		case authzpb.AuthorizationPolicy_ALLOW:
+			ret.Allow = append(ret.Allow, p)
+		case authzpb.AuthorizationPolicy_DENY:
+			ret.Deny = append(ret.Deny, p)
+		case authzpb.AuthorizationPolicy_AUDIT:
+			ret.Audit = append(ret.Audit, p)
+		case authzpb.AuthorizationPolicy_CUSTOM:
+			ret.Custom = append(ret.Custom, p)
+		}
+	}
+	return ret
+}
+
+// input is the request normalized to the attributes seen by the RBAC filter.
+type input struct {
+	tcp             bool
+	sourcePrincipal string
+	sourceIP        net.IP
+	remoteIP        net.IP
+	destinationIP   net.IP
+	destinationPort uint32
+	sni             string
+	headers         map[string]string
+	path            string
+	// metadata is the dynamic metadata of the Istio authn filter.
+	metadata map[string]interface{}
+}
+
+func newInput(req *Request) (*input, error) {
+	in := &input{
+		tcp:             req.TCP,
+		sourcePrincipal: req.SourcePrincipal,
+		destinationPort: req.DestinationPort,
+		sni:             req.SNI,
+		headers:         map[string]string{},
+		metadata:        map[string]interface{}{},
+	}
+	if in.sourcePrincipal == "" && req.SourceNamespace != "" {
+		in.sourcePrincipal = fmt.Sprintf("%s/ns/%s/sa/default", DefaultTrustDomain, req.SourceNamespace)
+	}
+	var err error
+	if in.sourceIP, err = parseIP("source IP", req.SourceIP); err != nil {
+		return nil, err
+	}
+	in.remoteIP = in.sourceIP
+	if req.RemoteIP != "" {
+		if in.remoteIP, err = parseIP("remote IP", req.RemoteIP); err != nil {
+			return nil, err
+		}
+	}
+	if in.destinationIP, err = parseIP("destination IP", req.DestinationIP); err != nil {
+		return nil, err
+	}
+	if in.sourcePrincipal != "" {
+		in.metadata[attrSrcPrincipal] = in.sourcePrincipal
+	}
+	if req.TCP {
+		return in, nil
+	}
+
+	for k, v := range req.Headers {
+		in.headers[strings.ToLower(k)] = v
+	}
+	in.path = req.Path
+	if in.path == "" {
+		in.path = "/"
+	}
+	in.headers[":path"] = in.path
+	if i := strings.IndexAny(in.path, "?#"); i >= 0 {
+		in.path = in.path[:i]
+	}
+	method := req.Method
+	if method == "" {
+		method = "GET"
+	}
+	in.headers[":method"] = method
+	if req.Host != "" {
+		in.headers[":authority"] = req.Host
+	}
+
+	requestPrincipal := req.RequestPrincipal
+	if requestPrincipal == "" {
+		iss, _ := req.Claims["iss"].(string)
+		sub, _ := req.Claims["sub"].(string)
+		if iss != "" && sub != "" {
+			requestPrincipal = iss + "/" + sub
+		}
+	}
+	if requestPrincipal != "" {
+		in.metadata[attrRequestPrincipal] = requestPrincipal
+	}
+	if len(req.Audiences) != 0 {
+		in.metadata[attrRequestAudiences] = req.Audiences
+	}
+	if req.Presenter != "" {
+		in.metadata[attrRequestPresenter] = req.Presenter
+	}
+	if len(req.Claims) != 0 {
+		in.metadata[attrRequestClaims] = req.Claims
+	}
+	return in, nil
+}
+
+func parseIP(name, ip string) (net.IP, error) {
+	if ip == "" {
+		return nil, nil
+	}
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid %s %q", name, ip)
+	}
+	return parsed, nil
+}
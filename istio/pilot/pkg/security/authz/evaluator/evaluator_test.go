@@ -0,0 +1,216 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluator
+
+import (
+	"testing"
+
+	authzpb "istio.io/api/security/v1beta1"
+	typepb "istio.io/api/type/v1beta1"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/security/trustdomain"
+	"istio.io/istio/pkg/config/labels"
+)
+
+func policy(name string, action authzpb.AuthorizationPolicy_Action, rules ...*authzpb.Rule) model.AuthorizationPolicy {
+	return model.AuthorizationPolicy{
+		Name:      name,
+		Namespace: "foo",
+		Spec: &authzpb.AuthorizationPolicy{
+			Selector: &typepb.WorkloadSelector{MatchLabels: map[string]string{"app": "httpbin"}},
+			Action:   action,
+			Rules:    rules,
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	denyAdmin := policy("deny-admin", authzpb.AuthorizationPolicy_DENY, &authzpb.Rule{
+		To: []*authzpb.Rule_To{{Operation: &authzpb.Operation{Paths: []string{"/admin*"}}}},
+	})
+	allowSleep := policy("allow-sleep", authzpb.AuthorizationPolicy_ALLOW,
+		&authzpb.Rule{
+			From: []*authzpb.Rule_From{{Source: &authzpb.Source{Namespaces: []string{"bar"}}}},
+			To:   []*authzpb.Rule_To{{Operation: &authzpb.Operation{Methods: []string{"POST"}}}},
+		},
+		&authzpb.Rule{
+			From: []*authzpb.Rule_From{{Source: &authzpb.Source{Principals: []string{"cluster.local/ns/foo/sa/sleep"}}}},
+			To:   []*authzpb.Rule_To{{Operation: &authzpb.Operation{Methods: []string{"GET"}, Ports: []string{"8000"}}}},
+		},
+	)
+	allowJWT := policy("allow-jwt", authzpb.AuthorizationPolicy_ALLOW, &authzpb.Rule{
+		From: []*authzpb.Rule_From{{Source: &authzpb.Source{RequestPrincipals: []string{"https://issuer.example.com/*"}}}},
+		When: []*authzpb.Condition{
+			{Key: "request.auth.claims[groups]", Values: []string{"admin"}},
+			{Key: "request.auth.claims[nested][role]", Values: []string{"dev*"}},
+			{Key: "request.headers[x-tenant]", NotValues: []string{"blocked"}},
+			{Key: "source.ip", Values: []string{"10.0.0.0/16"}},
+		},
+	})
+	allowTCP := policy("allow-tcp", authzpb.AuthorizationPolicy_ALLOW, &authzpb.Rule{
+		From: []*authzpb.Rule_From{{Source: &authzpb.Source{Principals: []string{"*/sa/sleep"}}}},
+		To:   []*authzpb.Rule_To{{Operation: &authzpb.Operation{Ports: []string{"9000"}}}},
+	}, &authzpb.Rule{
+		To: []*authzpb.Rule_To{{Operation: &authzpb.Operation{Methods: []string{"GET"}}}},
+	})
+	denyAll := policy("deny-all", authzpb.AuthorizationPolicy_ALLOW)
+	custom := policy("ext-authz", authzpb.AuthorizationPolicy_CUSTOM, &authzpb.Rule{
+		To: []*authzpb.Rule_To{{Operation: &authzpb.Operation{Hosts: []string{"*.example.com"}}}},
+	})
+	custom.Spec.ActionDetail = &authzpb.AuthorizationPolicy_Provider{
+		Provider: &authzpb.AuthorizationPolicy_ExtensionProvider{Name: "opa"},
+	}
+
+	testCases := []struct {
+		name       string
+		policies   []model.AuthorizationPolicy
+		req        Request
+		wantResult Result
+		wantNext   *Result
+	}{
+		{
+			name:       "no policy",
+			req:        Request{Path: "/admin"},
+			wantResult: Result{Decision: Allow, Rule: -1},
+		},
+		{
+			name:       "deny before allow",
+			policies:   []model.AuthorizationPolicy{allowSleep, denyAdmin},
+			req:        Request{SourcePrincipal: "cluster.local/ns/foo/sa/sleep", Path: "/admin/users", DestinationPort: 8000},
+			wantResult: Result{Decision: Deny, Policy: "foo/deny-admin", Rule: 0},
+		},
+		{
+			name:       "allow by principal",
+			policies:   []model.AuthorizationPolicy{allowSleep, denyAdmin},
+			req:        Request{SourcePrincipal: "cluster.local/ns/foo/sa/sleep", Path: "/ip", DestinationPort: 8000},
+			wantResult: Result{Decision: Allow, Policy: "foo/allow-sleep", Rule: 1},
+		},
+		{
+			name:       "allow by namespace",
+			policies:   []model.AuthorizationPolicy{allowSleep, denyAdmin},
+			req:        Request{SourceNamespace: "bar", Method: "POST"},
+			wantResult: Result{Decision: Allow, Policy: "foo/allow-sleep", Rule: 0},
+		},
+		{
+			name:       "no allow rule matched",
+			policies:   []model.AuthorizationPolicy{allowSleep},
+			req:        Request{SourcePrincipal: "cluster.local/ns/foo/sa/sleep", DestinationPort: 9000},
+			wantResult: Result{Decision: Deny, Rule: -1},
+		},
+		{
+			name:     "allow by JWT claims",
+			policies: []model.AuthorizationPolicy{allowJWT},
+			req: Request{
+				SourceIP: "10.0.1.2",
+				Claims: map[string]interface{}{
+					"iss":    "https://issuer.example.com",
+					"sub":    "alice",
+					"groups": []string{"dev", "admin"},
+					"nested": map[string]interface{}{"role": "developer"},
+				},
+			},
+			wantResult: Result{Decision: Allow, Policy: "foo/allow-jwt", Rule: 0},
+		},
+		{
+			name:     "denied by header not value",
+			policies: []model.AuthorizationPolicy{allowJWT},
+			req: Request{
+				SourceIP: "10.0.1.2",
+				Headers:  map[string]string{"X-Tenant": "blocked"},
+				Claims: map[string]interface{}{
+					"iss":    "https://issuer.example.com",
+					"sub":    "alice",
+					"groups": "admin",
+					"nested": map[string]interface{}{"role": "developer"},
+				},
+			},
+			wantResult: Result{Decision: Deny, Rule: -1},
+		},
+		{
+			name:       "denied by source IP",
+			policies:   []model.AuthorizationPolicy{allowJWT},
+			req:        Request{SourceIP: "10.1.1.2", RequestPrincipal: "https://issuer.example.com/alice"},
+			wantResult: Result{Decision: Deny, Rule: -1},
+		},
+		{
+			name:       "TCP ignores HTTP only rule in ALLOW policy",
+			policies:   []model.AuthorizationPolicy{allowTCP},
+			req:        Request{TCP: true, SourcePrincipal: "cluster.local/ns/foo/sa/sleep", DestinationPort: 9000},
+			wantResult: Result{Decision: Allow, Policy: "foo/allow-tcp", Rule: 0},
+		},
+		{
+			name:       "TCP denied",
+			policies:   []model.AuthorizationPolicy{allowTCP},
+			req:        Request{TCP: true, SourcePrincipal: "cluster.local/ns/foo/sa/sleep", DestinationPort: 8000},
+			wantResult: Result{Decision: Deny, Rule: -1},
+		},
+		{
+			name:       "ALLOW policy without rules",
+			policies:   []model.AuthorizationPolicy{denyAll},
+			req:        Request{},
+			wantResult: Result{Decision: Deny, Rule: -1},
+		},
+		{
+			name:       "CUSTOM",
+			policies:   []model.AuthorizationPolicy{custom, denyAdmin},
+			req:        Request{Host: "www.example.com", Path: "/admin"},
+			wantResult: Result{Decision: Custom, Policy: "foo/ext-authz", Rule: 0, Provider: "opa"},
+			wantNext:   &Result{Decision: Deny, Policy: "foo/deny-admin", Rule: 0},
+		},
+		{
+			name:       "CUSTOM not matched",
+			policies:   []model.AuthorizationPolicy{custom},
+			req:        Request{Host: "www.example.org"},
+			wantResult: Result{Decision: Allow, Rule: -1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			authzPolicies := &model.AuthorizationPolicies{
+				NamespaceToPolicies: map[string][]model.AuthorizationPolicy{"foo": tc.policies},
+				RootNamespace:       "istio-system",
+			}
+			policies := authzPolicies.ListAuthorizationPolicies("foo", labels.Collection{{"app": "httpbin"}})
+			got, err := New(policies, trustdomain.NewBundle("cluster.local", nil)).Evaluate(&tc.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkResult(t, got, &tc.wantResult)
+			if tc.wantNext != nil {
+				if got.Next == nil {
+					t.Fatalf("got no next result, want %v", tc.wantNext)
+				}
+				checkResult(t, got.Next, tc.wantNext)
+			}
+		})
+	}
+}
+
+func checkResult(t *testing.T, got, want *Result) {
+	t.Helper()
+	if got.Decision != want.Decision || got.Policy != want.Policy || got.Rule != want.Rule || got.Provider != want.Provider {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+	if got.Reason == "" {
+		t.Errorf("got empty reason")
+	}
+}
+
+func TestEvaluateInvalidRequest(t *testing.T) {
+	if _, err := New(model.AuthorizationPoliciesResult{}, trustdomain.Bundle{}).Evaluate(&Request{SourceIP: "10.0.0"}); err == nil {
+		t.Errorf("expected error for invalid source IP")
+	}
+}
@@ -0,0 +1,264 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package evaluator
+
+import (
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+
+	corepb "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	rbacpb "github.com/envoyproxy/go-control-plane/envoy/config/rbac/v3"
+	routepb "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	matcherpb "github.com/envoyproxy/go-control-plane/envoy/type/matcher/v3"
+
+	sm "istio.io/istio/pilot/pkg/security/model"
+	"istio.io/istio/pkg/spiffe"
+)
+
+// Keys of the Istio authn filter metadata matched by the RBAC filter.
+const (
+	attrSrcPrincipal     = "source.principal"
+	attrRequestPrincipal = "request.auth.principal"
+	attrRequestAudiences = "request.auth.audiences"
+	attrRequestPresenter = "request.auth.presenter"
+	attrRequestClaims    = "request.auth.claims"
+)
+
+// matchPolicy returns true if both any permission and any principal of the policy match.
+func (in *input) matchPolicy(policy *rbacpb.Policy) bool {
+	permissionMatched := false
+	for _, p := range policy.Permissions {
+		if in.matchPermission(p) {
+			permissionMatched = true
+			break
+		}
+	}
+	if !permissionMatched {
+		return false
+	}
+	for _, p := range policy.Principals {
+		if in.matchPrincipal(p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (in *input) matchPermission(p *rbacpb.Permission) bool {
+	switch r := p.GetRule().(type) {
+	case *rbacpb.Permission_Any:
+		return r.Any
+	case *rbacpb.Permission_AndRules:
+		for _, rule := range r.AndRules.GetRules() {
+			if !in.matchPermission(rule) {
+				return false
+			}
+		}
+		return true
+	case *rbacpb.Permission_OrRules:
+		for _, rule := range r.OrRules.GetRules() {
+			if in.matchPermission(rule) {
+				return true
+			}
+		}
+		return false
+	case *rbacpb.Permission_NotRule:
+		return !in.matchPermission(r.NotRule)
+	case *rbacpb.Permission_Header:
+		return in.matchHeader(r.Header)
+	case *rbacpb.Permission_UrlPath:
+		return !in.tcp && matchString(r.UrlPath.GetPath(), in.path)
+	case *rbacpb.Permission_DestinationIp:
+		return matchCidr(r.DestinationIp, in.destinationIP)
+	case *rbacpb.Permission_DestinationPort:
+		return r.DestinationPort == in.destinationPort
+	case *rbacpb.Permission_RequestedServerName:
+		return in.sni != "" && matchString(r.RequestedServerName, in.sni)
+	case *rbacpb.Permission_Metadata:
+		return in.matchMetadata(r.Metadata)
+	default:
+		return false
+	}
+}
+
+func (in *input) matchPrincipal(p *rbacpb.Principal) bool {
+	switch id := p.GetIdentifier().(type) {
+	case *rbacpb.Principal_Any:
+		return id.Any
+	case *rbacpb.Principal_AndIds:
+		for _, i := range id.AndIds.GetIds() {
+			if !in.matchPrincipal(i) {
+				return false
+			}
+		}
+		return true
+	case *rbacpb.Principal_OrIds:
+		for _, i := range id.OrIds.GetIds() {
+			if in.matchPrincipal(i) {
+				return true
+			}
+		}
+		return false
+	case *rbacpb.Principal_NotId:
+		return !in.matchPrincipal(id.NotId)
+	case *rbacpb.Principal_Authenticated_:
+		if in.sourcePrincipal == "" {
+			return false
+		}
+		if id.Authenticated.GetPrincipalName() == nil {
+			return true
+		}
+		return matchString(id.Authenticated.GetPrincipalName(), spiffe.URIPrefix+in.sourcePrincipal)
+	case *rbacpb.Principal_DirectRemoteIp:
+		return matchCidr(id.DirectRemoteIp, in.sourceIP)
+	case *rbacpb.Principal_RemoteIp:
+		return matchCidr(id.RemoteIp, in.remoteIP)
+	case *rbacpb.Principal_SourceIp:
+		return matchCidr(id.SourceIp, in.sourceIP)
+	case *rbacpb.Principal_Header:
+		return in.matchHeader(id.Header)
+	case *rbacpb.Principal_Metadata:
+		return in.matchMetadata(id.Metadata)
+	default:
+		return false
+	}
+}
+
+func (in *input) matchHeader(h *routepb.HeaderMatcher) bool {
+	if in.tcp {
+		return false
+	}
+	value, found := in.headers[strings.ToLower(h.GetName())]
+	var matched bool
+	switch m := h.GetHeaderMatchSpecifier().(type) {
+	case *routepb.HeaderMatcher_PresentMatch:
+		matched = found == m.PresentMatch
+	case *routepb.HeaderMatcher_ExactMatch:
+		matched = found && value == m.ExactMatch
+	case *routepb.HeaderMatcher_PrefixMatch:
+		matched = found && strings.HasPrefix(value, m.PrefixMatch)
+	case *routepb.HeaderMatcher_SuffixMatch:
+		matched = found && strings.HasSuffix(value, m.SuffixMatch)
+	case *routepb.HeaderMatcher_ContainsMatch:
+		matched = found && strings.Contains(value, m.ContainsMatch)
+	case *routepb.HeaderMatcher_SafeRegexMatch:
+		matched = found && matchRegex(m.SafeRegexMatch.GetRegex(), value)
+	default:
+		return false
+	}
+	return matched != h.GetInvertMatch()
+}
+
+func (in *input) matchMetadata(m *matcherpb.MetadataMatcher) bool {
+	if m.GetFilter() != sm.AuthnFilterName {
+		return false
+	}
+	var value interface{} = in.metadata
+	for _, segment := range m.GetPath() {
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			value = nil
+			break
+		}
+		value = fields[segment.GetKey()]
+	}
+	return matchValue(m.GetValue(), value)
+}
+
+func matchValue(m *matcherpb.ValueMatcher, value interface{}) bool {
+	switch p := m.GetMatchPattern().(type) {
+	case *matcherpb.ValueMatcher_StringMatch:
+		s, ok := value.(string)
+		return ok && matchString(p.StringMatch, s)
+	case *matcherpb.ValueMatcher_PresentMatch:
+		return (value != nil) == p.PresentMatch
+	case *matcherpb.ValueMatcher_ListMatch:
+		// String values are treated as a single-element list, the same as the string claims
+		// populated by the Istio authn filter.
+		for _, v := range toList(value) {
+			if matchValue(p.ListMatch.GetOneOf(), v) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func toList(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case string:
+		return []interface{}{v}
+	case []string:
+		ret := make([]interface{}, 0, len(v))
+		for _, s := range v {
+			ret = append(ret, s)
+		}
+		return ret
+	case []interface{}:
+		return v
+	default:
+		return nil
+	}
+}
+
+func matchString(m *matcherpb.StringMatcher, value string) bool {
+	if m.GetIgnoreCase() {
+		value = strings.ToLower(value)
+	}
+	lower := func(s string) string {
+		if m.GetIgnoreCase() {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+	switch p := m.GetMatchPattern().(type) {
+	case *matcherpb.StringMatcher_Exact:
+		return value == lower(p.Exact)
+	case *matcherpb.StringMatcher_Prefix:
+		return strings.HasPrefix(value, lower(p.Prefix))
+	case *matcherpb.StringMatcher_Suffix:
+		return strings.HasSuffix(value, lower(p.Suffix))
+	case *matcherpb.StringMatcher_Contains:
+		return strings.Contains(value, lower(p.Contains))
+	case *matcherpb.StringMatcher_SafeRegex:
+		return matchRegex(p.SafeRegex.GetRegex(), value)
+	default:
+		return false
+	}
+}
+
+// matchRegex matches the whole value like the Envoy RE2 matcher.
+func matchRegex(regex, value string) bool {
+	re, err := regexp.Compile("^(?:" + regex + ")$")
+	if err != nil {
+		return false
+	}
+	return re.MatchString(value)
+}
+
+func matchCidr(cidr *corepb.CidrRange, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	_, network, err := net.ParseCIDR(cidr.GetAddressPrefix() + "/" + strconv.FormatUint(uint64(cidr.GetPrefixLen().GetValue()), 10))
+	if err != nil {
+		return false
+	}
+	return network.Contains(ip)
+}
@@ -0,0 +1,138 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package issuance applies the CertificateIssuancePolicy resources to the certificates issued by the Istio CA.
+package issuance
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/config"
+	securityv1beta1 "istio.io/istio/pkg/config/apis/security/v1beta1"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/spiffe"
+	"istio.io/pkg/log"
+)
+
+var issuanceLog = log.RegisterScope("issuance", "Certificate issuance policy", 0)
+
+// Policy evaluates the CertificateIssuancePolicy resources of a config store. It implements the IssuancePolicy of
+// the CA server.
+type Policy struct {
+	store         model.ConfigStore
+	rootNamespace func() string
+}
+
+// NewPolicy returns a Policy for the resources of the store. The root namespace is resolved on each evaluation,
+// so that it follows the changes of the mesh config.
+func NewPolicy(store model.ConfigStore, rootNamespace func() string) *Policy {
+	return &Policy{store: store, rootNamespace: rootNamespace}
+}
+
+// Evaluate denies the certificate if any of the policies that apply to one of the identities denies it, and
+// otherwise clamps the requested TTL to the lowest max TTL of these policies. Identities that are not SPIFFE
+// identities of a service account are only subject to the policies of the root namespace that select all service
+// accounts.
+func (p *Policy) Evaluate(identities []string, requestedTTL time.Duration) (time.Duration, error) {
+	rootNamespace := p.rootNamespace()
+	rootPolicies := p.list(rootNamespace)
+	ttl := requestedTTL
+	for _, identity := range identities {
+		policies := rootPolicies
+		id, err := spiffe.ParseIdentity(identity)
+		if err == nil && id.Namespace != rootNamespace {
+			policies = append(append([]config.Config{}, rootPolicies...), p.list(id.Namespace)...)
+		}
+		for _, cfg := range policies {
+			spec := cfg.Spec.(*securityv1beta1.CertificateIssuancePolicySpec)
+			if !selects(cfg.Namespace, spec, id, err == nil) {
+				continue
+			}
+			if spec.Action == securityv1beta1.ActionDeny {
+				return 0, fmt.Errorf("identity %s is denied by %s/%s", identity, cfg.Namespace, cfg.Name)
+			}
+			if spec.MaxCertTTL != nil && spec.MaxCertTTL.Duration > 0 && (ttl == 0 || ttl > spec.MaxCertTTL.Duration) {
+				issuanceLog.Debugf("TTL of %s clamped to %v by %s/%s", identity, spec.MaxCertTTL.Duration, cfg.Namespace, cfg.Name)
+				ttl = spec.MaxCertTTL.Duration
+			}
+		}
+	}
+	return ttl, nil
+}
+
+// selects returns true if the policy of the namespace applies to the identity. The identity is known to be in the
+// namespace, or the namespace is the root namespace.
+func selects(namespace string, spec *securityv1beta1.CertificateIssuancePolicySpec, id spiffe.Identity, isServiceAccount bool) bool {
+	if len(spec.ServiceAccounts) == 0 {
+		return true
+	}
+	if !isServiceAccount || id.Namespace != namespace {
+		return false
+	}
+	for _, sa := range spec.ServiceAccounts {
+		if sa == id.ServiceAccount {
+			return true
+		}
+	}
+	return false
+}
+
+// DeniedSerialNumbers returns the serial numbers denied by the policies of the root namespace, normalized by
+// NormalizeSerialNumber and sorted.
+func (p *Policy) DeniedSerialNumbers() []string {
+	seen := map[string]struct{}{}
+	out := []string{}
+	for _, cfg := range p.list(p.rootNamespace()) {
+		for _, serial := range cfg.Spec.(*securityv1beta1.CertificateIssuancePolicySpec).DeniedSerialNumbers {
+			normalized, ok := NormalizeSerialNumber(serial)
+			if !ok {
+				issuanceLog.Warnf("ignoring invalid serial number %q of %s/%s", serial, cfg.Namespace, cfg.Name)
+				continue
+			}
+			if _, f := seen[normalized]; !f {
+				seen[normalized] = struct{}{}
+				out = append(out, normalized)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (p *Policy) list(namespace string) []config.Config {
+	if namespace == "" {
+		return nil
+	}
+	configs, err := p.store.List(gvk.CertificateIssuancePolicy, namespace)
+	if err != nil {
+		issuanceLog.Errorf("failed to list the certificate issuance policies of %s: %v", namespace, err)
+		return nil
+	}
+	return configs
+}
+
+// NormalizeSerialNumber returns the lower case hex encoding, without leading zeros, of a hex encoded serial number
+// that may be separated by colons. This is the encoding of big.Int.Text(16).
+func NormalizeSerialNumber(serial string) (string, bool) {
+	n, ok := new(big.Int).SetString(strings.ReplaceAll(serial, ":", ""), 16)
+	if !ok {
+		return "", false
+	}
+	return n.Text(16), true
+}
@@ -0,0 +1,153 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package issuance
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"istio.io/istio/pilot/pkg/config/memory"
+	"istio.io/istio/pkg/config"
+	securityv1beta1 "istio.io/istio/pkg/config/apis/security/v1beta1"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/config/schema/gvk"
+)
+
+func newPolicy(t *testing.T, policies map[string]*securityv1beta1.CertificateIssuancePolicySpec) *Policy {
+	t.Helper()
+	// invalid resources may still reach the store, for example before the webhook is configured
+	store := memory.MakeSkipValidation(collections.Pilot, true)
+	for key, spec := range policies {
+		parts := strings.Split(key, "/")
+		if _, err := store.Create(config.Config{
+			Meta: config.Meta{
+				GroupVersionKind: gvk.CertificateIssuancePolicy,
+				Namespace:        parts[0],
+				Name:             parts[1],
+			},
+			Spec: spec,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return NewPolicy(store, func() string { return "istio-system" })
+}
+
+func TestEvaluate(t *testing.T) {
+	ttl := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	policy := newPolicy(t, map[string]*securityv1beta1.CertificateIssuancePolicySpec{
+		"istio-system/mesh-wide": {MaxCertTTL: ttl(12 * time.Hour)},
+		// only applies to the service account of the root namespace
+		"istio-system/root-sa": {ServiceAccounts: []string{"compromised"}, Action: securityv1beta1.ActionDeny},
+		"bookinfo/compromised": {ServiceAccounts: []string{"compromised"}, Action: securityv1beta1.ActionDeny},
+		"bookinfo/short":       {ServiceAccounts: []string{"short"}, MaxCertTTL: ttl(time.Hour)},
+		"denied/all":           {Action: securityv1beta1.ActionDeny},
+	})
+
+	testCases := []struct {
+		name       string
+		identities []string
+		requested  time.Duration
+		expected   time.Duration
+		denied     bool
+	}{
+		{
+			name:       "requested TTL below the mesh-wide max",
+			identities: []string{"spiffe://cluster.local/ns/bookinfo/sa/default"},
+			requested:  time.Hour,
+			expected:   time.Hour,
+		},
+		{
+			name:       "clamped by the mesh-wide max",
+			identities: []string{"spiffe://cluster.local/ns/bookinfo/sa/default"},
+			requested:  24 * time.Hour,
+			expected:   12 * time.Hour,
+		},
+		{
+			name:       "default TTL clamped",
+			identities: []string{"spiffe://cluster.local/ns/bookinfo/sa/default"},
+			expected:   12 * time.Hour,
+		},
+		{
+			name:       "clamped by the service account policy",
+			identities: []string{"spiffe://cluster.local/ns/bookinfo/sa/short"},
+			requested:  24 * time.Hour,
+			expected:   time.Hour,
+		},
+		{
+			name:       "service account denied",
+			identities: []string{"spiffe://cluster.local/ns/bookinfo/sa/compromised"},
+			requested:  time.Hour,
+			denied:     true,
+		},
+		{
+			name:       "same service account name in another namespace",
+			identities: []string{"spiffe://cluster.local/ns/other/sa/compromised"},
+			requested:  time.Hour,
+			expected:   time.Hour,
+		},
+		{
+			name:       "namespace denied",
+			identities: []string{"spiffe://cluster.local/ns/denied/sa/default"},
+			requested:  time.Hour,
+			denied:     true,
+		},
+		{
+			name:       "one of the identities denied",
+			identities: []string{"spiffe://cluster.local/ns/bookinfo/sa/default", "spiffe://cluster.local/ns/denied/sa/default"},
+			requested:  time.Hour,
+			denied:     true,
+		},
+		{
+			name:       "identity that is not a service account",
+			identities: []string{"vm.example.com"},
+			requested:  24 * time.Hour,
+			expected:   12 * time.Hour,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := policy.Evaluate(tc.identities, tc.requested)
+			if tc.denied {
+				if err == nil {
+					t.Fatalf("expected the issuance to be denied")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.expected {
+				t.Fatalf("expected TTL %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestDeniedSerialNumbers(t *testing.T) {
+	policy := newPolicy(t, map[string]*securityv1beta1.CertificateIssuancePolicySpec{
+		"istio-system/a": {DeniedSerialNumbers: []string{"0A:BC:DE", "1f"}},
+		"istio-system/b": {DeniedSerialNumbers: []string{"abcde", "not-hex"}},
+		// only honored in the root namespace
+		"bookinfo/c": {DeniedSerialNumbers: []string{"ff"}},
+	})
+	if got, expected := policy.DeniedSerialNumbers(), []string{"1f", "abcde"}; !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
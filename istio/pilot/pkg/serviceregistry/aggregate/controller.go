@@ -108,9 +108,9 @@ func (c *Controller) getRegistryIndex(clusterID string, provider serviceregistry
 
 // Services lists services from all platforms
 func (c *Controller) Services() ([]*model.Service, error) {
-	// smap is a map of hostname (string) to service, used to identify services that
-	// are installed in multiple clusters.
-	smap := make(map[host.Name]*model.Service)
+	// smap is a map of hostname (string) to the index of the service in services, used to identify services
+	// that are installed in multiple clusters.
+	smap := make(map[host.Name]int)
 
 	services := make([]*model.Service, 0)
 	var errs error
@@ -126,18 +126,23 @@ func (c *Controller) Services() ([]*model.Service, error) {
 			services = append(services, svcs...)
 		} else {
 			for _, s := range svcs {
-				sp, ok := smap[s.Hostname]
+				i, ok := smap[s.Hostname]
 				if !ok {
 					// First time we see a service. The result will have a single service per hostname
 					// The first cluster will be listed first, so the services in the primary cluster
 					// will be used for default settings. If a service appears in multiple clusters,
 					// the order is less clear.
-					sp = s
-					smap[s.Hostname] = sp
-					services = append(services, sp)
+					smap[s.Hostname] = len(services)
+					services = append(services, s)
 				} else {
+					if s.Attributes.ClusterLocal && !services[i].Attributes.ClusterLocal {
+						// The service is cluster-local if it is marked so in any cluster. A copy is marked, as the
+						// service is owned by the registry of the first cluster.
+						services[i] = services[i].DeepCopy()
+						services[i].Attributes.ClusterLocal = true
+					}
 					// If it is seen second time, that means it is from a different cluster, update cluster VIPs.
-					mergeService(sp, s, r.Cluster())
+					mergeService(services[i], s, r.Cluster())
 				}
 			}
 		}
@@ -166,6 +171,8 @@ func (c *Controller) GetService(hostname host.Name) (*model.Service, error) {
 		} else {
 			// If we are seeing the service for the second time, it means it is available in multiple clusters.
 			mergeService(out, service, r.Cluster())
+			// The service is cluster-local if it is marked so in any cluster.
+			out.Attributes.ClusterLocal = out.Attributes.ClusterLocal || service.Attributes.ClusterLocal
 		}
 	}
 	return out, errs
@@ -496,3 +496,47 @@ func TestSkipSearchingRegistryForProxy(t *testing.T) {
 		}
 	}
 }
+
+func TestServicesClusterLocal(t *testing.T) {
+	hello1 := mock.MakeService("hello.default.svc.cluster.local", "10.1.1.0", []string{}, "cluster-1")
+	hello2 := mock.MakeService("hello.default.svc.cluster.local", "10.1.2.0", []string{}, "cluster-2")
+	hello2.Attributes.ClusterLocal = true
+	ctls := NewController(Options{})
+	for i, svc := range []*model.Service{hello1, hello2} {
+		ctls.AddRegistry(serviceregistry.Simple{
+			ProviderID:       serviceregistry.Kubernetes,
+			ClusterID:        fmt.Sprintf("cluster-%d", i+1),
+			ServiceDiscovery: mock.NewDiscovery(map[host.Name]*model.Service{svc.Hostname: svc}, 2),
+			Controller:       &mock.Controller{},
+		})
+	}
+
+	clusterLocal := func() (bool, bool) {
+		t.Helper()
+		services, err := ctls.Services()
+		if err != nil || len(services) != 1 {
+			t.Fatalf("unexpected services %v: %v", services, err)
+		}
+		svc, err := ctls.GetService(hello1.Hostname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return services[0].Attributes.ClusterLocal, svc.Attributes.ClusterLocal
+	}
+
+	// The service is cluster-local as it is marked so in cluster-2.
+	if fromServices, fromGet := clusterLocal(); !fromServices || !fromGet {
+		t.Fatalf("expected the merged service to be cluster-local, got %v from Services() and %v from GetService()",
+			fromServices, fromGet)
+	}
+	if hello1.Attributes.ClusterLocal {
+		t.Fatalf("expected the service of cluster-1 not to be modified")
+	}
+
+	// Removing the annotation in cluster-2 clears the flag.
+	hello2.Attributes.ClusterLocal = false
+	if fromServices, fromGet := clusterLocal(); fromServices || fromGet {
+		t.Fatalf("expected the merged service not to be cluster-local, got %v from Services() and %v from GetService()",
+			fromServices, fromGet)
+	}
+}
@@ -618,6 +618,16 @@ func (c *Controller) HasSynced() bool {
 	return true
 }
 
+// ObjectCounts returns the number of objects of each kind in the informer caches.
+func (c *Controller) ObjectCounts() map[string]int {
+	return map[string]int{
+		"Services":  len(c.serviceInformer.GetIndexer().ListKeys()),
+		"Endpoints": len(c.endpoints.getInformer().GetIndexer().ListKeys()),
+		"Pods":      len(c.pods.informer.GetIndexer().ListKeys()),
+		"Nodes":     len(c.nodeInformer.GetIndexer().ListKeys()),
+	}
+}
+
 // SyncAll syncs all the objects node->service->pod->endpoint in order
 // TODO: sync same kind of objects in parallel
 // This can cause great performance cost in multi clusters scenario.
@@ -950,7 +960,8 @@ func (c *Controller) hydrateWorkloadInstance(si *model.WorkloadInstance) []*mode
 			}
 
 			for _, port := range service.Ports {
-				if port.Protocol == protocol.UDP {
+				// UDP ports share the instances of a port of another protocol with the same value, if any.
+				if _, f := service.Ports.GetByPort(port.Port); f && port.Protocol == protocol.UDP {
 					continue
 				}
 				// Similar code as UpdateServiceShards in eds.go
@@ -1012,7 +1023,8 @@ func (c *Controller) WorkloadInstanceHandler(si *model.WorkloadInstance, event m
 			// We need one endpoint object for each service port
 			endpoints := make([]*model.IstioEndpoint, 0)
 			for _, port := range service.Ports {
-				if port.Protocol == protocol.UDP {
+				// UDP ports share the instances of a port of another protocol with the same value, if any.
+				if _, f := service.Ports.GetByPort(port.Port); f && port.Protocol == protocol.UDP {
 					continue
 				}
 				// Similar code as UpdateServiceShards in eds.go
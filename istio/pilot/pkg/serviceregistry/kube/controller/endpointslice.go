@@ -30,6 +30,7 @@ import (
 	"istio.io/istio/pilot/pkg/serviceregistry/kube/controller/filter"
 	"istio.io/istio/pkg/config/host"
 	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/protocol"
 	"istio.io/pkg/log"
 )
 
@@ -223,6 +224,10 @@ func (esc *endpointSliceController) InstancesByPort(c *Controller, svc *model.Se
 
 	// Locate all ports in the actual service
 	svcPort, exists := svc.Ports.GetByPort(reqSvcPort)
+	if !exists {
+		// A UDP port has its own endpoints if no port of another protocol has the same value.
+		svcPort, exists = svc.Ports.GetByPortAndProtocol(reqSvcPort, protocol.UDP)
+	}
 	if !exists {
 		return nil
 	}
@@ -36,6 +36,7 @@ import (
 	"istio.io/istio/pkg/kube/secretcontroller"
 	"istio.io/istio/pkg/webhooks"
 	"istio.io/pkg/log"
+	"istio.io/pkg/monitoring"
 )
 
 const (
@@ -50,6 +51,28 @@ var (
 	validationWebhookConfigNameTemplate = "istiod-" + validationWebhookConfigNameTemplateVar
 )
 
+var (
+	clusterIDTag = monitoring.MustCreateLabel("cluster_id")
+	stateTag     = monitoring.MustCreateLabel("state")
+
+	remoteClusterState = monitoring.NewGauge(
+		"pilot_remote_cluster_state",
+		"State of the remote clusters, 1 for the current state of a cluster and 0 for the other states.",
+		monitoring.WithLabels(clusterIDTag, stateTag),
+	)
+
+	remoteClusterLastSync = monitoring.NewGauge(
+		"pilot_remote_cluster_last_sync_timestamp_seconds",
+		"The last time the informers of a remote cluster synced, in seconds since the epoch.",
+		monitoring.WithLabels(clusterIDTag),
+	)
+)
+
+func init() {
+	monitoring.MustRegister(remoteClusterState)
+	monitoring.MustRegister(remoteClusterLastSync)
+}
+
 type kubeController struct {
 	*Controller
 	stopCh             chan struct{}
@@ -71,7 +94,7 @@ type Multicluster struct {
 	serviceEntryStore *serviceentry.ServiceEntryStore
 	XDSUpdater        model.XDSUpdater
 
-	m                     sync.Mutex // protects remoteKubeControllers
+	m                     sync.Mutex // protects remoteKubeControllers and secretController
 	remoteKubeControllers map[string]*kubeController
 	networksWatcher       mesh.NetworksWatcher
 
@@ -296,9 +319,42 @@ func (m *Multicluster) GetRemoteKubeClient(clusterID string) kubernetes.Interfac
 }
 
 func (m *Multicluster) InitSecretController(stop <-chan struct{}) {
-	m.secretController = secretcontroller.StartSecretController(
-		m.client, m.AddMemberCluster, m.UpdateMemberCluster, m.DeleteMemberCluster,
-		m.secretNamespace, m.syncInterval, stop)
+	sc := secretcontroller.StartSecretController(
+		m.client, m.AddMemberCluster, m.UpdateMemberCluster, m.DeleteMemberCluster, recordClusterStatus,
+		m.secretNamespace, m.syncInterval, features.RemoteClusterTimeout, stop)
+	m.m.Lock()
+	m.secretController = sc
+	m.m.Unlock()
+}
+
+// ClusterStatuses returns the status of the remote clusters, with the number of objects in the informer caches of
+// the registries of the clusters.
+func (m *Multicluster) ClusterStatuses() []secretcontroller.ClusterStatus {
+	m.m.Lock()
+	defer m.m.Unlock()
+	if m.secretController == nil {
+		return nil
+	}
+	statuses := m.secretController.ClusterStatuses()
+	for i, status := range statuses {
+		if kc := m.remoteKubeControllers[status.ID]; kc != nil {
+			statuses[i].Objects = kc.ObjectCounts()
+		}
+	}
+	return statuses
+}
+
+func recordClusterStatus(status secretcontroller.ClusterStatus) {
+	for _, state := range secretcontroller.ClusterStates {
+		value := 0.0
+		if state == status.State {
+			value = 1
+		}
+		remoteClusterState.With(clusterIDTag.Value(status.ID), stateTag.Value(string(state))).Record(value)
+	}
+	if status.LastSyncTime != nil {
+		remoteClusterLastSync.With(clusterIDTag.Value(status.ID)).Record(float64(status.LastSyncTime.Unix()))
+	}
 }
 
 func (m *Multicluster) HasSynced() bool {
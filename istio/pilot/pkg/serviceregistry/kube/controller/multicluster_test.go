@@ -111,6 +111,13 @@ func Test_KubeSecretController(t *testing.T) {
 	// Test - Verify that the remote controller has been added.
 	verifyControllers(t, mc, 1, "create remote controller")
 
+	// Test - Verify that the remote cluster is reported as synced, with the objects of its registry.
+	pkgtest.NewEventualOpts(10*time.Millisecond, 5*time.Second).Eventually(t, "remote cluster synced", func() bool {
+		statuses := mc.ClusterStatuses()
+		return len(statuses) == 1 && statuses[0].ID == "testRemoteCluster" &&
+			statuses[0].State == secretcontroller.Synced && statuses[0].Objects != nil
+	})
+
 	// Delete the mulicluster secret.
 	err = deleteMultiClusterSecret(clientset)
 	if err != nil {
@@ -119,4 +126,7 @@ func Test_KubeSecretController(t *testing.T) {
 
 	// Test - Verify that the remote controller has been removed.
 	verifyControllers(t, mc, 0, "delete remote controller")
+	pkgtest.NewEventualOpts(10*time.Millisecond, 5*time.Second).Eventually(t, "remote cluster status removed", func() bool {
+		return len(mc.ClusterStatuses()) == 0
+	})
 }
@@ -41,6 +41,12 @@ const (
 	// that can be used to select a subset of nodes from the pool of k8s nodes
 	// It is used for multi-cluster scenario, and with nodePort type gateway service.
 	NodeSelectorAnnotation = "traffic.istio.io/nodeSelector"
+
+	// TODO: move to API
+	// ClusterLocalAnnotation marks the service as cluster-local when set to "true": clients only reach the endpoints
+	// of the service in their own cluster, and the service is not exposed to other networks by the network gateways.
+	// This annotation is experimental.
+	ClusterLocalAnnotation = "networking.istio.io/clusterLocal"
 )
 
 func convertPort(port coreV1.ServicePort) *model.Port {
@@ -114,6 +120,7 @@ func ConvertService(svc coreV1.Service, domainSuffix string, clusterID string) *
 			UID:             formatUID(svc.Namespace, svc.Name),
 			ExportTo:        exportTo,
 			LabelSelectors:  labelSelectors,
+			ClusterLocal:    svc.Annotations[ClusterLocalAnnotation] == "true",
 		},
 	}
 
@@ -37,6 +37,7 @@ var (
 
 func TestConvertProtocol(t *testing.T) {
 	http := "http"
+	postgresql := "postgresql"
 	type protocolCase struct {
 		port        int32
 		name        string
@@ -72,7 +73,12 @@ func TestConvertProtocol(t *testing.T) {
 		{8888, "redis-test", nil, coreV1.ProtocolTCP, protocol.Redis},
 		{8888, "mysql", nil, coreV1.ProtocolTCP, protocol.MySQL},
 		{8888, "mysql-test", nil, coreV1.ProtocolTCP, protocol.MySQL},
+		{8888, "kafka", nil, coreV1.ProtocolTCP, protocol.Kafka},
+		{8888, "kafka-test", nil, coreV1.ProtocolTCP, protocol.Kafka},
+		{8888, "postgres", nil, coreV1.ProtocolTCP, protocol.Postgres},
+		{8888, "postgres-test", nil, coreV1.ProtocolTCP, protocol.Postgres},
 		{8888, "tcp", &http, coreV1.ProtocolTCP, protocol.HTTP},
+		{8888, "tcp", &postgresql, coreV1.ProtocolTCP, protocol.Postgres},
 	}
 
 	// Create the list of cases for all of the names in both upper and lowercase.
@@ -148,7 +154,8 @@ func TestServiceConversion(t *testing.T) {
 			Annotations: map[string]string{
 				annotation.AlphaKubernetesServiceAccounts.Name: saA + "," + saB,
 				annotation.AlphaCanonicalServiceAccounts.Name:  saC + "," + saD,
-				"other/annotation": "test",
+				ClusterLocalAnnotation:                         "true",
+				"other/annotation":                             "test",
 			},
 			CreationTimestamp: metaV1.Time{Time: tnow},
 		},
@@ -202,6 +209,10 @@ func TestServiceConversion(t *testing.T) {
 			localSvc.Spec.Selector)
 	}
 
+	if !service.Attributes.ClusterLocal {
+		t.Fatalf("service should be cluster-local")
+	}
+
 	sa := service.ServiceAccounts
 	if sa == nil || len(sa) != 4 {
 		t.Fatalf("number of service accounts is incorrect")
@@ -96,7 +96,7 @@ func ServiceToServiceEntry(svc *model.Service) *config.Config {
 	switch svc.Resolution {
 	case model.Passthrough: // 2
 		resolution = networking.ServiceEntry_NONE // 0
-	case model.DNSLB: // 1
+	case model.DNSLB, model.DynamicDNSLB: // 1, 3
 		resolution = networking.ServiceEntry_DNS // 2
 	case model.ClientSideLB: // 0
 		resolution = networking.ServiceEntry_STATIC // 1
@@ -195,6 +195,15 @@ func convertServices(cfg config.Config) []*model.Service {
 
 	out = append(out, buildServices(hostAddresses, cfg.Namespace, svcPorts, serviceEntry.Location, resolution,
 		exportTo, labelSelectors, serviceEntry.SubjectAltNames, creationTime)...)
+
+	// Wildcard hosts without endpoints can not be resolved ahead of time, the proxy resolves each requested host instead.
+	if resolution == model.DNSLB && len(serviceEntry.Endpoints) == 0 && serviceEntry.WorkloadSelector == nil {
+		for _, svc := range out {
+			if svc.Hostname.IsWildCarded() {
+				svc.Resolution = model.DynamicDNSLB
+			}
+		}
+	}
 	return out
 }
 
@@ -290,6 +299,10 @@ func convertServiceEntryToInstances(cfg config.Config, services []*model.Service
 		services = convertServices(cfg)
 	}
 	for _, service := range services {
+		// The hosts of dynamically resolved services are only known at request time.
+		if service.Resolution == model.DynamicDNSLB {
+			continue
+		}
 		for _, serviceEntryPort := range serviceEntry.Ports {
 			if len(serviceEntry.Endpoints) == 0 && serviceEntry.WorkloadSelector == nil &&
 				serviceEntry.Resolution == networking.ServiceEntry_DNS {
@@ -151,6 +151,23 @@ var httpDNSnoEndpoints = &config.Config{
 	},
 }
 
+var httpDNSWildcard = &config.Config{
+	Meta: config.Meta{
+		GroupVersionKind:  gvk.ServiceEntry,
+		Name:              "httpDNSWildcard",
+		Namespace:         "httpDNSWildcard",
+		CreationTimestamp: GlobalTime,
+	},
+	Spec: &networking.ServiceEntry{
+		Hosts: []string{"*.google.com", "www.wikipedia.org"},
+		Ports: []*networking.Port{
+			{Number: 80, Name: "http-port", Protocol: "http"},
+		},
+		Location:   networking.ServiceEntry_MESH_EXTERNAL,
+		Resolution: networking.ServiceEntry_DNS,
+	},
+}
+
 var dnsTargetPort = &config.Config{
 	Meta: config.Meta{
 		GroupVersionKind:  gvk.ServiceEntry,
@@ -550,6 +567,16 @@ func TestConvertService(t *testing.T) {
 					map[string]int{"http-port": 80, "http-alt-port": 8080}, true, model.DNSLB),
 			},
 		},
+		{
+			// service entry DNS with a wildcard host and no endpoints
+			externalSvc: httpDNSWildcard,
+			services: []*model.Service{
+				makeService("*.google.com", "httpDNSWildcard", constants.UnspecifiedIP,
+					map[string]int{"http-port": 80}, true, model.DynamicDNSLB),
+				makeService("www.wikipedia.org", "httpDNSWildcard", constants.UnspecifiedIP,
+					map[string]int{"http-port": 80}, true, model.DNSLB),
+			},
+		},
 		{
 			// service entry dns
 			externalSvc: httpDNS,
@@ -673,6 +700,13 @@ func TestConvertInstances(t *testing.T) {
 				makeInstance(httpDNSnoEndpoints, "www.wikipedia.org", 8080, httpDNSnoEndpoints.Spec.(*networking.ServiceEntry).Ports[1], nil, PlainText),
 			},
 		},
+		{
+			// service entry DNS with a wildcard host and no endpoints
+			externalSvc: httpDNSWildcard,
+			out: []*model.ServiceInstance{
+				makeInstance(httpDNSWildcard, "www.wikipedia.org", 80, httpDNSWildcard.Spec.(*networking.ServiceEntry).Ports[0], nil, PlainText),
+			},
+		},
 		{
 			// service entry DNS with workload selector and no endpoints
 			externalSvc: selectorDNS,
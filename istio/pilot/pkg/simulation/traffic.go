@@ -27,6 +27,7 @@ import (
 	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	listener "github.com/envoyproxy/go-control-plane/envoy/config/listener/v3"
 	route "github.com/envoyproxy/go-control-plane/envoy/config/route/v3"
+	thrift "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/network/thrift_proxy/v3"
 	tls "github.com/envoyproxy/go-control-plane/envoy/extensions/transport_sockets/tls/v3"
 	"github.com/golang/protobuf/ptypes"
 	"github.com/google/go-cmp/cmp"
@@ -49,6 +50,9 @@ const (
 	HTTP  Protocol = "http"
 	HTTP2 Protocol = "http2"
 	TCP   Protocol = "tcp"
+	UDP   Protocol = "udp"
+	// Thrift calls are routed by MethodName and Headers.
+	Thrift Protocol = "thrift"
 )
 
 type TLSMode string
@@ -113,6 +117,9 @@ type Call struct {
 
 	Sni string
 
+	// MethodName is the method of Thrift calls, prefixed by the service name for multiplexed services.
+	MethodName string
+
 	// CallMode describes the type of call to make.
 	CallMode CallMode
 }
@@ -257,6 +264,10 @@ func (sim *Simulation) Run(input Call) (result Result) {
 		return result
 	}
 
+	if input.Protocol == UDP {
+		return sim.runUDP(input, result)
+	}
+
 	// First we will match a listener
 	l := matchListener(sim.Listeners, input)
 	if l == nil {
@@ -344,10 +355,94 @@ func (sim *Simulation) Run(input Call) (result Result) {
 		}
 	} else if tcp := xdstest.ExtractTCPProxy(sim.t, fc); tcp != nil {
 		result.ClusterMatched = tcp.GetCluster()
+	} else if tp := xdstest.ExtractThriftProxy(sim.t, fc); tp != nil {
+		if input.Protocol != Thrift {
+			result.Error = ErrProtocolError
+			return
+		}
+		result.RouteConfigMatched = tp.GetRouteConfig().GetName()
+		r := sim.matchThriftRoute(tp.GetRouteConfig(), input)
+		if r == nil {
+			result.Error = ErrNoRoute
+			return
+		}
+		result.ClusterMatched = r.GetRoute().GetCluster()
 	}
 	return
 }
 
+// matchThriftRoute returns the first route matching the method name and headers of the call.
+func (sim *Simulation) matchThriftRoute(rc *thrift.RouteConfiguration, input Call) *thrift.Route {
+	for _, r := range rc.GetRoutes() {
+		matched := true
+		switch m := r.GetMatch().GetMatchSpecifier().(type) {
+		case *thrift.RouteMatch_MethodName:
+			matched = m.MethodName == "" || m.MethodName == input.MethodName
+		case *thrift.RouteMatch_ServiceName:
+			service := m.ServiceName
+			if service != "" && !strings.HasSuffix(service, ":") {
+				service += ":"
+			}
+			matched = strings.HasPrefix(input.MethodName, service)
+		}
+		if r.GetMatch().GetInvert() {
+			matched = !matched
+		}
+		if matched && sim.matchHeaders(r.GetMatch().GetHeaders(), input.Headers) {
+			return r
+		}
+	}
+	return nil
+}
+
+// matchHeaders returns true if all the header matchers match the headers.
+func (sim *Simulation) matchHeaders(matchers []*route.HeaderMatcher, headers http.Header) bool {
+	for _, m := range matchers {
+		var value string
+		present := false
+		for name, values := range headers {
+			if strings.EqualFold(name, m.Name) && len(values) > 0 {
+				value, present = values[0], true
+				break
+			}
+		}
+		matched := present
+		switch hm := m.GetHeaderMatchSpecifier().(type) {
+		case *route.HeaderMatcher_ExactMatch:
+			matched = present && value == hm.ExactMatch
+		case *route.HeaderMatcher_PrefixMatch:
+			matched = present && strings.HasPrefix(value, hm.PrefixMatch)
+		case *route.HeaderMatcher_SafeRegexMatch:
+			r, err := regexp.Compile(hm.SafeRegexMatch.GetRegex())
+			if err != nil {
+				sim.t.Fatalf("invalid regex %v: %v", hm.SafeRegexMatch.GetRegex(), err)
+			}
+			matched = present && r.MatchString(value)
+		case *route.HeaderMatcher_PresentMatch:
+			matched = present == hm.PresentMatch
+		}
+		if matched == m.InvertMatch {
+			return false
+		}
+	}
+	return true
+}
+
+// runUDP matches a UDP listener. UDP listeners have no filter chains, the udp_proxy listener filter forwards all
+// datagrams to a single cluster.
+func (sim *Simulation) runUDP(input Call, result Result) Result {
+	l := matchUDPListener(sim.Listeners, input)
+	if l == nil {
+		result.Error = ErrNoListener
+		return result
+	}
+	result.ListenerMatched = l.Name
+	if udp := xdstest.ExtractUDPProxy(sim.t, l); udp != nil {
+		result.ClusterMatched = udp.GetCluster()
+	}
+	return result
+}
+
 func (sim *Simulation) requiresMTLS(fc *listener.FilterChain) bool {
 	if fc.TransportSocket == nil {
 		return false
@@ -597,12 +692,12 @@ func matchListener(listeners []*listener.Listener, input Call) *listener.Listene
 	// First find exact match for the IP/Port, then fallback to wildcard IP/Port
 	// There is no wildcard port
 	for _, l := range listeners {
-		if matchAddress(l.GetAddress(), input.Address, input.Port) {
+		if !isUDP(l) && matchAddress(l.GetAddress(), input.Address, input.Port) {
 			return l
 		}
 	}
 	for _, l := range listeners {
-		if matchAddress(l.GetAddress(), "0.0.0.0", input.Port) {
+		if !isUDP(l) && matchAddress(l.GetAddress(), "0.0.0.0", input.Port) {
 			return l
 		}
 	}
@@ -617,6 +712,28 @@ func matchListener(listeners []*listener.Listener, input Call) *listener.Listene
 	return nil
 }
 
+// matchUDPListener finds the UDP listener for the call. There is no virtual listener for UDP: inbound and gateway
+// traffic reaches the listener bound to the port, and outbound traffic is redirected by iptables to the listener
+// bound to localhost on the same port.
+func matchUDPListener(listeners []*listener.Listener, input Call) *listener.Listener {
+	addresses := []string{input.Address, "0.0.0.0"}
+	if input.CallMode == CallModeOutbound {
+		addresses = []string{"127.0.0.1"}
+	}
+	for _, address := range addresses {
+		for _, l := range listeners {
+			if isUDP(l) && matchAddress(l.GetAddress(), address, input.Port) {
+				return l
+			}
+		}
+	}
+	return nil
+}
+
+func isUDP(l *listener.Listener) bool {
+	return l.GetAddress().GetSocketAddress().GetProtocol() == core.SocketAddress_UDP
+}
+
 func matchAddress(a *core.Address, address string, port int) bool {
 	if a.GetSocketAddress().GetAddress() != address {
 		return false
@@ -0,0 +1,170 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"sort"
+	"time"
+
+	"istio.io/istio/pilot/pkg/xds"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/pkg/monitoring"
+)
+
+// latencyRetention is how long the distribution latency of a generation is tracked after it is written.
+const latencyRetention = 10 * time.Minute
+
+var (
+	kindTag = monitoring.MustCreateLabel("kind")
+
+	distributionLatency = monitoring.NewDistribution(
+		"pilot_config_distribution_latency",
+		"Time in seconds from a config generation being written to its acknowledgement by a proxy.",
+		[]float64{.1, .5, 1, 3, 5, 10, 20, 30, 60},
+		monitoring.WithLabels(kindTag),
+	)
+)
+
+func init() {
+	monitoring.MustRegister(distributionLatency)
+}
+
+var _ xds.DistributionLatencyCache = &Reporter{}
+
+// latencyEntry tracks the distribution latency of a generation of a resource.
+type latencyEntry struct {
+	Resource
+	// the time the generation was written to the ledger
+	start time.Time
+	// the time from start to the first acknowledgement of a config version including the generation, per connection
+	acked map[string]time.Duration
+}
+
+// trackLatency starts tracking the distribution latency of a new generation of a resource, replacing the previous
+// one. Must have write lock before calling.
+func (r *Reporter) trackLatency(res Resource) {
+	now := r.clock.Now()
+	for key, entry := range r.latencies {
+		if now.Sub(entry.start) > latencyRetention {
+			delete(r.latencies, key)
+		}
+	}
+	r.latencies[res.ToModelKey()] = &latencyEntry{
+		Resource: res,
+		start:    now,
+		acked:    map[string]time.Duration{},
+	}
+}
+
+// connectionEntry tracks the distribution latencies recorded for a connection.
+type connectionEntry struct {
+	// the time the connection was established
+	connectedAt time.Time
+	// the last config version acknowledged by the connection
+	version string
+}
+
+// RegisterConnect starts tracking the generations acknowledged by a new connection. Generations written before the
+// connection was established are received with its initial config, and are not tracked.
+func (r *Reporter) RegisterConnect(conID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.connections[conID] = &connectionEntry{connectedAt: r.clock.Now()}
+}
+
+// recordLatency records the acknowledgement of a config version by a connection for each tracked generation the
+// version includes. Only the generations the connection did not acknowledge yet are looked up in the ledger, and the
+// lookups are done without holding the lock.
+func (r *Reporter) recordLatency(conID string, version string) {
+	now := r.clock.Now()
+	pending := map[string]*latencyEntry{}
+	r.mu.Lock()
+	con, f := r.connections[conID]
+	if !f || con.version == version {
+		// The connection is gone, or the version was acknowledged for another type already.
+		r.mu.Unlock()
+		return
+	}
+	con.version = version
+	for key, entry := range r.latencies {
+		if _, f := entry.acked[conID]; !f && !con.connectedAt.After(entry.start) {
+			pending[key] = entry
+		}
+	}
+	r.mu.Unlock()
+
+	for key, entry := range pending {
+		if generation, err := r.ledger.GetPreviousValue(version, key); err != nil || generation != entry.Generation {
+			delete(pending, key)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.connections[conID] != con {
+		return
+	}
+	for key, entry := range pending {
+		if _, f := entry.acked[conID]; f || r.latencies[key] != entry {
+			// The generation was acknowledged by a later event, or replaced meanwhile.
+			continue
+		}
+		latency := now.Sub(entry.start)
+		entry.acked[conID] = latency
+		kind := entry.Resource.Resource
+		if s, f := collections.All.FindByPlural(entry.Group, entry.Version, entry.Resource.Resource); f {
+			kind = s.Resource().Kind()
+		}
+		distributionLatency.With(kindTag.Value(kind)).Record(latency.Seconds())
+	}
+}
+
+// DistributionLatencies returns the distribution latency of the latest generation of the resource with the given
+// key, or of all tracked resources if the key is empty.
+func (r *Reporter) DistributionLatencies(key string) []xds.DistributionLatency {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]xds.DistributionLatency, 0, len(r.latencies))
+	for k, entry := range r.latencies {
+		if key != "" && k != key {
+			continue
+		}
+		dl := xds.DistributionLatency{
+			Resource:   k,
+			Generation: entry.Generation,
+			Start:      entry.start,
+		}
+		for _, latency := range entry.acked {
+			dl.Latencies = append(dl.Latencies, latency)
+		}
+		sort.Slice(dl.Latencies, func(i, j int) bool {
+			return dl.Latencies[i] < dl.Latencies[j]
+		})
+		for conID, con := range r.connections {
+			if _, f := entry.acked[conID]; !f && !con.connectedAt.After(entry.start) {
+				dl.Stragglers = append(dl.Stragglers, conID)
+			}
+		}
+		sort.Strings(dl.Stragglers)
+		out = append(out, dl)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Resource < out[j].Resource
+	})
+	return out
+}
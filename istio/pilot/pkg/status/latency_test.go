@@ -0,0 +1,105 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package status
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	clocktesting "k8s.io/utils/clock/testing"
+
+	"istio.io/istio/pilot/pkg/xds"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/pkg/ledger"
+)
+
+func TestDistributionLatencies(t *testing.T) {
+	RegisterTestingT(t)
+	clock := clocktesting.NewFakeClock(time.Now())
+	r := initReporterWithoutStarting()
+	r.clock = clock
+	r.ledger = ledger.Make(time.Minute)
+
+	// two proxies are connected before the resource is written, one of them did not acknowledge any config yet
+	r.RegisterConnect("conA")
+	r.RegisterConnect("conB")
+	r.processEvent("conA", "", r.ledger.RootHash())
+
+	clock.Step(time.Second)
+	vs := config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: collections.IstioNetworkingV1Alpha3Virtualservices.Resource().GroupVersionKind(),
+			Namespace:        "default",
+			Name:             "foo",
+			Generation:       1,
+		},
+	}
+	r.AddInProgressResource(vs)
+	key := config.Key("VirtualService", "foo", "default")
+	start := clock.Now()
+
+	// only one of them acknowledges it
+	clock.Step(2 * time.Second)
+	r.processEvent("conA", "", r.ledger.RootHash())
+	// a proxy connecting afterwards receives it with its initial config, and is not counted
+	r.RegisterConnect("conC")
+	r.processEvent("conC", "", r.ledger.RootHash())
+	// acknowledging again, or for another type, does not change the latency
+	clock.Step(time.Second)
+	r.processEvent("conA", "", r.ledger.RootHash())
+	r.processEvent("conA", "other", r.ledger.RootHash())
+	// acknowledgements of connections that are not tracked, e.g. gone, are ignored
+	r.processEvent("conD", "", r.ledger.RootHash())
+
+	Expect(r.DistributionLatencies(key)).To(Equal([]xds.DistributionLatency{{
+		Resource:   key,
+		Generation: "1",
+		Start:      start,
+		Latencies:  []time.Duration{2 * time.Second},
+		Stragglers: []string{"conB"},
+	}}))
+	Expect(r.DistributionLatencies("VirtualService/default/bar")).To(BeEmpty())
+	Expect(r.DistributionLatencies("")).To(HaveLen(1))
+
+	// disconnected proxies are no longer stragglers
+	r.RegisterDisconnect("conB", []xds.EventType{""})
+	Expect(r.DistributionLatencies(key)[0].Stragglers).To(BeEmpty())
+
+	// the latency of a new generation replaces the previous one
+	vs.Generation = 2
+	r.AddInProgressResource(vs)
+	latencies := r.DistributionLatencies(key)
+	Expect(latencies).To(HaveLen(1))
+	Expect(latencies[0].Generation).To(Equal("2"))
+	Expect(latencies[0].Stragglers).To(Equal([]string{"conA", "conC"}))
+
+	// and are pruned once they are no longer recent
+	clock.Step(latencyRetention + time.Second)
+	r.AddInProgressResource(config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: vs.GroupVersionKind,
+			Namespace:        "default",
+			Name:             "bar",
+			Generation:       1,
+		},
+	})
+	Expect(r.DistributionLatencies(key)).To(BeEmpty())
+
+	// deleted resources are no longer tracked
+	r.DeleteInProgressResource(config.Config{Meta: config.Meta{GroupVersionKind: vs.GroupVersionKind, Namespace: "default", Name: "bar"}})
+	Expect(r.DistributionLatencies("")).To(BeEmpty())
+}
@@ -29,6 +29,16 @@ type DistributionReport struct {
 	Reporter            string         `json:"reporter"`
 	DataPlaneCount      int            `json:"dataPlaneCount"`
 	InProgressResources map[string]int `json:"inProgressResources"`
+	// RejectedResources maps resources to the rejections of the config generated from them
+	RejectedResources map[string]Rejection `json:"rejectedResources,omitempty" yaml:"rejectedResources,omitempty"`
+}
+
+// Rejection describes proxies rejecting the config generated from a resource.
+type Rejection struct {
+	// Count is the number of proxies and types that rejected the config.
+	Count int `json:"count"`
+	// Message is one of the errors reported by the proxies.
+	Message string `json:"message"`
 }
 
 func ReportFromYaml(content []byte) (DistributionReport, error) {
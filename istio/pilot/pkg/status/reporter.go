@@ -29,6 +29,7 @@ import (
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/utils/clock"
 
+	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/xds"
 	"istio.io/istio/pkg/config"
 	"istio.io/pkg/ledger"
@@ -66,9 +67,25 @@ type Reporter struct {
 	ledger                 ledger.Ledger
 	distributionEventQueue chan distributionEvent
 	controller             *DistributionController
+	// map from connection id to the connections tracked for distribution latencies
+	connections map[string]*connectionEntry
+	// map from model key to the distribution latency of the latest generation of the resource
+	latencies map[string]*latencyEntry
+	// map from connection id and type to the config rejected by the connection
+	nacks map[string]nackEntry
 }
 
-var _ xds.DistributionStatusCache = &Reporter{}
+type nackEntry struct {
+	// the error reported by the proxy
+	message string
+	// the resources the rejected config was likely generated from
+	resources []Resource
+}
+
+var (
+	_ xds.DistributionStatusCache = &Reporter{}
+	_ xds.NackStatusCache         = &Reporter{}
+)
 
 const (
 	labelKey  = "internal.istio.io/distribution-report"
@@ -90,6 +107,9 @@ func (r *Reporter) Init(ledger ledger.Ledger) {
 	r.status = make(map[string]string)
 	r.reverseStatus = make(map[string]map[string]struct{})
 	r.inProgressResources = make(map[string]*inProgressEntry)
+	r.connections = make(map[string]*connectionEntry)
+	r.latencies = make(map[string]*latencyEntry)
+	r.nacks = make(map[string]nackEntry)
 	go r.readFromEventQueue()
 }
 
@@ -179,6 +199,21 @@ func (r *Reporter) buildReport() (DistributionReport, []Resource) {
 			}
 		}
 	}
+	for _, nack := range r.nacks {
+		for _, res := range nack.resources {
+			if out.RejectedResources == nil {
+				out.RejectedResources = map[string]Rejection{}
+			}
+			key := res.String()
+			rejection := out.RejectedResources[key]
+			rejection.Count++
+			// pick the same message on each report
+			if rejection.Message == "" || nack.message < rejection.Message {
+				rejection.Message = nack.message
+			}
+			out.RejectedResources[key] = rejection
+		}
+	}
 	return out, finishedResources
 }
 
@@ -219,6 +254,7 @@ func (r *Reporter) AddInProgressResource(res config.Config) {
 		Resource:            *myRes,
 		completedIterations: 0,
 	}
+	r.trackLatency(*myRes)
 }
 
 func (r *Reporter) DeleteInProgressResource(res config.Config) {
@@ -229,6 +265,7 @@ func (r *Reporter) DeleteInProgressResource(res config.Config) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	delete(r.inProgressResources, res.Key())
+	delete(r.latencies, res.Key())
 }
 
 // generate a distribution report and write it to a ConfigMap for the leader to read.
@@ -304,22 +341,23 @@ func (r *Reporter) readFromEventQueue() {
 }
 
 func (r *Reporter) processEvent(conID string, distributionType xds.EventType, nonce string) {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-	key := conID + distributionType // TODO: delimit?
-	r.deleteKeyFromReverseMap(key)
 	var version string
 	if len(nonce) > 12 {
 		version = nonce[:xds.VersionLen]
 	} else {
 		version = nonce
 	}
+	r.mu.Lock()
+	key := conID + distributionType // TODO: delimit?
+	r.deleteKeyFromReverseMap(key)
 	// touch
 	r.status[key] = version
 	if _, ok := r.reverseStatus[version]; !ok {
 		r.reverseStatus[version] = make(map[string]struct{})
 	}
 	r.reverseStatus[version][key] = struct{}{}
+	r.mu.Unlock()
+	r.recordLatency(conID, version)
 }
 
 // This is a helper function for keeping our reverseStatus map in step with status.
@@ -343,7 +381,33 @@ func (r *Reporter) RegisterDisconnect(conID string, types []xds.EventType) {
 		key := conID + xdsType // TODO: delimit?
 		r.deleteKeyFromReverseMap(key)
 		delete(r.status, key)
+		delete(r.nacks, key)
+	}
+	delete(r.connections, conID)
+}
+
+// RegisterNack records that a dataplane rejected the config of a type, attributing the rejection to the in
+// progress resources among the configs updated since the dataplane last accepted it.
+func (r *Reporter) RegisterNack(conID string, distributionType xds.EventType, detail *model.NackDetail) {
+	key := conID + distributionType
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if detail == nil {
+		delete(r.nacks, key)
+		return
+	}
+	entry := nackEntry{message: detail.Message}
+	for ck := range detail.ConfigsUpdated {
+		if ipr, f := r.inProgressResources[config.Key(ck.Kind.Kind, ck.Name, ck.Namespace)]; f {
+			entry.resources = append(entry.resources, ipr.Resource)
+		}
+	}
+	if len(entry.resources) == 0 {
+		// the rejection cannot be attributed to a resource
+		delete(r.nacks, key)
+		return
 	}
+	r.nacks[key] = entry
 }
 
 func (r *Reporter) SetController(controller *DistributionController) {
@@ -21,6 +21,7 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/utils/clock"
 
+	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/xds"
 	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/schema/collections"
@@ -55,6 +56,9 @@ func initReporterWithoutStarting() (out Reporter) {
 	out.cm = nil // TODO
 	out.reverseStatus = make(map[string]map[string]struct{})
 	out.status = make(map[string]string)
+	out.connections = make(map[string]*connectionEntry)
+	out.latencies = make(map[string]*latencyEntry)
+	out.nacks = make(map[string]nackEntry)
 	return
 }
 
@@ -124,3 +128,38 @@ func TestBuildReport(t *testing.T) {
 	}))
 	Expect(r.inProgressResources).NotTo(ContainElement(resources[0]))
 }
+
+func TestBuildReportRejections(t *testing.T) {
+	RegisterTestingT(t)
+	r := initReporterWithoutStarting()
+	r.ledger = ledger.Make(time.Minute)
+	vs := config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: collections.IstioNetworkingV1Alpha3Virtualservices.Resource().GroupVersionKind(),
+			Namespace:        "default",
+			Name:             "foo",
+			Generation:       1,
+		},
+	}
+	r.AddInProgressResource(vs)
+	res := ResourceFromModelConfig(vs).String()
+	updated := map[model.ConfigKey]struct{}{
+		{Kind: vs.GroupVersionKind, Name: "foo", Namespace: "default"}:     {},
+		{Kind: vs.GroupVersionKind, Name: "missing", Namespace: "default"}: {},
+	}
+	r.RegisterNack("conA", "lds", &model.NackDetail{Message: "invalid listener", ConfigsUpdated: updated})
+	r.RegisterNack("conB", "rds", &model.NackDetail{Message: "invalid route", ConfigsUpdated: updated})
+	// rejections that cannot be attributed to an in progress resource are not reported
+	r.RegisterNack("conC", "rds", &model.NackDetail{Message: "unknown", ConfigsUpdated: map[model.ConfigKey]struct{}{}})
+
+	rpt, _ := r.buildReport()
+	Expect(rpt.RejectedResources).To(Equal(map[string]Rejection{
+		res: {Count: 2, Message: "invalid listener"},
+	}))
+
+	// accepting the config again, or disconnecting, clears the rejection
+	r.RegisterNack("conA", "lds", nil)
+	r.RegisterDisconnect("conB", []xds.EventType{"rds"})
+	rpt, _ = r.buildReport()
+	Expect(rpt.RejectedResources).To(BeEmpty())
+}
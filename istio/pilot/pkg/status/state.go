@@ -64,11 +64,16 @@ type DistributionController struct {
 	workers         WorkerQueue
 	StaleInterval   time.Duration
 	cmInformer      cache.SharedIndexInformer
+	// ownsNamespace, if set, limits the status written by this controller to the namespaces it returns true for.
+	ownsNamespace func(namespace string) bool
+	// rejections holds the rejections of the config generated from each resource, per reporter.
+	rejections map[Resource]map[string]Rejection
 }
 
 func NewController(restConfig rest.Config, namespace string, cs model.ConfigStore) *DistributionController {
 	c := &DistributionController{
 		CurrentState:    make(map[Resource]map[string]Progress),
+		rejections:      make(map[Resource]map[string]Rejection),
 		ObservationTime: make(map[string]time.Time),
 		UpdateInterval:  200 * time.Millisecond,
 		StaleInterval:   time.Minute,
@@ -98,6 +103,14 @@ func NewController(restConfig rest.Config, namespace string, cs model.ConfigStor
 	return c
 }
 
+// SetNamespaceFilter limits the status written by this controller to the namespaces f returns true for. This is
+// used to split the status writing between replicas.
+func (c *DistributionController) SetNamespaceFilter(f func(namespace string) bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ownsNamespace = f
+}
+
 func (c *DistributionController) Start(stop <-chan struct{}) {
 	scope.Info("Starting status leader controller")
 
@@ -138,6 +151,18 @@ func (c *DistributionController) handleReport(d DistributionReport) {
 		}
 		c.CurrentState[res][d.Reporter] = Progress{d.InProgressResources[resstr], d.DataPlaneCount}
 	}
+	for res, rejections := range c.rejections {
+		if _, f := d.RejectedResources[res.String()]; !f {
+			delete(rejections, d.Reporter)
+		}
+	}
+	for resstr, rejection := range d.RejectedResources {
+		res := *ResourceFromString(resstr)
+		if _, ok := c.rejections[res]; !ok {
+			c.rejections[res] = make(map[string]Rejection)
+		}
+		c.rejections[res][d.Reporter] = rejection
+	}
 	c.ObservationTime[d.Reporter] = c.clock.Now()
 }
 
@@ -156,6 +181,10 @@ func (c *DistributionController) writeAllStatus() (staleReporters []string) {
 				distributionState.PlusEquals(w)
 			}
 		}
+		if c.ownsNamespace != nil && !c.ownsNamespace(config.Namespace) {
+			// another replica writes the status of this namespace.
+			continue
+		}
 		if distributionState.TotalInstances > 0 { // this is necessary when all reports are stale.
 			c.queueWriteStatus(config, distributionState)
 		}
@@ -188,7 +217,11 @@ func (c *DistributionController) writeStatus(config Resource, distributionState
 	}
 
 	// check if status needs updating
-	if needsReconcile, desiredStatus := ReconcileStatuses(current, distributionState, current.Generation); needsReconcile {
+	needsReconcile, desiredStatus := ReconcileStatuses(current, distributionState, current.Generation)
+	if reconcileRejection(desiredStatus, c.rejection(config)) {
+		needsReconcile = true
+	}
+	if needsReconcile {
 		// technically, we should be updating probe time even when reconciling isn't needed, but
 		// I'm skipping that for efficiency.
 		current.Status = desiredStatus
@@ -204,6 +237,21 @@ func (c *DistributionController) pruneOldVersion(config Resource) {
 	defer c.mu.Unlock()
 	c.mu.Lock()
 	delete(c.CurrentState, config)
+	delete(c.rejections, config)
+}
+
+// rejection aggregates the rejections of the config generated from the resource over all reporters.
+func (c *DistributionController) rejection(config Resource) Rejection {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var out Rejection
+	for _, r := range c.rejections[config] {
+		out.Count += r.Count
+		if out.Message == "" || r.Message < out.Message {
+			out.Message = r.Message
+		}
+	}
+	return out
 }
 
 func (c *DistributionController) removeStaleReporters(staleReporters []string) {
@@ -215,6 +263,11 @@ func (c *DistributionController) removeStaleReporters(staleReporters []string) {
 		}
 		c.CurrentState[key] = fractions
 	}
+	for _, rejections := range c.rejections {
+		for _, staleReporter := range staleReporters {
+			delete(rejections, staleReporter)
+		}
+	}
 }
 
 func (c *DistributionController) queueWriteStatus(config Resource, state Progress) {
@@ -286,6 +339,43 @@ func ReconcileStatuses(current *config.Config, desired Progress, generation int6
 	return needsReconcile, currentStatus
 }
 
+// reconcileRejection sets the ConfigRejected condition of the status if proxies rejected the config generated from
+// the resource, or removes it otherwise. Returns true if the status changed.
+func reconcileRejection(status *v1alpha1.IstioStatus, rejection Rejection) bool {
+	conditionIndex := -1
+	for i, c := range status.Conditions {
+		if c.Type == "ConfigRejected" {
+			conditionIndex = i
+		}
+	}
+	if rejection.Count == 0 {
+		if conditionIndex == -1 {
+			return false
+		}
+		status.Conditions = append(status.Conditions[:conditionIndex], status.Conditions[conditionIndex+1:]...)
+		return true
+	}
+	desiredCondition := v1alpha1.IstioCondition{
+		Type:               "ConfigRejected",
+		Status:             "True",
+		Reason:             "ProxyRejected",
+		LastProbeTime:      types.TimestampNow(),
+		LastTransitionTime: types.TimestampNow(),
+		Message: fmt.Sprintf("%d proxies rejected the configuration generated from this resource: %s",
+			rejection.Count, rejection.Message),
+	}
+	if conditionIndex == -1 {
+		status.Conditions = append(status.Conditions, &desiredCondition)
+		return true
+	}
+	currentCondition := status.Conditions[conditionIndex]
+	if currentCondition.Message == desiredCondition.Message {
+		return false
+	}
+	status.Conditions[conditionIndex] = &desiredCondition
+	return true
+}
+
 type DistroReportHandler struct {
 	dc *DistributionController
 }
@@ -15,9 +15,13 @@
 package status
 
 import (
+	"context"
 	"encoding/json"
 	"reflect"
 	"testing"
+	"time"
+
+	"k8s.io/utils/clock"
 
 	"istio.io/api/meta/v1alpha1"
 	"istio.io/istio/pkg/config"
@@ -197,3 +201,91 @@ func Test_getTypedStatus(t *testing.T) {
 		})
 	}
 }
+
+type recordingQueue struct {
+	pushed []Resource
+}
+
+func (q *recordingQueue) Push(target Resource, _ Progress) {
+	q.pushed = append(q.pushed, target)
+}
+
+func (q *recordingQueue) Run(context.Context) {}
+
+func (q *recordingQueue) Delete(Resource) {}
+
+func TestWriteAllStatusNamespaceFilter(t *testing.T) {
+	owned := Resource{Namespace: "owned", Name: "a"}
+	other := Resource{Namespace: "other", Name: "b"}
+	q := &recordingQueue{}
+	c := &DistributionController{
+		CurrentState: map[Resource]map[string]Progress{
+			owned: {"pod1": {1, 2}},
+			other: {"pod1": {1, 2}},
+		},
+		ObservationTime: map[string]time.Time{"pod1": time.Now()},
+		StaleInterval:   time.Minute,
+		clock:           clock.RealClock{},
+		workers:         q,
+	}
+	c.SetNamespaceFilter(func(namespace string) bool {
+		return namespace == "owned"
+	})
+	c.writeAllStatus()
+	if !reflect.DeepEqual(q.pushed, []Resource{owned}) {
+		t.Fatalf("expected status to be written for %v only, got %v", owned, q.pushed)
+	}
+}
+
+func TestReconcileRejection(t *testing.T) {
+	status := statusStillPropagating.DeepCopy()
+	if reconcileRejection(status, Rejection{}) {
+		t.Fatalf("expected no change without rejections")
+	}
+	if !reconcileRejection(status, Rejection{Count: 2, Message: "invalid route"}) {
+		t.Fatalf("expected the rejection to be added")
+	}
+	if len(status.Conditions) != 3 {
+		t.Fatalf("expected a ConfigRejected condition, got %v", status.Conditions)
+	}
+	cond := status.Conditions[2]
+	want := "2 proxies rejected the configuration generated from this resource: invalid route"
+	if cond.Type != "ConfigRejected" || cond.Status != "True" || cond.Reason != "ProxyRejected" || cond.Message != want {
+		t.Fatalf("unexpected condition %v", cond)
+	}
+	if reconcileRejection(status, Rejection{Count: 2, Message: "invalid route"}) {
+		t.Fatalf("expected no change for the same rejection")
+	}
+	if !reconcileRejection(status, Rejection{}) {
+		t.Fatalf("expected the rejection to be removed")
+	}
+	if len(status.Conditions) != 2 {
+		t.Fatalf("expected the ConfigRejected condition to be removed, got %v", status.Conditions)
+	}
+}
+
+func TestHandleReportRejections(t *testing.T) {
+	res := Resource{Namespace: "default", Name: "foo", Generation: "1"}
+	c := &DistributionController{
+		CurrentState:    map[Resource]map[string]Progress{},
+		rejections:      map[Resource]map[string]Rejection{},
+		ObservationTime: map[string]time.Time{},
+		clock:           clock.RealClock{},
+	}
+	c.handleReport(DistributionReport{
+		Reporter:          "pod1",
+		RejectedResources: map[string]Rejection{res.String(): {Count: 1, Message: "b"}},
+	})
+	c.handleReport(DistributionReport{
+		Reporter:          "pod2",
+		RejectedResources: map[string]Rejection{res.String(): {Count: 2, Message: "a"}},
+	})
+	if got := c.rejection(res); got != (Rejection{Count: 3, Message: "a"}) {
+		t.Fatalf("unexpected rejection %v", got)
+	}
+	c.handleReport(DistributionReport{Reporter: "pod2"})
+	c.removeStaleReporters([]string{"pod1"})
+	if got := c.rejection(res); got != (Rejection{}) {
+		t.Fatalf("expected no rejection, got %v", got)
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trustbundle
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sort"
+	"time"
+
+	"istio.io/istio/pkg/spiffe"
+)
+
+var (
+	// defaultSpiffeRefreshInterval is used for bundles that do not provide a refresh hint.
+	defaultSpiffeRefreshInterval = 5 * time.Minute
+	// minSpiffeRefreshInterval bounds the refresh hints of the bundles, to protect both ends from hints too low.
+	minSpiffeRefreshInterval = 30 * time.Second
+	// firstSpiffeRetryInterval is the backoff after the first failure to retrieve a bundle.
+	firstSpiffeRetryInterval = time.Second
+
+	// retrieveSpiffeBundle is overridable for tests.
+	retrieveSpiffeBundle = spiffe.RetrieveSpiffeBundle
+)
+
+// AddSpiffeBundleEndpoints polls the SPIFFE bundle endpoints of each of the trust domains until stop is closed,
+// keeping their roots in the SourceSpiffeEndpoint source. The endpoints are validated with the system cert pool
+// and the extra trusted certs, following the https_web profile. Each bundle is refreshed according to its refresh
+// hint.
+func (tb *TrustBundle) AddSpiffeBundleEndpoints(endpoints map[string]string, extraTrustedCerts []*x509.Certificate,
+	stop <-chan struct{}) error {
+	caCertPool, err := x509.SystemCertPool()
+	if err != nil {
+		return fmt.Errorf("failed to get SystemCertPool: %v", err)
+	}
+	for _, cert := range extraTrustedCerts {
+		caCertPool.AddCert(cert)
+	}
+	for trustDomain, endpoint := range endpoints {
+		go tb.pollSpiffeBundle(trustDomain, endpoint, caCertPool, stop)
+	}
+	return nil
+}
+
+func (tb *TrustBundle) pollSpiffeBundle(trustDomain, endpoint string, caCertPool *x509.CertPool, stop <-chan struct{}) {
+	retry := firstSpiffeRetryInterval
+	for {
+		next := defaultSpiffeRefreshInterval
+		certs, refreshHint, err := retrieveSpiffeBundle(trustDomain, endpoint, caCertPool)
+		if err != nil {
+			trustBundleLog.Warnf("failed to retrieve the SPIFFE bundle of %s, retry in %v: %v", trustDomain, retry, err)
+			next = retry
+			if retry *= 2; retry > defaultSpiffeRefreshInterval {
+				retry = defaultSpiffeRefreshInterval
+			}
+		} else {
+			retry = firstSpiffeRetryInterval
+			if refreshHint > 0 {
+				next = refreshHint
+			}
+			if next < minSpiffeRefreshInterval {
+				next = minSpiffeRefreshInterval
+			}
+			if err := tb.updateSpiffeBundle(trustDomain, certs); err != nil {
+				trustBundleLog.Errorf("failed to update the SPIFFE bundle of %s: %v", trustDomain, err)
+			}
+		}
+		select {
+		case <-stop:
+			return
+		case <-time.After(next):
+		}
+	}
+}
+
+// updateSpiffeBundle replaces the roots of a trust domain, and merges the roots of all trust domains into the
+// SourceSpiffeEndpoint source.
+func (tb *TrustBundle) updateSpiffeBundle(trustDomain string, certs []*x509.Certificate) error {
+	tb.mutex.Lock()
+	tb.spiffeBundles[trustDomain] = certs
+	pemCerts := []string{}
+	seen := map[string]struct{}{}
+	for _, tdCerts := range tb.spiffeBundles {
+		for _, cert := range tdCerts {
+			pemCert := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+			if _, f := seen[pemCert]; !f {
+				seen[pemCert] = struct{}{}
+				pemCerts = append(pemCerts, pemCert)
+			}
+		}
+	}
+	tb.mutex.Unlock()
+	sort.Strings(pemCerts)
+	return tb.UpdateTrustAnchor(&TrustAnchorUpdate{
+		TrustAnchorConfig: TrustAnchorConfig{Certs: pemCerts},
+		Source:            SourceSpiffeEndpoint,
+	})
+}
+
+// GetSpiffeBundles returns the roots retrieved from the SPIFFE bundle endpoints, per trust domain. These are meant
+// to be kept in sync with the mappings of a spiffe.PeerCertVerifier.
+func (tb *TrustBundle) GetSpiffeBundles() map[string][]*x509.Certificate {
+	tb.mutex.RLock()
+	defer tb.mutex.RUnlock()
+	out := make(map[string][]*x509.Certificate, len(tb.spiffeBundles))
+	for trustDomain, certs := range tb.spiffeBundles {
+		out[trustDomain] = certs
+	}
+	return out
+}
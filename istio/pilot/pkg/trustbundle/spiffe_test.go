@@ -0,0 +1,120 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trustbundle
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"istio.io/istio/pkg/spiffe"
+	"istio.io/istio/pkg/test/util/retry"
+)
+
+func parseCert(t *testing.T, cert string) *x509.Certificate {
+	t.Helper()
+	block, _ := pem.Decode([]byte(cert))
+	if block == nil {
+		t.Fatalf("failed to decode cert")
+	}
+	out, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestSpiffeBundleEndpoints(t *testing.T) {
+	root := parseCert(t, rootCACert)
+	rotated := parseCert(t, intermediateCACert)
+
+	var mu sync.Mutex
+	bundles := map[string][]*x509.Certificate{"foo": {root}}
+	defer func() { retrieveSpiffeBundle = spiffe.RetrieveSpiffeBundle }()
+	retrieveSpiffeBundle = func(trustDomain, endpoint string, _ *x509.CertPool) ([]*x509.Certificate, time.Duration, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(bundles[trustDomain]) == 0 {
+			return nil, 0, fmt.Errorf("unavailable")
+		}
+		return bundles[trustDomain], time.Millisecond, nil
+	}
+	minSpiffeRefreshInterval = 10 * time.Millisecond
+	firstSpiffeRetryInterval = 10 * time.Millisecond
+	defer func() {
+		minSpiffeRefreshInterval = 30 * time.Second
+		firstSpiffeRetryInterval = time.Second
+	}()
+
+	tb := NewTrustBundle()
+	updates := atomic.NewInt32(0)
+	tb.UpdateCb(func() { updates.Inc() })
+	stop := make(chan struct{})
+	defer close(stop)
+	if err := tb.AddSpiffeBundleEndpoints(map[string]string{"foo": "foo.com", "bar": "bar.com"}, nil, stop); err != nil {
+		t.Fatal(err)
+	}
+	expect := func(td string, certs []*x509.Certificate, anchors []string) {
+		t.Helper()
+		retry.UntilSuccessOrFail(t, func() error {
+			got := tb.GetSpiffeBundles()[td]
+			if len(got) != len(certs) {
+				return fmt.Errorf("expected %d roots for %s, got %d", len(certs), td, len(got))
+			}
+			for i := range certs {
+				if !got[i].Equal(certs[i]) {
+					return fmt.Errorf("unexpected root for %s", td)
+				}
+			}
+			if !checkSameCerts(tb.GetTrustBundle(), anchors) {
+				return fmt.Errorf("unexpected trust bundle %v", tb.GetTrustBundle())
+			}
+			return nil
+		}, retry.Timeout(time.Second*5))
+	}
+	expect("foo", []*x509.Certificate{root}, []string{rootCACert})
+	if updates.Load() != 1 {
+		t.Fatalf("expected a single update, got %d", updates.Load())
+	}
+
+	// the roots of a trust domain are retried until they can be retrieved
+	mu.Lock()
+	bundles["bar"] = []*x509.Certificate{root}
+	mu.Unlock()
+	expect("bar", []*x509.Certificate{root}, []string{rootCACert})
+
+	// rotated roots replace the previous ones on refresh
+	mu.Lock()
+	bundles["foo"] = []*x509.Certificate{rotated}
+	mu.Unlock()
+	expect("foo", []*x509.Certificate{rotated}, sortedCerts(rootCACert, intermediateCACert))
+	if updates.Load() != 2 {
+		t.Fatalf("expected an update for the rotation, got %d updates", updates.Load())
+	}
+}
+
+func sortedCerts(certs ...string) []string {
+	tb := NewTrustBundle()
+	_ = tb.UpdateTrustAnchor(&TrustAnchorUpdate{
+		TrustAnchorConfig: TrustAnchorConfig{Certs: certs},
+		Source:            SourceSpiffeEndpoint,
+	})
+	return tb.GetTrustBundle()
+}
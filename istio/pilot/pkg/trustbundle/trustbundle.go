@@ -17,6 +17,7 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -42,6 +43,12 @@ type TrustBundle struct {
 	mutex        sync.RWMutex
 	mergedCerts  []string
 	updatecb     func()
+	// updateMutex serializes the updates of the sources, which may come from several goroutines.
+	updateMutex sync.Mutex
+	// spiffeBundles holds the roots retrieved from the SPIFFE bundle endpoints, per trust domain.
+	spiffeBundles map[string][]*x509.Certificate
+	// deniedSerials holds the hex encoded serial numbers of the certificates that must no longer be trusted.
+	deniedSerials map[string]struct{}
 }
 
 var trustBundleLog = log.RegisterScope("trustBundle", "Workload mTLS trust bundle logs", 0)
@@ -50,6 +57,7 @@ const (
 	SourceIstioCA Source = iota
 	SourceMeshConfig
 	SourceIstioRA
+	SourceSpiffeEndpoint
 )
 
 func checkSameCerts(certs1 []string, certs2 []string) bool {
@@ -68,12 +76,15 @@ func checkSameCerts(certs1 []string, certs2 []string) bool {
 func NewTrustBundle() *TrustBundle {
 	tb := &TrustBundle{
 		sourceConfig: map[Source]TrustAnchorConfig{
-			SourceIstioCA:    {Certs: []string{}},
-			SourceMeshConfig: {Certs: []string{}},
-			SourceIstioRA:    {Certs: []string{}},
+			SourceIstioCA:        {Certs: []string{}},
+			SourceMeshConfig:     {Certs: []string{}},
+			SourceIstioRA:        {Certs: []string{}},
+			SourceSpiffeEndpoint: {Certs: []string{}},
 		},
-		mergedCerts: []string{},
-		updatecb:    nil,
+		mergedCerts:   []string{},
+		updatecb:      nil,
+		spiffeBundles: map[string][]*x509.Certificate{},
+		deniedSerials: map[string]struct{}{},
 	}
 	return tb
 }
@@ -131,6 +142,8 @@ func (tb *TrustBundle) UpdateTrustAnchor(anchorConfig *TrustAnchorUpdate) error
 	var ok bool
 	var err error
 
+	tb.updateMutex.Lock()
+	defer tb.updateMutex.Unlock()
 	cachedConfig, ok := tb.sourceConfig[anchorConfig.Source]
 	if !ok {
 		return fmt.Errorf("invalid source of TrustBundle configuration %v", anchorConfig.Source)
@@ -177,3 +190,47 @@ func (tb *TrustBundle) AddMeshConfigUpdate(cfg *meshconfig.MeshConfig) {
 		}
 	}
 }
+
+// UpdateDeniedSerialNumbers replaces the serial numbers of the certificates that must no longer be trusted, even if
+// they chain to one of the trust anchors. The serial numbers are hex encoded, as by big.Int.Text(16).
+//
+// The deny-list is enforced by istiod when verifying peer certificates and exposed on /debug/trustbundlez. It is not
+// pushed to proxies: neither the ProxyConfig sent over PCDS nor Envoy's validation context can carry a serial
+// deny-list without a CRL signed by the CA, so updates do not trigger the update callback.
+func (tb *TrustBundle) UpdateDeniedSerialNumbers(serials []string) {
+	tb.updateMutex.Lock()
+	defer tb.updateMutex.Unlock()
+	denied := make(map[string]struct{}, len(serials))
+	for _, serial := range serials {
+		denied[serial] = struct{}{}
+	}
+	tb.mutex.Lock()
+	if reflect.DeepEqual(denied, tb.deniedSerials) {
+		tb.mutex.Unlock()
+		return
+	}
+	tb.deniedSerials = denied
+	tb.mutex.Unlock()
+
+	trustBundleLog.Infof("updating denied serial numbers to %v", serials)
+}
+
+// GetDeniedSerialNumbers returns the sorted serial numbers of the certificates that must no longer be trusted.
+func (tb *TrustBundle) GetDeniedSerialNumbers() []string {
+	tb.mutex.RLock()
+	defer tb.mutex.RUnlock()
+	out := make([]string, 0, len(tb.deniedSerials))
+	for serial := range tb.deniedSerials {
+		out = append(out, serial)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// IsDenied returns true if the serial number of the certificate is denied.
+func (tb *TrustBundle) IsDenied(cert *x509.Certificate) bool {
+	tb.mutex.RLock()
+	defer tb.mutex.RUnlock()
+	_, f := tb.deniedSerials[cert.SerialNumber.Text(16)]
+	return f
+}
@@ -17,10 +17,12 @@ package trustbundle
 import (
 	"io/ioutil"
 	"path"
+	"reflect"
 	"sort"
 	"testing"
 
 	"istio.io/istio/pkg/test/env"
+	"istio.io/istio/security/pkg/pki/util"
 )
 
 func readCertFromFile(filename string) string {
@@ -178,3 +180,37 @@ func TestUpdateTrustAnchor(t *testing.T) {
 		t.Errorf("bad cert update failed. Callback value is %v", cbCounter)
 	}
 }
+
+func TestDeniedSerialNumbers(t *testing.T) {
+	tb := NewTrustBundle()
+	updates := 0
+	tb.UpdateCb(func() { updates++ })
+
+	cert, err := util.ParsePemEncodedCertificate([]byte(nonCaCert))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tb.IsDenied(cert) {
+		t.Fatalf("expected the certificate not to be denied")
+	}
+
+	tb.UpdateDeniedSerialNumbers([]string{cert.SerialNumber.Text(16), "1f"})
+	tb.UpdateDeniedSerialNumbers([]string{"1f", cert.SerialNumber.Text(16)})
+	// The deny-list is not pushed to proxies.
+	if updates != 0 {
+		t.Fatalf("expected no update, got %d", updates)
+	}
+	if !tb.IsDenied(cert) {
+		t.Fatalf("expected the certificate to be denied")
+	}
+	expected := []string{cert.SerialNumber.Text(16), "1f"}
+	sort.Strings(expected)
+	if got := tb.GetDeniedSerialNumbers(); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+
+	tb.UpdateDeniedSerialNumbers(nil)
+	if updates != 0 || tb.IsDenied(cert) {
+		t.Fatalf("expected the certificate to be trusted again")
+	}
+}
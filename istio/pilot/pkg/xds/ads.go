@@ -17,6 +17,7 @@ package xds
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"sync/atomic"
 	"time"
@@ -33,6 +34,7 @@ import (
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/util"
 	v3 "istio.io/istio/pilot/pkg/xds/v3"
+	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/spiffe"
 	"istio.io/pkg/env"
 	istiolog "istio.io/pkg/log"
@@ -202,7 +204,10 @@ func (s *DiscoveryServer) processRequest(req *discovery.DiscoveryRequest, con *C
 		return nil
 	}
 
-	if s.StatusReporter != nil {
+	// A rejected message is not distributed: the proxy keeps running the config it last accepted, so it is not
+	// reported as an event. Otherwise the distribution status and latencies would count the rejected version as
+	// applied. Rejections are reported to the NackStatusCache instead.
+	if s.StatusReporter != nil && req.ErrorDetail == nil {
 		s.StatusReporter.RegisterEvent(con.ConID, req.TypeUrl, req.ResponseNonce)
 	}
 	shouldRespond := s.shouldRespond(con, req)
@@ -275,6 +280,10 @@ func (s *DiscoveryServer) Stream(stream DiscoveryStream) error {
 	} else {
 		adsLog.Debug("Unauthenticated XDS: ", peerAddr)
 	}
+	if err := s.RateLimiter.Check(ids, peerAddr); err != nil {
+		adsLog.Warnf("Rejected XDS connection from %v with identity %v: %v", peerAddr, ids, err)
+		return err
+	}
 
 	// InitContext returns immediately if the context was already initialized.
 	if err = s.globalPushContext().InitContext(s.Env, nil, nil); err != nil {
@@ -354,9 +363,23 @@ func (s *DiscoveryServer) shouldRespond(con *Connection, request *discovery.Disc
 		if s.StatusGen != nil {
 			s.StatusGen.OnNack(con.proxy, request)
 		}
+		nack := &model.NackDetail{
+			Nonce:   request.ResponseNonce,
+			Message: request.ErrorDetail.GetMessage(),
+			Time:    time.Now(),
+		}
 		con.proxy.Lock()
-		con.proxy.WatchedResources[request.TypeUrl].NonceNacked = request.ResponseNonce
+		if wr := con.proxy.WatchedResources[request.TypeUrl]; wr != nil {
+			wr.NonceNacked = request.ResponseNonce
+			if request.ResponseNonce == wr.NonceSent {
+				nack.ConfigsUpdated = wr.ConfigsUpdatedSent
+			}
+			wr.LastNack = nack
+		}
 		con.proxy.Unlock()
+		if nacks, ok := s.StatusReporter.(NackStatusCache); ok {
+			nacks.RegisterNack(con.ConID, request.TypeUrl, nack)
+		}
 		return false
 	}
 
@@ -410,12 +433,16 @@ func (s *DiscoveryServer) shouldRespond(con *Connection, request *discovery.Disc
 	// the ack details and respond if there is a change in resource names.
 	con.proxy.Lock()
 	previousResources := con.proxy.WatchedResources[request.TypeUrl].ResourceNames
+	wasNacked := con.proxy.WatchedResources[request.TypeUrl].LastNack != nil
 	con.proxy.WatchedResources[request.TypeUrl].VersionAcked = request.VersionInfo
 	con.proxy.WatchedResources[request.TypeUrl].NonceAcked = request.ResponseNonce
 	con.proxy.WatchedResources[request.TypeUrl].NonceNacked = ""
 	con.proxy.WatchedResources[request.TypeUrl].ResourceNames = request.ResourceNames
 	con.proxy.WatchedResources[request.TypeUrl].LastRequest = request
 	con.proxy.Unlock()
+	if nacks, ok := s.StatusReporter.(NackStatusCache); ok && wasNacked {
+		nacks.RegisterNack(con.ConID, request.TypeUrl, nil)
+	}
 
 	// Envoy can send two DiscoveryRequests with same version and nonce
 	// when it detects a new resource. We should respond if they change.
@@ -858,8 +885,12 @@ func (s *DiscoveryServer) startPush(req *model.PushRequest) {
 
 func (s *DiscoveryServer) addCon(conID string, con *Connection) {
 	s.adsClientsMutex.Lock()
-	defer s.adsClientsMutex.Unlock()
 	s.adsClients[conID] = con
+	s.adsClientsMutex.Unlock()
+
+	if latencies, ok := s.StatusReporter.(DistributionLatencyCache); ok {
+		latencies.RegisterConnect(conID)
+	}
 }
 
 func (s *DiscoveryServer) removeCon(conID string) {
@@ -936,6 +967,33 @@ func (conn *Connection) Synced(typeUrl string) (bool, bool) {
 	return nacked || acked == sent, time.Since(sendTime) > features.FlowControlTimeout
 }
 
+// Nacks returns the last message of each type rejected by the proxy, keyed by short type.
+func (conn *Connection) Nacks() map[string]*NackStatus {
+	conn.proxy.RLock()
+	defer conn.proxy.RUnlock()
+	var out map[string]*NackStatus
+	for typeURL, wr := range conn.proxy.WatchedResources {
+		if wr.LastNack == nil {
+			continue
+		}
+		if out == nil {
+			out = map[string]*NackStatus{}
+		}
+		ns := &NackStatus{
+			Nonce:   wr.LastNack.Nonce,
+			Message: wr.LastNack.Message,
+			Time:    wr.LastNack.Time,
+			Active:  wr.NonceNacked == wr.LastNack.Nonce,
+		}
+		for k := range wr.LastNack.ConfigsUpdated {
+			ns.Configs = append(ns.Configs, config.Key(k.Kind.Kind, k.Name, k.Namespace))
+		}
+		sort.Strings(ns.Configs)
+		out[v3.GetShortType(typeURL)] = ns
+	}
+	return out
+}
+
 // nolint
 func (conn *Connection) NonceAcked(typeUrl string) string {
 	conn.proxy.RLock()
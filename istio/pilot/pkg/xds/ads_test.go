@@ -16,6 +16,7 @@ package xds_test
 import (
 	"fmt"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -1029,3 +1030,96 @@ func TestEdsCache(t *testing.T) {
 	assertEndpoints(ads)
 	t.Logf("endpoints: %+v", ads.GetEndpoints())
 }
+
+func TestAdsNackTracking(t *testing.T) {
+	s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{})
+	ads := s.ConnectADS().WithType(v3.ClusterType)
+	ads.RequestResponseAck(nil)
+
+	ef := model.ConfigKey{Kind: gvk.EnvoyFilter, Name: "foo", Namespace: "default"}
+	s.Discovery.AdsPushAll("v1", &model.PushRequest{
+		Full:           true,
+		Push:           s.PushContext(),
+		ConfigsUpdated: map[model.ConfigKey]struct{}{ef: {}},
+		Reason:         []model.TriggerReason{model.ConfigUpdate},
+	})
+	res := ads.ExpectResponse()
+	ads.Request(&discovery.DiscoveryRequest{ResponseNonce: res.Nonce, ErrorDetail: &status.Status{Message: "invalid cluster"}})
+
+	nacks := func() map[string]*xds.NackStatus {
+		return s.Discovery.AllClients()[0].Nacks()
+	}
+	retry.UntilSuccessOrFail(t, func() error {
+		nack := nacks()["CDS"]
+		if nack == nil {
+			return fmt.Errorf("expected a CDS nack, got %v", nacks())
+		}
+		if !nack.Active || nack.Nonce != res.Nonce || nack.Message != "invalid cluster" ||
+			!reflect.DeepEqual(nack.Configs, []string{"EnvoyFilter/default/foo"}) {
+			return fmt.Errorf("unexpected nack %+v", nack)
+		}
+		return nil
+	}, retry.Timeout(time.Second*5))
+
+	// Accepting the next push keeps the last nack, but it is no longer active
+	xds.AdsPushAll(s.Discovery)
+	res = ads.ExpectResponse()
+	ads.Request(&discovery.DiscoveryRequest{ResponseNonce: res.Nonce})
+	retry.UntilSuccessOrFail(t, func() error {
+		if nack := nacks()["CDS"]; nack == nil || nack.Active {
+			return fmt.Errorf("expected an inactive CDS nack, got %+v", nack)
+		}
+		return nil
+	}, retry.Timeout(time.Second*5))
+}
+
+// eventRecorder is a DistributionStatusCache recording the nonces of the registered events.
+type eventRecorder struct {
+	mu     sync.Mutex
+	nonces map[string]bool
+}
+
+func (r *eventRecorder) RegisterEvent(_ string, _ xds.EventType, nonce string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nonces[nonce] = true
+}
+
+func (r *eventRecorder) RegisterDisconnect(string, []xds.EventType) {}
+
+func (r *eventRecorder) QueryLastNonce(string, xds.EventType) string {
+	return ""
+}
+
+func (r *eventRecorder) registered(nonce string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.nonces[nonce]
+}
+
+func TestAdsNackNotRegisteredAsEvent(t *testing.T) {
+	s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{})
+	events := &eventRecorder{nonces: map[string]bool{}}
+	s.Discovery.StatusReporter = events
+	ads := s.ConnectADS().WithType(v3.ClusterType)
+	ads.RequestResponseAck(nil)
+
+	// The proxy keeps the previous config when it rejects a message, so its version is not distributed.
+	xds.AdsPushAll(s.Discovery)
+	nacked := ads.ExpectResponse()
+	ads.Request(&discovery.DiscoveryRequest{ResponseNonce: nacked.Nonce, ErrorDetail: &status.Status{Message: "invalid cluster"}})
+
+	xds.AdsPushAll(s.Discovery)
+	acked := ads.ExpectResponse()
+	ads.Request(&discovery.DiscoveryRequest{ResponseNonce: acked.Nonce})
+	retry.UntilSuccessOrFail(t, func() error {
+		if !events.registered(acked.Nonce) {
+			return fmt.Errorf("expected an event for the acked nonce %v", acked.Nonce)
+		}
+		return nil
+	}, retry.Timeout(time.Second*5))
+	// Requests are processed in order, so the nack was processed before the ack.
+	if events.registered(nacked.Nonce) {
+		t.Fatalf("unexpected event for the nacked nonce %v", nacked.Nonce)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
+	"istio.io/istio/pkg/ratelimit"
+	"istio.io/istio/pkg/security"
+)
+
+type fakeAuthenticator struct {
+	identities []string
+}
+
+func (f fakeAuthenticator) Authenticate(context.Context) (*security.Caller, error) {
+	return &security.Caller{Identities: f.identities}, nil
+}
+
+func (fakeAuthenticator) AuthenticatorType() string {
+	return "fake"
+}
+
+func TestStreamRateLimit(t *testing.T) {
+	authPlaintext = true
+	defer func() { authPlaintext = false }()
+
+	s := NewFakeDiscoveryServer(t, FakeOptions{})
+	s.Discovery.Authenticators = []security.Authenticator{
+		fakeAuthenticator{identities: []string{"spiffe://cluster.local/ns/default/sa/restarting"}},
+	}
+	s.Discovery.RateLimiter = ratelimit.New("xds", ratelimit.Config{IdentityQPS: 0.001, Burst: 1})
+
+	s.ConnectADS().WithType(v3.ClusterType).RequestResponseAck(nil)
+
+	// The reconnect of the same identity exceeds its rate.
+	err := s.ConnectADS().ExpectError()
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted, got %v", err)
+	}
+}
@@ -35,6 +35,7 @@ var skippedCdsConfigs = map[config.GroupVersionKind]struct{}{
 	gvk.AuthorizationPolicy:   {},
 	gvk.RequestAuthentication: {},
 	gvk.Secret:                {},
+	gvk.ProxyConfig:           {},
 }
 
 // Map all configs that impacts CDS for gateways.
@@ -113,6 +113,20 @@ type SyncStatus struct {
 	RouteAcked    string `json:"route_acked,omitempty"`
 	EndpointSent  string `json:"endpoint_sent,omitempty"`
 	EndpointAcked string `json:"endpoint_acked,omitempty"`
+	// Nacks holds the last message of each type rejected by the proxy, keyed by the short type, such as LDS.
+	Nacks map[string]*NackStatus `json:"nacks,omitempty"`
+}
+
+// NackStatus describes the last message of a type rejected by a proxy.
+type NackStatus struct {
+	Nonce   string    `json:"nonce,omitempty"`
+	Message string    `json:"message,omitempty"`
+	Time    time.Time `json:"time"`
+	// Configs holds the keys of the configs updated since the proxy last accepted a message of the type, which
+	// likely caused the rejection.
+	Configs []string `json:"configs,omitempty"`
+	// Active is true until the proxy accepts a message of the type again.
+	Active bool `json:"active,omitempty"`
 }
 
 // SyncedVersions shows what resourceVersion of a given resource has been acked by Envoy.
@@ -123,6 +137,19 @@ type SyncedVersions struct {
 	RouteVersion    string `json:"route_acked,omitempty"`
 }
 
+// DistributionLatency shows how long a generation of a resource took to be acknowledged by the proxies connected
+// to a Pilot instance.
+type DistributionLatency struct {
+	Resource   string `json:"resource"`
+	Generation string `json:"generation"`
+	// Start is the time the generation was written to the ledger.
+	Start time.Time `json:"start"`
+	// Latencies holds, for each proxy that acknowledged the generation, the time from Start to its acknowledgement.
+	Latencies []time.Duration `json:"latencies,omitempty"`
+	// Stragglers holds the connections that were connected at Start, but have not acknowledged the generation yet.
+	Stragglers []string `json:"stragglers,omitempty"`
+}
+
 // InitDebug initializes the debug handlers and adds a debug in-memory registry.
 func (s *DiscoveryServer) InitDebug(mux *http.ServeMux, sctl *aggregate.Controller, enableProfiling bool, fetchWebhook func() map[string]string) {
 	// For debugging and load testing v2 we add an memory registry.
@@ -167,6 +194,12 @@ func (s *DiscoveryServer) AddDebugHandlers(mux *http.ServeMux, enableProfiling b
 
 	s.addDebugHandler(mux, "/debug/syncz", "Synchronization status of all Envoys connected to this Pilot instance", s.Syncz)
 	s.addDebugHandler(mux, "/debug/config_distribution", "Version status of all Envoys connected to this Pilot instance", s.distributedVersions)
+	s.addDebugHandler(mux, "/debug/distribution_latency", "Distribution latency of config to the Envoys connected to this Pilot instance",
+		s.distributionLatency)
+	s.addDebugHandler(mux, "/debug/carotationz", "Status of the plugged-in CA cert rotation", s.caRotationz)
+	s.addDebugHandler(mux, "/debug/trustbundlez", "Trust anchors and denied certificate serial numbers of the mesh", s.trustBundlez)
+	s.addDebugHandler(mux, "/debug/jwksz", "Status of the JWKS fetched for RequestAuthentication issuers", s.jwksz)
+	s.addDebugHandler(mux, "/debug/clusterz", "Status of the remote clusters", s.clusterz)
 
 	s.addDebugHandler(mux, "/debug/registryz", "Debug support for registry", s.registryz)
 	s.addDebugHandler(mux, "/debug/endpointz", "Debug support for endpoints", s.endpointz)
@@ -210,6 +243,7 @@ func (s *DiscoveryServer) Syncz(w http.ResponseWriter, _ *http.Request) {
 				RouteAcked:    con.NonceAcked(v3.RouteType),
 				EndpointSent:  con.NonceSent(v3.EndpointType),
 				EndpointAcked: con.NonceAcked(v3.EndpointType),
+				Nacks:         con.Nacks(),
 			})
 		}
 	}
@@ -353,6 +387,97 @@ func (s *DiscoveryServer) distributedVersions(w http.ResponseWriter, req *http.R
 	}
 }
 
+// distributionLatency reports how long the latest generation of the resource given by the 'resource' querystring
+// parameter, or of all tracked resources, took to be acknowledged by the connected proxies.
+func (s *DiscoveryServer) distributionLatency(w http.ResponseWriter, req *http.Request) {
+	cache, ok := s.StatusReporter.(DistributionLatencyCache)
+	if !features.EnableDistributionTracking || !ok {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = fmt.Fprint(w, "Pilot Version tracking is disabled.  Please set the "+
+			"PILOT_ENABLE_CONFIG_DISTRIBUTION_TRACKING environment variable to true to enable.")
+		return
+	}
+	latencies := cache.DistributionLatencies(req.URL.Query().Get("resource"))
+	out, err := json.MarshalIndent(latencies, "", "    ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to marshal distribution latency information: %v", err)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
+// caRotationz reports the phase of the plugged-in CA cert rotation and the root distribution progress.
+func (s *DiscoveryServer) caRotationz(w http.ResponseWriter, _ *http.Request) {
+	if s.CARotationStatus == nil {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = fmt.Fprint(w, "Plugged-in CA cert rotation is disabled.")
+		return
+	}
+	out, err := json.MarshalIndent(s.CARotationStatus(), "", "    ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to marshal CA rotation status: %v", err)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
+// trustBundlez reports the trust anchors of the mesh and the serial numbers of the certificates that are denied even
+// though they chain to one of them.
+func (s *DiscoveryServer) trustBundlez(w http.ResponseWriter, _ *http.Request) {
+	tb := s.Env.TrustBundle
+	if tb == nil {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = fmt.Fprint(w, "Trust bundle is disabled.")
+		return
+	}
+	out, err := json.MarshalIndent(struct {
+		TrustAnchors        []string `json:"trustAnchors"`
+		DeniedSerialNumbers []string `json:"deniedSerialNumbers"`
+	}{
+		TrustAnchors:        tb.GetTrustBundle(),
+		DeniedSerialNumbers: tb.GetDeniedSerialNumbers(),
+	}, "", "    ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to marshal trust bundle: %v", err)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
+// clusterz reports the state, last sync time and number of objects of the remote clusters.
+func (s *DiscoveryServer) clusterz(w http.ResponseWriter, _ *http.Request) {
+	if s.RemoteClusterStatus == nil {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = fmt.Fprint(w, "Multicluster is disabled.")
+		return
+	}
+	out, err := json.MarshalIndent(s.RemoteClusterStatus(), "", "    ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to marshal remote cluster status: %v", err)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
+func (s *DiscoveryServer) jwksz(w http.ResponseWriter, _ *http.Request) {
+	out, err := json.MarshalIndent(model.GetJwtKeyResolver().Status(), "", "    ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, "unable to marshal JWKS status: %v", err)
+		return
+	}
+	w.Header().Add("Content-Type", "application/json")
+	_, _ = w.Write(out)
+}
+
 // The Config Version is only used as the nonce prefix, but we can reconstruct it because is is a
 // b64 encoding of a 64 bit array, which will always be 12 chars in length.
 // len = ceil(bitlength/(2^6))+1
@@ -557,8 +682,13 @@ func (s *DiscoveryServer) ConfigDump(w http.ResponseWriter, req *http.Request) {
 // configDump converts the connection internal state into an Envoy Admin API config dump proto
 // It is used in debugging to create a consistent object for comparison between Envoy and Pilot outputs
 func (s *DiscoveryServer) configDump(conn *Connection) (*adminapi.ConfigDump, error) {
+	return s.proxyConfigDump(conn.proxy, conn)
+}
+
+// proxyConfigDump generates the config dump of the proxy, for the resources watched by the connection.
+func (s *DiscoveryServer) proxyConfigDump(proxy *model.Proxy, conn *Connection) (*adminapi.ConfigDump, error) {
 	dynamicActiveClusters := make([]*adminapi.ClustersConfigDump_DynamicCluster, 0)
-	clusters := s.ConfigGenerator.BuildClusters(conn.proxy, s.globalPushContext())
+	clusters := s.ConfigGenerator.BuildClusters(proxy, s.globalPushContext())
 
 	for _, cs := range clusters {
 		cluster, err := ptypes.MarshalAny(cs)
@@ -576,7 +706,7 @@ func (s *DiscoveryServer) configDump(conn *Connection) (*adminapi.ConfigDump, er
 	}
 
 	dynamicActiveListeners := make([]*adminapi.ListenersConfigDump_DynamicListener, 0)
-	listeners := s.ConfigGenerator.BuildListeners(conn.proxy, s.globalPushContext())
+	listeners := s.ConfigGenerator.BuildListeners(proxy, s.globalPushContext())
 	for _, cs := range listeners {
 		listener, err := ptypes.MarshalAny(cs)
 		if err != nil {
@@ -595,7 +725,7 @@ func (s *DiscoveryServer) configDump(conn *Connection) (*adminapi.ConfigDump, er
 		return nil, err
 	}
 
-	routes := s.ConfigGenerator.BuildHTTPRoutes(conn.proxy, s.globalPushContext(), conn.Routes())
+	routes := s.ConfigGenerator.BuildHTTPRoutes(proxy, s.globalPushContext(), conn.Routes())
 	routeConfigAny := util.MessageToAny(&adminapi.RoutesConfigDump{})
 	if len(routes) > 0 {
 		dynamicRouteConfig := make([]*adminapi.RoutesConfigDump_DynamicRouteConfig, 0)
@@ -614,7 +744,7 @@ func (s *DiscoveryServer) configDump(conn *Connection) (*adminapi.ConfigDump, er
 
 	secretsDump := &adminapi.SecretsConfigDump{}
 	if s.Generators[v3.SecretType] != nil {
-		secrets, _ := s.Generators[v3.SecretType].Generate(conn.proxy, s.globalPushContext(), conn.Watched(v3.SecretType), nil)
+		secrets, _ := s.Generators[v3.SecretType].Generate(proxy, s.globalPushContext(), conn.Watched(v3.SecretType), nil)
 		if len(secrets) > 0 {
 			for _, secretAny := range secrets {
 				secret := &tls.Secret{}
@@ -21,7 +21,10 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	adminapi "github.com/envoyproxy/go-control-plane/envoy/admin/v3"
+	cluster "github.com/envoyproxy/go-control-plane/envoy/config/cluster/v3"
 	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	"github.com/golang/protobuf/ptypes"
 
 	"istio.io/istio/istioctl/pkg/util/configdump"
 	"istio.io/istio/pilot/pkg/model"
@@ -178,6 +181,67 @@ func TestConfigDump(t *testing.T) {
 	}
 }
 
+func TestSidecarScopeDebug(t *testing.T) {
+	s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{ConfigString: `
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: a
+  namespace: default
+spec:
+  hosts:
+  - a.example.com
+  ports:
+  - name: http
+    number: 80
+    protocol: HTTP
+  resolution: DNS
+---
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: b
+  namespace: other
+spec:
+  hosts:
+  - b.example.com
+  ports:
+  - name: http
+    number: 80
+    protocol: HTTP
+  resolution: DNS
+`})
+	ads := s.ConnectADS().WithType(v3.ClusterType)
+	ads.RequestResponseAck(nil)
+
+	debug := s.ConnectADS().
+		WithID("sidecar~1.1.1.2~debug.default~default.svc.cluster.local").
+		WithMetadata(model.NodeMetadata{Generator: "event"}).
+		WithType(xds.TypeDebugSidecarScope)
+	res := debug.RequestResponseAck(&discovery.DiscoveryRequest{
+		ResourceNames: []string{"test.default", "default/a.example.com"},
+	})
+	wrapper := &configdump.Wrapper{ConfigDump: &adminapi.ConfigDump{Configs: res.Resources}}
+	clusters, err := wrapper.GetDynamicClusterDump(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, c := range clusters.DynamicActiveClusters {
+		cl := &cluster.Cluster{}
+		if err := ptypes.UnmarshalAny(c.Cluster, cl); err != nil {
+			t.Fatal(err)
+		}
+		names[cl.Name] = true
+	}
+	if !names["outbound|80||a.example.com"] {
+		t.Errorf("expected the cluster of the egress host, got %v", names)
+	}
+	if names["outbound|80||b.example.com"] {
+		t.Errorf("unexpected cluster of a host outside the egress hosts, got %v", names)
+	}
+}
+
 func getConfigDump(t *testing.T, s *xds.DiscoveryServer, proxyID string, wantCode int) *configdump.Wrapper {
 	path := "/config_dump"
 	if proxyID != "" {
@@ -35,6 +35,7 @@ import (
 	"istio.io/istio/pilot/pkg/serviceregistry/memory"
 	"istio.io/istio/pilot/pkg/util/sets"
 	v3 "istio.io/istio/pilot/pkg/xds/v3"
+	"istio.io/istio/pkg/ratelimit"
 	"istio.io/istio/pkg/security"
 )
 
@@ -140,6 +141,15 @@ type DiscoveryServer struct {
 
 	// Cache for XDS resources
 	Cache model.XdsCache
+
+	// CARotationStatus returns the status of the plugged-in CA cert rotation, if enabled.
+	CARotationStatus func() interface{}
+
+	// RemoteClusterStatus returns the status of the remote clusters, if multicluster is enabled.
+	RemoteClusterStatus func() interface{}
+
+	// RateLimiter, if set, limits the XDS connections of each caller.
+	RateLimiter *ratelimit.Limiter
 }
 
 // EndpointShards holds the set of endpoint shards of a service. Registries update
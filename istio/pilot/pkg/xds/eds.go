@@ -56,7 +56,8 @@ func (s *DiscoveryServer) UpdateServiceShards(push *model.PushContext) error {
 			}
 			endpoints := make([]*model.IstioEndpoint, 0)
 			for _, port := range svc.Ports {
-				if port.Protocol == protocol.UDP {
+				// UDP ports share the instances of a port of another protocol with the same value, if any.
+				if _, f := svc.Ports.GetByPort(port.Port); f && port.Protocol == protocol.UDP {
 					continue
 				}
 
@@ -266,12 +267,16 @@ func (s *DiscoveryServer) llbEndpointAndOptionsForCluster(b EndpointBuilder) ([]
 	// against such behavior and returns nil. When the updated cluster warms up in Envoy, it would update with new endpoints
 	// automatically.
 	// Gateways use EDS for Passthrough cluster. So we should allow Passthrough here.
-	if b.service.Resolution == model.DNSLB {
+	if b.service.Resolution == model.DNSLB || b.service.Resolution == model.DynamicDNSLB {
 		adsLog.Infof("cluster %s in eds cluster, but its resolution now is updated to %v, skipping it.", b.clusterName, b.service.Resolution)
 		return nil, fmt.Errorf("cluster %s in eds cluster", b.clusterName)
 	}
 
 	svcPort, f := b.service.Ports.GetByPort(b.port)
+	if !f {
+		// A UDP port has its own endpoints if no port of another protocol has the same value.
+		svcPort, f = b.service.Ports.GetByPortAndProtocol(b.port, protocol.UDP)
+	}
 	if !f {
 		// Shouldn't happen here
 		adsLog.Debugf("can not find the service port %d for cluster %s", b.port, b.clusterName)
@@ -305,6 +310,7 @@ func (s *DiscoveryServer) generateEndpoints(b EndpointBuilder) *endpoint.Cluster
 	llbOpts = b.ApplyTunnelSetting(llbOpts, b.tunnelType)
 
 	l := b.createClusterLoadAssignment(llbOpts)
+	failoverPriority := b.failoverPriorityFor(llbOpts)
 
 	// If locality aware routing is enabled, prioritize endpoints or set their lb weight.
 	// Failover should only be enabled when there is an outlier detection, otherwise Envoy
@@ -314,7 +320,7 @@ func (s *DiscoveryServer) generateEndpoints(b EndpointBuilder) *endpoint.Cluster
 	if lbSetting != nil {
 		// Make a shallow copy of the cla as we are mutating the endpoints with priorities/weights relative to the calling proxy
 		l = util.CloneClusterLoadAssignment(l)
-		loadbalancer.ApplyLocalityLBSetting(b.locality, l, lbSetting, enableFailover)
+		loadbalancer.ApplyLocalityLBSetting(b.locality, l, lbSetting, enableFailover, failoverPriority)
 	}
 	return l
 }
@@ -335,6 +341,7 @@ var skippedEdsConfigs = map[config.GroupVersionKind]struct{}{
 	gvk.AuthorizationPolicy:   {},
 	gvk.RequestAuthentication: {},
 	gvk.Secret:                {},
+	gvk.ProxyConfig:           {},
 }
 
 func edsNeedsPush(updates model.XdsUpdates) bool {
@@ -30,6 +30,7 @@ import (
 	"testing"
 	"time"
 
+	core "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
 	endpoint "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
 	uatomic "go.uber.org/atomic"
 
@@ -325,6 +326,140 @@ func TestEdsWeightedServiceEntry(t *testing.T) {
 	}
 }
 
+func TestEdsFailoverPriority(t *testing.T) {
+	config := `
+apiVersion: networking.istio.io/v1alpha3
+kind: ServiceEntry
+metadata:
+  name: failover
+  namespace: default
+spec:
+  hosts:
+  - failover.static.svc.cluster.local
+  ports:
+  - number: 80
+    name: http
+    protocol: HTTP
+  resolution: STATIC
+  endpoints:
+  - address: 1.1.1.1
+    locality: region1/zone1/subzone1
+    network: n1
+    labels:
+      topology.istio.io/cluster: c1
+  - address: 1.1.1.2
+    locality: region1/zone1/subzone1
+    network: n2
+    labels:
+      topology.istio.io/cluster: c2
+  - address: 1.1.1.3
+    locality: region1/zone2/subzone1
+    network: n1
+    labels:
+      topology.istio.io/cluster: c2
+  - address: 1.1.1.4
+    locality: region1/zone2/subzone1
+    network: n1
+    labels:
+      topology.istio.io/cluster: c1
+---
+apiVersion: networking.istio.io/v1alpha3
+kind: DestinationRule
+metadata:
+  name: failover
+  namespace: default
+  annotations:
+    networking.istio.io/failoverPriority: "{{ .FailoverPriority }}"
+spec:
+  host: failover.static.svc.cluster.local
+  trafficPolicy:
+    loadBalancer:
+      localityLbSetting:
+        enabled: true
+{{- if not .NoOutlierDetection }}
+    outlierDetection:
+      consecutive5xxErrors: 5
+{{- end }}
+`
+	cases := []struct {
+		name               string
+		failoverPriority   string
+		noOutlierDetection bool
+		expected           map[string]uint32
+	}{
+		{
+			name: "locality",
+			expected: map[string]uint32{
+				"1.1.1.1": 0,
+				"1.1.1.2": 0,
+				"1.1.1.3": 1,
+				"1.1.1.4": 1,
+			},
+		},
+		{
+			name:             "network and cluster before locality",
+			failoverPriority: "topology.istio.io/network,topology.istio.io/cluster",
+			expected: map[string]uint32{
+				"1.1.1.1": 0,
+				"1.1.1.4": 1,
+				"1.1.1.3": 2,
+				"1.1.1.2": 3,
+			},
+		},
+		{
+			name:             "network before locality",
+			failoverPriority: "topology.istio.io/network",
+			expected: map[string]uint32{
+				"1.1.1.1": 0,
+				"1.1.1.3": 1,
+				"1.1.1.4": 1,
+				"1.1.1.2": 2,
+			},
+		},
+		{
+			// Like locality failover, the priorities are only set with outlier detection.
+			name:               "without outlier detection",
+			failoverPriority:   "topology.istio.io/network,topology.istio.io/cluster",
+			noOutlierDetection: true,
+			expected: map[string]uint32{
+				"1.1.1.1": 0,
+				"1.1.1.2": 0,
+				"1.1.1.3": 0,
+				"1.1.1.4": 0,
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{
+				ConfigString: config,
+				ConfigTemplateInput: map[string]interface{}{
+					"FailoverPriority":   tt.failoverPriority,
+					"NoOutlierDetection": tt.noOutlierDetection,
+				},
+			})
+			proxy := s.SetupProxy(&model.Proxy{
+				Metadata: &model.NodeMetadata{Network: "n1", ClusterID: "c1"},
+				Locality: &core.Locality{Region: "region1", Zone: "zone1", SubZone: "subzone1"},
+			})
+			got := map[string]uint32{}
+			for _, cla := range s.Endpoints(proxy) {
+				if cla.ClusterName != "outbound|80||failover.static.svc.cluster.local" {
+					continue
+				}
+				for _, llb := range cla.Endpoints {
+					for _, e := range llb.LbEndpoints {
+						got[e.GetEndpoint().Address.GetSocketAddress().Address] = llb.Priority
+					}
+				}
+			}
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("Expected priorities %v got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
 var (
 	watchEds = []string{v3.ClusterType, v3.EndpointType}
 	watchAll = []string{v3.ClusterType, v3.EndpointType, v3.ListenerType, v3.RouteType}
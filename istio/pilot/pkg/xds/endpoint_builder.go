@@ -23,12 +23,16 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/wrappers"
 
+	"istio.io/api/label"
 	networkingapi "istio.io/api/networking/v1alpha3"
+	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking"
+	"istio.io/istio/pilot/pkg/networking/core/v1alpha3/loadbalancer"
 	"istio.io/istio/pilot/pkg/networking/util"
 	"istio.io/istio/pkg/config"
 	"istio.io/istio/pkg/config/host"
+	"istio.io/istio/pkg/config/labels"
 	"istio.io/istio/pkg/config/schema/gvk"
 )
 
@@ -62,6 +66,10 @@ type EndpointBuilder struct {
 	destinationRule *config.Config
 	service         *model.Service
 	tunnelType      networking.TunnelType
+	// failoverPriority are the labels used, in order, to prioritize the endpoints on failover.
+	failoverPriority []string
+	// proxyLabels are the labels of the proxy, only set with failoverPriority.
+	proxyLabels labels.Instance
 
 	// These fields are provided for convenience only
 	subsetName string
@@ -73,15 +81,23 @@ type EndpointBuilder struct {
 func NewEndpointBuilder(clusterName string, proxy *model.Proxy, push *model.PushContext) EndpointBuilder {
 	_, subsetName, hostname, port := model.ParseSubsetKey(clusterName)
 	svc := push.ServiceForHostname(proxy, hostname)
+	dr := push.DestinationRule(proxy, svc)
+	failoverPriority := loadbalancer.GetFailoverPriority(features.LocalityFailoverPriority, dr)
+	var proxyLabels labels.Instance
+	if len(failoverPriority) > 0 {
+		proxyLabels = topologyLabels(proxy.Metadata.Labels, proxy.Metadata.Network, proxy.Metadata.ClusterID)
+	}
 	return EndpointBuilder{
-		clusterName:     clusterName,
-		network:         proxy.Metadata.Network,
-		networkView:     model.GetNetworkView(proxy),
-		clusterID:       proxy.Metadata.ClusterID,
-		locality:        proxy.Locality,
-		service:         svc,
-		destinationRule: push.DestinationRule(proxy, svc),
-		tunnelType:      GetTunnelBuilderType(clusterName, proxy, push),
+		clusterName:      clusterName,
+		network:          proxy.Metadata.Network,
+		networkView:      model.GetNetworkView(proxy),
+		clusterID:        proxy.Metadata.ClusterID,
+		locality:         proxy.Locality,
+		service:          svc,
+		destinationRule:  dr,
+		tunnelType:       GetTunnelBuilderType(clusterName, proxy, push),
+		failoverPriority: failoverPriority,
+		proxyLabels:      proxyLabels,
 
 		push:       push,
 		subsetName: subsetName,
@@ -114,9 +130,55 @@ func (b EndpointBuilder) Key() string {
 		sort.Strings(nv)
 		params = append(params, nv...)
 	}
+	// The priorities depend on the values of the failover priority labels of the proxy.
+	for _, l := range b.failoverPriority {
+		params = append(params, l+"="+b.proxyLabels[l])
+	}
 	return strings.Join(params, "~")
 }
 
+// topologyLabels returns the labels of a proxy or an endpoint, with the network and cluster labels set from its
+// network and cluster ID, so they can be used as failover priority labels.
+func topologyLabels(in labels.Instance, network, clusterID string) labels.Instance {
+	out := make(labels.Instance, len(in)+2)
+	for k, v := range in {
+		out[k] = v
+	}
+	if _, f := out[label.TopologyNetwork.Name]; !f && network != "" {
+		out[label.TopologyNetwork.Name] = network
+	}
+	if _, f := out[label.TopologyCluster.Name]; !f && clusterID != "" {
+		out[label.TopologyCluster.Name] = clusterID
+	}
+	return out
+}
+
+// failoverPriorityLabels returns the labels of the endpoint compared with the proxy ones on failover, or nil if the
+// endpoints are not prioritized by labels.
+func (b *EndpointBuilder) failoverPriorityLabels(ep *model.IstioEndpoint) labels.Instance {
+	if len(b.failoverPriority) == 0 {
+		return nil
+	}
+	return topologyLabels(ep.Labels, ep.Network, ep.Locality.ClusterID)
+}
+
+// failoverPriorityFor returns the failover priority of the endpoints of the ClusterLoadAssignment built from the
+// LocLbEndpointsAndOptions, or nil if the endpoints are not prioritized by labels.
+func (b *EndpointBuilder) failoverPriorityFor(llbOpts []*LocLbEndpointsAndOptions) *loadbalancer.FailoverPriority {
+	if len(b.failoverPriority) == 0 {
+		return nil
+	}
+	endpointLabels := make([][]labels.Instance, 0, len(llbOpts))
+	for _, l := range llbOpts {
+		endpointLabels = append(endpointLabels, l.endpointLabels)
+	}
+	return &loadbalancer.FailoverPriority{
+		Labels:         b.failoverPriority,
+		ProxyLabels:    b.proxyLabels,
+		EndpointLabels: endpointLabels,
+	}
+}
+
 // MultiNetworkConfigured determines if we have gateways to use for building cross-network endpoints.
 func (b *EndpointBuilder) MultiNetworkConfigured() bool {
 	return b.push.NetworkGateways() != nil && len(b.push.NetworkGateways()) > 0
@@ -188,6 +250,9 @@ type LocLbEndpointsAndOptions struct {
 	llbEndpoints endpoint.LocalityLbEndpoints
 	// The runtime information of the LbEndpoint slice. Each LbEndpoint has individual metadata at the same index.
 	tunnelMetadata []EndpointTunnelApplier
+	// The labels compared with the proxy ones on failover. Each LbEndpoint has its labels at the same index,
+	// or nil if the endpoints are not prioritized by labels.
+	endpointLabels []labels.Instance
 }
 
 // Return prefer H2 tunnel metadata.
@@ -198,14 +263,24 @@ func MakeTunnelApplier(le *endpoint.LbEndpoint, tunnelOpt networking.TunnelAbili
 	return &EndpointNoTunnelApplier{}
 }
 
-func (e *LocLbEndpointsAndOptions) append(le *endpoint.LbEndpoint, tunnelOpt networking.TunnelAbility) {
+func (e *LocLbEndpointsAndOptions) append(le *endpoint.LbEndpoint, tunnelOpt networking.TunnelAbility, epLabels labels.Instance) {
 	e.llbEndpoints.LbEndpoints = append(e.llbEndpoints.LbEndpoints, le)
 	e.tunnelMetadata = append(e.tunnelMetadata, MakeTunnelApplier(le, tunnelOpt))
+	e.endpointLabels = append(e.endpointLabels, epLabels)
 }
 
-func (e *LocLbEndpointsAndOptions) emplace(le *endpoint.LbEndpoint, tunnelMetadata EndpointTunnelApplier) {
+func (e *LocLbEndpointsAndOptions) emplace(le *endpoint.LbEndpoint, tunnelMetadata EndpointTunnelApplier, epLabels labels.Instance) {
 	e.llbEndpoints.LbEndpoints = append(e.llbEndpoints.LbEndpoints, le)
 	e.tunnelMetadata = append(e.tunnelMetadata, tunnelMetadata)
+	e.endpointLabels = append(e.endpointLabels, epLabels)
+}
+
+// labelsAt returns the labels of the LbEndpoint at the index, if any.
+func (e *LocLbEndpointsAndOptions) labelsAt(i int) labels.Instance {
+	if i < len(e.endpointLabels) {
+		return e.endpointLabels[i]
+	}
+	return nil
 }
 
 func (e *LocLbEndpointsAndOptions) refreshWeight() {
@@ -279,13 +354,14 @@ func (b *EndpointBuilder) buildLocalityLbEndpointsFromShards(
 						LbEndpoints: make([]*endpoint.LbEndpoint, 0, len(endpoints)),
 					},
 					make([]EndpointTunnelApplier, 0, len(endpoints)),
+					nil,
 				}
 				localityEpMap[ep.Locality.Label] = locLbEps
 			}
 			if ep.EnvoyEndpoint == nil {
 				ep.EnvoyEndpoint = buildEnvoyLbEndpoint(ep)
 			}
-			locLbEps.append(ep.EnvoyEndpoint, ep.TunnelAbility)
+			locLbEps.append(ep.EnvoyEndpoint, ep.TunnelAbility, b.failoverPriorityLabels(ep))
 		}
 	}
 	shards.mutex.Unlock()
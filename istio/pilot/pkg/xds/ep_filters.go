@@ -71,7 +71,7 @@ func (b *EndpointBuilder) EndpointsByNetworkFilter(endpoints []*LocLbEndpointsAn
 				clonedLbEp.LoadBalancingWeight = &wrappers.UInt32Value{
 					Value: uint32(multiples),
 				}
-				lbEndpoints.emplace(clonedLbEp, ep.tunnelMetadata[i])
+				lbEndpoints.emplace(clonedLbEp, ep.tunnelMetadata[i], ep.labelsAt(i))
 			} else {
 				if !b.canViewNetwork(epNetwork) {
 					continue
@@ -121,7 +121,7 @@ func (b *EndpointBuilder) EndpointsByNetworkFilter(endpoints []*LocLbEndpointsAn
 				// TODO: figure out a way to extract locality data from the gateway public endpoints in meshNetworks
 				gwEp.Metadata = util.BuildLbEndpointMetadata(network, model.IstioMutualTLSModeLabel, "", "", b.clusterID, labels.Instance{})
 				// Currently gateway endpoint does not support tunnel.
-				lbEndpoints.append(gwEp, networking.MakeTunnelAbility())
+				lbEndpoints.append(gwEp, networking.MakeTunnelAbility(), b.gatewayFailoverPriorityLabels(network))
 			}
 		}
 
@@ -133,6 +133,15 @@ func (b *EndpointBuilder) EndpointsByNetworkFilter(endpoints []*LocLbEndpointsAn
 	return filtered
 }
 
+// gatewayFailoverPriorityLabels returns the labels of the gateway endpoint of a network compared with the proxy ones
+// on failover, or nil if the endpoints are not prioritized by labels.
+func (b *EndpointBuilder) gatewayFailoverPriorityLabels(network string) labels.Instance {
+	if len(b.failoverPriority) == 0 {
+		return nil
+	}
+	return topologyLabels(nil, network, "")
+}
+
 // TODO: remove this, filtering should be done before generating the config, and
 // network metadata should not be included in output. A node only receives endpoints
 // in the same network as itself - so passing an network meta, with exactly
@@ -14,7 +14,10 @@
 
 package xds
 
-import v3 "istio.io/istio/pilot/pkg/xds/v3"
+import (
+	"istio.io/istio/pilot/pkg/model"
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
+)
 
 // EventType represents the type of object we are tracking, mapping to envoy TypeUrl.
 type EventType = string
@@ -37,3 +40,21 @@ type DistributionStatusCache interface {
 	RegisterDisconnect(s string, types []EventType)
 	QueryLastNonce(conID string, eventType EventType) (noncePrefix string)
 }
+
+// DistributionLatencyCache is implemented by DistributionStatusCaches that also track how long config takes to be
+// acknowledged by the proxies.
+type DistributionLatencyCache interface {
+	// RegisterConnect notifies the implementer of a new connection, which receives the config written before with
+	// its initial config.
+	RegisterConnect(conID string)
+	// DistributionLatencies returns the latency of the latest generation of the resource with the given key, as
+	// returned by config.Key, or of all tracked resources if the key is empty.
+	DistributionLatencies(key string) []DistributionLatency
+}
+
+// NackStatusCache is implemented by DistributionStatusCaches that also track the config rejected by proxies.
+type NackStatusCache interface {
+	// RegisterNack notifies the implementer that the connection rejected the last message of the type. A nil
+	// detail notifies that the connection accepted a message of the type again.
+	RegisterNack(conID string, eventType EventType, detail *model.NackDetail)
+}
@@ -121,6 +121,11 @@ func (s *DiscoveryServer) pushXds(con *Connection, push *model.PushContext,
 		recordSendError(w.TypeUrl, con.ConID, err)
 		return err
 	}
+	con.proxy.Lock()
+	if wr := con.proxy.WatchedResources[w.TypeUrl]; wr != nil {
+		wr.ConfigsUpdatedSent = configsUpdatedSent(wr, req)
+	}
+	con.proxy.Unlock()
 
 	// Some types handle logs inside Generate, skip them here
 	if _, f := SkipLogTypes[w.TypeUrl]; !f {
@@ -136,6 +141,27 @@ func (s *DiscoveryServer) pushXds(con *Connection, push *model.PushContext,
 	return nil
 }
 
+// configsUpdatedSent returns the configs updated by the push request, and those of the previous pushes if their
+// message was rejected, as the rejected config is likely to still be part of the new message.
+func configsUpdatedSent(wr *model.WatchedResource, req *model.PushRequest) map[model.ConfigKey]struct{} {
+	var updated map[model.ConfigKey]struct{}
+	if req != nil {
+		updated = req.ConfigsUpdated
+	}
+	if wr.NonceNacked == "" || len(wr.ConfigsUpdatedSent) == 0 {
+		// The map of the request is not modified after the push, so it can be shared.
+		return updated
+	}
+	out := make(map[model.ConfigKey]struct{}, len(wr.ConfigsUpdatedSent)+len(updated))
+	for k := range wr.ConfigsUpdatedSent {
+		out[k] = struct{}{}
+	}
+	for k := range updated {
+		out[k] = struct{}{}
+	}
+	return out
+}
+
 func ResourceSize(r model.Resources) int {
 	// Approximate size by looking at the Any marshaled size. This avoids high cost
 	// proto.Size, at the expense of slightly under counting.
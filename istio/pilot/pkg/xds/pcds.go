@@ -15,9 +15,15 @@
 package xds
 
 import (
+	"sort"
+	"time"
+
 	mesh "istio.io/api/mesh/v1alpha1"
 	"istio.io/istio/pilot/pkg/model"
 	tb "istio.io/istio/pilot/pkg/trustbundle"
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
+	"istio.io/istio/pkg/config/labels"
+	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/util/gogo"
 )
 
@@ -43,20 +49,58 @@ func pcdsNeedsPush(req *model.PushRequest) bool {
 		return true
 	}
 
+	for config := range req.ConfigsUpdated {
+		if config.Kind == gvk.ProxyConfig {
+			return true
+		}
+	}
+
 	return false
 }
 
-// Generate returns ProxyConfig protobuf containing TrustBundle for given proxy
+// Generate returns ProxyConfig protobuf for given proxy. It carries the mesh default ProxyConfig with the ProxyConfig
+// resources selecting the proxy applied, and the TrustBundle if one is configured. The stats matcher and tracing are
+// always set, so that the agent reverts them when the resource setting them is removed.
 func (e *PcdsGenerator) Generate(proxy *model.Proxy, push *model.PushContext, w *model.WatchedResource, req *model.PushRequest) (model.Resources, error) {
 	if !pcdsNeedsPush(req) {
 		return nil, nil
 	}
-	if e.TrustBundle == nil {
-		return nil, nil
+	var workloadLabels labels.Instance
+	if proxy.Metadata != nil {
+		workloadLabels = proxy.Metadata.Labels
+	}
+	pc := push.ProxyConfigs.EffectiveProxyConfig(proxy.ConfigNamespace, workloadLabels, push.Mesh)
+	if pc.ProxyStatsMatcher == nil {
+		pc.ProxyStatsMatcher = &mesh.ProxyConfig_ProxyStatsMatcher{}
 	}
-	// TODO: For now, only TrustBundle updates are pushed. Eventually, this should push entire Proxy Configuration
-	pc := &mesh.ProxyConfig{
-		CaCertificatesPem: e.TrustBundle.GetTrustBundle(),
+	if pc.Tracing == nil {
+		pc.Tracing = &mesh.Tracing{}
+	}
+	// The CA certificates of the mesh default are not a trust bundle update.
+	pc.CaCertificatesPem = nil
+	if e.TrustBundle != nil {
+		pc.CaCertificatesPem = e.TrustBundle.GetTrustBundle()
 	}
 	return model.Resources{gogo.MessageToAny(pc)}, nil
 }
+
+// ProxyConfigUnackedSince returns the IDs of the connections watching PCDS which did not acknowledge a response
+// sent at or after since, and how many connections watch PCDS in total. This is used to confirm that the trust
+// bundle reached the proxies.
+func (s *DiscoveryServer) ProxyConfigUnackedSince(since time.Time) (unacked []string, total int) {
+	for _, con := range s.ClientsOf(v3.ProxyConfigType) {
+		total++
+		w := con.Watched(v3.ProxyConfigType)
+		if w == nil {
+			unacked = append(unacked, con.ConID)
+			continue
+		}
+		con.proxy.RLock()
+		if w.LastSent.Before(since) || w.NonceSent == "" || w.NonceAcked != w.NonceSent {
+			unacked = append(unacked, con.ConID)
+		}
+		con.proxy.RUnlock()
+	}
+	sort.Strings(unacked)
+	return unacked, total
+}
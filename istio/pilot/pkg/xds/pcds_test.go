@@ -0,0 +1,113 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package xds_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	gogotypes "github.com/gogo/protobuf/types"
+
+	meshconfig "istio.io/api/mesh/v1alpha1"
+	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pilot/pkg/trustbundle"
+	"istio.io/istio/pilot/pkg/xds"
+	v3 "istio.io/istio/pilot/pkg/xds/v3"
+	"istio.io/istio/pkg/config"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+	"istio.io/istio/pkg/config/schema/gvk"
+	"istio.io/istio/pkg/test/util/retry"
+	"istio.io/istio/pkg/util/gogo"
+)
+
+func TestProxyConfigUnackedSince(t *testing.T) {
+	s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{})
+	s.Discovery.Generators[v3.ProxyConfigType] = &xds.PcdsGenerator{Server: s.Discovery, TrustBundle: trustbundle.NewTrustBundle()}
+	before := time.Now()
+	if unacked, total := s.Discovery.ProxyConfigUnackedSince(before); len(unacked) != 0 || total != 0 {
+		t.Fatalf("expected no proxies, got %v/%d", unacked, total)
+	}
+
+	ads := s.ConnectADS().WithType(v3.ProxyConfigType)
+	ads.RequestResponseAck(&discovery.DiscoveryRequest{})
+	retry.UntilSuccessOrFail(t, func() error {
+		if unacked, total := s.Discovery.ProxyConfigUnackedSince(before); len(unacked) != 0 || total != 1 {
+			return fmt.Errorf("expected 1/1 proxies to ack, got unacked %v of %d", unacked, total)
+		}
+		return nil
+	})
+
+	// Responses sent before the given time do not count.
+	unacked, total := s.Discovery.ProxyConfigUnackedSince(time.Now().Add(time.Minute))
+	if total != 1 || len(unacked) != 1 || !strings.HasPrefix(unacked[0], ads.ID) {
+		t.Fatalf("expected proxy %v to not ack, got unacked %v of %d", ads.ID, unacked, total)
+	}
+}
+
+func TestPcdsProxyConfigOverrides(t *testing.T) {
+	s := xds.NewFakeDiscoveryServer(t, xds.FakeOptions{})
+	s.Discovery.Generators[v3.ProxyConfigType] = &xds.PcdsGenerator{Server: s.Discovery}
+	ads := s.ConnectADS().WithType(v3.ProxyConfigType).WithMetadata(model.NodeMetadata{
+		Namespace: "default",
+		Labels:    map[string]string{"app": "foo"},
+	})
+
+	proxyConfig := func(resp *discovery.DiscoveryResponse) *meshconfig.ProxyConfig {
+		t.Helper()
+		if len(resp.Resources) != 1 {
+			t.Fatalf("expected 1 resource, got %d", len(resp.Resources))
+		}
+		pc := &meshconfig.ProxyConfig{}
+		if err := gogotypes.UnmarshalAny(gogo.ConvertAny(resp.Resources[0]), pc); err != nil {
+			t.Fatal(err)
+		}
+		return pc
+	}
+
+	// Without resources, the proxy gets the mesh default.
+	meshDefault := s.Env().Mesh().GetDefaultConfig().GetConcurrency().GetValue()
+	if pc := proxyConfig(ads.RequestResponseAck(nil)); pc.Concurrency.GetValue() != meshDefault {
+		t.Fatalf("expected the mesh default concurrency %d, got %v", meshDefault, pc.Concurrency)
+	}
+
+	concurrency := int32(4)
+	if _, err := s.Store().Create(config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.ProxyConfig,
+			Name:             "foo",
+			Namespace:        "default",
+		},
+		Spec: &networkingv1beta1.ProxyConfigSpec{
+			Selector:    &networkingv1beta1.WorkloadSelector{MatchLabels: map[string]string{"app": "foo"}},
+			Concurrency: &concurrency,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if pc := proxyConfig(ads.ExpectResponse()); pc.Concurrency.GetValue() != concurrency {
+		t.Fatalf("expected concurrency %d, got %v", concurrency, pc.Concurrency)
+	}
+
+	// Removing the resource reverts to the mesh default, not to the value the proxy started with.
+	if err := s.Store().Delete(gvk.ProxyConfig, "foo", "default", nil); err != nil {
+		t.Fatal(err)
+	}
+	if pc := proxyConfig(ads.ExpectResponse()); pc.Concurrency.GetValue() != meshDefault {
+		t.Fatalf("expected the mesh default concurrency %d, got %v", meshDefault, pc.Concurrency)
+	}
+}
@@ -35,6 +35,7 @@ var skippedRdsConfigs = map[config.GroupVersionKind]struct{}{
 	gvk.RequestAuthentication: {},
 	gvk.PeerAuthentication:    {},
 	gvk.Secret:                {},
+	gvk.ProxyConfig:           {},
 }
 
 func rdsNeedsPush(req *model.PushRequest) bool {
@@ -23,9 +23,12 @@ import (
 	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/any"
 
+	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/networking/util"
 	v3 "istio.io/istio/pilot/pkg/xds/v3"
+	"istio.io/istio/pkg/config"
+	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/pkg/log"
 )
 
@@ -47,6 +50,10 @@ const (
 	// TypeDebugConfigDump requests Envoy configuration for a proxy without creating one
 	TypeDebugConfigDump = "istio.io/debug/config_dump"
 
+	// TypeDebugSidecarScope requests the Envoy configuration a proxy would get if it was selected by a Sidecar
+	// with the given egress hosts. The first resource name is the proxy ID, the others are the egress hosts.
+	TypeDebugSidecarScope = "istio.io/debug/sidecar_scope"
+
 	// TODO: TypeURLReady - readiness events for endpoints, agent can propagate
 )
 
@@ -90,6 +97,18 @@ func (sg *StatusGen) Generate(proxy *model.Proxy, push *model.PushContext, w *mo
 			log.Infof("%s failed: %v", TypeDebugConfigDump, err)
 			break
 		}
+	case TypeDebugSidecarScope:
+		if len(w.ResourceNames) < 2 {
+			// Malformed request from client
+			log.Infof("%s with %d ResourceNames", TypeDebugSidecarScope, len(w.ResourceNames))
+			break
+		}
+		var err error
+		res, err = sg.debugSidecarScope(w.ResourceNames[0], w.ResourceNames[1:], push)
+		if err != nil {
+			log.Infof("%s failed: %v", TypeDebugSidecarScope, err)
+			break
+		}
 	}
 	return res, nil
 }
@@ -157,6 +176,10 @@ func debugSyncStatus(wr *model.WatchedResource) status.ConfigStatus {
 	if wr.NonceAcked == wr.NonceSent {
 		return status.ConfigStatus_SYNCED
 	}
+	if wr.NonceNacked == wr.NonceSent {
+		// The last message was rejected
+		return status.ConfigStatus_ERROR
+	}
 	return status.ConfigStatus_STALE
 }
 
@@ -176,6 +199,33 @@ func (sg *StatusGen) debugConfigDump(proxyID string) ([]*any.Any, error) {
 	return dump.Configs, nil
 }
 
+// debugSidecarScope generates the config dump of the proxy with the scope of a Sidecar limiting its egress to
+// the hosts, without affecting the configuration pushed to it.
+func (sg *StatusGen) debugSidecarScope(proxyID string, hosts []string, push *model.PushContext) ([]*any.Any, error) {
+	conn := sg.Server.getProxyConnection(proxyID)
+	if conn == nil {
+		return nil, fmt.Errorf("sidecar scope could not find connection for proxyID %q", proxyID)
+	}
+
+	sidecar := &config.Config{
+		Meta: config.Meta{
+			GroupVersionKind: gvk.Sidecar,
+			Name:             "debug",
+			Namespace:        conn.proxy.ConfigNamespace,
+		},
+		Spec: &networking.Sidecar{
+			Egress: []*networking.IstioEgressListener{{Hosts: hosts}},
+		},
+	}
+	proxy := conn.proxy.WithSidecarScope(model.ConvertToSidecarScope(push, sidecar, conn.proxy.ConfigNamespace))
+	dump, err := sg.Server.proxyConfigDump(proxy, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	return dump.Configs, nil
+}
+
 func (sg *StatusGen) OnConnect(con *Connection) {
 	sg.pushStatusEvent(TypeURLConnect, []proto.Message{con.node})
 }
@@ -0,0 +1,53 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"github.com/gogo/protobuf/proto"
+
+	meshAPI "istio.io/api/mesh/v1alpha1"
+)
+
+// ApplyProxyConfigOverrides returns a copy of base with the fields of effective that take effect through the
+// bootstrap applied: concurrency, drain and parent shutdown durations, tracing sampling and the stats matcher.
+// Base is the startup ProxyConfig, which includes the ProxyConfig resources resolved at injection time. Effective
+// is the mesh default ProxyConfig with the current ProxyConfig resources of the proxy applied, as pushed by istiod,
+// so removing a resource reverts its fields to the mesh default. Fields set in pinned, the pod's proxy.istio.io/config
+// annotation, keep the value of base as the annotation takes precedence over resources. Fields unset in effective,
+// e.g. when pushed by an istiod which only sends the trust bundle, and a zero concurrency, which lets the agent
+// derive it from the CPU at startup, also keep the value of base.
+func ApplyProxyConfigOverrides(base, effective, pinned *meshAPI.ProxyConfig) *meshAPI.ProxyConfig {
+	pc := proto.Clone(base).(*meshAPI.ProxyConfig)
+	if effective.GetConcurrency().GetValue() > 0 && pinned.GetConcurrency() == nil {
+		pc.Concurrency = effective.Concurrency
+	}
+	if effective.GetDrainDuration() != nil && pinned.GetDrainDuration() == nil {
+		pc.DrainDuration = effective.DrainDuration
+	}
+	if effective.GetParentShutdownDuration() != nil && pinned.GetParentShutdownDuration() == nil {
+		pc.ParentShutdownDuration = effective.ParentShutdownDuration
+	}
+	if effective.GetTracing() != nil && pinned.GetTracing().GetSampling() == 0 {
+		if pc.Tracing != nil {
+			pc.Tracing.Sampling = effective.Tracing.Sampling
+		} else if effective.Tracing.Sampling != 0 {
+			pc.Tracing = &meshAPI.Tracing{Sampling: effective.Tracing.Sampling}
+		}
+	}
+	if effective.GetProxyStatsMatcher() != nil && pinned.GetProxyStatsMatcher() == nil {
+		pc.ProxyStatsMatcher = effective.ProxyStatsMatcher
+	}
+	return pc
+}
@@ -0,0 +1,87 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bootstrap
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+
+	meshAPI "istio.io/api/mesh/v1alpha1"
+)
+
+func TestApplyProxyConfigOverrides(t *testing.T) {
+	base := &meshAPI.ProxyConfig{
+		DiscoveryAddress: "istiod:15012",
+		Concurrency:      &types.Int32Value{Value: 2},
+		DrainDuration:    types.DurationProto(45 * time.Second),
+		Tracing:          &meshAPI.Tracing{Sampling: 1},
+	}
+	overrides := &meshAPI.ProxyConfig{
+		Concurrency:   &types.Int32Value{Value: 4},
+		DrainDuration: types.DurationProto(10 * time.Second),
+		Tracing:       &meshAPI.Tracing{Sampling: 50},
+	}
+	pinned := &meshAPI.ProxyConfig{
+		DrainDuration: types.DurationProto(45 * time.Second),
+	}
+
+	got := ApplyProxyConfigOverrides(base, overrides, pinned)
+	if got.GetConcurrency().GetValue() != 4 {
+		t.Errorf("got concurrency %v, want 4", got.GetConcurrency())
+	}
+	if d, _ := types.DurationFromProto(got.GetDrainDuration()); d != 45*time.Second {
+		t.Errorf("got drain duration %v, want the pinned 45s", d)
+	}
+	if got.GetTracing().GetSampling() != 50 {
+		t.Errorf("got sampling %v, want 50", got.GetTracing().GetSampling())
+	}
+	if got.GetDiscoveryAddress() != base.DiscoveryAddress {
+		t.Errorf("got discovery address %q, want %q", got.GetDiscoveryAddress(), base.DiscoveryAddress)
+	}
+	if base.GetConcurrency().GetValue() != 2 || base.GetTracing().GetSampling() != 1 {
+		t.Errorf("base was modified: %v", base)
+	}
+
+	// Without the effective configuration, e.g. from an istiod only sending the trust bundle, the proxy keeps its
+	// startup configuration.
+	if got := ApplyProxyConfigOverrides(base, &meshAPI.ProxyConfig{}, nil); !proto.Equal(got, base) {
+		t.Errorf("got %v, want %v", got, base)
+	}
+
+	// A proxy started with a resource, resolved at injection time, reverts to the mesh default once it is removed.
+	injected := &meshAPI.ProxyConfig{
+		Concurrency:       &types.Int32Value{Value: 4},
+		Tracing:           &meshAPI.Tracing{Sampling: 50},
+		ProxyStatsMatcher: &meshAPI.ProxyConfig_ProxyStatsMatcher{InclusionPrefixes: []string{"cluster.outbound"}},
+	}
+	meshDefault := &meshAPI.ProxyConfig{
+		Concurrency:       &types.Int32Value{Value: 2},
+		Tracing:           &meshAPI.Tracing{},
+		ProxyStatsMatcher: &meshAPI.ProxyConfig_ProxyStatsMatcher{},
+	}
+	got = ApplyProxyConfigOverrides(injected, meshDefault, nil)
+	if got.GetConcurrency().GetValue() != 2 || got.GetTracing().GetSampling() != 0 ||
+		len(got.GetProxyStatsMatcher().GetInclusionPrefixes()) != 0 {
+		t.Errorf("got %v, want the mesh default", got)
+	}
+
+	// A zero concurrency keeps the startup value, derived from the CPU.
+	if got := ApplyProxyConfigOverrides(injected, &meshAPI.ProxyConfig{Concurrency: &types.Int32Value{}}, nil); got.GetConcurrency().GetValue() != 4 {
+		t.Errorf("got concurrency %v, want 4", got.GetConcurrency())
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v1beta1 contains networking.istio.io kinds that are defined in this repository rather than in
+// istio.io/api. They are plain Kubernetes API types; there is no generated client for them, so Istio
+// serves them through the dynamic client.
+//
+// The CRDs for these kinds are maintained in manifests/charts/base/crds/crd-all.gen.yaml alongside the
+// istio.io/api ones.
+// +k8s:deepcopy-gen=package
+// +groupName=networking.istio.io
+package v1beta1
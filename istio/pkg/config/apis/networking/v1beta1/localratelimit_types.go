@@ -0,0 +1,153 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	metav1alpha1 "istio.io/api/meta/v1alpha1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalRateLimit limits the rate of the HTTP requests received by a set of workloads, using token buckets
+// enforced independently by each proxy.
+//
+// A LocalRateLimit without a selector applies to all workloads of its namespace, one with a selector applies
+// to the matching workloads of its namespace. Limits apply to the inbound HTTP routes of sidecars and to the
+// HTTP routes of gateways. Each route is limited by the first matching rule of the oldest LocalRateLimit
+// selecting the workload. Requests exceeding the limit are rejected with a 429 status code.
+//
+// ```yaml
+// apiVersion: networking.istio.io/v1beta1
+// kind: LocalRateLimit
+// metadata:
+//   name: ratings
+//   namespace: bookinfo
+// spec:
+//   selector:
+//     matchLabels:
+//       app: ratings
+//   rules:
+//   - match:
+//       ports:
+//       - 9080
+//     tokenBucket:
+//       maxTokens: 100
+//       fillInterval: 1s
+//     descriptors:
+//     - header:
+//         name: x-user-tier
+//         value: free
+//       tokenBucket:
+//         maxTokens: 10
+//         fillInterval: 1s
+// ```
+type LocalRateLimit struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalRateLimitSpec       `json:"spec,omitempty"`
+	Status metav1alpha1.IstioStatus `json:"status,omitempty"`
+}
+
+// LocalRateLimitSpec defines the rate limits and the workloads they apply to.
+type LocalRateLimitSpec struct {
+	// Selector restricts the workloads this LocalRateLimit applies to. If omitted, it applies to all
+	// workloads in the namespace.
+	// +optional
+	Selector *WorkloadSelector `json:"selector,omitempty"`
+
+	// Rules are the rate limits to apply. A route is limited by the first rule matching it.
+	Rules []LocalRateLimitRule `json:"rules,omitempty"`
+}
+
+// LocalRateLimitRule limits the requests of the routes it matches.
+type LocalRateLimitRule struct {
+	// Match selects the routes limited by this rule. If omitted, the rule matches all routes.
+	// +optional
+	Match *LocalRateLimitMatch `json:"match,omitempty"`
+
+	// TokenBucket limits all the requests of the matched routes.
+	TokenBucket TokenBucket `json:"tokenBucket"`
+
+	// Descriptors limit the requests of the matched routes that have the given attributes with their own
+	// token bucket, which is used instead of the bucket of the rule.
+	// +optional
+	Descriptors []LocalRateLimitDescriptor `json:"descriptors,omitempty"`
+}
+
+// LocalRateLimitMatch selects routes by port and by name. A route must match both the ports and the routes
+// when they are set.
+type LocalRateLimitMatch struct {
+	// Ports are the ports of the routes. For sidecars this is the port of the workload, for gateways the
+	// port of the server.
+	// +optional
+	Ports []uint32 `json:"ports,omitempty"`
+
+	// Routes are the names of the routes, as set in the `name` of the VirtualService HTTP routes.
+	// +optional
+	Routes []string `json:"routes,omitempty"`
+}
+
+// TokenBucket configures a token bucket. A request consumes a token and is rejected if the bucket is empty.
+type TokenBucket struct {
+	// MaxTokens is the capacity of the bucket, which is also its initial number of tokens.
+	MaxTokens uint32 `json:"maxTokens"`
+
+	// TokensPerFill is the number of tokens added to the bucket every fill interval. Defaults to 1.
+	// +optional
+	TokensPerFill *uint32 `json:"tokensPerFill,omitempty"`
+
+	// FillInterval is the interval at which tokens are added to the bucket. It must be at least 50ms.
+	FillInterval metav1.Duration `json:"fillInterval"`
+}
+
+// LocalRateLimitDescriptor limits the requests that have an attribute. Exactly one of Header,
+// SourcePrincipal or PathPrefix must be set.
+type LocalRateLimitDescriptor struct {
+	// Header matches requests with a header of the given value.
+	// +optional
+	Header *LocalRateLimitHeader `json:"header,omitempty"`
+
+	// SourcePrincipal matches requests from the given mTLS peer identity, such as
+	// `cluster.local/ns/default/sa/sleep`. The identity is taken from the verified peer certificate, so it only
+	// matches mTLS requests to sidecars in PERMISSIVE or STRICT mode, or to gateway servers in ISTIO_MUTUAL mode.
+	// +optional
+	SourcePrincipal string `json:"sourcePrincipal,omitempty"`
+
+	// PathPrefix matches requests whose path starts with the given prefix.
+	// +optional
+	PathPrefix string `json:"pathPrefix,omitempty"`
+
+	// TokenBucket limits the matched requests. Its fill interval must be a multiple of the fill interval
+	// of the rule.
+	TokenBucket TokenBucket `json:"tokenBucket"`
+}
+
+// LocalRateLimitHeader matches a request header by exact value.
+type LocalRateLimitHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalRateLimitList contains a list of LocalRateLimit.
+type LocalRateLimitList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalRateLimit `json:"items"`
+}
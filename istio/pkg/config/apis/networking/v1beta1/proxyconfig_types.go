@@ -0,0 +1,124 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	metav1alpha1 "istio.io/api/meta/v1alpha1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ProxyConfig overrides proxy settings of the mesh-wide `defaultConfig` for a set of workloads.
+//
+// A ProxyConfig without a selector in the root namespace applies to the whole mesh, one without a selector in
+// any other namespace applies to all workloads of that namespace, and one with a selector applies to the
+// matching workloads of its namespace. Settings are resolved in the order mesh config, root namespace,
+// namespace, workload and finally the `proxy.istio.io/config` annotation, each level overriding the fields
+// it sets. If several resources apply at the same level, the oldest one is used.
+//
+// The settings are applied at injection, and istiod pushes later changes to running proxies over PCDS. The agent
+// then hot restarts Envoy with a new bootstrap, except for terminationDrainDuration which only takes effect when
+// the workload restarts. Settings of the `proxy.istio.io/config` annotation are never overridden at runtime.
+//
+// ```yaml
+// apiVersion: networking.istio.io/v1beta1
+// kind: ProxyConfig
+// metadata:
+//   name: per-workload
+//   namespace: bookinfo
+// spec:
+//   selector:
+//     matchLabels:
+//       app: ratings
+//   concurrency: 4
+//   terminationDrainDuration: 30s
+// ```
+type ProxyConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProxyConfigSpec          `json:"spec,omitempty"`
+	Status metav1alpha1.IstioStatus `json:"status,omitempty"`
+}
+
+// ProxyConfigSpec defines the proxy settings and the workloads they apply to. Unset fields are inherited from
+// the previous level.
+type ProxyConfigSpec struct {
+	// Selector restricts the workloads this ProxyConfig applies to. If omitted, it applies to all workloads in
+	// the namespace.
+	// +optional
+	Selector *WorkloadSelector `json:"selector,omitempty"`
+
+	// Concurrency is the number of worker threads to run. If set to 0, it is determined from the CPU
+	// requests or limits of the proxy.
+	// +optional
+	Concurrency *int32 `json:"concurrency,omitempty"`
+
+	// DrainDuration is the time in seconds that Envoy will drain connections during a hot restart.
+	// +optional
+	DrainDuration *metav1.Duration `json:"drainDuration,omitempty"`
+
+	// ParentShutdownDuration is the time in seconds that Envoy will wait before shutting down the parent
+	// process during a hot restart.
+	// +optional
+	ParentShutdownDuration *metav1.Duration `json:"parentShutdownDuration,omitempty"`
+
+	// TerminationDrainDuration is the amount of time allowed for connections to complete on proxy shutdown.
+	// +optional
+	TerminationDrainDuration *metav1.Duration `json:"terminationDrainDuration,omitempty"`
+
+	// Tracing overrides the tracing settings of the proxy.
+	// +optional
+	Tracing *ProxyTracing `json:"tracing,omitempty"`
+
+	// ProxyStatsMatcher selects the additional Envoy stats to be emitted by the proxy.
+	// +optional
+	ProxyStatsMatcher *ProxyStatsMatcher `json:"proxyStatsMatcher,omitempty"`
+}
+
+// WorkloadSelector selects workloads by their labels.
+type WorkloadSelector struct {
+	// MatchLabels are the labels a workload must have to be selected.
+	MatchLabels map[string]string `json:"matchLabels,omitempty"`
+}
+
+// ProxyTracing configures the tracing settings of the proxy.
+type ProxyTracing struct {
+	// Sampling is the percentage of requests, from 0.0 to 100.0, that are sampled for tracing.
+	// +optional
+	Sampling *float64 `json:"sampling,omitempty"`
+}
+
+// ProxyStatsMatcher selects Envoy stats by name. Stats matching any of the prefixes, suffixes or regular
+// expressions are emitted in addition to the default set.
+type ProxyStatsMatcher struct {
+	// +optional
+	InclusionPrefixes []string `json:"inclusionPrefixes,omitempty"`
+	// +optional
+	InclusionSuffixes []string `json:"inclusionSuffixes,omitempty"`
+	// +optional
+	InclusionRegexps []string `json:"inclusionRegexps,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ProxyConfigList contains a list of ProxyConfig.
+type ProxyConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProxyConfig `json:"items"`
+}
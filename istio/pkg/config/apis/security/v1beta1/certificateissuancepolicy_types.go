@@ -0,0 +1,88 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	metav1alpha1 "istio.io/api/meta/v1alpha1"
+)
+
+const (
+	// ActionAllow allows the issuance of certificates, within the limits of the policy.
+	ActionAllow = "ALLOW"
+	// ActionDeny denies the issuance of certificates.
+	ActionDeny = "DENY"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CertificateIssuancePolicy controls the issuance of workload certificates by the Istio CA.
+//
+// A CertificateIssuancePolicy in the root namespace applies to the whole mesh, one in any other namespace
+// applies to the service accounts of that namespace. A certificate is denied if any of the policies that apply
+// to its identities denies it, and its TTL is clamped to the lowest `maxCertTTL` of these policies.
+//
+// The `deniedSerialNumbers` of the policies in the root namespace list certificates that must no longer be
+// trusted, for example those of a compromised workload. They are distributed along with the trust bundle.
+//
+// ```yaml
+// apiVersion: security.istio.io/v1beta1
+// kind: CertificateIssuancePolicy
+// metadata:
+//   name: compromised
+//   namespace: bookinfo
+// spec:
+//   serviceAccounts:
+//   - bookinfo-ratings
+//   action: DENY
+// ```
+type CertificateIssuancePolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CertificateIssuancePolicySpec `json:"spec,omitempty"`
+	Status metav1alpha1.IstioStatus      `json:"status,omitempty"`
+}
+
+// CertificateIssuancePolicySpec defines the identities a policy applies to and how their certificates are issued.
+type CertificateIssuancePolicySpec struct {
+	// ServiceAccounts restricts the policy to these service accounts of its namespace. If omitted, it applies to
+	// all service accounts of the namespace, or of the mesh for a policy in the root namespace.
+	// +optional
+	ServiceAccounts []string `json:"serviceAccounts,omitempty"`
+
+	// Action is either ALLOW, the default, or DENY.
+	// +optional
+	Action string `json:"action,omitempty"`
+
+	// MaxCertTTL is the maximum TTL of the certificates issued. Longer requested TTLs are clamped to it.
+	// +optional
+	MaxCertTTL *metav1.Duration `json:"maxCertTTL,omitempty"`
+
+	// DeniedSerialNumbers are the hex encoded serial numbers of certificates that must no longer be trusted. They
+	// are only honored in the root namespace.
+	// +optional
+	DeniedSerialNumbers []string `json:"deniedSerialNumbers,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// CertificateIssuancePolicyList contains a list of CertificateIssuancePolicy.
+type CertificateIssuancePolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CertificateIssuancePolicy `json:"items"`
+}
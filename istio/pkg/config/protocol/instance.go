@@ -52,6 +52,10 @@ const (
 	Redis Instance = "Redis"
 	// MySQL declares that the port carries MySQL traffic.
 	MySQL Instance = "MySQL"
+	// Kafka declares that the port carries Kafka traffic.
+	Kafka Instance = "Kafka"
+	// Postgres declares that the port carries PostgreSQL traffic.
+	Postgres Instance = "Postgres"
 	// Unsupported - value to signify that the protocol is unsupported.
 	Unsupported Instance = "UnsupportedProtocol"
 )
@@ -85,6 +89,10 @@ func Parse(s string) Instance {
 		return Redis
 	case "mysql":
 		return MySQL
+	case "kafka":
+		return Kafka
+	case "postgres", "postgresql":
+		return Postgres
 	}
 
 	return Unsupported
@@ -123,7 +131,7 @@ func (i Instance) IsThrift() bool {
 // IsTCP is true for protocols that use TCP as transport protocol
 func (i Instance) IsTCP() bool {
 	switch i {
-	case TCP, HTTPS, TLS, Mongo, Redis, MySQL, Thrift:
+	case TCP, HTTPS, TLS, Mongo, Redis, MySQL, Kafka, Postgres, Thrift:
 		return true
 	default:
 		return false
@@ -58,6 +58,11 @@ func TestParse(t *testing.T) {
 		{"mysql", protocol.MySQL},
 		{"MYSQL", protocol.MySQL},
 		{"MySQL", protocol.MySQL},
+		{"kafka", protocol.Kafka},
+		{"KAFKA", protocol.Kafka},
+		{"postgres", protocol.Postgres},
+		{"Postgres", protocol.Postgres},
+		{"postgresql", protocol.Postgres},
 		{"", protocol.Unsupported},
 		{"SMTP", protocol.Unsupported},
 	}
@@ -11,6 +11,8 @@ import (
 	istioioapimetav1alpha1 "istio.io/api/meta/v1alpha1"
 	istioioapinetworkingv1alpha3 "istio.io/api/networking/v1alpha3"
 	istioioapisecurityv1beta1 "istio.io/api/security/v1beta1"
+	istioioistiopkgconfigapisnetworkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+	istioioistiopkgconfigapissecurityv1beta1 "istio.io/istio/pkg/config/apis/security/v1beta1"
 	"istio.io/istio/pkg/config/schema/collection"
 	"istio.io/istio/pkg/config/schema/resource"
 	"istio.io/istio/pkg/config/validation"
@@ -208,6 +210,44 @@ var (
 		}.MustBuild(),
 	}.MustBuild()
 
+	// IstioNetworkingV1Beta1Localratelimits describes the collection
+	// istio/networking/v1beta1/localratelimits
+	IstioNetworkingV1Beta1Localratelimits = collection.Builder{
+		Name:         "istio/networking/v1beta1/localratelimits",
+		VariableName: "IstioNetworkingV1Beta1Localratelimits",
+		Disabled:     false,
+		Resource: resource.Builder{
+			Group:   "networking.istio.io",
+			Kind:    "LocalRateLimit",
+			Plural:  "localratelimits",
+			Version: "v1beta1",
+			Proto:   "istio.networking.v1beta1.LocalRateLimitSpec", StatusProto: "istio.meta.v1alpha1.IstioStatus",
+			ReflectType: reflect.TypeOf(&istioioistiopkgconfigapisnetworkingv1beta1.LocalRateLimitSpec{}).Elem(), StatusType: reflect.TypeOf(&istioioapimetav1alpha1.IstioStatus{}).Elem(),
+			ProtoPackage: "istio.io/istio/pkg/config/apis/networking/v1beta1", StatusPackage: "istio.io/api/meta/v1alpha1",
+			ClusterScoped: false,
+			ValidateProto: validation.ValidateLocalRateLimit,
+		}.MustBuild(),
+	}.MustBuild()
+
+	// IstioNetworkingV1Beta1Proxyconfigs describes the collection
+	// istio/networking/v1beta1/proxyconfigs
+	IstioNetworkingV1Beta1Proxyconfigs = collection.Builder{
+		Name:         "istio/networking/v1beta1/proxyconfigs",
+		VariableName: "IstioNetworkingV1Beta1Proxyconfigs",
+		Disabled:     false,
+		Resource: resource.Builder{
+			Group:   "networking.istio.io",
+			Kind:    "ProxyConfig",
+			Plural:  "proxyconfigs",
+			Version: "v1beta1",
+			Proto:   "istio.networking.v1beta1.ProxyConfigSpec", StatusProto: "istio.meta.v1alpha1.IstioStatus",
+			ReflectType: reflect.TypeOf(&istioioistiopkgconfigapisnetworkingv1beta1.ProxyConfigSpec{}).Elem(), StatusType: reflect.TypeOf(&istioioapimetav1alpha1.IstioStatus{}).Elem(),
+			ProtoPackage: "istio.io/istio/pkg/config/apis/networking/v1beta1", StatusPackage: "istio.io/api/meta/v1alpha1",
+			ClusterScoped: false,
+			ValidateProto: validation.ValidateProxyConfigResource,
+		}.MustBuild(),
+	}.MustBuild()
+
 	// IstioSecurityV1Beta1Authorizationpolicies describes the collection
 	// istio/security/v1beta1/authorizationpolicies
 	IstioSecurityV1Beta1Authorizationpolicies = collection.Builder{
@@ -227,6 +267,25 @@ var (
 		}.MustBuild(),
 	}.MustBuild()
 
+	// IstioSecurityV1Beta1Certificateissuancepolicies describes the
+	// collection istio/security/v1beta1/certificateissuancepolicies
+	IstioSecurityV1Beta1Certificateissuancepolicies = collection.Builder{
+		Name:         "istio/security/v1beta1/certificateissuancepolicies",
+		VariableName: "IstioSecurityV1Beta1Certificateissuancepolicies",
+		Disabled:     false,
+		Resource: resource.Builder{
+			Group:   "security.istio.io",
+			Kind:    "CertificateIssuancePolicy",
+			Plural:  "certificateissuancepolicies",
+			Version: "v1beta1",
+			Proto:   "istio.security.v1beta1.CertificateIssuancePolicySpec", StatusProto: "istio.meta.v1alpha1.IstioStatus",
+			ReflectType: reflect.TypeOf(&istioioistiopkgconfigapissecurityv1beta1.CertificateIssuancePolicySpec{}).Elem(), StatusType: reflect.TypeOf(&istioioapimetav1alpha1.IstioStatus{}).Elem(),
+			ProtoPackage: "istio.io/istio/pkg/config/apis/security/v1beta1", StatusPackage: "istio.io/api/meta/v1alpha1",
+			ClusterScoped: false,
+			ValidateProto: validation.ValidateCertificateIssuancePolicy,
+		}.MustBuild(),
+	}.MustBuild()
+
 	// IstioSecurityV1Beta1Peerauthentications describes the collection
 	// istio/security/v1beta1/peerauthentications
 	IstioSecurityV1Beta1Peerauthentications = collection.Builder{
@@ -277,7 +336,10 @@ var (
 		MustAdd(IstioNetworkingV1Alpha3Virtualservices).
 		MustAdd(IstioNetworkingV1Alpha3Workloadentries).
 		MustAdd(IstioNetworkingV1Alpha3Workloadgroups).
+		MustAdd(IstioNetworkingV1Beta1Localratelimits).
+		MustAdd(IstioNetworkingV1Beta1Proxyconfigs).
 		MustAdd(IstioSecurityV1Beta1Authorizationpolicies).
+		MustAdd(IstioSecurityV1Beta1Certificateissuancepolicies).
 		MustAdd(IstioSecurityV1Beta1Peerauthentications).
 		MustAdd(IstioSecurityV1Beta1Requestauthentications).
 		Build()
@@ -294,7 +356,10 @@ var (
 		MustAdd(IstioNetworkingV1Alpha3Virtualservices).
 		MustAdd(IstioNetworkingV1Alpha3Workloadentries).
 		MustAdd(IstioNetworkingV1Alpha3Workloadgroups).
+		MustAdd(IstioNetworkingV1Beta1Localratelimits).
+		MustAdd(IstioNetworkingV1Beta1Proxyconfigs).
 		MustAdd(IstioSecurityV1Beta1Authorizationpolicies).
+		MustAdd(IstioSecurityV1Beta1Certificateissuancepolicies).
 		MustAdd(IstioSecurityV1Beta1Peerauthentications).
 		MustAdd(IstioSecurityV1Beta1Requestauthentications).
 		Build()
@@ -313,7 +378,10 @@ var (
 		MustAdd(IstioNetworkingV1Alpha3Virtualservices).
 		MustAdd(IstioNetworkingV1Alpha3Workloadentries).
 		MustAdd(IstioNetworkingV1Alpha3Workloadgroups).
+		MustAdd(IstioNetworkingV1Beta1Localratelimits).
+		MustAdd(IstioNetworkingV1Beta1Proxyconfigs).
 		MustAdd(IstioSecurityV1Beta1Authorizationpolicies).
+		MustAdd(IstioSecurityV1Beta1Certificateissuancepolicies).
 		MustAdd(IstioSecurityV1Beta1Peerauthentications).
 		MustAdd(IstioSecurityV1Beta1Requestauthentications).
 		Build()
@@ -328,7 +396,10 @@ var (
 			MustAdd(IstioNetworkingV1Alpha3Virtualservices).
 			MustAdd(IstioNetworkingV1Alpha3Workloadentries).
 			MustAdd(IstioNetworkingV1Alpha3Workloadgroups).
+			MustAdd(IstioNetworkingV1Beta1Localratelimits).
+			MustAdd(IstioNetworkingV1Beta1Proxyconfigs).
 			MustAdd(IstioSecurityV1Beta1Authorizationpolicies).
+			MustAdd(IstioSecurityV1Beta1Certificateissuancepolicies).
 			MustAdd(IstioSecurityV1Beta1Peerauthentications).
 			MustAdd(IstioSecurityV1Beta1Requestauthentications).
 			Build()
@@ -18,6 +18,8 @@ import (
 	istioioapimetav1alpha1 "istio.io/api/meta/v1alpha1"
 	istioioapinetworkingv1alpha3 "istio.io/api/networking/v1alpha3"
 	istioioapisecurityv1beta1 "istio.io/api/security/v1beta1"
+	istioioistiopkgconfigapisnetworkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+	istioioistiopkgconfigapissecurityv1beta1 "istio.io/istio/pkg/config/apis/security/v1beta1"
 	"istio.io/istio/pkg/config/schema/collection"
 	"istio.io/istio/pkg/config/schema/resource"
 	"istio.io/istio/pkg/config/validation"
@@ -215,6 +217,44 @@ var (
 		}.MustBuild(),
 	}.MustBuild()
 
+	// IstioNetworkingV1Beta1Localratelimits describes the collection
+	// istio/networking/v1beta1/localratelimits
+	IstioNetworkingV1Beta1Localratelimits = collection.Builder{
+		Name:         "istio/networking/v1beta1/localratelimits",
+		VariableName: "IstioNetworkingV1Beta1Localratelimits",
+		Disabled:     false,
+		Resource: resource.Builder{
+			Group:   "networking.istio.io",
+			Kind:    "LocalRateLimit",
+			Plural:  "localratelimits",
+			Version: "v1beta1",
+			Proto:   "istio.networking.v1beta1.LocalRateLimitSpec", StatusProto: "istio.meta.v1alpha1.IstioStatus",
+			ReflectType: reflect.TypeOf(&istioioistiopkgconfigapisnetworkingv1beta1.LocalRateLimitSpec{}).Elem(), StatusType: reflect.TypeOf(&istioioapimetav1alpha1.IstioStatus{}).Elem(),
+			ProtoPackage: "istio.io/istio/pkg/config/apis/networking/v1beta1", StatusPackage: "istio.io/api/meta/v1alpha1",
+			ClusterScoped: false,
+			ValidateProto: validation.ValidateLocalRateLimit,
+		}.MustBuild(),
+	}.MustBuild()
+
+	// IstioNetworkingV1Beta1Proxyconfigs describes the collection
+	// istio/networking/v1beta1/proxyconfigs
+	IstioNetworkingV1Beta1Proxyconfigs = collection.Builder{
+		Name:         "istio/networking/v1beta1/proxyconfigs",
+		VariableName: "IstioNetworkingV1Beta1Proxyconfigs",
+		Disabled:     false,
+		Resource: resource.Builder{
+			Group:   "networking.istio.io",
+			Kind:    "ProxyConfig",
+			Plural:  "proxyconfigs",
+			Version: "v1beta1",
+			Proto:   "istio.networking.v1beta1.ProxyConfigSpec", StatusProto: "istio.meta.v1alpha1.IstioStatus",
+			ReflectType: reflect.TypeOf(&istioioistiopkgconfigapisnetworkingv1beta1.ProxyConfigSpec{}).Elem(), StatusType: reflect.TypeOf(&istioioapimetav1alpha1.IstioStatus{}).Elem(),
+			ProtoPackage: "istio.io/istio/pkg/config/apis/networking/v1beta1", StatusPackage: "istio.io/api/meta/v1alpha1",
+			ClusterScoped: false,
+			ValidateProto: validation.ValidateProxyConfigResource,
+		}.MustBuild(),
+	}.MustBuild()
+
 	// IstioSecurityV1Beta1Authorizationpolicies describes the collection
 	// istio/security/v1beta1/authorizationpolicies
 	IstioSecurityV1Beta1Authorizationpolicies = collection.Builder{
@@ -234,6 +274,25 @@ var (
 		}.MustBuild(),
 	}.MustBuild()
 
+	// IstioSecurityV1Beta1Certificateissuancepolicies describes the
+	// collection istio/security/v1beta1/certificateissuancepolicies
+	IstioSecurityV1Beta1Certificateissuancepolicies = collection.Builder{
+		Name:         "istio/security/v1beta1/certificateissuancepolicies",
+		VariableName: "IstioSecurityV1Beta1Certificateissuancepolicies",
+		Disabled:     false,
+		Resource: resource.Builder{
+			Group:   "security.istio.io",
+			Kind:    "CertificateIssuancePolicy",
+			Plural:  "certificateissuancepolicies",
+			Version: "v1beta1",
+			Proto:   "istio.security.v1beta1.CertificateIssuancePolicySpec", StatusProto: "istio.meta.v1alpha1.IstioStatus",
+			ReflectType: reflect.TypeOf(&istioioistiopkgconfigapissecurityv1beta1.CertificateIssuancePolicySpec{}).Elem(), StatusType: reflect.TypeOf(&istioioapimetav1alpha1.IstioStatus{}).Elem(),
+			ProtoPackage: "istio.io/istio/pkg/config/apis/security/v1beta1", StatusPackage: "istio.io/api/meta/v1alpha1",
+			ClusterScoped: false,
+			ValidateProto: validation.ValidateCertificateIssuancePolicy,
+		}.MustBuild(),
+	}.MustBuild()
+
 	// IstioSecurityV1Beta1Peerauthentications describes the collection
 	// istio/security/v1beta1/peerauthentications
 	IstioSecurityV1Beta1Peerauthentications = collection.Builder{
@@ -626,6 +685,44 @@ var (
 		}.MustBuild(),
 	}.MustBuild()
 
+	// K8SNetworkingIstioIoV1Beta1Localratelimits describes the collection
+	// k8s/networking.istio.io/v1beta1/localratelimits
+	K8SNetworkingIstioIoV1Beta1Localratelimits = collection.Builder{
+		Name:         "k8s/networking.istio.io/v1beta1/localratelimits",
+		VariableName: "K8SNetworkingIstioIoV1Beta1Localratelimits",
+		Disabled:     false,
+		Resource: resource.Builder{
+			Group:   "networking.istio.io",
+			Kind:    "LocalRateLimit",
+			Plural:  "localratelimits",
+			Version: "v1beta1",
+			Proto:   "istio.networking.v1beta1.LocalRateLimitSpec", StatusProto: "istio.meta.v1alpha1.IstioStatus",
+			ReflectType: reflect.TypeOf(&istioioistiopkgconfigapisnetworkingv1beta1.LocalRateLimitSpec{}).Elem(), StatusType: reflect.TypeOf(&istioioapimetav1alpha1.IstioStatus{}).Elem(),
+			ProtoPackage: "istio.io/istio/pkg/config/apis/networking/v1beta1", StatusPackage: "istio.io/api/meta/v1alpha1",
+			ClusterScoped: false,
+			ValidateProto: validation.ValidateLocalRateLimit,
+		}.MustBuild(),
+	}.MustBuild()
+
+	// K8SNetworkingIstioIoV1Beta1Proxyconfigs describes the collection
+	// k8s/networking.istio.io/v1beta1/proxyconfigs
+	K8SNetworkingIstioIoV1Beta1Proxyconfigs = collection.Builder{
+		Name:         "k8s/networking.istio.io/v1beta1/proxyconfigs",
+		VariableName: "K8SNetworkingIstioIoV1Beta1Proxyconfigs",
+		Disabled:     false,
+		Resource: resource.Builder{
+			Group:   "networking.istio.io",
+			Kind:    "ProxyConfig",
+			Plural:  "proxyconfigs",
+			Version: "v1beta1",
+			Proto:   "istio.networking.v1beta1.ProxyConfigSpec", StatusProto: "istio.meta.v1alpha1.IstioStatus",
+			ReflectType: reflect.TypeOf(&istioioistiopkgconfigapisnetworkingv1beta1.ProxyConfigSpec{}).Elem(), StatusType: reflect.TypeOf(&istioioapimetav1alpha1.IstioStatus{}).Elem(),
+			ProtoPackage: "istio.io/istio/pkg/config/apis/networking/v1beta1", StatusPackage: "istio.io/api/meta/v1alpha1",
+			ClusterScoped: false,
+			ValidateProto: validation.ValidateProxyConfigResource,
+		}.MustBuild(),
+	}.MustBuild()
+
 	// K8SSecurityIstioIoV1Beta1Authorizationpolicies describes the collection
 	// k8s/security.istio.io/v1beta1/authorizationpolicies
 	K8SSecurityIstioIoV1Beta1Authorizationpolicies = collection.Builder{
@@ -645,6 +742,25 @@ var (
 		}.MustBuild(),
 	}.MustBuild()
 
+	// K8SSecurityIstioIoV1Beta1Certificateissuancepolicies describes the
+	// collection k8s/security.istio.io/v1beta1/certificateissuancepolicies
+	K8SSecurityIstioIoV1Beta1Certificateissuancepolicies = collection.Builder{
+		Name:         "k8s/security.istio.io/v1beta1/certificateissuancepolicies",
+		VariableName: "K8SSecurityIstioIoV1Beta1Certificateissuancepolicies",
+		Disabled:     false,
+		Resource: resource.Builder{
+			Group:   "security.istio.io",
+			Kind:    "CertificateIssuancePolicy",
+			Plural:  "certificateissuancepolicies",
+			Version: "v1beta1",
+			Proto:   "istio.security.v1beta1.CertificateIssuancePolicySpec", StatusProto: "istio.meta.v1alpha1.IstioStatus",
+			ReflectType: reflect.TypeOf(&istioioistiopkgconfigapissecurityv1beta1.CertificateIssuancePolicySpec{}).Elem(), StatusType: reflect.TypeOf(&istioioapimetav1alpha1.IstioStatus{}).Elem(),
+			ProtoPackage: "istio.io/istio/pkg/config/apis/security/v1beta1", StatusPackage: "istio.io/api/meta/v1alpha1",
+			ClusterScoped: false,
+			ValidateProto: validation.ValidateCertificateIssuancePolicy,
+		}.MustBuild(),
+	}.MustBuild()
+
 	// K8SSecurityIstioIoV1Beta1Peerauthentications describes the collection
 	// k8s/security.istio.io/v1beta1/peerauthentications
 	K8SSecurityIstioIoV1Beta1Peerauthentications = collection.Builder{
@@ -809,7 +925,10 @@ var (
 		MustAdd(IstioNetworkingV1Alpha3Virtualservices).
 		MustAdd(IstioNetworkingV1Alpha3Workloadentries).
 		MustAdd(IstioNetworkingV1Alpha3Workloadgroups).
+		MustAdd(IstioNetworkingV1Beta1Localratelimits).
+		MustAdd(IstioNetworkingV1Beta1Proxyconfigs).
 		MustAdd(IstioSecurityV1Beta1Authorizationpolicies).
+		MustAdd(IstioSecurityV1Beta1Certificateissuancepolicies).
 		MustAdd(IstioSecurityV1Beta1Peerauthentications).
 		MustAdd(IstioSecurityV1Beta1Requestauthentications).
 		MustAdd(K8SAdmissionregistrationK8SIoV1Mutatingwebhookconfigurations).
@@ -831,7 +950,10 @@ var (
 		MustAdd(K8SNetworkingIstioIoV1Alpha3Virtualservices).
 		MustAdd(K8SNetworkingIstioIoV1Alpha3Workloadentries).
 		MustAdd(K8SNetworkingIstioIoV1Alpha3Workloadgroups).
+		MustAdd(K8SNetworkingIstioIoV1Beta1Localratelimits).
+		MustAdd(K8SNetworkingIstioIoV1Beta1Proxyconfigs).
 		MustAdd(K8SSecurityIstioIoV1Beta1Authorizationpolicies).
+		MustAdd(K8SSecurityIstioIoV1Beta1Certificateissuancepolicies).
 		MustAdd(K8SSecurityIstioIoV1Beta1Peerauthentications).
 		MustAdd(K8SSecurityIstioIoV1Beta1Requestauthentications).
 		MustAdd(K8SServiceApisV1Alpha1Backendpolicies).
@@ -854,7 +976,10 @@ var (
 		MustAdd(IstioNetworkingV1Alpha3Virtualservices).
 		MustAdd(IstioNetworkingV1Alpha3Workloadentries).
 		MustAdd(IstioNetworkingV1Alpha3Workloadgroups).
+		MustAdd(IstioNetworkingV1Beta1Localratelimits).
+		MustAdd(IstioNetworkingV1Beta1Proxyconfigs).
 		MustAdd(IstioSecurityV1Beta1Authorizationpolicies).
+		MustAdd(IstioSecurityV1Beta1Certificateissuancepolicies).
 		MustAdd(IstioSecurityV1Beta1Peerauthentications).
 		MustAdd(IstioSecurityV1Beta1Requestauthentications).
 		Build()
@@ -880,7 +1005,10 @@ var (
 		MustAdd(K8SNetworkingIstioIoV1Alpha3Virtualservices).
 		MustAdd(K8SNetworkingIstioIoV1Alpha3Workloadentries).
 		MustAdd(K8SNetworkingIstioIoV1Alpha3Workloadgroups).
+		MustAdd(K8SNetworkingIstioIoV1Beta1Localratelimits).
+		MustAdd(K8SNetworkingIstioIoV1Beta1Proxyconfigs).
 		MustAdd(K8SSecurityIstioIoV1Beta1Authorizationpolicies).
+		MustAdd(K8SSecurityIstioIoV1Beta1Certificateissuancepolicies).
 		MustAdd(K8SSecurityIstioIoV1Beta1Peerauthentications).
 		MustAdd(K8SSecurityIstioIoV1Beta1Requestauthentications).
 		MustAdd(K8SServiceApisV1Alpha1Backendpolicies).
@@ -901,7 +1029,10 @@ var (
 		MustAdd(IstioNetworkingV1Alpha3Virtualservices).
 		MustAdd(IstioNetworkingV1Alpha3Workloadentries).
 		MustAdd(IstioNetworkingV1Alpha3Workloadgroups).
+		MustAdd(IstioNetworkingV1Beta1Localratelimits).
+		MustAdd(IstioNetworkingV1Beta1Proxyconfigs).
 		MustAdd(IstioSecurityV1Beta1Authorizationpolicies).
+		MustAdd(IstioSecurityV1Beta1Certificateissuancepolicies).
 		MustAdd(IstioSecurityV1Beta1Peerauthentications).
 		MustAdd(IstioSecurityV1Beta1Requestauthentications).
 		Build()
@@ -916,7 +1047,10 @@ var (
 			MustAdd(IstioNetworkingV1Alpha3Virtualservices).
 			MustAdd(IstioNetworkingV1Alpha3Workloadentries).
 			MustAdd(IstioNetworkingV1Alpha3Workloadgroups).
+			MustAdd(IstioNetworkingV1Beta1Localratelimits).
+			MustAdd(IstioNetworkingV1Beta1Proxyconfigs).
 			MustAdd(IstioSecurityV1Beta1Authorizationpolicies).
+			MustAdd(IstioSecurityV1Beta1Certificateissuancepolicies).
 			MustAdd(IstioSecurityV1Beta1Peerauthentications).
 			MustAdd(IstioSecurityV1Beta1Requestauthentications).
 			MustAdd(K8SServiceApisV1Alpha1Backendpolicies).
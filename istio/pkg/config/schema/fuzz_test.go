@@ -37,6 +37,8 @@ import (
 	clientsecurity "istio.io/client-go/pkg/apis/security/v1beta1"
 	"istio.io/istio/pilot/pkg/config/kube/crdclient"
 	"istio.io/istio/pkg/config"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+	securityv1beta1 "istio.io/istio/pkg/config/apis/security/v1beta1"
 	"istio.io/istio/pkg/config/schema/collections"
 	istiofuzz "istio.io/istio/pkg/config/schema/fuzz"
 )
@@ -92,6 +94,8 @@ func init() {
 	clientnetworkingalpha.AddToScheme(scheme)
 	clientnetworkingbeta.AddToScheme(scheme)
 	clientsecurity.AddToScheme(scheme)
+	networkingv1beta1.AddToScheme(scheme)
+	securityv1beta1.AddToScheme(scheme)
 }
 
 func createFuzzer() *fuzz.Fuzzer {
@@ -11,6 +11,7 @@ import (
 var (
 	AuthorizationPolicy = config.GroupVersionKind{Group: "security.istio.io", Version: "v1beta1", Kind: "AuthorizationPolicy"}
 	BackendPolicy = config.GroupVersionKind{Group: "networking.x-k8s.io", Version: "v1alpha1", Kind: "BackendPolicy"}
+	CertificateIssuancePolicy = config.GroupVersionKind{Group: "security.istio.io", Version: "v1beta1", Kind: "CertificateIssuancePolicy"}
 	ConfigMap = config.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
 	CustomResourceDefinition = config.GroupVersionKind{Group: "apiextensions.k8s.io", Version: "v1", Kind: "CustomResourceDefinition"}
 	Deployment = config.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
@@ -21,6 +22,7 @@ var (
 	GatewayClass = config.GroupVersionKind{Group: "networking.x-k8s.io", Version: "v1alpha1", Kind: "GatewayClass"}
 	HTTPRoute = config.GroupVersionKind{Group: "networking.x-k8s.io", Version: "v1alpha1", Kind: "HTTPRoute"}
 	Ingress = config.GroupVersionKind{Group: "extensions", Version: "v1beta1", Kind: "Ingress"}
+	LocalRateLimit = config.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "LocalRateLimit"}
 	MeshConfig = config.GroupVersionKind{Group: "", Version: "v1alpha1", Kind: "MeshConfig"}
 	MeshNetworks = config.GroupVersionKind{Group: "", Version: "v1alpha1", Kind: "MeshNetworks"}
 	MutatingWebhookConfiguration = config.GroupVersionKind{Group: "admissionregistration.k8s.io", Version: "v1", Kind: "MutatingWebhookConfiguration"}
@@ -28,6 +30,7 @@ var (
 	Node = config.GroupVersionKind{Group: "", Version: "v1", Kind: "Node"}
 	PeerAuthentication = config.GroupVersionKind{Group: "security.istio.io", Version: "v1beta1", Kind: "PeerAuthentication"}
 	Pod = config.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}
+	ProxyConfig = config.GroupVersionKind{Group: "networking.istio.io", Version: "v1beta1", Kind: "ProxyConfig"}
 	RequestAuthentication = config.GroupVersionKind{Group: "security.istio.io", Version: "v1beta1", Kind: "RequestAuthentication"}
 	Secret = config.GroupVersionKind{Group: "", Version: "v1", Kind: "Secret"}
 	Service = config.GroupVersionKind{Group: "", Version: "v1", Kind: "Service"}
@@ -123,6 +123,16 @@ collections:
     group: "networking.istio.io"
     pilot: true
 
+  - name: "istio/networking/v1beta1/localratelimits"
+    kind: "LocalRateLimit"
+    group: "networking.istio.io"
+    pilot: true
+
+  - name: "istio/networking/v1beta1/proxyconfigs"
+    kind: "ProxyConfig"
+    group: "networking.istio.io"
+    pilot: true
+
   - name: "istio/security/v1beta1/authorizationpolicies"
     kind: "AuthorizationPolicy"
     group: "security.istio.io"
@@ -138,6 +148,11 @@ collections:
     group: "security.istio.io"
     pilot: true
 
+  - name: "istio/security/v1beta1/certificateissuancepolicies"
+    kind: "CertificateIssuancePolicy"
+    group: "security.istio.io"
+    pilot: true
+
   ### K8s collections ###
 
   # Built-in K8s collections
@@ -242,6 +257,14 @@ collections:
     kind: "VirtualService"
     group: "networking.istio.io"
 
+  - name: "k8s/networking.istio.io/v1beta1/localratelimits"
+    kind: "LocalRateLimit"
+    group: "networking.istio.io"
+
+  - name: "k8s/networking.istio.io/v1beta1/proxyconfigs"
+    kind: "ProxyConfig"
+    group: "networking.istio.io"
+
   - name: "k8s/security.istio.io/v1beta1/authorizationpolicies"
     kind: "AuthorizationPolicy"
     group: "security.istio.io"
@@ -254,6 +277,10 @@ collections:
     kind: "PeerAuthentication"
     group: "security.istio.io"
 
+  - name: "k8s/security.istio.io/v1beta1/certificateissuancepolicies"
+    kind: "CertificateIssuancePolicy"
+    group: "security.istio.io"
+
 # The snapshots to generate
 snapshots:
   # Used by Galley to distribute configuration.
@@ -287,6 +314,7 @@ snapshots:
       - "istio/networking/v1alpha3/serviceentries"
       - "istio/networking/v1alpha3/sidecars"
       - "istio/networking/v1alpha3/virtualservices"
+      - "istio/networking/v1beta1/localratelimits"
       - "istio/security/v1beta1/authorizationpolicies"
       - "k8s/apiextensions.k8s.io/v1/customresourcedefinitions"
       - "k8s/admissionregistration.k8s.io/v1/mutatingwebhookconfigurations"
@@ -511,6 +539,28 @@ resources:
     statusProto: "istio.meta.v1alpha1.IstioStatus"
     statusProtoPackage: "istio.io/api/meta/v1alpha1"
 
+  - kind: "LocalRateLimit"
+    plural: "localratelimits"
+    group: "networking.istio.io"
+    version: "v1beta1"
+    proto: "istio.networking.v1beta1.LocalRateLimitSpec"
+    protoPackage: "istio.io/istio/pkg/config/apis/networking/v1beta1"
+    validate: "ValidateLocalRateLimit"
+    description: "describes local rate limits of workload requests"
+    statusProto: "istio.meta.v1alpha1.IstioStatus"
+    statusProtoPackage: "istio.io/api/meta/v1alpha1"
+
+  - kind: "ProxyConfig"
+    plural: "proxyconfigs"
+    group: "networking.istio.io"
+    version: "v1beta1"
+    proto: "istio.networking.v1beta1.ProxyConfigSpec"
+    protoPackage: "istio.io/istio/pkg/config/apis/networking/v1beta1"
+    validate: "ValidateProxyConfigResource"
+    description: "describes per-workload overrides of the proxy configuration"
+    statusProto: "istio.meta.v1alpha1.IstioStatus"
+    statusProtoPackage: "istio.io/api/meta/v1alpha1"
+
   - kind: "MeshConfig"
     plural: "meshconfigs"
     group: ""
@@ -558,6 +608,17 @@ resources:
     statusProto: "istio.meta.v1alpha1.IstioStatus"
     statusProtoPackage: "istio.io/api/meta/v1alpha1"
 
+  - kind: "CertificateIssuancePolicy"
+    plural: "certificateissuancepolicies"
+    group: "security.istio.io"
+    version: "v1beta1"
+    proto: "istio.security.v1beta1.CertificateIssuancePolicySpec"
+    protoPackage: "istio.io/istio/pkg/config/apis/security/v1beta1"
+    validate: "ValidateCertificateIssuancePolicy"
+    description: "describes the issuance of workload certificates by the Istio CA"
+    statusProto: "istio.meta.v1alpha1.IstioStatus"
+    statusProtoPackage: "istio.io/api/meta/v1alpha1"
+
 # Transform specific configurations
 transforms:
   - type: direct
@@ -572,9 +633,12 @@ transforms:
       "k8s/networking.istio.io/v1alpha3/workloadgroups": "istio/networking/v1alpha3/workloadgroups"
       "k8s/networking.istio.io/v1alpha3/sidecars": "istio/networking/v1alpha3/sidecars"
       "k8s/networking.istio.io/v1alpha3/virtualservices": "istio/networking/v1alpha3/virtualservices"
+      "k8s/networking.istio.io/v1beta1/localratelimits": "istio/networking/v1beta1/localratelimits"
+      "k8s/networking.istio.io/v1beta1/proxyconfigs": "istio/networking/v1beta1/proxyconfigs"
       "k8s/security.istio.io/v1beta1/authorizationpolicies": "istio/security/v1beta1/authorizationpolicies"
       "k8s/security.istio.io/v1beta1/requestauthentications": "istio/security/v1beta1/requestauthentications"
       "k8s/security.istio.io/v1beta1/peerauthentications": "istio/security/v1beta1/peerauthentications"
+      "k8s/security.istio.io/v1beta1/certificateissuancepolicies": "istio/security/v1beta1/certificateissuancepolicies"
       "k8s/apps/v1/deployments": "k8s/apps/v1/deployments"
       "k8s/core/v1/namespaces": "k8s/core/v1/namespaces"
       "k8s/core/v1/pods": "k8s/core/v1/pods"
@@ -17,6 +17,7 @@ package validation
 import (
 	"errors"
 	"fmt"
+	"math/big"
 	"net"
 	"net/http"
 	"path"
@@ -41,6 +42,8 @@ import (
 	type_beta "istio.io/api/type/v1beta1"
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pkg/config"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+	securityv1beta1 "istio.io/istio/pkg/config/apis/security/v1beta1"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/gateway"
 	"istio.io/istio/pkg/config/host"
@@ -1501,6 +1504,126 @@ func ValidateProxyConfig(config *meshconfig.ProxyConfig) (errs error) {
 	return
 }
 
+// ValidateProxyConfigResource checks that a ProxyConfig resource is well-formed.
+var ValidateProxyConfigResource = registerValidateFunc("ValidateProxyConfigResource",
+	func(cfg config.Config) (Warning, error) {
+		spec, ok := cfg.Spec.(*networkingv1beta1.ProxyConfigSpec)
+		if !ok {
+			return nil, errors.New("cannot cast to proxy config")
+		}
+
+		var errs error
+		if spec.Selector != nil {
+			errs = appendErrors(errs, validateWorkloadSelector(&type_beta.WorkloadSelector{MatchLabels: spec.Selector.MatchLabels}))
+			errs = appendErrors(errs, labels.Instance(spec.Selector.MatchLabels).Validate())
+		}
+		if spec.Concurrency != nil && *spec.Concurrency < 0 {
+			errs = appendErrors(errs, fmt.Errorf("concurrency must be greater than or equal to 0"))
+		}
+		if spec.DrainDuration != nil && spec.ParentShutdownDuration != nil {
+			errs = appendErrors(errs, ValidateParentAndDrain(
+				types.DurationProto(spec.DrainDuration.Duration), types.DurationProto(spec.ParentShutdownDuration.Duration)))
+		} else {
+			if spec.DrainDuration != nil {
+				errs = appendErrors(errs, multierror.Prefix(ValidateDuration(types.DurationProto(spec.DrainDuration.Duration)),
+					"invalid drain duration:"))
+			}
+			if spec.ParentShutdownDuration != nil {
+				errs = appendErrors(errs, multierror.Prefix(ValidateDuration(types.DurationProto(spec.ParentShutdownDuration.Duration)),
+					"invalid parent shutdown duration:"))
+			}
+		}
+		if spec.TerminationDrainDuration != nil {
+			errs = appendErrors(errs, multierror.Prefix(ValidateDuration(types.DurationProto(spec.TerminationDrainDuration.Duration)),
+				"invalid termination drain duration:"))
+		}
+		if spec.Tracing != nil && spec.Tracing.Sampling != nil {
+			if s := *spec.Tracing.Sampling; s < 0 || s > 100 {
+				errs = appendErrors(errs, fmt.Errorf("tracing sampling must be in range [0.0, 100.0]"))
+			}
+		}
+		if m := spec.ProxyStatsMatcher; m != nil {
+			for _, re := range m.InclusionRegexps {
+				if _, err := regexp.Compile(re); err != nil {
+					errs = appendErrors(errs, fmt.Errorf("invalid proxy stats matcher regexp %q: %v", re, err))
+				}
+			}
+		}
+		return nil, errs
+	})
+
+// ValidateLocalRateLimit checks that a LocalRateLimit resource is well-formed.
+var ValidateLocalRateLimit = registerValidateFunc("ValidateLocalRateLimit",
+	func(cfg config.Config) (Warning, error) {
+		spec, ok := cfg.Spec.(*networkingv1beta1.LocalRateLimitSpec)
+		if !ok {
+			return nil, errors.New("cannot cast to local rate limit")
+		}
+
+		var errs error
+		if spec.Selector != nil {
+			errs = appendErrors(errs, validateWorkloadSelector(&type_beta.WorkloadSelector{MatchLabels: spec.Selector.MatchLabels}))
+			errs = appendErrors(errs, labels.Instance(spec.Selector.MatchLabels).Validate())
+		}
+		if len(spec.Rules) == 0 {
+			errs = appendErrors(errs, errors.New("local rate limit must have at least one rule"))
+		}
+		for i, rule := range spec.Rules {
+			if rule.Match != nil {
+				for _, port := range rule.Match.Ports {
+					errs = appendErrors(errs, multierror.Prefix(ValidatePort(int(port)), fmt.Sprintf("rule %d:", i)))
+				}
+				for _, route := range rule.Match.Routes {
+					if route == "" {
+						errs = appendErrors(errs, fmt.Errorf("rule %d: route name must not be empty", i))
+					}
+				}
+			}
+			errs = appendErrors(errs, multierror.Prefix(validateTokenBucket(rule.TokenBucket), fmt.Sprintf("rule %d:", i)))
+			for j, d := range rule.Descriptors {
+				prefix := fmt.Sprintf("rule %d descriptor %d:", i, j)
+				sources := 0
+				if d.Header != nil {
+					sources++
+					errs = appendErrors(errs, multierror.Prefix(ValidateHTTPHeaderName(d.Header.Name), prefix))
+				}
+				if d.SourcePrincipal != "" {
+					sources++
+				}
+				if d.PathPrefix != "" {
+					sources++
+					if !strings.HasPrefix(d.PathPrefix, "/") {
+						errs = appendErrors(errs, fmt.Errorf("%s path prefix must start with /", prefix))
+					}
+				}
+				if sources != 1 {
+					errs = appendErrors(errs, fmt.Errorf("%s exactly one of header, sourcePrincipal or pathPrefix must be set", prefix))
+				}
+				errs = appendErrors(errs, multierror.Prefix(validateTokenBucket(d.TokenBucket), prefix))
+				// Envoy rejects descriptors whose fill interval is not a multiple of the fill interval of the filter.
+				if fill := rule.TokenBucket.FillInterval.Duration; fill > 0 && d.TokenBucket.FillInterval.Duration%fill != 0 {
+					errs = appendErrors(errs, fmt.Errorf("%s fill interval %v must be a multiple of the rule fill interval %v",
+						prefix, d.TokenBucket.FillInterval.Duration, fill))
+				}
+			}
+		}
+		return nil, errs
+	})
+
+func validateTokenBucket(bucket networkingv1beta1.TokenBucket) error {
+	var errs error
+	if bucket.MaxTokens == 0 {
+		errs = appendErrors(errs, errors.New("token bucket max tokens must be greater than 0"))
+	}
+	if bucket.TokensPerFill != nil && *bucket.TokensPerFill == 0 {
+		errs = appendErrors(errs, errors.New("token bucket tokens per fill must be greater than 0"))
+	}
+	if bucket.FillInterval.Duration < 50*time.Millisecond {
+		errs = appendErrors(errs, errors.New("token bucket fill interval must be at least 50ms"))
+	}
+	return errs
+}
+
 func validateWorkloadSelector(selector *type_beta.WorkloadSelector) error {
 	var errs error
 	if selector != nil {
@@ -1749,6 +1872,38 @@ var ValidatePeerAuthentication = registerValidateFunc("ValidatePeerAuthenticatio
 		return nil, errs
 	})
 
+// ValidateCertificateIssuancePolicy checks that a CertificateIssuancePolicy resource is well-formed.
+var ValidateCertificateIssuancePolicy = registerValidateFunc("ValidateCertificateIssuancePolicy",
+	func(cfg config.Config) (Warning, error) {
+		spec, ok := cfg.Spec.(*securityv1beta1.CertificateIssuancePolicySpec)
+		if !ok {
+			return nil, errors.New("cannot cast to certificate issuance policy")
+		}
+
+		var errs error
+		for _, sa := range spec.ServiceAccounts {
+			if sa == "" || strings.Contains(sa, "/") {
+				errs = appendErrors(errs, fmt.Errorf("invalid service account %q", sa))
+			}
+		}
+		switch spec.Action {
+		case "", securityv1beta1.ActionAllow, securityv1beta1.ActionDeny:
+		default:
+			errs = appendErrors(errs, fmt.Errorf("invalid action %q, must be %s or %s",
+				spec.Action, securityv1beta1.ActionAllow, securityv1beta1.ActionDeny))
+		}
+		if spec.MaxCertTTL != nil {
+			errs = appendErrors(errs, multierror.Prefix(ValidateDuration(types.DurationProto(spec.MaxCertTTL.Duration)),
+				"invalid max cert TTL:"))
+		}
+		for _, serial := range spec.DeniedSerialNumbers {
+			if _, ok := new(big.Int).SetString(strings.ReplaceAll(serial, ":", ""), 16); !ok {
+				errs = appendErrors(errs, fmt.Errorf("invalid serial number %q, must be hex encoded", serial))
+			}
+		}
+		return nil, errs
+	})
+
 // ValidateVirtualService checks that a v1alpha3 route rule is well-formed.
 var ValidateVirtualService = registerValidateFunc("ValidateVirtualService",
 	func(cfg config.Config) (Warning, error) {
@@ -2447,9 +2602,10 @@ var ValidateServiceEntry = registerValidateFunc("ValidateServiceEntry",
 		case networking.ServiceEntry_DNS:
 			if len(serviceEntry.Endpoints) == 0 {
 				for _, hostname := range serviceEntry.Hosts {
-					if err := ValidateFQDN(hostname); err != nil {
+					// Wildcard hosts are resolved by the proxy for each requested host.
+					if err := ValidateFQDN(strings.TrimPrefix(hostname, "*.")); err != nil {
 						errs = appendValidation(errs,
-							fmt.Errorf("hosts must be FQDN if no endpoints are provided for resolution mode DNS"))
+							fmt.Errorf("hosts must be FQDN or wildcard domains if no endpoints are provided for resolution mode DNS"))
 					}
 				}
 			}
@@ -23,6 +23,7 @@ import (
 	"github.com/gogo/protobuf/proto"
 	"github.com/gogo/protobuf/types"
 	"github.com/hashicorp/go-multierror"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	meshconfig "istio.io/api/mesh/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
@@ -30,6 +31,8 @@ import (
 	api "istio.io/api/type/v1beta1"
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pkg/config"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+	securityv1beta1 "istio.io/istio/pkg/config/apis/security/v1beta1"
 	"istio.io/istio/pkg/config/constants"
 )
 
@@ -1033,7 +1036,7 @@ func TestValidateServerPort(t *testing.T) {
 		{
 			"invalid protocol",
 			&networking.Port{
-				Protocol: "kafka",
+				Protocol: "cassandra",
 				Number:   1,
 				Name:     "Henry",
 			},
@@ -2575,6 +2578,240 @@ func TestValidateWorkloadEntry(t *testing.T) {
 	}
 }
 
+func TestValidateProxyConfigResource(t *testing.T) {
+	concurrency := func(c int32) *int32 { return &c }
+	sampling := func(s float64) *float64 { return &s }
+	duration := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	testCases := []struct {
+		name  string
+		in    *networkingv1beta1.ProxyConfigSpec
+		valid bool
+	}{
+		{
+			name: "valid",
+			in: &networkingv1beta1.ProxyConfigSpec{
+				Selector:                 &networkingv1beta1.WorkloadSelector{MatchLabels: map[string]string{"app": "ratings"}},
+				Concurrency:              concurrency(2),
+				DrainDuration:            duration(5 * time.Second),
+				ParentShutdownDuration:   duration(10 * time.Second),
+				TerminationDrainDuration: duration(30 * time.Second),
+				Tracing:                  &networkingv1beta1.ProxyTracing{Sampling: sampling(100)},
+				ProxyStatsMatcher:        &networkingv1beta1.ProxyStatsMatcher{InclusionRegexps: []string{".*outlier_detection.*"}},
+			},
+			valid: true,
+		},
+		{
+			name:  "empty",
+			in:    &networkingv1beta1.ProxyConfigSpec{},
+			valid: true,
+		},
+		{
+			name:  "wildcard selector",
+			in:    &networkingv1beta1.ProxyConfigSpec{Selector: &networkingv1beta1.WorkloadSelector{MatchLabels: map[string]string{"app": "*"}}},
+			valid: false,
+		},
+		{
+			name:  "negative concurrency",
+			in:    &networkingv1beta1.ProxyConfigSpec{Concurrency: concurrency(-1)},
+			valid: false,
+		},
+		{
+			name:  "parent shutdown shorter than drain",
+			in:    &networkingv1beta1.ProxyConfigSpec{DrainDuration: duration(10 * time.Second), ParentShutdownDuration: duration(5 * time.Second)},
+			valid: false,
+		},
+		{
+			name:  "sub-millisecond termination drain duration",
+			in:    &networkingv1beta1.ProxyConfigSpec{TerminationDrainDuration: duration(time.Microsecond)},
+			valid: false,
+		},
+		{
+			name:  "sampling out of range",
+			in:    &networkingv1beta1.ProxyConfigSpec{Tracing: &networkingv1beta1.ProxyTracing{Sampling: sampling(101)}},
+			valid: false,
+		},
+		{
+			name:  "invalid stats regexp",
+			in:    &networkingv1beta1.ProxyConfigSpec{ProxyStatsMatcher: &networkingv1beta1.ProxyStatsMatcher{InclusionRegexps: []string{"("}}},
+			valid: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			warn, err := ValidateProxyConfigResource(config.Config{Spec: tc.in})
+			checkValidation(t, warn, err, tc.valid, false)
+		})
+	}
+}
+
+func TestValidateLocalRateLimit(t *testing.T) {
+	bucket := func(max uint32, fill time.Duration) networkingv1beta1.TokenBucket {
+		return networkingv1beta1.TokenBucket{MaxTokens: max, FillInterval: metav1.Duration{Duration: fill}}
+	}
+	testCases := []struct {
+		name  string
+		in    *networkingv1beta1.LocalRateLimitSpec
+		valid bool
+	}{
+		{
+			name: "valid",
+			in: &networkingv1beta1.LocalRateLimitSpec{
+				Selector: &networkingv1beta1.WorkloadSelector{MatchLabels: map[string]string{"app": "ratings"}},
+				Rules: []networkingv1beta1.LocalRateLimitRule{{
+					Match:       &networkingv1beta1.LocalRateLimitMatch{Ports: []uint32{9080}, Routes: []string{"ratings"}},
+					TokenBucket: bucket(100, time.Second),
+					Descriptors: []networkingv1beta1.LocalRateLimitDescriptor{
+						{Header: &networkingv1beta1.LocalRateLimitHeader{Name: "x-user-tier", Value: "free"}, TokenBucket: bucket(10, time.Second)},
+						{SourcePrincipal: "cluster.local/ns/default/sa/sleep", TokenBucket: bucket(10, time.Minute)},
+						{PathPrefix: "/ratings", TokenBucket: bucket(10, 2*time.Second)},
+					},
+				}},
+			},
+			valid: true,
+		},
+		{
+			name:  "no rules",
+			in:    &networkingv1beta1.LocalRateLimitSpec{},
+			valid: false,
+		},
+		{
+			name: "wildcard selector",
+			in: &networkingv1beta1.LocalRateLimitSpec{
+				Selector: &networkingv1beta1.WorkloadSelector{MatchLabels: map[string]string{"app": "*"}},
+				Rules:    []networkingv1beta1.LocalRateLimitRule{{TokenBucket: bucket(100, time.Second)}},
+			},
+			valid: false,
+		},
+		{
+			name: "invalid port",
+			in: &networkingv1beta1.LocalRateLimitSpec{Rules: []networkingv1beta1.LocalRateLimitRule{{
+				Match:       &networkingv1beta1.LocalRateLimitMatch{Ports: []uint32{0}},
+				TokenBucket: bucket(100, time.Second),
+			}}},
+			valid: false,
+		},
+		{
+			name:  "no max tokens",
+			in:    &networkingv1beta1.LocalRateLimitSpec{Rules: []networkingv1beta1.LocalRateLimitRule{{TokenBucket: bucket(0, time.Second)}}},
+			valid: false,
+		},
+		{
+			name:  "fill interval too short",
+			in:    &networkingv1beta1.LocalRateLimitSpec{Rules: []networkingv1beta1.LocalRateLimitRule{{TokenBucket: bucket(100, 10*time.Millisecond)}}},
+			valid: false,
+		},
+		{
+			name: "descriptor without source",
+			in: &networkingv1beta1.LocalRateLimitSpec{Rules: []networkingv1beta1.LocalRateLimitRule{{
+				TokenBucket: bucket(100, time.Second),
+				Descriptors: []networkingv1beta1.LocalRateLimitDescriptor{{TokenBucket: bucket(10, time.Second)}},
+			}}},
+			valid: false,
+		},
+		{
+			name: "descriptor with several sources",
+			in: &networkingv1beta1.LocalRateLimitSpec{Rules: []networkingv1beta1.LocalRateLimitRule{{
+				TokenBucket: bucket(100, time.Second),
+				Descriptors: []networkingv1beta1.LocalRateLimitDescriptor{{
+					SourcePrincipal: "cluster.local/ns/default/sa/sleep",
+					PathPrefix:      "/ratings",
+					TokenBucket:     bucket(10, time.Second),
+				}},
+			}}},
+			valid: false,
+		},
+		{
+			name: "empty header name",
+			in: &networkingv1beta1.LocalRateLimitSpec{Rules: []networkingv1beta1.LocalRateLimitRule{{
+				TokenBucket: bucket(100, time.Second),
+				Descriptors: []networkingv1beta1.LocalRateLimitDescriptor{{
+					Header:      &networkingv1beta1.LocalRateLimitHeader{Value: "free"},
+					TokenBucket: bucket(10, time.Second),
+				}},
+			}}},
+			valid: false,
+		},
+		{
+			name: "relative path prefix",
+			in: &networkingv1beta1.LocalRateLimitSpec{Rules: []networkingv1beta1.LocalRateLimitRule{{
+				TokenBucket: bucket(100, time.Second),
+				Descriptors: []networkingv1beta1.LocalRateLimitDescriptor{{PathPrefix: "ratings", TokenBucket: bucket(10, time.Second)}},
+			}}},
+			valid: false,
+		},
+		{
+			name: "descriptor fill interval not a multiple",
+			in: &networkingv1beta1.LocalRateLimitSpec{Rules: []networkingv1beta1.LocalRateLimitRule{{
+				TokenBucket: bucket(100, time.Second),
+				Descriptors: []networkingv1beta1.LocalRateLimitDescriptor{{PathPrefix: "/ratings", TokenBucket: bucket(10, 1500*time.Millisecond)}},
+			}}},
+			valid: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			warn, err := ValidateLocalRateLimit(config.Config{Spec: tc.in})
+			checkValidation(t, warn, err, tc.valid, false)
+		})
+	}
+}
+
+func TestValidateCertificateIssuancePolicy(t *testing.T) {
+	duration := func(d time.Duration) *metav1.Duration { return &metav1.Duration{Duration: d} }
+	testCases := []struct {
+		name  string
+		in    *securityv1beta1.CertificateIssuancePolicySpec
+		valid bool
+	}{
+		{
+			name: "valid",
+			in: &securityv1beta1.CertificateIssuancePolicySpec{
+				ServiceAccounts:     []string{"bookinfo-ratings"},
+				Action:              securityv1beta1.ActionAllow,
+				MaxCertTTL:          duration(time.Hour),
+				DeniedSerialNumbers: []string{"0a:bc:de", "1F"},
+			},
+			valid: true,
+		},
+		{
+			name:  "empty",
+			in:    &securityv1beta1.CertificateIssuancePolicySpec{},
+			valid: true,
+		},
+		{
+			name:  "deny",
+			in:    &securityv1beta1.CertificateIssuancePolicySpec{Action: securityv1beta1.ActionDeny},
+			valid: true,
+		},
+		{
+			name:  "invalid action",
+			in:    &securityv1beta1.CertificateIssuancePolicySpec{Action: "AUDIT"},
+			valid: false,
+		},
+		{
+			name:  "invalid service account",
+			in:    &securityv1beta1.CertificateIssuancePolicySpec{ServiceAccounts: []string{"ns/sa"}},
+			valid: false,
+		},
+		{
+			name:  "negative max cert TTL",
+			in:    &securityv1beta1.CertificateIssuancePolicySpec{MaxCertTTL: duration(-time.Hour)},
+			valid: false,
+		},
+		{
+			name:  "invalid serial number",
+			in:    &securityv1beta1.CertificateIssuancePolicySpec{DeniedSerialNumbers: []string{"xyz"}},
+			valid: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			warn, err := ValidateCertificateIssuancePolicy(config.Config{Spec: tc.in})
+			checkValidation(t, warn, err, tc.valid, false)
+		})
+	}
+}
+
 func TestValidateWorkloadGroup(t *testing.T) {
 	testCases := []struct {
 		name    string
@@ -3832,7 +4069,7 @@ func TestValidateServiceEntries(t *testing.T) {
 
 		{
 			name: "discovery type DNS, non-FQDN host", in: networking.ServiceEntry{
-				Hosts: []string{"*.google.com"},
+				Hosts: []string{"*.*.google.com"},
 				Ports: []*networking.Port{
 					{Number: 80, Protocol: "http", Name: "http-valid1"},
 					{Number: 8080, Protocol: "http", Name: "http-valid2"},
@@ -3843,6 +4080,31 @@ func TestValidateServiceEntries(t *testing.T) {
 			valid: false,
 		},
 
+		{
+			name: "discovery type DNS, wildcard host", in: networking.ServiceEntry{
+				Hosts: []string{"*.google.com"},
+				Ports: []*networking.Port{
+					{Number: 80, Protocol: "http", Name: "http-valid1"},
+					{Number: 443, Protocol: "tls", Name: "tls-valid2"},
+				},
+
+				Resolution: networking.ServiceEntry_DNS,
+			},
+			valid: true,
+		},
+
+		{
+			name: "discovery type DNS, full wildcard host", in: networking.ServiceEntry{
+				Hosts: []string{"*"},
+				Ports: []*networking.Port{
+					{Number: 80, Protocol: "http", Name: "http-valid1"},
+				},
+
+				Resolution: networking.ServiceEntry_DNS,
+			},
+			valid: false,
+		},
+
 		{
 			name: "discovery type DNS, no endpoints", in: networking.ServiceEntry{
 				Hosts: []string{"google.com"},
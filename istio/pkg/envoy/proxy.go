@@ -150,6 +150,13 @@ func (e *envoy) args(fname string, epoch int, bootstrapConfig string) []string {
 var istioBootstrapOverrideVar = env.RegisterStringVar("ISTIO_BOOTSTRAP_OVERRIDE", "", "")
 
 func (e *envoy) Run(config interface{}, epoch int, abort <-chan error) error {
+	// A restart with a ProxyConfig, such as one resolved from the ProxyConfig resources pushed by istiod,
+	// bootstraps the new epoch with it.
+	if pc, ok := config.(*meshconfig.ProxyConfig); ok {
+		e = &envoy{ProxyConfig: e.ProxyConfig, extraArgs: e.extraArgs}
+		e.Config = *pc
+	}
+
 	var fname string
 	// Note: the cert checking still works, the generated file is updated if certs are changed.
 	// We just don't save the generated file, but use a custom one instead. Pilot will keep
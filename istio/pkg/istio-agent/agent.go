@@ -20,10 +20,14 @@ import (
 	"os"
 	"path"
 	"strings"
+	"sync"
+
+	"github.com/gogo/protobuf/proto"
 
 	mesh "istio.io/api/mesh/v1alpha1"
 	"istio.io/istio/pilot/pkg/dns"
 	"istio.io/istio/pilot/pkg/model"
+	"istio.io/istio/pkg/bootstrap"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/security"
 	"istio.io/istio/security/pkg/nodeagent/cache"
@@ -89,6 +93,10 @@ type Agent struct {
 
 	// local DNS Server that processes DNS requests locally and forwards to upstream DNS if needed.
 	localDNSServer *dns.LocalDNSServer
+
+	// bootstrapProxyConfig is the ProxyConfig Envoy was last bootstrapped with, after the overrides pushed by istiod.
+	bootstrapProxyConfig      *mesh.ProxyConfig
+	bootstrapProxyConfigMutex sync.Mutex
 }
 
 // AgentConfig contains additional config for the agent, not included in ProxyConfig.
@@ -127,6 +135,14 @@ type AgentConfig struct {
 
 	// Path to local UDS to communicate with Envoy
 	XdsUdsPath string
+
+	// ProxyConfigUpdated, if set, is called with the ProxyConfig Envoy should be bootstrapped with whenever the
+	// ProxyConfig resources pushed by istiod over PCDS change it.
+	ProxyConfigUpdated func(*mesh.ProxyConfig)
+
+	// PinnedProxyConfig holds the fields set by the pod's proxy.istio.io/config annotation. ProxyConfig
+	// resources pushed by istiod do not override them.
+	PinnedProxyConfig *mesh.ProxyConfig
 }
 
 // NewAgent hosts the functionality for local SDS and XDS. This consists of the local SDS server and
@@ -134,14 +150,34 @@ type AgentConfig struct {
 // health checking for VMs and DNS proxying).
 func NewAgent(proxyConfig *mesh.ProxyConfig, cfg *AgentConfig, sopts security.Options) *Agent {
 	sa := &Agent{
-		proxyConfig: proxyConfig,
-		cfg:         cfg,
-		secOpts:     sopts,
+		proxyConfig:          proxyConfig,
+		cfg:                  cfg,
+		secOpts:              sopts,
+		bootstrapProxyConfig: proxyConfig,
 	}
 
 	return sa
 }
 
+// updateProxyConfig applies the effective ProxyConfig pushed by istiod, the mesh default with the ProxyConfig
+// resources of the proxy applied, to the startup ProxyConfig, and notifies ProxyConfigUpdated if the resulting
+// bootstrap configuration changed.
+func (sa *Agent) updateProxyConfig(effective *mesh.ProxyConfig) {
+	if sa.cfg.ProxyConfigUpdated == nil {
+		return
+	}
+	pc := bootstrap.ApplyProxyConfigOverrides(sa.proxyConfig, effective, sa.cfg.PinnedProxyConfig)
+
+	sa.bootstrapProxyConfigMutex.Lock()
+	defer sa.bootstrapProxyConfigMutex.Unlock()
+	if proto.Equal(pc, sa.bootstrapProxyConfig) {
+		return
+	}
+	log.Infof("ProxyConfig changed, restarting Envoy with the new bootstrap configuration")
+	sa.bootstrapProxyConfig = pc
+	sa.cfg.ProxyConfigUpdated(pc)
+}
+
 // Simplified SDS setup. This is called if and only if user has explicitly mounted a K8S JWT token, and is not
 // using a hostPath mounted or external SDS server.
 //
@@ -147,24 +147,26 @@ func initXdsProxy(ia *Agent) (*XdsProxy, error) {
 			return nil
 		}
 	}
-	if ia.secretCache != nil {
-		proxy.handlers[v3.ProxyConfigType] = func(resp *discovery.DiscoveryResponse) error {
-			if len(resp.Resources) == 0 {
-				return fmt.Errorf("empty response")
-			}
-			var pc meshconfig.ProxyConfig
-			if err := gogotypes.UnmarshalAny(gogo.ConvertAny(resp.Resources[0]), &pc); err != nil {
-				log.Errorf("failed to unmarshall proxy config: %v", err)
-				return err
-			}
-			caCerts := pc.GetCaCertificatesPem()
-			log.Debugf("received new certificates to add to mesh trust domain: %v", caCerts)
-			trustBundle := []byte{}
-			for _, cert := range caCerts {
-				trustBundle = util.AppendCertByte(trustBundle, []byte(cert))
-			}
-			return ia.secretCache.UpdateConfigTrustBundle(trustBundle)
+	proxy.handlers[v3.ProxyConfigType] = func(resp *discovery.DiscoveryResponse) error {
+		if len(resp.Resources) == 0 {
+			return fmt.Errorf("empty response")
+		}
+		var pc meshconfig.ProxyConfig
+		if err := gogotypes.UnmarshalAny(gogo.ConvertAny(resp.Resources[0]), &pc); err != nil {
+			log.Errorf("failed to unmarshall proxy config: %v", err)
+			return err
+		}
+		ia.updateProxyConfig(&pc)
+		caCerts := pc.GetCaCertificatesPem()
+		if ia.secretCache == nil || len(caCerts) == 0 {
+			return nil
+		}
+		log.Debugf("received new certificates to add to mesh trust domain: %v", caCerts)
+		trustBundle := []byte{}
+		for _, cert := range caCerts {
+			trustBundle = util.AppendCertByte(trustBundle, []byte(cert))
 		}
+		return ia.secretCache.UpdateConfigTrustBundle(trustBundle)
 	}
 
 	proxyLog.Infof("Initializing with upstream address %q and cluster %q", proxy.istiodAddress, proxy.clusterID)
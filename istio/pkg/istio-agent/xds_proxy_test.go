@@ -29,13 +29,16 @@ import (
 	wasm "github.com/envoyproxy/go-control-plane/envoy/extensions/filters/http/wasm/v3"
 	wasmv3 "github.com/envoyproxy/go-control-plane/envoy/extensions/wasm/v3"
 	discovery "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	gogotypes "github.com/gogo/protobuf/types"
 	"github.com/golang/protobuf/ptypes"
+	"github.com/golang/protobuf/ptypes/any"
 	google_rpc "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/test/bufconn"
 	"google.golang.org/protobuf/proto"
 
+	meshconfig "istio.io/api/mesh/v1alpha1"
 	networking "istio.io/api/networking/v1alpha3"
 	"istio.io/istio/pilot/pkg/features"
 	"istio.io/istio/pilot/pkg/model"
@@ -50,6 +53,7 @@ import (
 	"istio.io/istio/pkg/test"
 	"istio.io/istio/pkg/test/env"
 	"istio.io/istio/pkg/test/util/retry"
+	"istio.io/istio/pkg/util/gogo"
 )
 
 // Validates basic xds proxy flow by proxying one CDS requests end to end.
@@ -551,3 +555,70 @@ func setupDownstreamConnectionUDS(t test.Failer, path string) *grpc.ClientConn {
 func setupDownstreamConnection(t *testing.T, proxy *XdsProxy) *grpc.ClientConn {
 	return setupDownstreamConnectionUDS(t, proxy.xdsUdsPath)
 }
+
+func TestXdsProxyProxyConfigUpdate(t *testing.T) {
+	proxyConfig := mesh.DefaultProxyConfig()
+	proxyConfig.DiscoveryAddress = "buffcon"
+	proxyConfig.ProxyMetadata = map[string]string{
+		MetadataClientCertChain: path.Join(env.IstioSrc, "tests/testdata/certs/pilot/cert-chain.pem"),
+		MetadataClientCertKey:   path.Join(env.IstioSrc, "tests/testdata/certs/pilot/key.pem"),
+		MetadataClientRootCert:  path.Join(env.IstioSrc, "tests/testdata/certs/pilot/root-cert.pem"),
+	}
+	var updates []*meshconfig.ProxyConfig
+	ia := NewAgent(&proxyConfig, &AgentConfig{
+		XdsUdsPath: filepath.Join(t.TempDir(), "XDS"),
+		ProxyConfigUpdated: func(pc *meshconfig.ProxyConfig) {
+			updates = append(updates, pc)
+		},
+		PinnedProxyConfig: &meshconfig.ProxyConfig{DrainDuration: proxyConfig.DrainDuration},
+	}, security.Options{FileMountedCerts: true})
+	t.Cleanup(ia.Close)
+	proxy, err := initXdsProxy(ia)
+	if err != nil {
+		t.Fatalf("Failed to initialize xds proxy %v", err)
+	}
+
+	push := func(pc *meshconfig.ProxyConfig) {
+		t.Helper()
+		resp := &discovery.DiscoveryResponse{
+			TypeUrl:   v3.ProxyConfigType,
+			Resources: []*any.Any{gogo.MessageToAny(pc)},
+		}
+		if err := proxy.handlers[v3.ProxyConfigType](resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Nothing overridden, Envoy keeps its bootstrap.
+	push(&meshconfig.ProxyConfig{})
+	if len(updates) != 0 {
+		t.Fatalf("expected no update, got %v", updates)
+	}
+
+	overrides := &meshconfig.ProxyConfig{
+		Concurrency:   &gogotypes.Int32Value{Value: 4},
+		DrainDuration: gogotypes.DurationProto(time.Second),
+	}
+	push(overrides)
+	if len(updates) != 1 {
+		t.Fatalf("expected 1 update, got %v", updates)
+	}
+	if updates[0].GetConcurrency().GetValue() != 4 {
+		t.Fatalf("expected concurrency 4, got %v", updates[0].GetConcurrency())
+	}
+	if !updates[0].GetDrainDuration().Equal(proxyConfig.DrainDuration) {
+		t.Fatalf("expected the pinned drain duration, got %v", updates[0].GetDrainDuration())
+	}
+
+	// The same configuration does not restart Envoy again.
+	push(overrides)
+	if len(updates) != 1 {
+		t.Fatalf("expected 1 update, got %v", updates)
+	}
+
+	// Removing the override reverts to the startup configuration.
+	push(&meshconfig.ProxyConfig{})
+	if len(updates) != 2 || updates[1].GetConcurrency().GetValue() != proxyConfig.GetConcurrency().GetValue() {
+		t.Fatalf("expected the startup concurrency, got %v", updates)
+	}
+}
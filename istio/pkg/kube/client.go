@@ -73,6 +73,8 @@ import (
 	istioclient "istio.io/client-go/pkg/clientset/versioned"
 	istiofake "istio.io/client-go/pkg/clientset/versioned/fake"
 	istioinformer "istio.io/client-go/pkg/informers/externalversions"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
+	securityv1beta1 "istio.io/istio/pkg/config/apis/security/v1beta1"
 	"istio.io/pkg/version"
 )
 
@@ -209,7 +211,13 @@ func NewFakeClient(objects ...runtime.Object) ExtendedClient {
 	c.metadata = metadatafake.NewSimpleMetadataClient(s)
 	c.metadataInformer = metadatainformer.NewSharedInformerFactory(c.metadata, resyncInterval)
 
-	c.dynamic = dynamicfake.NewSimpleDynamicClient(s)
+	// Istio kinds without a generated client are only accessible through the dynamic client, which needs to
+	// know their list kinds.
+	c.dynamic = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(s, map[schema.GroupVersionResource]string{
+		networkingv1beta1.SchemeGroupVersion.WithResource("localratelimits"):           "LocalRateLimitList",
+		networkingv1beta1.SchemeGroupVersion.WithResource("proxyconfigs"):              "ProxyConfigList",
+		securityv1beta1.SchemeGroupVersion.WithResource("certificateissuancepolicies"): "CertificateIssuancePolicyList",
+	})
 	c.dynamicInformer = dynamicinformer.NewDynamicSharedInformerFactory(c.dynamic, resyncInterval)
 
 	istioFake := istiofake.NewSimpleClientset()
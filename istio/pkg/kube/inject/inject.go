@@ -29,6 +29,7 @@ import (
 	"github.com/Masterminds/sprig/v3"
 	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/ghodss/yaml"
+	"github.com/gogo/protobuf/proto"
 	"github.com/hashicorp/go-multierror"
 	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/api/batch/v2alpha1"
@@ -280,6 +281,12 @@ func RunTemplate(params InjectionParameters) (mergedPod *corev1.Pod, templatePod
 		return nil, nil, err
 	}
 
+	if params.proxyConfigs != nil {
+		mc := proto.Clone(meshConfig).(*meshconfig.MeshConfig)
+		mc.DefaultConfig = params.proxyConfigs.EffectiveProxyConfig(metadata.Namespace, metadata.Labels, meshConfig)
+		meshConfig = mc
+	}
+
 	if pca, f := metadata.GetAnnotations()[annotation.ProxyConfig.Name]; f {
 		var merr error
 		meshConfig, merr = mesh.ApplyProxyConfig(pca, *meshConfig)
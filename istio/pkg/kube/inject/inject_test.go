@@ -27,14 +27,21 @@ import (
 	"github.com/gogo/protobuf/types"
 	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	meshapi "istio.io/api/mesh/v1alpha1"
+	"istio.io/istio/pilot/pkg/config/memory"
 	"istio.io/istio/pilot/pkg/features"
+	"istio.io/istio/pilot/pkg/model"
 	"istio.io/istio/pilot/pkg/util/sets"
 	"istio.io/istio/pilot/test/util"
+	"istio.io/istio/pkg/config"
+	networkingv1beta1 "istio.io/istio/pkg/config/apis/networking/v1beta1"
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/config/mesh"
+	"istio.io/istio/pkg/config/schema/collections"
+	"istio.io/istio/pkg/config/schema/gvk"
 	"istio.io/istio/pkg/kube"
 )
 
@@ -239,6 +246,14 @@ func TestInjection(t *testing.T) {
 			in:            "traffic-annotations-bad-excludeoutboundports.yaml",
 			expectedError: "excludeoutboundports",
 		},
+		{
+			in:            "traffic-annotations-bad-includeoutboundudpports.yaml",
+			expectedError: "includeoutboundudpports",
+		},
+		{
+			in:   "traffic-annotations-udp-ports.yaml",
+			want: "traffic-annotations-udp-ports.yaml.injected",
+		},
 		{
 			in:   "hello.yaml",
 			want: "hello-no-seccontext.yaml.injected",
@@ -726,6 +741,104 @@ func TestCleanProxyConfig(t *testing.T) {
 	}
 }
 
+func TestProxyConfigResources(t *testing.T) {
+	store := model.MakeIstioStore(memory.Make(collections.Pilot))
+	four, eight := int32(4), int32(8)
+	for _, pc := range []struct {
+		name, namespace string
+		spec            *networkingv1beta1.ProxyConfigSpec
+	}{
+		{"mesh", "istio-system", &networkingv1beta1.ProxyConfigSpec{
+			Concurrency:              &four,
+			TerminationDrainDuration: &metav1.Duration{Duration: 10 * time.Second},
+		}},
+		{"ratings", "default", &networkingv1beta1.ProxyConfigSpec{
+			Selector:    &networkingv1beta1.WorkloadSelector{MatchLabels: map[string]string{"app": "ratings"}},
+			Concurrency: &eight,
+		}},
+	} {
+		if _, err := store.Create(config.Config{
+			Meta: config.Meta{GroupVersionKind: gvk.ProxyConfig, Name: pc.name, Namespace: pc.namespace},
+			Spec: pc.spec,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m := mesh.DefaultMeshConfig()
+	webhook := &Webhook{
+		Config: &Config{
+			Templates: map[string]string{SidecarTemplateName: `
+spec:
+  containers:
+  - name: istio-proxy
+    image: proxy
+    args:
+    - --concurrency
+    - "{{ .ProxyConfig.Concurrency.GetValue }}"
+    - --termination-drain-duration
+    - "{{ formatDuration .ProxyConfig.TerminationDrainDuration }}"
+`},
+			Policy:           InjectionPolicyEnabled,
+			DefaultTemplates: []string{SidecarTemplateName},
+		},
+		meshConfig: &m,
+		env:        &model.Environment{IstioConfigStore: store},
+	}
+
+	// nolint: lll
+	expected := `
+apiVersion: v1
+kind: Pod
+metadata:
+  annotations:%s
+    prometheus.io/path: /stats/prometheus
+    prometheus.io/port: "15020"
+    prometheus.io/scrape: "true"
+    sidecar.istio.io/status: '{"version":"","initContainers":null,"containers":["istio-proxy"],"volumes":["istio-envoy","istio-data","istio-podinfo","istio-token","istiod-ca-cert"],"imagePullSecrets":null}'
+  labels:
+    app: %s
+  name: hello
+  namespace: default
+spec:
+  containers:
+    - name: hello
+      image: fake.docker.io/google-samples/hello-go-gke:1.0
+    - name: istio-proxy
+      image: proxy
+      args:
+      - --concurrency
+      - "%d"
+      - --termination-drain-duration
+      - 10s
+`
+	input := `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: hello
+  namespace: default
+  labels:
+    app: %s%s
+spec:
+  containers:
+  - name: hello
+    image: "fake.docker.io/google-samples/hello-go-gke:1.0"
+`
+	annotation := `
+  annotations:
+    proxy.istio.io/config: "concurrency: 2"`
+	outputAnnotation := `
+    proxy.istio.io/config: 'concurrency: 2'`
+
+	// The root namespace applies to all workloads.
+	runWebhook(t, webhook, []byte(fmt.Sprintf(input, "reviews", "")), []byte(fmt.Sprintf(expected, "", "reviews", 4)), false)
+	// The workload overrides the root namespace.
+	runWebhook(t, webhook, []byte(fmt.Sprintf(input, "ratings", "")), []byte(fmt.Sprintf(expected, "", "ratings", 8)), false)
+	// The annotation overrides the workload.
+	runWebhook(t, webhook, []byte(fmt.Sprintf(input, "ratings", annotation)),
+		[]byte(fmt.Sprintf(expected, outputAnnotation, "ratings", 2)), false)
+}
+
 func TestAppendMultusNetwork(t *testing.T) {
 	cases := []struct {
 		name string
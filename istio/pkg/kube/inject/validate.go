@@ -46,6 +46,7 @@ var (
 		annotation.SidecarTrafficIncludeInboundPorts.Name:         ValidateIncludeInboundPorts,
 		annotation.SidecarTrafficExcludeInboundPorts.Name:         ValidateExcludeInboundPorts,
 		annotation.SidecarTrafficExcludeOutboundPorts.Name:        ValidateExcludeOutboundPorts,
+		SidecarTrafficIncludeOutboundUDPPorts:                     ValidateIncludeOutboundUDPPorts,
 		annotation.PrometheusMergeMetrics.Name:                    validateBool,
 		annotation.ProxyConfig.Name:                               validateProxyConfig,
 	}
@@ -125,6 +126,11 @@ func ValidateExcludeOutboundPorts(ports string) error {
 	return validatePortList("excludeOutboundPorts", ports)
 }
 
+// ValidateIncludeOutboundUDPPorts validates the includeOutboundUDPPorts parameter
+func ValidateIncludeOutboundUDPPorts(ports string) error {
+	return validatePortList("includeOutboundUDPPorts", ports)
+}
+
 // validateStatusPort validates the statusPort parameter
 func validateStatusPort(port string) error {
 	if _, e := parsePort(port); e != nil {
@@ -333,6 +333,7 @@ type InjectionParameters struct {
 	revision            string
 	proxyEnvs           map[string]string
 	injectedAnnotations map[string]string
+	proxyConfigs        *model.ProxyConfigs
 }
 
 func checkPreconditions(params InjectionParameters) {
@@ -431,6 +432,11 @@ const OverrideAnnotation = "proxy.istio.io/overrides"
 // TODO move this to api repo
 const TemplatesAnnotation = "inject.istio.io/templates"
 
+// SidecarTrafficIncludeOutboundUDPPorts is a comma separated list of outbound UDP ports for which traffic is
+// redirected to Envoy.
+// TODO move this to api repo
+const SidecarTrafficIncludeOutboundUDPPorts = "traffic.sidecar.istio.io/includeOutboundUDPPorts"
+
 // reapplyOverwrittenContainers enables users to provide container level overrides for settings in the injection template
 // * originalPod: the pod before injection. If needed, we will apply some configurations from this pod on top of the final pod
 // * templatePod: the rendered injection template. This is needed only to see what containers we injected
@@ -781,6 +787,14 @@ func (wh *Webhook) inject(ar *kube.AdmissionReview, path string) *kube.Admission
 		}
 	}
 
+	var proxyConfigs *model.ProxyConfigs
+	if wh.env != nil && wh.env.IstioConfigStore != nil {
+		var err error
+		if proxyConfigs, err = model.GetProxyConfigs(wh.env, wh.meshConfig); err != nil {
+			log.Warnf("Failed to list ProxyConfigs, using mesh defaults for %s/%s: %v", pod.ObjectMeta.Namespace, podName, err)
+		}
+	}
+
 	deploy, typeMeta := kube.GetDeployMetaFromPod(&pod)
 	params := InjectionParameters{
 		pod:                 &pod,
@@ -794,6 +808,7 @@ func (wh *Webhook) inject(ar *kube.AdmissionReview, path string) *kube.Admission
 		revision:            wh.revision,
 		injectedAnnotations: wh.Config.InjectedAnnotations,
 		proxyEnvs:           parseInjectEnvs(path),
+		proxyConfigs:        proxyConfigs,
 	}
 	wh.mu.RUnlock()
 
@@ -0,0 +1,120 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretcontroller
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+
+	"istio.io/pkg/env"
+)
+
+var execCommandAllowlist = env.RegisterStringVar(
+	"PILOT_REMOTE_CLUSTER_EXEC_ALLOWLIST",
+	"",
+	"Comma separated list of the commands the kubeconfigs of the remote cluster secrets can run to get credentials, "+
+		"with exec credential plugins or the cmd-path of the gcp auth provider. Commands are matched on their full path. "+
+		"Kubeconfigs running other commands are rejected.",
+)
+
+var credentialDirs = env.RegisterStringVar(
+	"PILOT_REMOTE_CLUSTER_CREDENTIAL_DIRS",
+	"",
+	"Comma separated list of the directories the kubeconfigs of the remote cluster secrets can read token files, "+
+		"client certificates and client keys from, once their symlinks are resolved. Kubeconfigs reading other files "+
+		"are rejected.",
+)
+
+// validateCredentials checks the kubeconfig only runs allowed commands and only reads files of the allowed directories
+// to get credentials. Otherwise, the author of a remote secret could run any command in Istiod, or send any file
+// Istiod can read, such as its own service account token, to the server of their choice.
+func validateCredentials(config *api.Config, allowlist, dirs string) error {
+	allowed := map[string]bool{}
+	for _, command := range strings.Split(allowlist, ",") {
+		if command = strings.TrimSpace(command); command != "" {
+			allowed[command] = true
+		}
+	}
+	var allowedDirs []string
+	for _, dir := range strings.Split(dirs, ",") {
+		if dir = strings.TrimSpace(dir); dir != "" {
+			if resolved, err := resolveSymlinks(filepath.Clean(dir)); err == nil {
+				dir = resolved
+			}
+			allowedDirs = append(allowedDirs, filepath.Clean(dir))
+		}
+	}
+	for name, authInfo := range config.AuthInfos {
+		if authInfo.Exec != nil && !allowed[authInfo.Exec.Command] {
+			return fmt.Errorf("user %q: exec credential command %q is not allowed by PILOT_REMOTE_CLUSTER_EXEC_ALLOWLIST",
+				name, authInfo.Exec.Command)
+		}
+		if authInfo.AuthProvider != nil {
+			if command := authInfo.AuthProvider.Config["cmd-path"]; command != "" && !allowed[command] {
+				return fmt.Errorf("user %q: auth provider command %q is not allowed by PILOT_REMOTE_CLUSTER_EXEC_ALLOWLIST",
+					name, command)
+			}
+		}
+		for _, file := range []string{authInfo.TokenFile, authInfo.ClientCertificate, authInfo.ClientKey} {
+			if file != "" && !inDirs(file, allowedDirs) {
+				return fmt.Errorf("user %q: credential file %q is not in PILOT_REMOTE_CLUSTER_CREDENTIAL_DIRS", name, file)
+			}
+		}
+	}
+	return nil
+}
+
+// inDirs returns true if the absolute path is in one of the directories or their subdirectories, once its
+// symlinks are resolved. Otherwise, a symlink in the directories could point to any file.
+func inDirs(path string, dirs []string) bool {
+	if !filepath.IsAbs(path) {
+		return false
+	}
+	path, err := resolveSymlinks(filepath.Clean(path))
+	if err != nil {
+		return false
+	}
+	for _, dir := range dirs {
+		if strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveSymlinks returns the path with its symlinks resolved. The symlinks of a path which doesn't exist yet, e.g.
+// a token file which is not mounted yet, are resolved in its closest existing parent.
+func resolveSymlinks(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err == nil || !os.IsNotExist(err) {
+		return resolved, err
+	}
+	if _, lerr := os.Lstat(path); lerr == nil {
+		// The path is a dangling symlink, which could point outside the directories once its target exists.
+		return "", err
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+	if parent, err = resolveSymlinks(parent); err != nil {
+		return "", err
+	}
+	return filepath.Join(parent, filepath.Base(path)), nil
+}
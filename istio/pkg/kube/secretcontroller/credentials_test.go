@@ -0,0 +1,198 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretcontroller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestValidateCredentials(t *testing.T) {
+	cases := []struct {
+		name      string
+		authInfo  *api.AuthInfo
+		allowlist string
+		dirs      string
+		wantErr   bool
+	}{
+		{
+			name:     "token",
+			authInfo: &api.AuthInfo{Token: "token"},
+		},
+		{
+			name:     "token file",
+			authInfo: &api.AuthInfo{TokenFile: "/var/run/secrets/remote/token"},
+			dirs:     "/etc/certs, /var/run/secrets/remote/",
+		},
+		{
+			name:     "token file not allowed",
+			authInfo: &api.AuthInfo{TokenFile: "/var/run/secrets/remote/token"},
+			wantErr:  true,
+		},
+		{
+			name:     "istiod service account token",
+			authInfo: &api.AuthInfo{TokenFile: "/var/run/secrets/kubernetes.io/serviceaccount/token"},
+			dirs:     "/var/run/secrets/remote",
+			wantErr:  true,
+		},
+		{
+			name:     "token file escaping the directory",
+			authInfo: &api.AuthInfo{TokenFile: "/var/run/secrets/remote/../kubernetes.io/serviceaccount/token"},
+			dirs:     "/var/run/secrets/remote",
+			wantErr:  true,
+		},
+		{
+			name:     "relative token file",
+			authInfo: &api.AuthInfo{TokenFile: "remote/token"},
+			dirs:     "remote",
+			wantErr:  true,
+		},
+		{
+			name:     "directory prefix",
+			authInfo: &api.AuthInfo{TokenFile: "/var/run/secrets/remote-other/token"},
+			dirs:     "/var/run/secrets/remote",
+			wantErr:  true,
+		},
+		{
+			name:     "client certificate",
+			authInfo: &api.AuthInfo{ClientCertificate: "/etc/certs/cert.pem", ClientKey: "/etc/certs/key.pem"},
+			dirs:     "/etc/certs",
+		},
+		{
+			name:     "client key not allowed",
+			authInfo: &api.AuthInfo{ClientCertificate: "/etc/certs/cert.pem", ClientKey: "/etc/istio/key.pem"},
+			dirs:     "/etc/certs",
+			wantErr:  true,
+		},
+		{
+			name:     "exec not allowed",
+			authInfo: &api.AuthInfo{Exec: &api.ExecConfig{Command: "/usr/bin/aws-iam-authenticator"}},
+			wantErr:  true,
+		},
+		{
+			name:      "exec allowed",
+			authInfo:  &api.AuthInfo{Exec: &api.ExecConfig{Command: "/usr/bin/aws-iam-authenticator"}},
+			allowlist: "/usr/bin/gke-gcloud-auth-plugin, /usr/bin/aws-iam-authenticator",
+		},
+		{
+			name:      "exec matched on full path",
+			authInfo:  &api.AuthInfo{Exec: &api.ExecConfig{Command: "aws-iam-authenticator"}},
+			allowlist: "/usr/bin/aws-iam-authenticator",
+			wantErr:   true,
+		},
+		{
+			name:     "auth provider",
+			authInfo: &api.AuthInfo{AuthProvider: &api.AuthProviderConfig{Name: "oidc"}},
+		},
+		{
+			name: "auth provider command not allowed",
+			authInfo: &api.AuthInfo{AuthProvider: &api.AuthProviderConfig{
+				Name:   "gcp",
+				Config: map[string]string{"cmd-path": "/bin/sh"},
+			}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &api.Config{AuthInfos: map[string]*api.AuthInfo{"user": tt.authInfo}}
+			if err := validateCredentials(config, tt.allowlist, tt.dirs); (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCredentialsSymlinks(t *testing.T) {
+	root := t.TempDir()
+	allowed := filepath.Join(root, "remote")
+	outside := filepath.Join(root, "serviceaccount")
+	for _, dir := range []string{allowed, outside} {
+		if err := os.Mkdir(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{filepath.Join(allowed, "token"), filepath.Join(outside, "token")} {
+		if err := os.WriteFile(file, []byte("token"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	symlinks := map[string]string{
+		// Like the ..data symlinks of the Kubernetes volumes, pointing inside the directory.
+		filepath.Join(allowed, "current"):  filepath.Join(allowed, "token"),
+		filepath.Join(allowed, "escape"):   filepath.Join(outside, "token"),
+		filepath.Join(allowed, "sa"):       outside,
+		filepath.Join(allowed, "dangling"): filepath.Join(outside, "missing"),
+		filepath.Join(root, "link"):        allowed,
+	}
+	for link, target := range symlinks {
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		name      string
+		tokenFile string
+		dirs      string
+		wantErr   bool
+	}{
+		{
+			name:      "symlink in the directory",
+			tokenFile: filepath.Join(allowed, "current"),
+			dirs:      allowed,
+		},
+		{
+			name:      "file escaping through a symlink",
+			tokenFile: filepath.Join(allowed, "escape"),
+			dirs:      allowed,
+			wantErr:   true,
+		},
+		{
+			name:      "directory escaping through a symlink",
+			tokenFile: filepath.Join(allowed, "sa", "token"),
+			dirs:      allowed,
+			wantErr:   true,
+		},
+		{
+			name:      "file not yet created in a directory escaping through a symlink",
+			tokenFile: filepath.Join(allowed, "sa", "new-token"),
+			dirs:      allowed,
+			wantErr:   true,
+		},
+		{
+			name:      "dangling symlink",
+			tokenFile: filepath.Join(allowed, "dangling"),
+			dirs:      allowed,
+			wantErr:   true,
+		},
+		{
+			name:      "allowed directory through a symlink",
+			tokenFile: filepath.Join(allowed, "token"),
+			dirs:      filepath.Join(root, "link"),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &api.Config{AuthInfos: map[string]*api.AuthInfo{"user": {TokenFile: tt.tokenFile}}}
+			if err := validateCredentials(config, "", tt.dirs); (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -21,10 +21,13 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
+	"sync"
 	"time"
 
 	"go.uber.org/atomic"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -54,6 +57,70 @@ type updateSecretCallback func(clients kube.Client, dataKey string) error
 // removeSecretCallback prototype for the remove secret callback function.
 type removeSecretCallback func(dataKey string) error
 
+// statusCallback prototype for the function called when the status of a remote cluster changes.
+type statusCallback func(status ClusterStatus)
+
+// clusterHealthCheckInterval is the interval between checks of the API servers of the synced remote clusters.
+const clusterHealthCheckInterval = 30 * time.Second
+
+// ClusterState is the state of a remote cluster.
+type ClusterState string
+
+const (
+	// Connected clusters have a reachable API server, their informers are not started yet.
+	Connected ClusterState = "connected"
+	// Syncing clusters are waiting for their informers to sync.
+	Syncing ClusterState = "syncing"
+	// Synced clusters have synced informers and a reachable API server.
+	Synced ClusterState = "synced"
+	// Failed clusters have an invalid kubeconfig, an unreachable API server, rejected credentials, informers that
+	// did not sync in time or failed to be added.
+	Failed ClusterState = "failed"
+	// Removed clusters had their secret deleted.
+	Removed ClusterState = "removed"
+)
+
+// ClusterStates are the states of a remote cluster, except Removed.
+var ClusterStates = []ClusterState{Connected, Syncing, Synced, Failed}
+
+// ClusterStatus describes the state of a remote cluster.
+type ClusterStatus struct {
+	ID         string       `json:"id"`
+	SecretName string       `json:"secretName"`
+	State      ClusterState `json:"state"`
+	// LastSyncTime is the last time the informers of the cluster synced.
+	LastSyncTime *time.Time `json:"lastSyncTime,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	// Objects holds the number of objects of each kind in the informer caches of the cluster, if known.
+	Objects map[string]int `json:"objects,omitempty"`
+
+	// cluster is the instance of the cluster the status is about, the cluster is replaced when its kubeconfig
+	// changes.
+	cluster *RemoteCluster
+	// synced is true once the informers of the cluster instance synced.
+	synced bool
+	// unhealthy is true if the cluster failed because its API server is unreachable or rejects the credentials.
+	unhealthy bool
+}
+
+var (
+	errAPIServerUnreachable = errors.New("API server unreachable")
+	errCredentialsRejected  = errors.New("credentials rejected by the API server")
+)
+
+// checkAPIServer checks the API server of the cluster is reachable and accepts the credentials of the clients.
+func checkAPIServer(clients kube.Client) error {
+	_, err := clients.Kube().Discovery().ServerVersion()
+	switch {
+	case err == nil:
+		return nil
+	case apierrors.IsUnauthorized(err):
+		return fmt.Errorf("%w: %v", errCredentialsRejected, err)
+	default:
+		return fmt.Errorf("%w: %v", errAPIServerUnreachable, err)
+	}
+}
+
 // Controller is the controller implementation for Secret resources
 type Controller struct {
 	kubeclientset  kubernetes.Interface
@@ -64,8 +131,11 @@ type Controller struct {
 	addCallback    addSecretCallback
 	updateCallback updateSecretCallback
 	removeCallback removeSecretCallback
+	statusCallback statusCallback
 
 	syncInterval time.Duration
+	// syncTimeout is the time after which a remote cluster whose informers did not sync is failed.
+	syncTimeout time.Duration
 
 	initialSync atomic.Bool
 }
@@ -79,7 +149,11 @@ type RemoteCluster struct {
 
 // ClusterStore is a collection of clusters
 type ClusterStore struct {
+	// mu protects statuses, and remoteClusters writes. remoteClusters is only written by the worker, which can
+	// read it without the lock.
+	mu             sync.RWMutex
 	remoteClusters map[string]*RemoteCluster
+	statuses       map[string]*ClusterStatus
 }
 
 // newClustersStore initializes data struct to store clusters information
@@ -87,6 +161,7 @@ func newClustersStore() *ClusterStore {
 	remoteClusters := make(map[string]*RemoteCluster)
 	return &ClusterStore{
 		remoteClusters: remoteClusters,
+		statuses:       make(map[string]*ClusterStatus),
 	}
 }
 
@@ -97,7 +172,8 @@ func NewController(
 	cs *ClusterStore,
 	addCallback addSecretCallback,
 	updateCallback updateSecretCallback,
-	removeCallback removeSecretCallback) *Controller {
+	removeCallback removeSecretCallback,
+	statusCallback statusCallback) *Controller {
 	secretsInformer := cache.NewSharedIndexInformer(
 		&cache.ListWatch{
 			ListFunc: func(opts meta_v1.ListOptions) (runtime.Object, error) {
@@ -123,6 +199,7 @@ func NewController(
 		addCallback:    addCallback,
 		updateCallback: updateCallback,
 		removeCallback: removeCallback,
+		statusCallback: statusCallback,
 	}
 
 	log.Info("Setting up event handlers")
@@ -174,6 +251,7 @@ func (c *Controller) Run(stopCh <-chan struct{}) {
 	c.queue.Add(initialSyncSignal)
 
 	go wait.Until(c.runWorker, 5*time.Second, stopCh)
+	go wait.Until(c.checkClusterHealth, clusterHealthCheckInterval, stopCh)
 	<-stopCh
 }
 
@@ -181,18 +259,22 @@ func (c *Controller) HasSynced() bool {
 	return c.initialSync.Load()
 }
 
-// StartSecretController creates the secret controller.
+// StartSecretController creates the secret controller. The status callback, if not nil, is called when the status of a
+// remote cluster changes.
 func StartSecretController(
 	k8s kubernetes.Interface,
 	addCallback addSecretCallback, updateCallback updateSecretCallback,
 	removeCallback removeSecretCallback,
+	statusCallback statusCallback,
 	namespace string,
 	syncInterval time.Duration,
+	syncTimeout time.Duration,
 	stop <-chan struct{},
 ) *Controller {
 	clusterStore := newClustersStore()
-	controller := NewController(k8s, namespace, clusterStore, addCallback, updateCallback, removeCallback)
+	controller := NewController(k8s, namespace, clusterStore, addCallback, updateCallback, removeCallback, statusCallback)
 	controller.syncInterval = syncInterval
+	controller.syncTimeout = syncTimeout
 
 	go controller.Run(stop)
 
@@ -262,9 +344,13 @@ var BuildClientsFromConfig = func(kubeConfig []byte) (kube.Client, error) {
 		return nil, fmt.Errorf("kubeconfig is not valid: %v", err)
 	}
 
-	clientConfig := clientcmd.NewDefaultClientConfig(*rawConfig, &clientcmd.ConfigOverrides{})
+	if err := validateCredentials(rawConfig, execCommandAllowlist.Get(), credentialDirs.Get()); err != nil {
+		return nil, fmt.Errorf("kubeconfig is not allowed: %v", err)
+	}
 
-	clients, err := kube.NewClient(clientConfig)
+	// The clients keep expiring credentials fresh without being rebuilt: client-go re-runs exec credential plugins
+	// when the credentials expire or are rejected, re-reads token files every minute and reloads client certificates.
+	clients, err := kube.NewClient(clientcmd.NewDefaultClientConfig(*rawConfig, &clientcmd.ConfigOverrides{}))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create kube clients: %v", err)
 	}
@@ -293,11 +379,14 @@ func (c *Controller) addMemberCluster(secretName string, s *corev1.Secret) {
 			if err != nil {
 				log.Errorf("Failed to add remote cluster from secret=%v for cluster_id=%v: %v",
 					secretName, clusterID, err)
+				c.setStatus(clusterID, &RemoteCluster{secretName: secretName}, Failed, err)
 				continue
 			}
 
+			c.cs.mu.Lock()
 			c.cs.remoteClusters[clusterID] = remoteCluster
-			if err := c.addCallback(remoteCluster.clients, clusterID); err != nil {
+			c.cs.mu.Unlock()
+			if err := c.syncCluster(clusterID, remoteCluster, c.addCallback); err != nil {
 				log.Errorf("Error creating cluster_id=%s from secret %v: %v",
 					clusterID, secretName, err)
 			}
@@ -318,10 +407,13 @@ func (c *Controller) addMemberCluster(secretName string, s *corev1.Secret) {
 				if err != nil {
 					log.Errorf("Error updating cluster_id=%v from secret=%v: %v",
 						clusterID, secretName, err)
+					c.setStatus(clusterID, prev, Failed, err)
 					continue
 				}
+				c.cs.mu.Lock()
 				c.cs.remoteClusters[clusterID] = remoteCluster
-				if err := c.updateCallback(remoteCluster.clients, clusterID); err != nil {
+				c.cs.mu.Unlock()
+				if err := c.syncCluster(clusterID, remoteCluster, c.updateCallback); err != nil {
 					log.Errorf("Error updating cluster_id from secret=%v: %s %v",
 						clusterID, secretName, err)
 				}
@@ -341,8 +433,124 @@ func (c *Controller) deleteMemberCluster(secretName string) {
 				log.Errorf("Error removing cluster_id=%v configured by secret=%v: %v",
 					clusterID, secretName, err)
 			}
+			c.cs.mu.Lock()
 			delete(c.cs.remoteClusters, clusterID)
+			c.cs.mu.Unlock()
+		}
+	}
+	// Clusters that failed to be created are only known by their status.
+	for _, status := range c.ClusterStatuses() {
+		if status.SecretName == secretName {
+			c.setStatus(status.ID, status.cluster, Removed, nil)
 		}
 	}
 	log.Infof("Number of remote clusters: %d", len(c.cs.remoteClusters))
 }
+
+// syncCluster calls the callback initializing the remote cluster, which returns once its informers are synced, and
+// tracks the status of the cluster meanwhile.
+func (c *Controller) syncCluster(clusterID string, cluster *RemoteCluster,
+	callback func(clients kube.Client, dataKey string) error) error {
+	if err := checkAPIServer(cluster.clients); err != nil {
+		// The informers will keep retrying to reach the API server.
+		c.setStatus(clusterID, cluster, Failed, err)
+	} else {
+		c.setStatus(clusterID, cluster, Connected, nil)
+		c.setStatus(clusterID, cluster, Syncing, nil)
+	}
+
+	if c.syncTimeout > 0 {
+		timer := time.AfterFunc(c.syncTimeout, func() {
+			c.failIfNotSynced(clusterID, cluster)
+		})
+		defer timer.Stop()
+	}
+	if err := callback(cluster.clients, clusterID); err != nil {
+		c.setStatus(clusterID, cluster, Failed, err)
+		return err
+	}
+	c.setStatus(clusterID, cluster, Synced, nil)
+	return nil
+}
+
+func (c *Controller) failIfNotSynced(clusterID string, cluster *RemoteCluster) {
+	c.cs.mu.RLock()
+	status := c.cs.statuses[clusterID]
+	syncing := status != nil && status.cluster == cluster && status.State == Syncing
+	c.cs.mu.RUnlock()
+	if syncing {
+		log.Warnf("Informers of cluster_id=%v not synced after %v", clusterID, c.syncTimeout)
+		c.setStatus(clusterID, cluster, Failed, fmt.Errorf("informers not synced after %v", c.syncTimeout))
+	}
+}
+
+// checkClusterHealth checks the API servers of the synced remote clusters are still reachable and accept the
+// credentials. Expired or rejected credentials are refreshed by client-go.
+func (c *Controller) checkClusterHealth() {
+	for _, status := range c.ClusterStatuses() {
+		if !status.synced || !(status.State == Synced || status.State == Failed && status.unhealthy) {
+			continue
+		}
+		if err := checkAPIServer(status.cluster.clients); err != nil {
+			if status.State == Synced {
+				log.Warnf("Cluster_id=%v unhealthy: %v", status.ID, err)
+			}
+			c.setStatus(status.ID, status.cluster, Failed, err)
+		} else if status.State == Failed {
+			log.Infof("Cluster_id=%v healthy again", status.ID)
+			c.setStatus(status.ID, status.cluster, Synced, nil)
+		}
+	}
+}
+
+// setStatus records the state of the remote cluster, unless the status is about a cluster replaced since.
+func (c *Controller) setStatus(clusterID string, cluster *RemoteCluster, state ClusterState, err error) {
+	c.cs.mu.Lock()
+	status, f := c.cs.statuses[clusterID]
+	if f && status.cluster != cluster && c.cs.remoteClusters[clusterID] != cluster {
+		c.cs.mu.Unlock()
+		return
+	}
+	if !f {
+		status = &ClusterStatus{ID: clusterID}
+		c.cs.statuses[clusterID] = status
+	}
+	if status.cluster != cluster {
+		// The last sync time of the replaced cluster is kept.
+		status.cluster = cluster
+		status.synced = false
+	}
+	status.SecretName = cluster.secretName
+	status.State = state
+	status.Error = ""
+	if err != nil {
+		status.Error = err.Error()
+	}
+	status.unhealthy = errors.Is(err, errAPIServerUnreachable) || errors.Is(err, errCredentialsRejected)
+	if state == Synced && !status.synced {
+		now := time.Now()
+		status.LastSyncTime = &now
+		status.synced = true
+	}
+	if state == Removed {
+		delete(c.cs.statuses, clusterID)
+	}
+	out := *status
+	c.cs.mu.Unlock()
+
+	if c.statusCallback != nil {
+		c.statusCallback(out)
+	}
+}
+
+// ClusterStatuses returns the status of the remote clusters, sorted by ID.
+func (c *Controller) ClusterStatuses() []ClusterStatus {
+	c.cs.mu.RLock()
+	defer c.cs.mu.RUnlock()
+	out := make([]ClusterStatus, 0, len(c.cs.statuses))
+	for _, status := range c.cs.statuses {
+		out = append(out, *status)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
@@ -16,6 +16,7 @@ package secretcontroller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -117,7 +118,8 @@ func Test_SecretController(t *testing.T) {
 	t.Cleanup(func() {
 		close(stopCh)
 	})
-	c := StartSecretController(clientset, addCallback, updateCallback, deleteCallback, secretNamespace, time.Microsecond, stopCh)
+	c := StartSecretController(clientset, addCallback, updateCallback, deleteCallback, nil, secretNamespace,
+		time.Microsecond, 0, stopCh)
 	kube.WaitForCacheSyncInterval(stopCh, time.Microsecond, c.informer.HasSynced)
 	clientset.RunAndWait(stopCh)
 
@@ -168,3 +170,86 @@ func Test_SecretController(t *testing.T) {
 		})
 	}
 }
+
+func Test_SecretControllerClusterStatus(t *testing.T) {
+	BuildClientsFromConfig = func(kubeConfig []byte) (kube.Client, error) {
+		if string(kubeConfig) == "invalid" {
+			return nil, errors.New("invalid kubeconfig")
+		}
+		return kube.NewFakeClient(), nil
+	}
+	clientset := kube.NewFakeClient()
+
+	// The informers of c0 sync once release is closed.
+	release := make(chan struct{})
+	syncCallback := func(_ kube.Client, id string) error {
+		if id == "c0" {
+			<-release
+		}
+		return nil
+	}
+	var statesMu sync.Mutex
+	var states []ClusterState
+	statusCallback := func(status ClusterStatus) {
+		if status.ID != "c0" {
+			return
+		}
+		statesMu.Lock()
+		defer statesMu.Unlock()
+		states = append(states, status.State)
+	}
+
+	stopCh := make(chan struct{})
+	t.Cleanup(func() {
+		close(stopCh)
+	})
+	c := StartSecretController(clientset, syncCallback, syncCallback, deleteCallback, statusCallback, secretNamespace,
+		time.Microsecond, 100*time.Millisecond, stopCh)
+	kube.WaitForCacheSyncInterval(stopCh, time.Microsecond, c.informer.HasSynced)
+	clientset.RunAndWait(stopCh)
+
+	g := NewWithT(t)
+	status := func(id string) func() *ClusterStatus {
+		return func() *ClusterStatus {
+			for _, s := range c.ClusterStatuses() {
+				if s.ID == id {
+					return &s
+				}
+			}
+			return nil
+		}
+	}
+
+	_, err := clientset.CoreV1().Secrets(secretNamespace).Create(context.TODO(),
+		makeSecret("s0", "c0", []byte("kubeconfig0")), metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(status("c0"), 10*time.Second).Should(And(Not(BeNil()), WithTransform(func(s *ClusterStatus) ClusterState {
+		return s.State
+	}, Equal(Failed))))
+	g.Expect(status("c0")().Error).Should(ContainSubstring("informers not synced"))
+	g.Expect(status("c0")().LastSyncTime).Should(BeNil())
+
+	close(release)
+	g.Eventually(func() ClusterState { return status("c0")().State }, 10*time.Second).Should(Equal(Synced))
+	synced := status("c0")()
+	g.Expect(synced.SecretName).Should(Equal(secretNamespace + "/s0"))
+	g.Expect(synced.Error).Should(BeEmpty())
+	g.Expect(synced.LastSyncTime).ShouldNot(BeNil())
+	statesMu.Lock()
+	g.Expect(states).Should(Equal([]ClusterState{Connected, Syncing, Failed, Synced}))
+	statesMu.Unlock()
+
+	_, err = clientset.CoreV1().Secrets(secretNamespace).Create(context.TODO(),
+		makeSecret("s1", "c1", []byte("invalid")), metav1.CreateOptions{})
+	g.Expect(err).Should(BeNil())
+	g.Eventually(status("c1"), 10*time.Second).ShouldNot(BeNil())
+	g.Expect(status("c1")().State).Should(Equal(Failed))
+	g.Expect(status("c1")().Error).Should(Equal("invalid kubeconfig"))
+
+	g.Expect(clientset.CoreV1().Secrets(secretNamespace).Delete(context.TODO(), "s0", metav1.DeleteOptions{})).Should(Succeed())
+	g.Expect(clientset.CoreV1().Secrets(secretNamespace).Delete(context.TODO(), "s1", metav1.DeleteOptions{})).Should(Succeed())
+	g.Eventually(c.ClusterStatuses, 10*time.Second).Should(BeEmpty())
+	statesMu.Lock()
+	g.Expect(states[len(states)-1]).Should(Equal(Removed))
+	statesMu.Unlock()
+}
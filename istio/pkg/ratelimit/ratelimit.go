@@ -0,0 +1,208 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit limits the rate of requests to the istiod gRPC services per caller identity and per source IP.
+package ratelimit
+
+import (
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"istio.io/istio/pkg/spiffe"
+	"istio.io/pkg/monitoring"
+)
+
+const (
+	// idleTimeout is how long a caller is tracked after its last request.
+	idleTimeout = 10 * time.Minute
+	// gcInterval is the minimum interval between the removal of idle callers.
+	gcInterval = time.Minute
+)
+
+var (
+	serviceTag = monitoring.MustCreateLabel("service")
+	limitTag   = monitoring.MustCreateLabel("limit")
+
+	rateLimitedCounts = monitoring.NewSum(
+		"istiod_rate_limited_requests_total",
+		"The number of requests rejected by the per caller rate limits.",
+		monitoring.WithLabels(serviceTag, limitTag),
+	)
+)
+
+func init() {
+	monitoring.MustRegister(rateLimitedCounts)
+}
+
+// Config configures the rate limits of a service. The limits are token buckets filled at the
+// QPS rate up to Burst tokens.
+type Config struct {
+	// IdentityQPS limits the requests of each authenticated caller identity, 0 disables the limit.
+	IdentityQPS float64
+	// IPQPS limits the requests from each source IP, 0 disables the limit.
+	IPQPS float64
+	// Burst is the number of requests a caller can make at once.
+	Burst int
+	// Allowlist are the caller identities which are not limited, e.g. "cluster.local/ns/istio-system/sa/istiod".
+	// A "*" matches any characters, e.g. "*/ns/istio-system/sa/istiod*".
+	Allowlist []string
+}
+
+// Limiter limits the rate of requests of each caller to a service. A nil Limiter allows all requests.
+type Limiter struct {
+	service    string
+	allowlist  []string
+	identities *keyedLimiter
+	ips        *keyedLimiter
+}
+
+// New returns a limiter for the service, nil if the config has no limit.
+func New(service string, config Config) *Limiter {
+	if config.IdentityQPS <= 0 && config.IPQPS <= 0 {
+		return nil
+	}
+	burst := config.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	return &Limiter{
+		service:    service,
+		allowlist:  config.Allowlist,
+		identities: newKeyedLimiter(config.IdentityQPS, burst),
+		ips:        newKeyedLimiter(config.IPQPS, burst),
+	}
+}
+
+// Check takes a token for the caller, identified by its authenticated identities and the peer
+// address of the connection. It returns a gRPC ResourceExhausted error if the caller exceeded
+// its rate. Callers with an allowlisted identity or connecting from a loopback address are not limited.
+func (l *Limiter) Check(identities []string, peerAddr string) error {
+	if l == nil || l.allowed(identities) {
+		return nil
+	}
+	ip := peerIP(peerAddr)
+	if ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	// Callers with multiple identities share the bucket of the first one, like the certificate SAN.
+	if len(identities) > 0 && !l.identities.allow(identities[0]) {
+		rateLimitedCounts.With(serviceTag.Value(l.service), limitTag.Value("identity")).Increment()
+		return status.Errorf(codes.ResourceExhausted, "rate limit of %s exceeded for identity %s", l.service, identities[0])
+	}
+	if ip != nil && !l.ips.allow(ip.String()) {
+		rateLimitedCounts.With(serviceTag.Value(l.service), limitTag.Value("ip")).Increment()
+		return status.Errorf(codes.ResourceExhausted, "rate limit of %s exceeded for source IP %s", l.service, ip)
+	}
+	return nil
+}
+
+func (l *Limiter) allowed(identities []string) bool {
+	for _, id := range identities {
+		id = strings.TrimPrefix(id, spiffe.URIPrefix)
+		for _, pattern := range l.allowlist {
+			if matchPattern(pattern, id) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchPattern matches the value against a pattern where "*" matches any characters.
+func matchPattern(pattern, value string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == value
+	}
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(value, part)
+		if i < 0 {
+			return false
+		}
+		value = value[i+len(part):]
+	}
+	return len(value) >= len(last) && strings.HasSuffix(value, last)
+}
+
+func peerIP(addr string) net.IP {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	return net.ParseIP(host)
+}
+
+type keyedEntry struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// keyedLimiter is a token bucket per key. Keys idle for longer than idleTimeout are removed, as a
+// new bucket starts full.
+type keyedLimiter struct {
+	limit rate.Limit
+	burst int
+
+	mu      sync.Mutex
+	entries map[string]*keyedEntry
+	lastGC  time.Time
+	now     func() time.Time
+}
+
+func newKeyedLimiter(qps float64, burst int) *keyedLimiter {
+	if qps <= 0 {
+		return nil
+	}
+	return &keyedLimiter{
+		limit:   rate.Limit(qps),
+		burst:   burst,
+		entries: map[string]*keyedEntry{},
+		now:     time.Now,
+	}
+}
+
+func (k *keyedLimiter) allow(key string) bool {
+	if k == nil {
+		return true
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	now := k.now()
+	if now.Sub(k.lastGC) >= gcInterval {
+		for key, e := range k.entries {
+			if now.Sub(e.lastSeen) >= idleTimeout {
+				delete(k.entries, key)
+			}
+		}
+		k.lastGC = now
+	}
+	e, f := k.entries[key]
+	if !f {
+		e = &keyedEntry{limiter: rate.NewLimiter(k.limit, k.burst)}
+		k.entries[key] = e
+	}
+	e.lastSeen = now
+	return e.limiter.AllowN(now, 1)
+}
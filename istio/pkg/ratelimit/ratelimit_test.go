@@ -0,0 +1,111 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestLimiter(t *testing.T) {
+	if New("xds", Config{Burst: 10}) != nil {
+		t.Fatalf("expected no limiter without limits")
+	}
+	var disabled *Limiter
+	if err := disabled.Check([]string{"spiffe://cluster.local/ns/foo/sa/bar"}, "10.0.0.1:1234"); err != nil {
+		t.Fatalf("nil limiter returns %v", err)
+	}
+
+	l := New("ca", Config{
+		IdentityQPS: 0.001,
+		IPQPS:       0.001,
+		Burst:       2,
+		Allowlist:   []string{"*/ns/istio-system/sa/istiod*"},
+	})
+	// The identity bucket is shared across source IPs.
+	for i, addr := range []string{"10.0.0.1:1234", "10.0.0.2:1234"} {
+		if err := l.Check([]string{"spiffe://cluster.local/ns/foo/sa/bar"}, addr); err != nil {
+			t.Fatalf("request %d: unexpected error %v", i, err)
+		}
+	}
+	err := l.Check([]string{"spiffe://cluster.local/ns/foo/sa/bar"}, "10.0.0.3:1234")
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted for the identity, got %v", err)
+	}
+
+	// The IP bucket is shared across identities, and also limits unauthenticated callers.
+	if err := l.Check([]string{"spiffe://cluster.local/ns/foo/sa/other"}, "10.0.0.1:1234"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := l.Check([]string{"spiffe://cluster.local/ns/foo/sa/third"}, "10.0.0.1:1234"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted for the source IP, got %v", err)
+	}
+	if err := l.Check(nil, "10.0.0.2:1234"); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if err := l.Check(nil, "10.0.0.2:1234"); status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("expected ResourceExhausted for the unauthenticated source IP, got %v", err)
+	}
+
+	// Allowlisted identities and loopback callers are not limited.
+	for i := 0; i < 5; i++ {
+		if err := l.Check([]string{"spiffe://cluster.local/ns/istio-system/sa/istiod-service-account"}, "10.0.0.1:1234"); err != nil {
+			t.Fatalf("allowlisted identity: unexpected error %v", err)
+		}
+		if err := l.Check([]string{"spiffe://cluster.local/ns/foo/sa/bar"}, "127.0.0.1:1234"); err != nil {
+			t.Fatalf("loopback: unexpected error %v", err)
+		}
+	}
+}
+
+func TestKeyedLimiterIdleRemoval(t *testing.T) {
+	now := time.Now()
+	k := newKeyedLimiter(0.001, 1)
+	k.now = func() time.Time { return now }
+	if !k.allow("a") || k.allow("a") {
+		t.Fatalf("expected the bucket of a single token to be exhausted")
+	}
+	now = now.Add(idleTimeout)
+	if !k.allow("b") {
+		t.Fatalf("expected a new key to be allowed")
+	}
+	if _, f := k.entries["a"]; f {
+		t.Fatalf("expected the idle key to be removed")
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	cases := []struct {
+		pattern, value string
+		want           bool
+	}{
+		{"cluster.local/ns/istio-system/sa/istiod", "cluster.local/ns/istio-system/sa/istiod", true},
+		{"cluster.local/ns/istio-system/sa/istiod", "cluster.local/ns/istio-system/sa/istiod-1", false},
+		{"*/ns/istio-system/sa/istiod*", "td/ns/istio-system/sa/istiod-service-account", true},
+		{"*/ns/istio-system/sa/istiod*", "td/ns/foo/sa/istiod", false},
+		{"*", "anything", true},
+		{"a*b*c", "abbc", true},
+		{"a*bc", "abc", true},
+		{"ab*bc", "abc", false},
+	}
+	for _, c := range cases {
+		if got := matchPattern(c.pattern, c.value); got != c.want {
+			t.Errorf("matchPattern(%q, %q) = %v, want %v", c.pattern, c.value, got, c.want)
+		}
+	}
+}
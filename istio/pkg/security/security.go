@@ -54,8 +54,14 @@ const (
 	WorkloadKeyCertResourceName = "default"
 
 	// Credential fetcher type
-	GCE  = "GoogleComputeEngine"
-	Mock = "Mock" // testing only
+	GCE              = "GoogleComputeEngine"
+	TokenFile        = "TokenFile"
+	InstanceMetadata = "InstanceMetadata"
+	Mock             = "Mock" // testing only
+
+	// WorkloadKeyPolicyHeader is the response header in which the CA sends the workload key policy, the
+	// comma separated list of the allowed key algorithms, e.g. "ECDSA-P256,RSA-3072".
+	WorkloadKeyPolicyHeader = "istio-workload-key-policy"
 )
 
 // TODO: For 1.8, make sure MeshConfig is updated with those settings,
@@ -216,6 +222,12 @@ type Client interface {
 	Close()
 }
 
+// KeyPolicyClient is implemented by the CA clients that learn the workload key policy from the CA.
+type KeyPolicyClient interface {
+	// WorkloadKeyPolicy returns the last workload key policy sent by the CA, empty if none.
+	WorkloadKeyPolicy() string
+}
+
 // SecretManager defines secrets management interface which is used by SDS.
 type SecretManager interface {
 	// GenerateSecret generates new secret for the given resource.
@@ -253,7 +265,8 @@ type CredFetcher interface {
 	// GetPlatformCredential fetches workload credential provided by the platform.
 	GetPlatformCredential() (string, error)
 
-	// GetType returns credential fetcher type. Currently the supported type is "GoogleComputeEngine".
+	// GetType returns credential fetcher type. Currently the supported types are "GoogleComputeEngine",
+	// "TokenFile" and "InstanceMetadata".
 	GetType() string
 
 	// The name of the IdentityProvider that can authenticate the workload credential.
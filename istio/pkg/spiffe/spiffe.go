@@ -163,6 +163,16 @@ func GetTrustDomainFromURISAN(uriSan string) (string, error) {
 func RetrieveSpiffeBundleRootCertsFromStringInput(inputString string, extraTrustedCerts []*x509.Certificate) (
 	map[string][]*x509.Certificate, error) {
 	spiffeLog.Infof("Processing SPIFFE bundle configuration: %v", inputString)
+	config, err := ParseSpiffeBundleEndpoints(inputString)
+	if err != nil {
+		return nil, err
+	}
+	return RetrieveSpiffeBundleRootCerts(config, extraTrustedCerts)
+}
+
+// ParseSpiffeBundleEndpoints parses trust domain to SPIFFE bundle endpoint mappings in the format of:
+// "foo|URL1||bar|URL2||baz|URL3..."
+func ParseSpiffeBundleEndpoints(inputString string) (map[string]string, error) {
 	config := make(map[string]string)
 	tuples := strings.Split(inputString, "||")
 	for _, tuple := range tuples {
@@ -174,7 +184,59 @@ func RetrieveSpiffeBundleRootCertsFromStringInput(inputString string, extraTrust
 		endpoint := items[1]
 		config[trustDomain] = endpoint
 	}
-	return RetrieveSpiffeBundleRootCerts(config, extraTrustedCerts)
+	return config, nil
+}
+
+// RetrieveSpiffeBundle fetches the SPIFFE bundle of a trust domain once from an endpoint using the https_web
+// profile, validating the endpoint with the given cert pool. It returns all the X.509 roots of the bundle along
+// with the refresh hint of the bundle, which is zero if the bundle does not provide one.
+func RetrieveSpiffeBundle(trustDomain, endpoint string, caCertPool *x509.CertPool) ([]*x509.Certificate, time.Duration, error) {
+	if !strings.HasPrefix(endpoint, "https://") {
+		endpoint = "https://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to split the SPIFFE bundle URL: %v", err)
+	}
+	httpClient := &http.Client{
+		Timeout: totalRetryTimeout,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				ServerName: u.Hostname(),
+				RootCAs:    caCertPool,
+			},
+		},
+	}
+	resp, err := httpClient.Get(endpoint)
+	if err != nil {
+		return nil, 0, fmt.Errorf("calling %s failed with error: %v", endpoint, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b := make([]byte, 1024)
+		n, _ := resp.Body.Read(b)
+		return nil, 0, fmt.Errorf("calling %s failed with unexpected status: %v, fetching bundle: %s",
+			endpoint, resp.StatusCode, string(b[:n]))
+	}
+
+	doc := new(bundleDoc)
+	if err := json.NewDecoder(resp.Body).Decode(doc); err != nil {
+		return nil, 0, fmt.Errorf("trust domain [%s] at URL [%s] failed to decode bundle: %v", trustDomain, endpoint, err)
+	}
+	var certs []*x509.Certificate
+	for i, key := range doc.Keys {
+		if key.Use == "x509-svid" {
+			if len(key.Certificates) != 1 {
+				return nil, 0, fmt.Errorf("trust domain [%s] at URL [%s] expected 1 certificate in x509-svid entry %d; got %d",
+					trustDomain, endpoint, i, len(key.Certificates))
+			}
+			certs = append(certs, key.Certificates[0])
+		}
+	}
+	if len(certs) == 0 {
+		return nil, 0, fmt.Errorf("trust domain [%s] at URL [%s] does not provide a X509 SVID", trustDomain, endpoint)
+	}
+	return certs, time.Duration(doc.RefreshHint) * time.Second, nil
 }
 
 // RetrieveSpiffeBundleRootCerts retrieves the trusted CA certificates from a list of SPIFFE bundle endpoints.
@@ -271,8 +333,10 @@ func RetrieveSpiffeBundleRootCerts(config map[string]string, extraTrustedCerts [
 
 // PeerCertVerifier is an instance to verify the peer certificate in the SPIFFE way using the retrieved root certificates.
 type PeerCertVerifier struct {
+	mu              sync.RWMutex
 	generalCertPool *x509.CertPool
 	certPools       map[string]*x509.CertPool
+	certs           map[string][]*x509.Certificate
 }
 
 // NewPeerCertVerifier returns a new PeerCertVerifier.
@@ -280,16 +344,21 @@ func NewPeerCertVerifier() *PeerCertVerifier {
 	return &PeerCertVerifier{
 		generalCertPool: x509.NewCertPool(),
 		certPools:       make(map[string]*x509.CertPool),
+		certs:           make(map[string][]*x509.Certificate),
 	}
 }
 
 // GetGeneralCertPool returns generalCertPool containing all root certs.
 func (v *PeerCertVerifier) GetGeneralCertPool() *x509.CertPool {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
 	return v.generalCertPool
 }
 
 // AddMapping adds a new trust domain to certificates mapping to the certPools map.
 func (v *PeerCertVerifier) AddMapping(trustDomain string, certs []*x509.Certificate) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
 	if v.certPools[trustDomain] == nil {
 		v.certPools[trustDomain] = x509.NewCertPool()
 	}
@@ -297,9 +366,30 @@ func (v *PeerCertVerifier) AddMapping(trustDomain string, certs []*x509.Certific
 		v.certPools[trustDomain].AddCert(cert)
 		v.generalCertPool.AddCert(cert)
 	}
+	v.certs[trustDomain] = append(v.certs[trustDomain], certs...)
 	spiffeLog.Infof("Added %d certs to trust domain %s in peer cert verifier", len(certs), trustDomain)
 }
 
+// ReplaceMapping replaces the certificates of a trust domain, such as when its roots rotate.
+func (v *PeerCertVerifier) ReplaceMapping(trustDomain string, certs []*x509.Certificate) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	pool := x509.NewCertPool()
+	for _, cert := range certs {
+		pool.AddCert(cert)
+	}
+	v.certPools[trustDomain] = pool
+	v.certs[trustDomain] = certs
+	// The certs of the previous mapping cannot be removed from the general pool, so it is rebuilt.
+	v.generalCertPool = x509.NewCertPool()
+	for _, tdCerts := range v.certs {
+		for _, cert := range tdCerts {
+			v.generalCertPool.AddCert(cert)
+		}
+	}
+	spiffeLog.Infof("Replaced certs of trust domain %s in peer cert verifier with %d certs", trustDomain, len(certs))
+}
+
 // AddMappingFromPEM adds multiple RootCA's to the spiffe Trust bundle in the trustDomain namespace
 func (v *PeerCertVerifier) AddMappingFromPEM(trustDomain string, rootCertBytes []byte) error {
 	block, rest := pem.Decode(rootCertBytes)
@@ -355,7 +445,9 @@ func (v *PeerCertVerifier) VerifyPeerCert(rawCerts [][]byte, _ [][]*x509.Certifi
 	if err != nil {
 		return err
 	}
+	v.mu.RLock()
 	rootCertPool, ok := v.certPools[trustDomain]
+	v.mu.RUnlock()
 	if !ok {
 		return fmt.Errorf("no cert pool found for trust domain %s", trustDomain)
 	}
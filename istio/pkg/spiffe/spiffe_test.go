@@ -789,3 +789,57 @@ func TestIdentity(t *testing.T) {
 		})
 	}
 }
+
+func TestRetrieveSpiffeBundle(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(validSpiffeX509Bundle))
+	}))
+	defer server.Close()
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	certs, refreshHint, err := RetrieveSpiffeBundle("foo", server.Listener.Addr().String(), pool)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(certs) != 1 {
+		t.Fatalf("expected 1 root, got %d", len(certs))
+	}
+	if refreshHint != 450000*time.Second {
+		t.Fatalf("unexpected refresh hint %v", refreshHint)
+	}
+
+	status = http.StatusServiceUnavailable
+	if _, _, err := RetrieveSpiffeBundle("foo", server.Listener.Addr().String(), pool); err == nil ||
+		!strings.Contains(err.Error(), "unexpected status: 503") {
+		t.Fatalf("expected an unexpected status error, got %v", err)
+	}
+	if _, _, err := RetrieveSpiffeBundle("foo", server.Listener.Addr().String(), x509.NewCertPool()); err == nil {
+		t.Fatalf("expected an error for an untrusted endpoint")
+	}
+}
+
+func TestPeerCertVerifierReplaceMapping(t *testing.T) {
+	verifier := NewPeerCertVerifier()
+	if err := verifier.AddMappingFromPEM("foo.domain.com", []byte(validRootCert)); err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.AddMappingFromPEM("bar.domain.com", []byte(validRootCert2)); err != nil {
+		t.Fatal(err)
+	}
+	block, _ := pem.Decode([]byte(validRootCert2))
+	rotated, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier.ReplaceMapping("foo.domain.com", []*x509.Certificate{rotated})
+	if certs := verifier.certs["foo.domain.com"]; len(certs) != 1 || !certs[0].Equal(rotated) {
+		t.Fatalf("expected the roots of foo.domain.com to be replaced, got %v", certs)
+	}
+	// nolint: staticcheck
+	if subjects := verifier.GetGeneralCertPool().Subjects(); len(subjects) != 1 {
+		t.Fatalf("expected the general pool to only contain the rotated root, got %d subjects", len(subjects))
+	}
+}
@@ -17,18 +17,70 @@ package credentialfetcher
 
 import (
 	"fmt"
+	"strings"
 
 	"istio.io/istio/pkg/security"
 	"istio.io/istio/security/pkg/credentialfetcher/plugin"
 )
 
-func NewCredFetcher(credtype, trustdomain, jwtPath, identityProvider string) (security.CredFetcher, error) {
+// Config holds the settings of the credential fetcher plugins which are not derived from the trust domain.
+type Config struct {
+	// TokenFile is the file the TokenFile plugin reads the token from.
+	TokenFile string
+	// MetadataURL is the endpoint the InstanceMetadata plugin fetches the token from.
+	// plugin.AudiencePlaceholder in the URL is replaced by the audience.
+	MetadataURL string
+	// MetadataHeaders are the headers sent to the metadata endpoint.
+	MetadataHeaders map[string]string
+	// MetadataAudience is the audience of the token requested from the metadata endpoint. Defaults to the trust domain.
+	MetadataAudience string
+	// MetadataTokenField is the JSON field of the metadata response holding the token.
+	// If empty, the response body is the token.
+	MetadataTokenField string
+}
+
+func NewCredFetcher(credtype, trustdomain, jwtPath, identityProvider string, cfg Config) (security.CredFetcher, error) {
 	switch credtype {
 	case security.GCE:
 		return plugin.CreateGCEPlugin(trustdomain, jwtPath, identityProvider), nil
+	case security.TokenFile:
+		if cfg.TokenFile == "" {
+			return nil, fmt.Errorf("token file is required for credential fetcher type %s", credtype)
+		}
+		return plugin.CreateFilePlugin(cfg.TokenFile, jwtPath, identityProvider), nil
+	case security.InstanceMetadata:
+		if cfg.MetadataURL == "" {
+			return nil, fmt.Errorf("metadata URL is required for credential fetcher type %s", credtype)
+		}
+		audience := cfg.MetadataAudience
+		if audience == "" {
+			audience = trustdomain
+		}
+		return plugin.CreateMetadataPlugin(plugin.MetadataOptions{
+			URL:        cfg.MetadataURL,
+			Headers:    cfg.MetadataHeaders,
+			Audience:   audience,
+			TokenField: cfg.MetadataTokenField,
+		}, jwtPath, identityProvider), nil
 	case security.Mock: // for test only
 		return plugin.CreateMockPlugin("test_token"), nil
 	default:
 		return nil, fmt.Errorf("invalid credential fetcher type %s", credtype)
 	}
 }
+
+// ParseHeaders parses a comma separated list of "Name: value" headers.
+func ParseHeaders(headers string) (map[string]string, error) {
+	if headers == "" {
+		return nil, nil
+	}
+	out := map[string]string{}
+	for _, h := range strings.Split(headers, ",") {
+		kv := strings.SplitN(h, ":", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, fmt.Errorf("invalid header %q, expected \"Name: value\"", h)
+		}
+		out[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return out, nil
+}
@@ -15,6 +15,8 @@
 package credentialfetcher
 
 import (
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"istio.io/istio/pkg/security"
@@ -22,11 +24,16 @@ import (
 )
 
 func TestNewCredFetcher(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "istio-token")
+	if err := ioutil.WriteFile(tokenFile, []byte("file_token"), 0600); err != nil {
+		t.Fatal(err)
+	}
 	testCases := map[string]struct {
 		fetcherType      string
 		trustdomain      string
 		jwtPath          string
 		identityProvider string
+		config           Config
 		expectedErr      string
 		expectedToken    string
 		expectedIdp      string
@@ -49,6 +56,29 @@ func TestNewCredFetcher(t *testing.T) {
 			expectedToken:    "test_token",
 			expectedIdp:      "fakeIDP",
 		},
+		"token file test": {
+			fetcherType:      security.TokenFile,
+			trustdomain:      "cluster.local",
+			identityProvider: "fakeIDP",
+			config:           Config{TokenFile: tokenFile},
+			expectedToken:    "file_token",
+			expectedIdp:      "fakeIDP",
+		},
+		"token file not set": {
+			fetcherType: security.TokenFile,
+			expectedErr: "token file is required for credential fetcher type TokenFile",
+		},
+		"instance metadata test": {
+			fetcherType:      security.InstanceMetadata,
+			trustdomain:      "cluster.local",
+			identityProvider: "fakeIDP",
+			config:           Config{MetadataURL: "http://169.254.169.254/identity"},
+			expectedIdp:      "fakeIDP",
+		},
+		"instance metadata url not set": {
+			fetcherType: security.InstanceMetadata,
+			expectedErr: "metadata URL is required for credential fetcher type InstanceMetadata",
+		},
 		"invalid test": {
 			fetcherType:      "foo",
 			trustdomain:      "",
@@ -60,13 +90,16 @@ func TestNewCredFetcher(t *testing.T) {
 		},
 	}
 
-	// Disable token refresh for GCE VM credential fetcher.
+	// Disable token refresh for GCE VM credential fetcher, and restore it for other tests once the parallel
+	// subtests completed.
 	plugin.SetTokenRotation(false)
+	t.Cleanup(func() { plugin.SetTokenRotation(true) })
 	for id, tc := range testCases {
+		id, tc := id, tc
 		t.Run(id, func(t *testing.T) {
 			t.Parallel()
 			cf, err := NewCredFetcher(
-				tc.fetcherType, tc.trustdomain, tc.jwtPath, tc.identityProvider)
+				tc.fetcherType, tc.trustdomain, tc.jwtPath, tc.identityProvider, tc.config)
 			if cf != nil {
 				defer cf.Stop()
 			}
@@ -85,7 +118,7 @@ func TestNewCredFetcher(t *testing.T) {
 				if idp != tc.expectedIdp {
 					t.Errorf("%s: GetIdentityProvider returned %s, expected %s", id, idp, tc.expectedIdp)
 				}
-				if tc.fetcherType == security.Mock {
+				if tc.fetcherType == security.Mock || tc.fetcherType == security.TokenFile {
 					token, err := cf.GetPlatformCredential()
 					if err != nil {
 						t.Errorf("%s: unexpected error calling GetPlatformCredential: %v", id, err)
@@ -97,6 +130,17 @@ func TestNewCredFetcher(t *testing.T) {
 			}
 		})
 	}
-	// Restore token refresh for other tests.
-	plugin.SetTokenRotation(true)
+}
+
+func TestParseHeaders(t *testing.T) {
+	got, err := ParseHeaders("Metadata: true, X-Token:abc:def")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got["Metadata"] != "true" || got["X-Token"] != "abc:def" {
+		t.Errorf("unexpected headers %v", got)
+	}
+	if _, err := ParseHeaders("Metadata"); err == nil {
+		t.Errorf("expected an error for a header without value")
+	}
 }
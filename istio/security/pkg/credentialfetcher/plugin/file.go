@@ -0,0 +1,139 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This is the token file plugin of credentialfetcher.
+package plugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"sync"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/pkg/filewatcher"
+	"istio.io/pkg/log"
+)
+
+var filecredLog = log.RegisterScope("filecred", "Token file credential fetcher for istio agent", 0)
+
+// The plugin object.
+type FilePlugin struct {
+	// The file the platform writes the token to, e.g. a projected token volume.
+	tokenFile string
+
+	// The location to save the identity token, if different from tokenFile.
+	jwtPath string
+
+	// identity provider
+	identityProvider string
+
+	// token refresh
+	watcher    filewatcher.FileWatcher
+	tokenCache string
+	// mutex lock is required to avoid race condition when updating token file and token cache.
+	tokenMutex sync.RWMutex
+}
+
+// CreateFilePlugin creates a credential fetcher plugin reading the token from tokenFile, and reloading it
+// whenever the file is rotated. Return the pointer to the created plugin.
+func CreateFilePlugin(tokenFile, jwtPath, identityProvider string) *FilePlugin {
+	p := &FilePlugin{
+		tokenFile:        tokenFile,
+		jwtPath:          jwtPath,
+		identityProvider: identityProvider,
+	}
+	if tokenFile == "" {
+		return p
+	}
+	if _, err := p.reload(); err != nil {
+		filecredLog.Warnf("failed to load token file %s: %v", tokenFile, err)
+	}
+	if rotateToken {
+		p.watcher = filewatcher.NewWatcher()
+		if err := p.watcher.Add(tokenFile); err != nil {
+			// The token is read on demand instead.
+			filecredLog.Warnf("failed to watch token file %s: %v", tokenFile, err)
+			_ = p.watcher.Close()
+			p.watcher = nil
+			return p
+		}
+		go func() {
+			for range p.watcher.Events(tokenFile) {
+				if _, err := p.reload(); err != nil {
+					filecredLog.Errorf("credential refresh failed: %v", err)
+				}
+			}
+		}()
+	}
+	return p
+}
+
+func (p *FilePlugin) Stop() {
+	if p.watcher != nil {
+		_ = p.watcher.Close()
+	}
+}
+
+// reload reads the token file, and writes it to jwtPath.
+func (p *FilePlugin) reload() (string, error) {
+	p.tokenMutex.Lock()
+	defer p.tokenMutex.Unlock()
+
+	if p.tokenFile == "" {
+		return "", fmt.Errorf("token file is unset")
+	}
+	b, err := ioutil.ReadFile(p.tokenFile)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(b))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", p.tokenFile)
+	}
+	if token == p.tokenCache {
+		return token, nil
+	}
+	p.tokenCache = token
+	filecredLog.Debugf("loaded token from %s: %d", p.tokenFile, len(token))
+	if p.jwtPath != "" && p.jwtPath != p.tokenFile {
+		if err := ioutil.WriteFile(p.jwtPath, []byte(token), 0640); err != nil {
+			filecredLog.Errorf("Encountered error when writing identity token: %v", err)
+			return "", err
+		}
+	}
+	return token, nil
+}
+
+// GetPlatformCredential returns the token last loaded from the token file. The file is read again if no token
+// was loaded yet, or if the file is not watched.
+func (p *FilePlugin) GetPlatformCredential() (string, error) {
+	p.tokenMutex.RLock()
+	token := p.tokenCache
+	p.tokenMutex.RUnlock()
+	if token != "" && p.watcher != nil {
+		return token, nil
+	}
+	return p.reload()
+}
+
+// GetType returns credential fetcher type.
+func (p *FilePlugin) GetType() string {
+	return security.TokenFile
+}
+
+// GetIdentityProvider returns the name of the identity provider that can authenticate the workload credential.
+func (p *FilePlugin) GetIdentityProvider() string {
+	return p.identityProvider
+}
@@ -0,0 +1,96 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/pkg/test/util/retry"
+)
+
+func TestFilePlugin(t *testing.T) {
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "token")
+	jwtPath := filepath.Join(dir, "istio-token")
+	if err := ioutil.WriteFile(tokenFile, []byte(thirdPartyJwt+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := CreateFilePlugin(tokenFile, jwtPath, "fakeIDP")
+	defer p.Stop()
+	if p.GetType() != security.TokenFile || p.GetIdentityProvider() != "fakeIDP" {
+		t.Errorf("unexpected type %s or identity provider %s", p.GetType(), p.GetIdentityProvider())
+	}
+	token, err := p.GetPlatformCredential()
+	if err != nil {
+		t.Fatalf("GetPlatformCredential() returns err: %v", err)
+	}
+	verifyToken(t, "initial token", jwtPath, []string{token}, thirdPartyJwt)
+
+	// The token is reloaded when the file is rotated.
+	if err := ioutil.WriteFile(tokenFile, []byte(firstPartyJwt), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	retry.UntilSuccessOrFail(t, func() error {
+		token, err := p.GetPlatformCredential()
+		if err != nil {
+			return err
+		}
+		if token != firstPartyJwt {
+			return fmt.Errorf("got token %s, want the rotated token", token)
+		}
+		return nil
+	})
+	verifyToken(t, "rotated token", jwtPath, []string{firstPartyJwt}, firstPartyJwt)
+}
+
+func TestFilePluginErrors(t *testing.T) {
+	SetTokenRotation(false)
+	t.Cleanup(func() {
+		SetTokenRotation(true)
+	})
+	dir := t.TempDir()
+
+	p := CreateFilePlugin(filepath.Join(dir, "missing"), "", "")
+	if _, err := p.GetPlatformCredential(); err == nil {
+		t.Errorf("expected an error for a missing token file")
+	}
+
+	empty := filepath.Join(dir, "empty")
+	if err := ioutil.WriteFile(empty, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p = CreateFilePlugin(empty, "", "")
+	if _, err := p.GetPlatformCredential(); err == nil {
+		t.Errorf("expected an error for an empty token file")
+	}
+
+	// Without rotation watching, the file is read on every call.
+	tokenFile := filepath.Join(dir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte(thirdPartyJwt), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	p = CreateFilePlugin(tokenFile, "", "")
+	if err := ioutil.WriteFile(tokenFile, []byte(firstPartyJwt), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if token, err := p.GetPlatformCredential(); err != nil || token != firstPartyJwt {
+		t.Errorf("GetPlatformCredential() returns %s, %v, want the updated token", token, err)
+	}
+}
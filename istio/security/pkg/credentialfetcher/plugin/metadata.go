@@ -0,0 +1,212 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This is the generic instance metadata plugin of credentialfetcher.
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/security/pkg/util"
+	"istio.io/pkg/log"
+)
+
+var metadatacredLog = log.RegisterScope("metadatacred", "Instance metadata credential fetcher for istio agent", 0)
+
+// AudiencePlaceholder is replaced by the audience in the URL of the metadata endpoint.
+const AudiencePlaceholder = "{{audience}}"
+
+// MetadataOptions configures the instance metadata plugin.
+type MetadataOptions struct {
+	// URL of the local metadata endpoint returning the identity token. AudiencePlaceholder in the URL is replaced
+	// by the audience.
+	URL string
+	// Headers sent with the request, e.g. "Metadata: true".
+	Headers map[string]string
+	// Audience of the requested token.
+	Audience string
+	// TokenField is the field of the JSON response holding the token. If empty, the response body is the token.
+	TokenField string
+}
+
+// The plugin object.
+type MetadataPlugin struct {
+	opts MetadataOptions
+
+	// The location to save the identity token
+	jwtPath string
+
+	// identity provider
+	identityProvider string
+
+	client *http.Client
+
+	// token refresh
+	rotationTicker *time.Ticker
+	closing        chan bool
+	tokenCache     string
+	// mutex lock is required to avoid race condition when updating token file and token cache.
+	tokenMutex sync.RWMutex
+}
+
+// CreateMetadataPlugin creates a credential fetcher plugin fetching the token from an instance metadata endpoint.
+// Return the pointer to the created plugin.
+func CreateMetadataPlugin(opts MetadataOptions, jwtPath, identityProvider string) *MetadataPlugin {
+	p := &MetadataPlugin{
+		opts:             opts,
+		jwtPath:          jwtPath,
+		identityProvider: identityProvider,
+		client:           &http.Client{Timeout: 5 * time.Second},
+		closing:          make(chan bool),
+	}
+	if rotateToken {
+		go p.startTokenRotationJob()
+	}
+	return p
+}
+
+func (p *MetadataPlugin) Stop() {
+	close(p.closing)
+}
+
+func (p *MetadataPlugin) startTokenRotationJob() {
+	// Wake up once in a while and refresh the credential.
+	p.rotationTicker = time.NewTicker(rotationInterval)
+	for {
+		select {
+		case <-p.rotationTicker.C:
+			p.rotate()
+		case <-p.closing:
+			if p.rotationTicker != nil {
+				p.rotationTicker.Stop()
+			}
+			return
+		}
+	}
+}
+
+func (p *MetadataPlugin) rotate() {
+	if p.shouldRotate(time.Now()) {
+		if _, err := p.fetch(); err != nil {
+			metadatacredLog.Errorf("credential refresh failed: %+v", err)
+		}
+	}
+}
+
+func (p *MetadataPlugin) shouldRotate(now time.Time) bool {
+	p.tokenMutex.RLock()
+	defer p.tokenMutex.RUnlock()
+
+	if p.tokenCache == "" {
+		return true
+	}
+	exp, err := util.GetExp(p.tokenCache)
+	// When fails to get expiration time from token, always refresh the token.
+	if err != nil || exp.IsZero() {
+		return true
+	}
+	return now.After(exp.Add(-gracePeriod))
+}
+
+// GetPlatformCredential returns the cached identity token, or fetches it from the metadata endpoint if it is
+// missing or about to expire. The token is written to jwtPath, if set.
+func (p *MetadataPlugin) GetPlatformCredential() (string, error) {
+	if !p.shouldRotate(time.Now()) {
+		p.tokenMutex.RLock()
+		defer p.tokenMutex.RUnlock()
+		return p.tokenCache, nil
+	}
+	return p.fetch()
+}
+
+func (p *MetadataPlugin) fetch() (string, error) {
+	p.tokenMutex.Lock()
+	defer p.tokenMutex.Unlock()
+
+	if p.opts.URL == "" {
+		return "", fmt.Errorf("metadata URL is unset")
+	}
+	u := strings.ReplaceAll(p.opts.URL, AudiencePlaceholder, url.QueryEscape(p.opts.Audience))
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range p.opts.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		metadatacredLog.Errorf("Failed to get identity token from metadata endpoint: %v", err)
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata endpoint returned status %d: %s", resp.StatusCode, string(body))
+	}
+	token, err := p.extractToken(body)
+	if err != nil {
+		return "", err
+	}
+	// Update token cache.
+	p.tokenCache = token
+	metadatacredLog.Debugf("Got identity token: %d", len(token))
+	if p.jwtPath != "" {
+		if err := ioutil.WriteFile(p.jwtPath, []byte(token), 0640); err != nil {
+			metadatacredLog.Errorf("Encountered error when writing identity token: %v", err)
+			return "", err
+		}
+	}
+	return token, nil
+}
+
+func (p *MetadataPlugin) extractToken(body []byte) (string, error) {
+	if p.opts.TokenField == "" {
+		token := strings.TrimSpace(string(body))
+		if token == "" {
+			return "", fmt.Errorf("metadata endpoint returned an empty token")
+		}
+		return token, nil
+	}
+	fields := map[string]interface{}{}
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return "", fmt.Errorf("failed to parse metadata response: %v", err)
+	}
+	token, ok := fields[p.opts.TokenField].(string)
+	if !ok || token == "" {
+		return "", fmt.Errorf("metadata response has no %q field", p.opts.TokenField)
+	}
+	return token, nil
+}
+
+// GetType returns credential fetcher type.
+func (p *MetadataPlugin) GetType() string {
+	return security.InstanceMetadata
+}
+
+// GetIdentityProvider returns the name of the identity provider that can authenticate the workload credential.
+func (p *MetadataPlugin) GetIdentityProvider() string {
+	return p.identityProvider
+}
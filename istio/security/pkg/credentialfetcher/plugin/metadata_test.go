@@ -0,0 +1,108 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package plugin
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"istio.io/istio/pkg/security"
+)
+
+func TestMetadataPlugin(t *testing.T) {
+	SetTokenRotation(false)
+	ms, err := StartMetadataServer()
+	if err != nil {
+		t.Fatalf("StartMetadataServer() returns err: %v", err)
+	}
+	t.Cleanup(func() {
+		ms.Stop()
+		SetTokenRotation(true)
+	})
+
+	testCases := map[string]struct {
+		opts          MetadataOptions
+		response      string
+		expectedToken string
+		expectedErr   string
+		expectedURI   string
+	}{
+		"raw token with audience and headers": {
+			opts: MetadataOptions{
+				URL:      ms.URL() + "/identity?audience=" + AudiencePlaceholder,
+				Headers:  map[string]string{"Metadata": "true"},
+				Audience: "spiffe://cluster.local",
+			},
+			response:      thirdPartyJwt,
+			expectedToken: thirdPartyJwt,
+			expectedURI:   "/identity?audience=spiffe%3A%2F%2Fcluster.local",
+		},
+		"token in JSON field": {
+			opts: MetadataOptions{
+				URL:        ms.URL() + "/token",
+				TokenField: "access_token",
+			},
+			response:      fmt.Sprintf(`{"access_token": %q, "expires_in": 3600}`, thirdPartyJwt),
+			expectedToken: thirdPartyJwt,
+			expectedURI:   "/token",
+		},
+		"missing JSON field": {
+			opts: MetadataOptions{
+				URL:        ms.URL() + "/token",
+				TokenField: "access_token",
+			},
+			response:    `{"token": "foo"}`,
+			expectedErr: `metadata response has no "access_token" field`,
+		},
+		"url not set": {
+			expectedErr: "metadata URL is unset",
+		},
+	}
+
+	for id, tc := range testCases {
+		t.Run(id, func(t *testing.T) {
+			jwtPath := filepath.Join(t.TempDir(), "istio-token")
+			p := CreateMetadataPlugin(tc.opts, jwtPath, "fakeIDP")
+			defer p.Stop()
+			ms.Reset()
+			ms.setToken(tc.response)
+
+			token, err := p.GetPlatformCredential()
+			if tc.expectedErr != "" {
+				if err == nil || err.Error() != tc.expectedErr {
+					t.Fatalf("GetPlatformCredential() returns err: %v, want: %v", err, tc.expectedErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetPlatformCredential() returns err: %v", err)
+			}
+			verifyToken(t, id, jwtPath, []string{token}, tc.expectedToken)
+			req := ms.LastRequest()
+			if req.URL.RequestURI() != tc.expectedURI {
+				t.Errorf("metadata server got request %s, want %s", req.URL.RequestURI(), tc.expectedURI)
+			}
+			for k, v := range tc.opts.Headers {
+				if got := req.Header.Get(k); got != v {
+					t.Errorf("metadata server got header %s: %s, want %s", k, got, v)
+				}
+			}
+			if p.GetType() != security.InstanceMetadata || p.GetIdentityProvider() != "fakeIDP" {
+				t.Errorf("unexpected type %s or identity provider %s", p.GetType(), p.GetIdentityProvider())
+			}
+		})
+	}
+}
@@ -69,6 +69,7 @@ type MetadataServer struct {
 
 	numGetTokenCall int
 	credential      string
+	lastRequest     *http.Request
 	mutex           sync.RWMutex
 }
 
@@ -100,6 +101,19 @@ func (ms *MetadataServer) NumGetTokenCall() int {
 	return ms.numGetTokenCall
 }
 
+// URL returns the URL of the mock metadata server.
+func (ms *MetadataServer) URL() string {
+	return ms.server.URL
+}
+
+// LastRequest returns the last token fetching request.
+func (ms *MetadataServer) LastRequest() *http.Request {
+	ms.mutex.RLock()
+	defer ms.mutex.RUnlock()
+
+	return ms.lastRequest
+}
+
 // ResetGetTokenCall resets members to default values.
 func (ms *MetadataServer) Reset() {
 	ms.mutex.Lock()
@@ -107,6 +121,7 @@ func (ms *MetadataServer) Reset() {
 
 	ms.numGetTokenCall = 0
 	ms.credential = ""
+	ms.lastRequest = nil
 }
 
 func (ms *MetadataServer) getToken(w http.ResponseWriter, req *http.Request) {
@@ -114,6 +129,7 @@ func (ms *MetadataServer) getToken(w http.ResponseWriter, req *http.Request) {
 	defer ms.mutex.Unlock()
 
 	ms.numGetTokenCall++
+	ms.lastRequest = req
 	token := fmt.Sprintf("%s%d", fakeTokenPrefix, ms.numGetTokenCall)
 	if ms.credential != "" {
 		token = ms.credential
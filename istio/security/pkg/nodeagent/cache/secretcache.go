@@ -540,19 +540,15 @@ func (sc *SecretManagerClient) generateNewSecret(resourceName string) (*security
 	}
 
 	// Generate the cert/key, send CSR to CA.
-	csrPEM, keyPEM, err := pkiutil.GenCSR(options)
-	if err != nil {
-		cacheLog.Errorf("%s failed to generate key and certificate for CSR: %v", logPrefix, err)
-		return nil, err
+	keyPolicy := sc.workloadKeyPolicy()
+	certChainPEM, keyPEM, err := sc.signCSR(resourceName, options, keyPolicy)
+	if err != nil && sc.workloadKeyPolicy() != keyPolicy {
+		// The CA may have rejected the key under a policy we did not know yet, retry with a compliant key.
+		keyPolicy = sc.workloadKeyPolicy()
+		cacheLog.Infof("%s workload key policy changed to %q, retrying with a new key", logPrefix, keyPolicy)
+		certChainPEM, keyPEM, err = sc.signCSR(resourceName, options, keyPolicy)
 	}
-
-	numOutgoingRequests.With(RequestType.Value(monitoring.CSR)).Increment()
-	timeBeforeCSR := time.Now()
-	certChainPEM, err := sc.caClient.CSRSign(csrPEM, int64(sc.configOptions.SecretTTL.Seconds()))
-	csrLatency := float64(time.Since(timeBeforeCSR).Nanoseconds()) / float64(time.Millisecond)
-	outgoingLatency.With(RequestType.Value(monitoring.CSR)).Record(csrLatency)
 	if err != nil {
-		numFailedOutgoingRequests.With(RequestType.Value(monitoring.CSR)).Increment()
 		return nil, err
 	}
 
@@ -579,6 +575,41 @@ func (sc *SecretManagerClient) generateNewSecret(resourceName string) (*security
 	}, nil
 }
 
+// workloadKeyPolicy returns the workload key policy learned from the CA, empty if none.
+func (sc *SecretManagerClient) workloadKeyPolicy() string {
+	if c, ok := sc.caClient.(security.KeyPolicyClient); ok {
+		return c.WorkloadKeyPolicy()
+	}
+	return ""
+}
+
+// signCSR generates a key allowed by the workload key policy and sends its CSR to the CA. It returns the
+// certificate chain and the private key.
+func (sc *SecretManagerClient) signCSR(resourceName string, options pkiutil.CertOptions,
+	keyPolicy string) ([]string, []byte, error) {
+	logPrefix := cacheLogPrefix(resourceName)
+	policy, err := pkiutil.ParseKeyPolicy(keyPolicy)
+	if err != nil {
+		cacheLog.Warnf("%s ignoring invalid workload key policy: %v", logPrefix, err)
+	}
+	csrPEM, keyPEM, err := pkiutil.GenCSR(policy.Apply(options))
+	if err != nil {
+		cacheLog.Errorf("%s failed to generate key and certificate for CSR: %v", logPrefix, err)
+		return nil, nil, err
+	}
+
+	numOutgoingRequests.With(RequestType.Value(monitoring.CSR)).Increment()
+	timeBeforeCSR := time.Now()
+	certChainPEM, err := sc.caClient.CSRSign(csrPEM, int64(sc.configOptions.SecretTTL.Seconds()))
+	csrLatency := float64(time.Since(timeBeforeCSR).Nanoseconds()) / float64(time.Millisecond)
+	outgoingLatency.With(RequestType.Value(monitoring.CSR)).Record(csrLatency)
+	if err != nil {
+		numFailedOutgoingRequests.With(RequestType.Value(monitoring.CSR)).Increment()
+		return nil, nil, err
+	}
+	return certChainPEM, keyPEM, nil
+}
+
 func (sc *SecretManagerClient) rotateTime(secret security.SecretItem) time.Duration {
 	secretLifeTime := secret.ExpireTime.Sub(secret.CreatedTime)
 	gracePeriod := time.Duration((sc.configOptions.SecretRotationGracePeriodRatio) * float64(secretLifeTime))
@@ -701,6 +732,9 @@ func (sc *SecretManagerClient) setConfigTrustBundle(trustBundle []byte) {
 
 // UpdateConfigTrustBundle : Update the Configured Trust Bundle in the secret Manager client
 func (sc *SecretManagerClient) UpdateConfigTrustBundle(trustBundle []byte) error {
+	if bytes.Equal(sc.getConfigTrustBundle(), trustBundle) {
+		return nil
+	}
 	sc.setConfigTrustBundle(trustBundle)
 	sc.CallUpdateCallback(security.RootCertReqResourceName)
 	return nil
@@ -16,6 +16,7 @@ package cache
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -23,6 +24,7 @@ import (
 	"reflect"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -32,6 +34,7 @@ import (
 	"istio.io/istio/pkg/test/util/retry"
 	"istio.io/istio/pkg/testcerts"
 	"istio.io/istio/security/pkg/nodeagent/caclient/providers/mock"
+	pkiutil "istio.io/istio/security/pkg/pki/util"
 	"istio.io/istio/tests/util/leak"
 	"istio.io/pkg/log"
 )
@@ -594,3 +597,28 @@ func TestProxyConfigAnchors(t *testing.T) {
 		RootCert:     rootCert,
 	})
 }
+
+func TestWorkloadKeyPolicy(t *testing.T) {
+	fakeCACli, err := mock.NewMockCAClient(time.Hour)
+	if err != nil {
+		t.Fatalf("Error creating Mock CA client: %v", err)
+	}
+	fakeCACli.KeyPolicy = pkiutil.KeyPolicy{{ECSigAlg: pkiutil.EcdsaSigAlg, ECCCurve: pkiutil.P384Curve}}
+
+	// The agent generates an RSA key, which is rejected, then retries with the key of the policy sent by the CA.
+	sc := createCache(t, fakeCACli, func(resourceName string) {}, security.Options{})
+	gotSecret, err := sc.GenerateSecret(security.WorkloadKeyCertResourceName)
+	if err != nil {
+		t.Fatalf("Failed to get secrets: %v", err)
+	}
+	if got := atomic.LoadUint64(&fakeCACli.SignInvokeCount); got != 2 {
+		t.Errorf("expected 2 CSRs, got %d", got)
+	}
+	key, err := pkiutil.ParsePemEncodedKey(gotSecret.PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ecKey, ok := key.(*ecdsa.PrivateKey); !ok || ecKey.Curve.Params().Name != "P-384" {
+		t.Errorf("expected an ECDSA P-384 key, got %T", key)
+	}
+}
@@ -27,6 +27,7 @@ import (
 	"istio.io/istio/pkg/config/constants"
 	"istio.io/istio/pkg/jwt"
 	"istio.io/istio/pkg/security"
+	"istio.io/istio/security/pkg/credentialfetcher"
 	"istio.io/istio/security/pkg/stsservice"
 	stsmock "istio.io/istio/security/pkg/stsservice/mock"
 	"istio.io/istio/security/pkg/stsservice/tokenmanager/google"
@@ -69,7 +70,7 @@ func TestGetTokenForXDS(t *testing.T) {
 		SecretRotationGracePeriodRatio: 0.5,
 	}
 	secOpts, err := secop.SetupSecurityOptions(proxyConfig, sop, jwtPolicy,
-		credFetcherTypeEnv, credIdentityProvider)
+		credFetcherTypeEnv, credIdentityProvider, credentialfetcher.Config{})
 	if err != nil {
 		t.Fatalf("failed to setup security options: %v", err)
 	}
@@ -49,8 +49,12 @@ type CitadelClient struct {
 	provider      *caclient.TokenProvider
 	opts          security.Options
 	usingMtls     *atomic.Bool
+	// keyPolicy is the last workload key policy sent by the CA.
+	keyPolicy *atomic.String
 }
 
+var _ security.KeyPolicyClient = &CitadelClient{}
+
 // NewCitadelClient create a CA client for Citadel.
 func NewCitadelClient(opts security.Options, tls bool, rootCert []byte) (*CitadelClient, error) {
 	c := &CitadelClient{
@@ -59,6 +63,7 @@ func NewCitadelClient(opts security.Options, tls bool, rootCert []byte) (*Citade
 		opts:          opts,
 		provider:      caclient.NewCATokenProvider(opts),
 		usingMtls:     atomic.NewBool(false),
+		keyPolicy:     atomic.NewString(""),
 	}
 
 	conn, err := c.buildConnection()
@@ -87,7 +92,15 @@ func (c *CitadelClient) CSRSign(csrPEM []byte, certValidTTLInSec int64) ([]strin
 		return nil, err
 	}
 	ctx := metadata.NewOutgoingContext(context.Background(), metadata.Pairs("ClusterID", c.opts.ClusterID))
-	resp, err := c.client.CreateCertificate(ctx, req)
+	var header metadata.MD
+	resp, err := c.client.CreateCertificate(ctx, req, grpc.Header(&header))
+	// A successful response without the header means the CA has no key policy. Failed requests may
+	// have been rejected before the CA sent the policy, so keep the last one.
+	if policy := header.Get(security.WorkloadKeyPolicyHeader); len(policy) > 0 {
+		c.keyPolicy.Store(policy[0])
+	} else if err == nil {
+		c.keyPolicy.Store("")
+	}
 	if err != nil {
 		return nil, fmt.Errorf("create certificate: %v", err)
 	}
@@ -99,6 +112,11 @@ func (c *CitadelClient) CSRSign(csrPEM []byte, certValidTTLInSec int64) ([]strin
 	return resp.CertChain, nil
 }
 
+// WorkloadKeyPolicy returns the last workload key policy sent by the CA.
+func (c *CitadelClient) WorkloadKeyPolicy() string {
+	return c.keyPolicy.Load()
+}
+
 func (c *CitadelClient) getTLSDialOption() (grpc.DialOption, error) {
 	// Load the TLS root certificate from the specified file.
 	// Create a certificate pool
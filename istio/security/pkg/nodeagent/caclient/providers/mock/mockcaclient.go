@@ -39,6 +39,10 @@ type CAClient struct {
 	bundle          util.KeyCertBundle
 	certLifetime    time.Duration
 	GeneratedCerts  [][]string // Cache the generated certificates for verification purpose.
+	// KeyPolicy, if set, rejects the CSRs with keys it does not allow. Like the CA, the policy is only
+	// known to the client after the first CSR.
+	KeyPolicy  util.KeyPolicy
+	policySent uint32
 }
 
 // NewMockCAClient creates an instance of CAClient. errors is used to specify the number of errors
@@ -68,6 +72,10 @@ func (c *CAClient) CSRSign(csrPEM []byte, certValidTTLInSec int64) ([]string, er
 	if err != nil {
 		return nil, fmt.Errorf("csr sign error: %v", err)
 	}
+	atomic.StoreUint32(&c.policySent, 1)
+	if err := c.KeyPolicy.CheckPublicKey(csr.PublicKey); err != nil {
+		return nil, fmt.Errorf("csr sign error: %v", err)
+	}
 	subjectIDs := []string{"test"}
 	certBytes, err := util.GenCertFromCSR(csr, signingCert, csr.PublicKey, *signingKey, subjectIDs, c.certLifetime, false)
 	if err != nil {
@@ -85,6 +93,14 @@ func (c *CAClient) CSRSign(csrPEM []byte, certValidTTLInSec int64) ([]string, er
 	return ret, nil
 }
 
+// WorkloadKeyPolicy returns the key policy once a CSR was sent.
+func (c *CAClient) WorkloadKeyPolicy() string {
+	if atomic.LoadUint32(&c.policySent) == 0 {
+		return ""
+	}
+	return c.KeyPolicy.String()
+}
+
 // TokenExchangeServer is the mocked token exchange server for testing.
 type TokenExchangeServer struct {
 	exchangeMap map[string]string
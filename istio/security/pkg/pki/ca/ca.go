@@ -86,6 +86,9 @@ type IstioCAOptions struct {
 
 	// Config for creating self-signed root cert rotator.
 	RotatorConfig *SelfSignedCARootCertRotatorConfig
+
+	// Config for creating plugged-in cert rotator. The rotator is disabled if nil.
+	PluggedCertRotatorConfig *PluggedCertRotatorConfig
 }
 
 // NewSelfSignedIstioCAOptions returns a new IstioCAOptions instance using self-signed certificate.
@@ -255,6 +258,10 @@ type IstioCA struct {
 	// rootCertRotator periodically rotates self-signed root cert for CA. It is nil
 	// if CA is not self-signed CA.
 	rootCertRotator *SelfSignedCARootCertRotator
+
+	// pluggedCertRotator rotates plugged-in certs staged in the CA files. It is nil
+	// if CA is not a plugged-in cert CA, or the rotation is disabled.
+	pluggedCertRotator *PluggedCertRotator
 }
 
 // NewIstioCA returns a new IstioCA instance.
@@ -269,6 +276,10 @@ func NewIstioCA(opts *IstioCAOptions) (*IstioCA, error) {
 	if opts.CAType == selfSignedCA && opts.RotatorConfig.CheckInterval > time.Duration(0) {
 		ca.rootCertRotator = NewSelfSignedCARootCertRotator(opts.RotatorConfig, ca)
 	}
+	if opts.CAType == pluggedCertCA && opts.PluggedCertRotatorConfig != nil &&
+		opts.PluggedCertRotatorConfig.CheckInterval > time.Duration(0) {
+		ca.pluggedCertRotator = NewPluggedCertRotator(opts.PluggedCertRotatorConfig, ca)
+	}
 
 	// if CA cert becomes invalid before workload cert it's going to cause workload cert to be invalid too,
 	// however citatel won't rotate if that happens, this function will prevent that using cert chain TTL as
@@ -287,6 +298,14 @@ func (ca *IstioCA) Run(stopChan chan struct{}) {
 		// Start root cert rotator in a separate goroutine.
 		go ca.rootCertRotator.Run(stopChan)
 	}
+	if ca.pluggedCertRotator != nil {
+		go ca.pluggedCertRotator.Run(stopChan)
+	}
+}
+
+// GetPluggedCertRotator returns the plugged-in cert rotator, or nil if it is not enabled.
+func (ca *IstioCA) GetPluggedCertRotator() *PluggedCertRotator {
+	return ca.pluggedCertRotator
 }
 
 // Sign takes a PEM-encoded CSR, subject IDs and lifetime, and returns a signed certificate. If forCA is true,
@@ -0,0 +1,47 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"istio.io/pkg/monitoring"
+)
+
+var (
+	phaseTag = monitoring.MustCreateLabel("phase")
+
+	rotationPhase = monitoring.NewGauge(
+		"citadel_plugged_cert_rotation_phase",
+		"The current phase of the plugged-in CA cert rotation, 1 for the active phase and 0 otherwise.",
+		monitoring.WithLabels(phaseTag),
+	)
+
+	unconfirmedProxies = monitoring.NewGauge(
+		"citadel_plugged_cert_rotation_unconfirmed_proxies",
+		"The number of proxies which have not yet confirmed receiving the new roots of the plugged-in CA.",
+	)
+
+	rotationCompletedCounts = monitoring.NewSum(
+		"citadel_plugged_cert_rotation_completed_count",
+		"The number of completed plugged-in CA cert rotations.",
+	)
+)
+
+func init() {
+	monitoring.MustRegister(
+		rotationPhase,
+		unconfirmedProxies,
+		rotationCompletedCounts,
+	)
+}
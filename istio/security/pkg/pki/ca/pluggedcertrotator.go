@@ -0,0 +1,342 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"bytes"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"istio.io/istio/security/pkg/pki/util"
+	"istio.io/pkg/log"
+)
+
+var pluggedCertRotatorLog = log.RegisterScope("pluggedcertrotator", "Plugged-in CA cert rotator log", 0)
+
+// RotationPhase is the phase of a plugged-in CA certificate rotation.
+type RotationPhase string
+
+const (
+	// RotationPhaseIdle means no rotation is in progress: the CA signs with, and workloads trust, the active bundle.
+	RotationPhaseIdle RotationPhase = "Idle"
+	// RotationPhaseDistributingRoots means a new bundle is staged. The union of the old and new roots is
+	// published, and signing switches once all proxies have confirmed they received it, or once the
+	// RootDistributionTimeout expired.
+	RotationPhaseDistributingRoots RotationPhase = "DistributingRoots"
+	// RotationPhaseSigningWithNewCA means the CA signs with the new bundle. The old roots remain published until
+	// every certificate issued by the old CA has expired.
+	RotationPhaseSigningWithNewCA RotationPhase = "SigningWithNewCA"
+)
+
+var rotationPhases = []RotationPhase{RotationPhaseIdle, RotationPhaseDistributingRoots, RotationPhaseSigningWithNewCA}
+
+// PluggedCertRotatorConfig holds the configuration of a PluggedCertRotator.
+type PluggedCertRotatorConfig struct {
+	SigningCertFile string
+	SigningKeyFile  string
+	CertChainFile   string
+	RootCertFile    string
+
+	// CheckInterval is how often the files are checked for a staged bundle and the rotation is advanced.
+	CheckInterval time.Duration
+	// OldRootGracePeriod is how long the old roots stay published after signing switched to the new bundle.
+	// Defaults to the CA's max workload cert TTL, so that all certs issued by the old CA have expired.
+	OldRootGracePeriod time.Duration
+	// RootDistributionTimeout is how long to wait for all proxies to confirm the new roots before signing switches
+	// to the new bundle anyway. Zero or a negative value waits until all proxies confirmed.
+	RootDistributionTimeout time.Duration
+	// KeyPolicy, if set, restricts the keys of the certificate chain of the staged bundles.
+	KeyPolicy util.KeyPolicy
+
+	// RootsChanged is called with the PEM encoded roots workloads should trust, whenever they change.
+	RootsChanged func(roots []byte)
+	// RootsConfirmed returns the IDs of the connected proxies which have not confirmed receiving the roots
+	// published at or after since, and the number of connected proxies. With several istiod replicas, it must
+	// include the proxies connected to the other replicas. If nil, the roots are considered confirmed as soon as
+	// they are published.
+	RootsConfirmed func(since time.Time) (unconfirmed []string, total int)
+}
+
+// PluggedCertRotationStatus is the status of a plugged-in CA certificate rotation.
+type PluggedCertRotationStatus struct {
+	Phase RotationPhase `json:"phase"`
+	// Since is when the current phase started.
+	Since time.Time `json:"since,omitempty"`
+	// ConfirmedProxies and TotalProxies report the root distribution progress in the DistributingRoots phase.
+	ConfirmedProxies int `json:"confirmedProxies"`
+	TotalProxies     int `json:"totalProxies"`
+	// UnconfirmedProxies are the IDs of the proxies the DistributingRoots phase is waiting for.
+	UnconfirmedProxies []string `json:"unconfirmedProxies,omitempty"`
+	// OldRootsRemovalTime is when the old roots will be dropped in the SigningWithNewCA phase.
+	OldRootsRemovalTime time.Time `json:"oldRootsRemovalTime,omitempty"`
+	// CompletedRotations is the number of rotations completed since istiod started.
+	CompletedRotations int    `json:"completedRotations"`
+	LastError          string `json:"lastError,omitempty"`
+}
+
+// pluggedCerts is the content of the plugged-in CA files.
+type pluggedCerts struct {
+	cert  []byte
+	key   []byte
+	chain []byte
+	root  []byte
+}
+
+func (c *pluggedCerts) equal(o *pluggedCerts) bool {
+	return o != nil && bytes.Equal(c.cert, o.cert) && bytes.Equal(c.key, o.key) &&
+		bytes.Equal(c.chain, o.chain) && bytes.Equal(c.root, o.root)
+}
+
+// PluggedCertRotator rotates the signing certificate of a plugged-in CA without downtime. When a new bundle is
+// staged in the CA files (e.g. by updating the "cacerts" secret), it:
+// 1. publishes the union of the old and new roots, while still signing with the old bundle;
+// 2. switches signing to the new bundle once all proxies confirmed they received the new roots, or once
+// RootDistributionTimeout expired;
+// 3. drops the old roots after OldRootGracePeriod, once certificates issued by the old CA have expired.
+// The rotation state is kept in memory by each istiod replica, RootsConfirmed coordinates the replicas so that
+// none signs with the new bundle before the proxies of the others trust it. If istiod restarts mid-rotation, it
+// loads the staged bundle directly, so root-cert.pem of the staged bundle should also contain the old root until
+// the rotation completes.
+type PluggedCertRotator struct {
+	config *PluggedCertRotatorConfig
+	ca     *IstioCA
+
+	mutex  sync.RWMutex
+	status PluggedCertRotationStatus
+	active *pluggedCerts
+	staged *pluggedCerts
+}
+
+// NewPluggedCertRotator returns a rotator for the plugged-in certificates the ca was created with.
+func NewPluggedCertRotator(config *PluggedCertRotatorConfig, ca *IstioCA) *PluggedCertRotator {
+	if config.OldRootGracePeriod == 0 {
+		config.OldRootGracePeriod = ca.maxCertTTL
+	}
+	cert, key, chain, root := ca.keyCertBundle.GetAllPem()
+	rotator := &PluggedCertRotator{
+		config: config,
+		ca:     ca,
+		active: &pluggedCerts{cert: cert, key: key, chain: chain, root: root},
+		status: PluggedCertRotationStatus{Phase: RotationPhaseIdle, Since: time.Now()},
+	}
+	rotator.recordPhase()
+	return rotator
+}
+
+// Run periodically checks the CA files and advances the rotation, until stopCh is closed.
+func (r *PluggedCertRotator) Run(stopCh chan struct{}) {
+	ticker := time.NewTicker(r.config.CheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			r.check(time.Now())
+		case <-stopCh:
+			pluggedCertRotatorLog.Info("Received stop signal, so stop the plugged-in cert rotator.")
+			return
+		}
+	}
+}
+
+// Status returns the current rotation status.
+func (r *PluggedCertRotator) Status() PluggedCertRotationStatus {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.status
+}
+
+// check reads the CA files and advances the rotation state machine.
+func (r *PluggedCertRotator) check(now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	switch r.status.Phase {
+	case RotationPhaseIdle:
+		next, err := r.readFiles()
+		if err != nil {
+			r.setError(err)
+			return
+		}
+		if next.equal(r.active) {
+			return
+		}
+		r.stage(next, now)
+	case RotationPhaseDistributingRoots:
+		next, err := r.readFiles()
+		if err != nil {
+			r.setError(err)
+			return
+		}
+		if next.equal(r.active) {
+			// The staged bundle was withdrawn before signing switched, so the new roots can be dropped right away.
+			pluggedCertRotatorLog.Info("staged CA bundle was reverted, aborting rotation")
+			r.staged = nil
+			if err := r.setBundle(r.active, r.active.root); err != nil {
+				r.setError(err)
+				return
+			}
+			r.setPhase(RotationPhaseIdle, now)
+			return
+		}
+		if !next.equal(r.staged) {
+			// A different bundle was staged, restart the distribution for it.
+			r.stage(next, now)
+			return
+		}
+		var unconfirmed []string
+		total := 0
+		if r.config.RootsConfirmed != nil {
+			unconfirmed, total = r.config.RootsConfirmed(r.status.Since)
+		}
+		r.status.ConfirmedProxies, r.status.TotalProxies = total-len(unconfirmed), total
+		r.status.UnconfirmedProxies = unconfirmed
+		unconfirmedProxies.Record(float64(len(unconfirmed)))
+		timeout := r.config.RootDistributionTimeout
+		if len(unconfirmed) > 0 && (timeout <= 0 || now.Sub(r.status.Since) < timeout) {
+			pluggedCertRotatorLog.Debugf("waiting for proxies to confirm new roots: %d/%d", r.status.ConfirmedProxies, total)
+			return
+		}
+		_, _, _, roots := r.ca.keyCertBundle.GetAllPem()
+		if err := r.setBundle(r.staged, roots); err != nil {
+			r.setError(err)
+			return
+		}
+		if len(unconfirmed) > 0 {
+			pluggedCertRotatorLog.Warnf("%d/%d proxies did not confirm the new roots within %v, signing with the new CA anyway: %v",
+				len(unconfirmed), total, timeout, unconfirmed)
+		} else {
+			pluggedCertRotatorLog.Infof("all %d proxies confirmed the new roots, signing with the new CA", total)
+		}
+		r.setPhase(RotationPhaseSigningWithNewCA, now)
+		r.status.OldRootsRemovalTime = now.Add(r.config.OldRootGracePeriod)
+	case RotationPhaseSigningWithNewCA:
+		// Bundles staged in this phase are picked up once the current rotation completed.
+		if now.Before(r.status.OldRootsRemovalTime) {
+			return
+		}
+		if err := r.setBundle(r.staged, r.staged.root); err != nil {
+			r.setError(err)
+			return
+		}
+		pluggedCertRotatorLog.Info("old CA certificates expired, dropping the old roots")
+		r.active, r.staged = r.staged, nil
+		r.status.CompletedRotations++
+		rotationCompletedCounts.Increment()
+		r.setPhase(RotationPhaseIdle, now)
+	}
+}
+
+// stage starts distributing the union of the active and the next roots, still signing with the active bundle.
+func (r *PluggedCertRotator) stage(next *pluggedCerts, now time.Time) {
+	if err := util.Verify(next.cert, next.key, next.chain, next.root); err != nil {
+		r.setError(fmt.Errorf("invalid staged CA bundle: %v", err))
+		return
+	}
+	chain := bytes.Join([][]byte{next.cert, next.chain, next.root}, []byte("\n"))
+	if err := r.config.KeyPolicy.CheckPemCertificates(chain); err != nil {
+		r.setError(fmt.Errorf("staged CA bundle does not comply with the CA key policy: %v", err))
+		return
+	}
+	roots := mergeRootCerts(r.active.root, next.root)
+	if err := r.setBundle(r.active, roots); err != nil {
+		r.setError(err)
+		return
+	}
+	pluggedCertRotatorLog.Info("new CA bundle staged, distributing the new roots")
+	r.staged = next
+	r.setPhase(RotationPhaseDistributingRoots, now)
+}
+
+// setBundle sets the signing certs of the CA, and notifies if the roots changed.
+func (r *PluggedCertRotator) setBundle(certs *pluggedCerts, roots []byte) error {
+	_, _, _, oldRoots := r.ca.keyCertBundle.GetAllPem()
+	if err := r.ca.keyCertBundle.VerifyAndSetAll(certs.cert, certs.key, certs.chain, roots); err != nil {
+		return fmt.Errorf("failed to update CA bundle: %v", err)
+	}
+	if !bytes.Equal(oldRoots, roots) && r.config.RootsChanged != nil {
+		r.config.RootsChanged(roots)
+	}
+	return nil
+}
+
+func (r *PluggedCertRotator) setPhase(phase RotationPhase, now time.Time) {
+	r.status = PluggedCertRotationStatus{
+		Phase:              phase,
+		Since:              now,
+		CompletedRotations: r.status.CompletedRotations,
+	}
+	unconfirmedProxies.Record(0)
+	r.recordPhase()
+}
+
+func (r *PluggedCertRotator) setError(err error) {
+	pluggedCertRotatorLog.Errorf("plugged-in CA cert rotation: %v", err)
+	r.status.LastError = err.Error()
+}
+
+func (r *PluggedCertRotator) recordPhase() {
+	for _, p := range rotationPhases {
+		v := 0.0
+		if p == r.status.Phase {
+			v = 1
+		}
+		rotationPhase.With(phaseTag.Value(string(p))).Record(v)
+	}
+}
+
+func (r *PluggedCertRotator) readFiles() (*pluggedCerts, error) {
+	certs := &pluggedCerts{}
+	var err error
+	if certs.cert, err = ioutil.ReadFile(r.config.SigningCertFile); err != nil {
+		return nil, err
+	}
+	if certs.key, err = ioutil.ReadFile(r.config.SigningKeyFile); err != nil {
+		return nil, err
+	}
+	certs.chain = []byte{}
+	if r.config.CertChainFile != "" {
+		if certs.chain, err = ioutil.ReadFile(r.config.CertChainFile); err != nil {
+			return nil, err
+		}
+	}
+	if certs.root, err = ioutil.ReadFile(r.config.RootCertFile); err != nil {
+		return nil, err
+	}
+	return certs, nil
+}
+
+// mergeRootCerts returns the PEM encoded certificates of a, followed by those of b not already in a.
+func mergeRootCerts(a, b []byte) []byte {
+	var out []byte
+	seen := map[string]struct{}{}
+	for _, rest := range [][]byte{a, b} {
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			if _, f := seen[string(block.Bytes)]; f {
+				continue
+			}
+			seen[string(block.Bytes)] = struct{}{}
+			out = append(out, pem.EncodeToMemory(block)...)
+		}
+	}
+	return out
+}
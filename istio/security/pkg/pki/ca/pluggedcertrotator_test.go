@@ -0,0 +1,311 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+type testCABundle struct {
+	root, cert, key []byte
+}
+
+func genTestCABundle(t *testing.T, org string) testCABundle {
+	t.Helper()
+	rootCertBytes, rootKeyBytes, err := util.GenCertKeyFromOptions(util.CertOptions{
+		IsCA:         true,
+		IsSelfSigned: true,
+		TTL:          time.Hour,
+		Org:          org + " Root",
+		RSAKeySize:   2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootCert, err := util.ParsePemEncodedCertificate(rootCertBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootKey, err := util.ParsePemEncodedKey(rootKeyBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, key, err := util.GenCertKeyFromOptions(util.CertOptions{
+		IsCA:       true,
+		TTL:        time.Hour,
+		Org:        org + " Intermediate",
+		RSAKeySize: 2048,
+		SignerCert: rootCert,
+		SignerPriv: rootKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testCABundle{root: rootCertBytes, cert: cert, key: key}
+}
+
+func writeTestCABundle(t *testing.T, dir string, b testCABundle) {
+	t.Helper()
+	for name, content := range map[string][]byte{
+		caCertID:       b.cert,
+		caPrivateKeyID: b.key,
+		CertChainID:    b.cert,
+		RootCertID:     b.root,
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPluggedCertRotator(t *testing.T) {
+	dir := t.TempDir()
+	oldBundle := genTestCABundle(t, "old")
+	newBundle := genTestCABundle(t, "new")
+	writeTestCABundle(t, dir, oldBundle)
+
+	var publishedRoots []byte
+	var unconfirmed []string
+	total := 0
+	caOpts, err := NewPluggedCertIstioCAOptions(filepath.Join(dir, CertChainID), filepath.Join(dir, caCertID),
+		filepath.Join(dir, caPrivateKeyID), filepath.Join(dir, RootCertID), time.Hour, 2*time.Hour, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caOpts.PluggedCertRotatorConfig = &PluggedCertRotatorConfig{
+		SigningCertFile: filepath.Join(dir, caCertID),
+		SigningKeyFile:  filepath.Join(dir, caPrivateKeyID),
+		CertChainFile:   filepath.Join(dir, CertChainID),
+		RootCertFile:    filepath.Join(dir, RootCertID),
+		CheckInterval:   time.Minute,
+		RootsChanged: func(roots []byte) {
+			publishedRoots = roots
+		},
+		RootsConfirmed: func(time.Time) ([]string, int) {
+			return unconfirmed, total
+		},
+	}
+	ca, err := NewIstioCA(caOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotator := ca.GetPluggedCertRotator()
+	if rotator == nil {
+		t.Fatal("expected plugged-in cert rotator to be enabled")
+	}
+
+	signingCert := func() []byte {
+		cert, _, _, _ := ca.GetCAKeyCertBundle().GetAllPem()
+		return cert
+	}
+	expectPhase := func(phase RotationPhase) {
+		t.Helper()
+		if got := rotator.Status().Phase; got != phase {
+			t.Fatalf("expected phase %v, got %v (status %+v)", phase, got, rotator.Status())
+		}
+	}
+
+	now := time.Now()
+	rotator.check(now)
+	expectPhase(RotationPhaseIdle)
+	if publishedRoots != nil {
+		t.Fatalf("unexpected roots published without a staged bundle")
+	}
+
+	// An invalid staged bundle is rejected.
+	writeTestCABundle(t, dir, testCABundle{root: newBundle.root, cert: newBundle.cert, key: oldBundle.key})
+	rotator.check(now)
+	expectPhase(RotationPhaseIdle)
+	if rotator.Status().LastError == "" {
+		t.Fatalf("expected an error for the invalid staged bundle")
+	}
+
+	// Staging a new bundle distributes the union of roots, still signing with the old CA.
+	writeTestCABundle(t, dir, newBundle)
+	unconfirmed, total = []string{"b"}, 2
+	rotator.check(now)
+	expectPhase(RotationPhaseDistributingRoots)
+	union := mergeRootCerts(oldBundle.root, newBundle.root)
+	if !bytes.Equal(publishedRoots, union) || !bytes.Equal(ca.GetCAKeyCertBundle().GetRootCertPem(), union) {
+		t.Fatalf("expected the union of old and new roots to be published")
+	}
+	if !bytes.Equal(signingCert(), oldBundle.cert) {
+		t.Fatalf("expected to still sign with the old CA")
+	}
+
+	// Signing does not switch until all proxies confirmed.
+	rotator.check(now.Add(time.Minute))
+	expectPhase(RotationPhaseDistributingRoots)
+	if s := rotator.Status(); s.ConfirmedProxies != 1 || s.TotalProxies != 2 || !reflect.DeepEqual(s.UnconfirmedProxies, unconfirmed) {
+		t.Fatalf("unexpected confirmation progress %+v", s)
+	}
+	unconfirmed = nil
+	rotator.check(now.Add(2 * time.Minute))
+	expectPhase(RotationPhaseSigningWithNewCA)
+	if !bytes.Equal(signingCert(), newBundle.cert) {
+		t.Fatalf("expected to sign with the new CA")
+	}
+	if !bytes.Equal(ca.GetCAKeyCertBundle().GetRootCertPem(), union) {
+		t.Fatalf("expected the old roots to still be published")
+	}
+
+	// The old roots are dropped after the grace period, which defaults to the max cert TTL.
+	rotator.check(now.Add(time.Hour))
+	expectPhase(RotationPhaseSigningWithNewCA)
+	rotator.check(now.Add(3 * time.Hour))
+	expectPhase(RotationPhaseIdle)
+	if !bytes.Equal(publishedRoots, newBundle.root) || !bytes.Equal(ca.GetCAKeyCertBundle().GetRootCertPem(), newBundle.root) {
+		t.Fatalf("expected only the new root to be published")
+	}
+	if rotator.Status().CompletedRotations != 1 {
+		t.Fatalf("expected one completed rotation, got %+v", rotator.Status())
+	}
+}
+
+func TestPluggedCertRotatorRevert(t *testing.T) {
+	dir := t.TempDir()
+	oldBundle := genTestCABundle(t, "old")
+	newBundle := genTestCABundle(t, "new")
+	writeTestCABundle(t, dir, oldBundle)
+
+	var publishedRoots []byte
+	caOpts, err := NewPluggedCertIstioCAOptions(filepath.Join(dir, CertChainID), filepath.Join(dir, caCertID),
+		filepath.Join(dir, caPrivateKeyID), filepath.Join(dir, RootCertID), time.Hour, 2*time.Hour, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caOpts.PluggedCertRotatorConfig = &PluggedCertRotatorConfig{
+		SigningCertFile: filepath.Join(dir, caCertID),
+		SigningKeyFile:  filepath.Join(dir, caPrivateKeyID),
+		CertChainFile:   filepath.Join(dir, CertChainID),
+		RootCertFile:    filepath.Join(dir, RootCertID),
+		CheckInterval:   time.Minute,
+		RootsChanged: func(roots []byte) {
+			publishedRoots = roots
+		},
+		RootsConfirmed: func(time.Time) ([]string, int) {
+			return []string{"a"}, 1
+		},
+	}
+	ca, err := NewIstioCA(caOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotator := ca.GetPluggedCertRotator()
+
+	now := time.Now()
+	writeTestCABundle(t, dir, newBundle)
+	rotator.check(now)
+	if rotator.Status().Phase != RotationPhaseDistributingRoots {
+		t.Fatalf("expected new roots to be distributed, got %+v", rotator.Status())
+	}
+
+	writeTestCABundle(t, dir, oldBundle)
+	rotator.check(now.Add(time.Minute))
+	if rotator.Status().Phase != RotationPhaseIdle {
+		t.Fatalf("expected rotation to be aborted, got %+v", rotator.Status())
+	}
+	if !bytes.Equal(publishedRoots, oldBundle.root) {
+		t.Fatalf("expected only the old root to be published")
+	}
+}
+
+func TestPluggedCertRotatorRootDistributionTimeout(t *testing.T) {
+	dir := t.TempDir()
+	oldBundle := genTestCABundle(t, "old")
+	newBundle := genTestCABundle(t, "new")
+	writeTestCABundle(t, dir, oldBundle)
+
+	caOpts, err := NewPluggedCertIstioCAOptions(filepath.Join(dir, CertChainID), filepath.Join(dir, caCertID),
+		filepath.Join(dir, caPrivateKeyID), filepath.Join(dir, RootCertID), time.Hour, 2*time.Hour, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caOpts.PluggedCertRotatorConfig = &PluggedCertRotatorConfig{
+		SigningCertFile:         filepath.Join(dir, caCertID),
+		SigningKeyFile:          filepath.Join(dir, caPrivateKeyID),
+		CertChainFile:           filepath.Join(dir, CertChainID),
+		RootCertFile:            filepath.Join(dir, RootCertID),
+		CheckInterval:           time.Minute,
+		RootDistributionTimeout: 10 * time.Minute,
+		RootsConfirmed: func(time.Time) ([]string, int) {
+			return []string{"a"}, 2
+		},
+	}
+	ca, err := NewIstioCA(caOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotator := ca.GetPluggedCertRotator()
+
+	now := time.Now()
+	writeTestCABundle(t, dir, newBundle)
+	rotator.check(now)
+	rotator.check(now.Add(time.Minute))
+	if s := rotator.Status(); s.Phase != RotationPhaseDistributingRoots || !reflect.DeepEqual(s.UnconfirmedProxies, []string{"a"}) {
+		t.Fatalf("expected to wait for proxy a, got %+v", s)
+	}
+
+	// Signing switches once the timeout expired, even though a proxy did not confirm.
+	rotator.check(now.Add(10 * time.Minute))
+	if s := rotator.Status(); s.Phase != RotationPhaseSigningWithNewCA {
+		t.Fatalf("expected to sign with the new CA after the timeout, got %+v", s)
+	}
+	if cert, _, _, _ := ca.GetCAKeyCertBundle().GetAllPem(); !bytes.Equal(cert, newBundle.cert) {
+		t.Fatalf("expected to sign with the new CA")
+	}
+}
+
+func TestPluggedCertRotatorKeyPolicy(t *testing.T) {
+	dir := t.TempDir()
+	writeTestCABundle(t, dir, genTestCABundle(t, "old"))
+
+	caOpts, err := NewPluggedCertIstioCAOptions(filepath.Join(dir, CertChainID), filepath.Join(dir, caCertID),
+		filepath.Join(dir, caPrivateKeyID), filepath.Join(dir, RootCertID), time.Hour, 2*time.Hour, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := util.ParseKeyPolicy("ECDSA-P256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	caOpts.PluggedCertRotatorConfig = &PluggedCertRotatorConfig{
+		SigningCertFile: filepath.Join(dir, caCertID),
+		SigningKeyFile:  filepath.Join(dir, caPrivateKeyID),
+		CertChainFile:   filepath.Join(dir, CertChainID),
+		RootCertFile:    filepath.Join(dir, RootCertID),
+		CheckInterval:   time.Minute,
+		KeyPolicy:       policy,
+	}
+	ca, err := NewIstioCA(caOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rotator := ca.GetPluggedCertRotator()
+
+	// The staged bundle has RSA keys, which the policy does not allow.
+	writeTestCABundle(t, dir, genTestCABundle(t, "new"))
+	rotator.check(time.Now())
+	if s := rotator.Status(); s.Phase != RotationPhaseIdle || s.LastError == "" {
+		t.Fatalf("expected the staged bundle to be rejected, got %+v", s)
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ra
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	acmeStatusValid   = "valid"
+	acmeStatusInvalid = "invalid"
+)
+
+// acmeDirectory lists the URLs of an ACME-style CA.
+type acmeDirectory struct {
+	NewOrder string `json:"newOrder"`
+	// RootCert is the URL of the PEM encoded root certificates. This is not part of the ACME directory.
+	RootCert string `json:"rootCert,omitempty"`
+}
+
+type acmeIdentifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type acmeOrderRequest struct {
+	Identifiers []acmeIdentifier `json:"identifiers"`
+	NotAfter    string           `json:"notAfter,omitempty"`
+}
+
+type acmeOrder struct {
+	Status      string `json:"status"`
+	Finalize    string `json:"finalize"`
+	Certificate string `json:"certificate,omitempty"`
+	Error       *struct {
+		Detail string `json:"detail"`
+	} `json:"error,omitempty"`
+}
+
+type acmeFinalizeRequest struct {
+	CSR string `json:"csr"`
+}
+
+// ACMESigner signs certificates with an external CA exposing an ACME-style flow: an order is created for the
+// identities, finalized with the CSR, and polled until the certificate can be downloaded. The account of istiod is
+// pre-authorized by the CA and authenticated with a bearer token, so there are no challenges to complete: as a
+// registration authority, istiod has already validated the identities of the CSR.
+type ACMESigner struct {
+	directoryURL string
+	tokenFile    string
+	client       *http.Client
+	// pollInterval is the interval between the polls of a pending order.
+	pollInterval time.Duration
+}
+
+// NewACMESigner returns a signer for the ACME-style flow of the CA with the given directory URL.
+func NewACMESigner(directoryURL, tokenFile string, client *http.Client) *ACMESigner {
+	if client == nil {
+		client = &http.Client{Timeout: externalCATimeout}
+	}
+	return &ACMESigner{
+		directoryURL: directoryURL,
+		tokenFile:    tokenFile,
+		client:       client,
+		pollInterval: 500 * time.Millisecond,
+	}
+}
+
+func (s *ACMESigner) do(method, url string, in interface{}, out interface{}) (*http.Response, error) {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := setToken(req, s.tokenFile, "Authorization", "Bearer "); err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("calling %s failed with status %d", url, resp.StatusCode)
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, fmt.Errorf("failed to decode the response of %s: %v", url, err)
+		}
+	}
+	return resp, nil
+}
+
+func (s *ACMESigner) directory() (*acmeDirectory, error) {
+	dir := &acmeDirectory{}
+	if _, err := s.do(http.MethodGet, s.directoryURL, nil, dir); err != nil {
+		return nil, err
+	}
+	return dir, nil
+}
+
+// Sign implements ExternalSigner.
+func (s *ACMESigner) Sign(csrPEM []byte, subjectIDs []string, lifetime time.Duration) ([]byte, error) {
+	block, _ := pem.Decode(csrPEM)
+	if block == nil {
+		return nil, fmt.Errorf("certificate signing request is not properly encoded")
+	}
+	dir, err := s.directory()
+	if err != nil {
+		return nil, err
+	}
+	orderRequest := acmeOrderRequest{NotAfter: time.Now().Add(lifetime).UTC().Format(time.RFC3339)}
+	for _, id := range subjectIDs {
+		orderRequest.Identifiers = append(orderRequest.Identifiers, acmeIdentifier{Type: "uri", Value: id})
+	}
+	order := &acmeOrder{}
+	resp, err := s.do(http.MethodPost, dir.NewOrder, orderRequest, order)
+	if err != nil {
+		return nil, err
+	}
+	orderURL := resp.Header.Get("Location")
+	if orderURL == "" {
+		return nil, fmt.Errorf("no location for the order created at %s", dir.NewOrder)
+	}
+	if _, err := s.do(http.MethodPost, order.Finalize, acmeFinalizeRequest{
+		CSR: base64.RawURLEncoding.EncodeToString(block.Bytes),
+	}, order); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(externalCATimeout)
+	for order.Status != acmeStatusValid {
+		if order.Status == acmeStatusInvalid {
+			if order.Error != nil {
+				return nil, fmt.Errorf("order %s is invalid: %s", orderURL, order.Error.Detail)
+			}
+			return nil, fmt.Errorf("order %s is invalid", orderURL)
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for order %s, last status %q", orderURL, order.Status)
+		}
+		time.Sleep(s.pollInterval)
+		if _, err := s.do(http.MethodGet, orderURL, nil, order); err != nil {
+			return nil, err
+		}
+	}
+	return fetchPEM(s.client, order.Certificate, s.tokenFile, "Authorization", "Bearer ")
+}
+
+// RootCerts implements ExternalSigner.
+func (s *ACMESigner) RootCerts() ([]byte, error) {
+	dir, err := s.directory()
+	if err != nil {
+		return nil, err
+	}
+	return fetchPEM(s.client, dir.RootCert, s.tokenFile, "Authorization", "Bearer ")
+}
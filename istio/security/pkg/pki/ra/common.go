@@ -50,6 +50,12 @@ type IstioRAOptions struct {
 	K8sClient certificatesv1beta1.CertificatesV1beta1Interface
 	// TrustDomain
 	TrustDomain string
+	// ExternalCASignURL : URL of the signing API of the external CA, or of the directory for the ACME-style flow
+	ExternalCASignURL string
+	// ExternalCARootURL : URL of the PEM encoded root certificates of the external CA, for the HTTP signing API
+	ExternalCARootURL string
+	// ExternalCATokenFile : File containing the token to authenticate to the external CA
+	ExternalCATokenFile string
 }
 
 const (
@@ -59,6 +65,12 @@ const (
 	// ExtCAGrpc : Integration with external CA using Istio CA gRPC API
 	ExtCAGrpc CaExternalType = "ISTIOD_RA_ISTIO_API"
 
+	// ExtCAHTTP : Integration with external CA using a Vault PKI like HTTP signing API
+	ExtCAHTTP CaExternalType = "ISTIOD_RA_HTTP_API"
+
+	// ExtCAACME : Integration with external CA using an ACME-style flow
+	ExtCAACME CaExternalType = "ISTIOD_RA_ACME_API"
+
 	// DefaultExtCACertDir : Location of external CA certificate
 	DefaultExtCACertDir string = "./etc/external-ca-cert"
 )
@@ -92,12 +104,25 @@ func ValidateCSR(csrPEM []byte, subjectIDs []string) bool {
 // NewIstioRA is a factory method that returns an RA that implements the RegistrationAuthority functionality.
 // the caOptions defines the external provider
 func NewIstioRA(opts *IstioRAOptions) (RegistrationAuthority, error) {
-	if opts.ExternalCAType == ExtCAK8s {
+	switch opts.ExternalCAType {
+	case ExtCAK8s:
 		istioRA, err := NewKubernetesRA(opts)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create an K8s CA: %v", err)
 		}
 		return istioRA, err
+	case ExtCAHTTP:
+		istioRA, err := NewExternalRA(opts, NewHTTPSigner(opts.ExternalCASignURL, opts.ExternalCARootURL, opts.ExternalCATokenFile, nil))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create an HTTP API CA: %v", err)
+		}
+		return istioRA, err
+	case ExtCAACME:
+		istioRA, err := NewExternalRA(opts, NewACMESigner(opts.ExternalCASignURL, opts.ExternalCATokenFile, nil))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create an ACME CA: %v", err)
+		}
+		return istioRA, err
 	}
 	return nil, fmt.Errorf("invalid CA Name %s", opts.ExternalCAType)
 }
@@ -0,0 +1,152 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ra
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	raerror "istio.io/istio/security/pkg/pki/error"
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// ExternalSigner is the backend of an ExternalRA, which signs certificates with an external CA.
+type ExternalSigner interface {
+	// Sign signs the PEM encoded CSR for the given identities and lifetime. It returns the PEM encoded certificate,
+	// optionally followed by the intermediate certificates of the CA.
+	Sign(csrPEM []byte, subjectIDs []string, lifetime time.Duration) ([]byte, error)
+	// RootCerts returns the PEM encoded root certificates of the CA.
+	RootCerts() ([]byte, error)
+}
+
+// ExternalRA integrates with an external CA through a pluggable ExternalSigner backend.
+type ExternalRA struct {
+	signer        ExternalSigner
+	keyCertBundle util.KeyCertBundle
+	raOpts        *IstioRAOptions
+}
+
+// NewExternalRA : Create a RA that interfaces with an external CA through the given signer. The root certificates
+// are read from the CaCertFile if it exists, or retrieved from the signer otherwise.
+func NewExternalRA(raOpts *IstioRAOptions, signer ExternalSigner) (*ExternalRA, error) {
+	rootCertBytes, err := ioutil.ReadFile(raOpts.CaCertFile)
+	if err != nil {
+		if rootCertBytes, err = signer.RootCerts(); err != nil {
+			return nil, raerror.NewError(raerror.CAInitFail, fmt.Errorf("failed to retrieve the root certificates of the external CA: %v", err))
+		}
+	}
+	if _, err := parseCertChain(rootCertBytes); err != nil {
+		return nil, raerror.NewError(raerror.CAInitFail, fmt.Errorf("invalid root certificates of the external CA: %v", err))
+	}
+	return &ExternalRA{
+		signer:        signer,
+		keyCertBundle: util.NewKeyCertBundleWithRootCert(rootCertBytes),
+		raOpts:        raOpts,
+	}, nil
+}
+
+// sign returns the PEM encoded certificate and the PEM encoded chain of intermediate certificates between the
+// certificate and the root.
+func (r *ExternalRA) sign(csrPEM []byte, subjectIDs []string, requestedLifetime time.Duration, forCA bool) ([]byte, []byte, error) {
+	lifetime, err := preSign(r.raOpts, csrPEM, subjectIDs, requestedLifetime, forCA)
+	if err != nil {
+		return nil, nil, err
+	}
+	signed, err := r.signer.Sign(csrPEM, subjectIDs, lifetime)
+	if err != nil {
+		return nil, nil, raerror.NewError(raerror.CertGenError, err)
+	}
+	certs, err := parseCertChain(signed)
+	if err != nil {
+		return nil, nil, raerror.NewError(raerror.CertGenError, fmt.Errorf("invalid certificate from the external CA: %v", err))
+	}
+	roots, _ := parseCertChain(r.keyCertBundle.GetRootCertPem())
+	var chain []byte
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		if isRoot(cert, roots) {
+			// the root is appended by SignWithCertChain
+			continue
+		}
+		intermediates.AddCert(cert)
+		chain = append(chain, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	if r.raOpts.VerifyAppendCA {
+		rootPool := x509.NewCertPool()
+		for _, root := range roots {
+			rootPool.AddCert(root)
+		}
+		if _, err := certs[0].Verify(x509.VerifyOptions{
+			Roots:         rootPool,
+			Intermediates: intermediates,
+			KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		}); err != nil {
+			return nil, nil, raerror.NewError(raerror.CertGenError, fmt.Errorf("failed to verify the certificate from the external CA: %v", err))
+		}
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certs[0].Raw}), chain, nil
+}
+
+// Sign takes a PEM-encoded CSR, subject IDs and lifetime, and returns a certificate signed by the external CA.
+func (r *ExternalRA) Sign(csrPEM []byte, subjectIDs []string, requestedLifetime time.Duration, forCA bool) ([]byte, error) {
+	cert, _, err := r.sign(csrPEM, subjectIDs, requestedLifetime, forCA)
+	return cert, err
+}
+
+// SignWithCertChain is similar to Sign but returns the leaf cert and the entire cert chain.
+func (r *ExternalRA) SignWithCertChain(csrPEM []byte, subjectIDs []string, ttl time.Duration, forCA bool) ([]byte, error) {
+	cert, chain, err := r.sign(csrPEM, subjectIDs, ttl, forCA)
+	if err != nil {
+		return nil, err
+	}
+	cert = append(cert, chain...)
+	if r.raOpts.VerifyAppendCA {
+		cert = append(cert, r.GetCAKeyCertBundle().GetRootCertPem()...)
+	}
+	return cert, nil
+}
+
+// GetCAKeyCertBundle returns the KeyCertBundle for the CA.
+func (r *ExternalRA) GetCAKeyCertBundle() util.KeyCertBundle {
+	return r.keyCertBundle
+}
+
+// parseCertChain parses all the certificates of a PEM encoded chain.
+func parseCertChain(chainPEM []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(chainPEM); block != nil; block, rest = pem.Decode(rest) {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM encoded certificate found")
+	}
+	return certs, nil
+}
+
+func isRoot(cert *x509.Certificate, roots []*x509.Certificate) bool {
+	for _, root := range roots {
+		if cert.Equal(root) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ra
+
+import (
+	"crypto/x509"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func externalRAOptions(caType CaExternalType) *IstioRAOptions {
+	return &IstioRAOptions{
+		ExternalCAType: caType,
+		DefaultCertTTL: 30 * time.Minute,
+		MaxCertTTL:     time.Hour,
+		CaCertFile:     "/does/not/exist",
+		VerifyAppendCA: true,
+	}
+}
+
+func TestExternalRA(t *testing.T) {
+	signer, err := newLocalSigner("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(signer.HTTPHandler())
+	defer httpServer.Close()
+	acmeServer := httptest.NewServer(signer.ACMEHandler())
+	defer acmeServer.Close()
+
+	httpOpts := externalRAOptions(ExtCAHTTP)
+	httpOpts.ExternalCASignURL = httpServer.URL + "/sign"
+	httpOpts.ExternalCARootURL = httpServer.URL + "/ca/pem"
+	httpOpts.ExternalCATokenFile = tokenFile
+	acmeOpts := externalRAOptions(ExtCAACME)
+	acmeOpts.ExternalCASignURL = acmeServer.URL + "/directory"
+	acmeOpts.ExternalCATokenFile = tokenFile
+
+	localRA, err := NewExternalRA(externalRAOptions(""), signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpRA, err := NewIstioRA(httpOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acmeRA, err := NewIstioRA(acmeOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	acmeRA.(*ExternalRA).signer.(*ACMESigner).pollInterval = time.Millisecond
+
+	for name, r := range map[string]RegistrationAuthority{"local": localRA, "http": httpRA, "acme": acmeRA} {
+		t.Run(name, func(t *testing.T) {
+			if got := string(r.GetCAKeyCertBundle().GetRootCertPem()); got != string(signer.rootPEM) {
+				t.Fatalf("expected the root of the external CA, got %v", got)
+			}
+			chain, err := r.SignWithCertChain(createFakeCsr(t), []string{testCsrHostName}, 0, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			certs, err := parseCertChain(chain)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// leaf, intermediate and root
+			if len(certs) != 3 {
+				t.Fatalf("expected a chain of 3 certificates, got %d", len(certs))
+			}
+			if certs[0].URIs[0].String() != testCsrHostName {
+				t.Fatalf("unexpected identity %v", certs[0].URIs)
+			}
+			if lifetime := certs[0].NotAfter.Sub(certs[0].NotBefore); lifetime < 29*time.Minute || lifetime > 31*time.Minute {
+				t.Fatalf("expected the default lifetime, got %v", lifetime)
+			}
+			roots := x509.NewCertPool()
+			roots.AddCert(certs[2])
+			intermediates := x509.NewCertPool()
+			intermediates.AddCert(certs[1])
+			if _, err := certs[0].Verify(x509.VerifyOptions{
+				Roots:         roots,
+				Intermediates: intermediates,
+				KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+			}); err != nil {
+				t.Fatalf("failed to verify the chain: %v", err)
+			}
+
+			leaf, err := r.Sign(createFakeCsr(t), []string{testCsrHostName}, time.Minute, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if certs, _ := parseCertChain(leaf); len(certs) != 1 {
+				t.Fatalf("expected only the leaf certificate, got %d", len(certs))
+			}
+
+			// pre-sign validation
+			if _, err := r.Sign(createFakeCsr(t), []string{"spiffe://cluster.local/ns/other/sa/other"}, 0, false); err == nil {
+				t.Fatalf("expected an error for mismatched identities")
+			}
+			if _, err := r.Sign(createFakeCsr(t), []string{testCsrHostName}, 2*time.Hour, false); err == nil {
+				t.Fatalf("expected an error for a TTL above the max")
+			}
+			if _, err := r.Sign(createFakeCsr(t), []string{testCsrHostName}, 0, true); err == nil {
+				t.Fatalf("expected an error for a CA certificate")
+			}
+		})
+	}
+}
+
+func TestExternalRAErrors(t *testing.T) {
+	signer, err := newLocalSigner("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(signer.HTTPHandler())
+	defer server.Close()
+
+	// without a token, the CA rejects the requests
+	opts := externalRAOptions(ExtCAHTTP)
+	opts.ExternalCASignURL = server.URL + "/sign"
+	opts.ExternalCARootURL = server.URL + "/ca/pem"
+	r, err := NewIstioRA(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Sign(createFakeCsr(t), []string{testCsrHostName}, 0, false); err == nil ||
+		!strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("expected a permission denied error, got %v", err)
+	}
+
+	// certificates not issued by the roots are rejected
+	other, err := newLocalSigner("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err = NewExternalRA(externalRAOptions(""), &mismatchedRootSigner{localSigner: other, root: signer.rootPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Sign(createFakeCsr(t), []string{testCsrHostName}, 0, false); err == nil ||
+		!strings.Contains(err.Error(), "failed to verify") {
+		t.Fatalf("expected a verification error, got %v", err)
+	}
+
+	// the roots are required
+	opts.ExternalCARootURL = ""
+	if _, err := NewIstioRA(opts); err == nil {
+		t.Fatalf("expected an error without root certificates")
+	}
+}
+
+type mismatchedRootSigner struct {
+	*localSigner
+	root []byte
+}
+
+func (s *mismatchedRootSigner) RootCerts() ([]byte, error) {
+	return s.root, nil
+}
@@ -0,0 +1,169 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ra
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// vaultTokenHeader carries the token of the HTTP signing API.
+	vaultTokenHeader = "X-Vault-Token"
+	// externalCATimeout bounds each call to an external CA.
+	externalCATimeout = 10 * time.Second
+)
+
+// httpSignRequest is the body of a request to the HTTP signing API.
+type httpSignRequest struct {
+	CSR     string `json:"csr"`
+	URISans string `json:"uri_sans,omitempty"`
+	TTL     string `json:"ttl,omitempty"`
+}
+
+// httpSignResponse is the body of a response of the HTTP signing API.
+type httpSignResponse struct {
+	Data struct {
+		Certificate string   `json:"certificate"`
+		IssuingCA   string   `json:"issuing_ca"`
+		CAChain     []string `json:"ca_chain"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// HTTPSigner signs certificates with an external CA exposing a Vault PKI like HTTP API. The CSR is posted as JSON
+// to the sign URL, and the certificate is returned in the data of the response along with the chain of the CA.
+type HTTPSigner struct {
+	signURL   string
+	rootURL   string
+	tokenFile string
+	client    *http.Client
+}
+
+// NewHTTPSigner returns a signer for the HTTP signing API. The root certificates are retrieved in PEM from the
+// root URL. If set, the token is read from the token file on each call, so that it can be rotated.
+func NewHTTPSigner(signURL, rootURL, tokenFile string, client *http.Client) *HTTPSigner {
+	if client == nil {
+		client = &http.Client{Timeout: externalCATimeout}
+	}
+	return &HTTPSigner{
+		signURL:   signURL,
+		rootURL:   rootURL,
+		tokenFile: tokenFile,
+		client:    client,
+	}
+}
+
+// Sign implements ExternalSigner.
+func (s *HTTPSigner) Sign(csrPEM []byte, subjectIDs []string, lifetime time.Duration) ([]byte, error) {
+	body, err := json.Marshal(httpSignRequest{
+		CSR:     string(csrPEM),
+		URISans: strings.Join(subjectIDs, ","),
+		TTL:     fmt.Sprintf("%ds", int64(lifetime.Seconds())),
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.signURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if err := setToken(req, s.tokenFile, vaultTokenHeader, ""); err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling %s failed: %v", s.signURL, err)
+	}
+	defer resp.Body.Close()
+	out := httpSignResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode the response of %s (status %d): %v", s.signURL, resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calling %s failed with status %d: %s", s.signURL, resp.StatusCode, strings.Join(out.Errors, "; "))
+	}
+	if out.Data.Certificate == "" {
+		return nil, fmt.Errorf("no certificate in the response of %s", s.signURL)
+	}
+	chain := []string{out.Data.Certificate}
+	if len(out.Data.CAChain) > 0 {
+		chain = append(chain, out.Data.CAChain...)
+	} else if out.Data.IssuingCA != "" {
+		chain = append(chain, out.Data.IssuingCA)
+	}
+	return joinPEM(chain), nil
+}
+
+// RootCerts implements ExternalSigner.
+func (s *HTTPSigner) RootCerts() ([]byte, error) {
+	return fetchPEM(s.client, s.rootURL, s.tokenFile, vaultTokenHeader, "")
+}
+
+// setToken sets the token read from the token file, if any, as value of the header.
+func setToken(req *http.Request, tokenFile, header, prefix string) error {
+	if tokenFile == "" {
+		return nil
+	}
+	token, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read the token of the external CA: %v", err)
+	}
+	req.Header.Set(header, prefix+strings.TrimSpace(string(token)))
+	return nil
+}
+
+// fetchPEM retrieves PEM encoded certificates from the URL.
+func fetchPEM(client *http.Client, url, tokenFile, header, prefix string) ([]byte, error) {
+	if url == "" {
+		return nil, fmt.Errorf("no URL to retrieve the certificates from")
+	}
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := setToken(req, tokenFile, header, prefix); err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("calling %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("calling %s failed with status %d: %s", url, resp.StatusCode, string(body))
+	}
+	return body, nil
+}
+
+// joinPEM concatenates PEM encoded certificates, making sure each ends with a new line.
+func joinPEM(certs []string) []byte {
+	var out []byte
+	for _, cert := range certs {
+		out = append(out, strings.TrimSpace(cert)...)
+		out = append(out, '\n')
+	}
+	return out
+}
@@ -0,0 +1,279 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ra
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"istio.io/istio/security/pkg/pki/util"
+)
+
+// localSigner is an in-memory stand-in for an external CA. It signs certificates with an intermediate
+// CA issued by a self-signed root, and can serve both the HTTP signing API and the ACME-style flow.
+type localSigner struct {
+	rootPEM         []byte
+	intermediatePEM []byte
+	intermediate    *x509.Certificate
+	intermediateKey crypto.PrivateKey
+	token           string
+	mu              sync.Mutex
+	orders          map[string]*localOrder
+	nextOrderID     int
+}
+
+type localOrder struct {
+	acmeOrder
+	identities []string
+	notAfter   time.Time
+	cert       []byte
+}
+
+// newLocalSigner returns a localSigner with a new root and intermediate CA. If set, the servers of the signer require
+// the token.
+func newLocalSigner(token string) (*localSigner, error) {
+	rootPEM, rootKeyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Org:          "Local stand-in CA",
+		TTL:          24 * time.Hour,
+		IsCA:         true,
+		IsSelfSigned: true,
+		ECSigAlg:     util.EcdsaSigAlg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	root, err := util.ParsePemEncodedCertificate(rootPEM)
+	if err != nil {
+		return nil, err
+	}
+	rootKey, err := util.ParsePemEncodedKey(rootKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	intermediatePEM, intermediateKeyPEM, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Org:        "Local stand-in intermediate CA",
+		TTL:        24 * time.Hour,
+		IsCA:       true,
+		SignerCert: root,
+		SignerPriv: rootKey,
+		ECSigAlg:   util.EcdsaSigAlg,
+	})
+	if err != nil {
+		return nil, err
+	}
+	intermediate, err := util.ParsePemEncodedCertificate(intermediatePEM)
+	if err != nil {
+		return nil, err
+	}
+	intermediateKey, err := util.ParsePemEncodedKey(intermediateKeyPEM)
+	if err != nil {
+		return nil, err
+	}
+	return &localSigner{
+		rootPEM:         rootPEM,
+		intermediatePEM: intermediatePEM,
+		intermediate:    intermediate,
+		intermediateKey: intermediateKey,
+		token:           token,
+		orders:          map[string]*localOrder{},
+	}, nil
+}
+
+// Sign implements ExternalSigner.
+func (s *localSigner) Sign(csrPEM []byte, subjectIDs []string, lifetime time.Duration) ([]byte, error) {
+	csr, err := util.ParsePemEncodedCSR(csrPEM)
+	if err != nil {
+		return nil, err
+	}
+	return s.signCSR(csr, subjectIDs, lifetime)
+}
+
+func (s *localSigner) signCSR(csr *x509.CertificateRequest, subjectIDs []string, lifetime time.Duration) ([]byte, error) {
+	der, err := util.GenCertFromCSR(csr, s.intermediate, csr.PublicKey, s.intermediateKey, subjectIDs, lifetime, false)
+	if err != nil {
+		return nil, err
+	}
+	return append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), s.intermediatePEM...), nil
+}
+
+// RootCerts implements ExternalSigner.
+func (s *localSigner) RootCerts() ([]byte, error) {
+	return s.rootPEM, nil
+}
+
+func (s *localSigner) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+	return r.Header.Get(vaultTokenHeader) == s.token || r.Header.Get("Authorization") == "Bearer "+s.token
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// HTTPHandler serves the HTTP signing API, with the sign endpoint at /sign and the root certificates at /ca/pem.
+func (s *localSigner) HTTPHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ca/pem", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(s.rootPEM)
+	})
+	mux.HandleFunc("/sign", func(w http.ResponseWriter, r *http.Request) {
+		resp := httpSignResponse{}
+		if !s.authorized(r) {
+			resp.Errors = []string{"permission denied"}
+			writeJSON(w, http.StatusForbidden, resp)
+			return
+		}
+		req := httpSignRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			resp.Errors = []string{err.Error()}
+			writeJSON(w, http.StatusBadRequest, resp)
+			return
+		}
+		ttl, err := time.ParseDuration(req.TTL)
+		if err != nil {
+			resp.Errors = []string{fmt.Sprintf("invalid ttl: %v", err)}
+			writeJSON(w, http.StatusBadRequest, resp)
+			return
+		}
+		var ids []string
+		if req.URISans != "" {
+			ids = strings.Split(req.URISans, ",")
+		}
+		chain, err := s.Sign([]byte(req.CSR), ids, ttl)
+		if err != nil {
+			resp.Errors = []string{err.Error()}
+			writeJSON(w, http.StatusBadRequest, resp)
+			return
+		}
+		certs := strings.SplitAfterN(string(chain), "-----END CERTIFICATE-----\n", 2)
+		resp.Data.Certificate = certs[0]
+		resp.Data.IssuingCA = string(s.intermediatePEM)
+		resp.Data.CAChain = []string{string(s.intermediatePEM)}
+		writeJSON(w, http.StatusOK, resp)
+	})
+	return mux
+}
+
+// ACMEHandler serves the ACME-style flow, with the directory at /directory. The orders are only valid once they have
+// been polled, so that clients exercise the polling.
+func (s *localSigner) ACMEHandler() http.Handler {
+	mux := http.NewServeMux()
+	base := func(r *http.Request) string {
+		return "http://" + r.Host
+	}
+	mux.HandleFunc("/directory", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, acmeDirectory{NewOrder: base(r) + "/new-order", RootCert: base(r) + "/root"})
+	})
+	mux.HandleFunc("/root", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(s.rootPEM)
+	})
+	mux.HandleFunc("/new-order", func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		req := acmeOrderRequest{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		notAfter, err := time.Parse(time.RFC3339, req.NotAfter)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.mu.Lock()
+		s.nextOrderID++
+		id := fmt.Sprint(s.nextOrderID)
+		order := &localOrder{notAfter: notAfter}
+		order.Status = "pending"
+		order.Finalize = base(r) + "/order/" + id + "/finalize"
+		for _, identifier := range req.Identifiers {
+			order.identities = append(order.identities, identifier.Value)
+		}
+		s.orders[id] = order
+		resp := order.acmeOrder
+		s.mu.Unlock()
+		w.Header().Set("Location", base(r)+"/order/"+id)
+		writeJSON(w, http.StatusCreated, resp)
+	})
+	mux.HandleFunc("/order/", func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/order/"), "/")
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		order, f := s.orders[parts[0]]
+		if !f {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		switch {
+		case len(parts) == 2 && parts[1] == "finalize":
+			req := acmeFinalizeRequest{}
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			der, err := base64.RawURLEncoding.DecodeString(req.CSR)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			csr, err := x509.ParseCertificateRequest(der)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if order.cert, err = s.signCSR(csr, order.identities, time.Until(order.notAfter)); err != nil {
+				order.Status = acmeStatusInvalid
+			} else {
+				order.Status = "processing"
+			}
+		case len(parts) == 2 && parts[1] == "cert":
+			if order.Status != acmeStatusValid {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/pem-certificate-chain")
+			_, _ = w.Write(order.cert)
+			return
+		case len(parts) == 1:
+			if order.Status == "processing" {
+				order.Status = acmeStatusValid
+				order.Certificate = base(r) + "/order/" + parts[0] + "/cert"
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, order.acmeOrder)
+	})
+	return mux
+}
@@ -43,10 +43,30 @@ import (
 type SupportedECSignatureAlgorithms string
 
 const (
-	// only ECDSA using P256 is currently supported
+	// only ECDSA is currently supported
 	EcdsaSigAlg SupportedECSignatureAlgorithms = "ECDSA"
 )
 
+// SupportedEllipticCurves are the curves of the EC private keys.
+type SupportedEllipticCurves string
+
+const (
+	P256Curve SupportedEllipticCurves = "P256"
+	P384Curve SupportedEllipticCurves = "P384"
+)
+
+// ellipticCurve returns the curve of the given name, P256 if empty.
+func ellipticCurve(c SupportedEllipticCurves) (elliptic.Curve, error) {
+	switch c {
+	case "", P256Curve:
+		return elliptic.P256(), nil
+	case P384Curve:
+		return elliptic.P384(), nil
+	default:
+		return nil, fmt.Errorf("unsupported elliptic curve %q", c)
+	}
+}
+
 // CertOptions contains options for generating a new certificate.
 type CertOptions struct {
 	// Comma-separated hostnames and IPs to generate a certificate for.
@@ -97,6 +117,9 @@ type CertOptions struct {
 	// when generating private keys. Currently only ECDSA is supported.
 	// If empty, RSA is used, otherwise ECC is used.
 	ECSigAlg SupportedECSignatureAlgorithms
+
+	// The curve of the EC private key. P256 is used if empty.
+	ECCCurve SupportedEllipticCurves
 }
 
 // GenCertKeyFromOptions generates a X.509 certificate and a private key with the given options.
@@ -111,7 +134,11 @@ func GenCertKeyFromOptions(options CertOptions) (pemCert []byte, pemKey []byte,
 
 		switch options.ECSigAlg {
 		case EcdsaSigAlg:
-			ecPriv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			curve, err := ellipticCurve(options.ECCCurve)
+			if err != nil {
+				return nil, nil, fmt.Errorf("cert generation fails at EC key generation (%v)", err)
+			}
+			ecPriv, err = ecdsa.GenerateKey(curve, rand.Reader)
 			if err != nil {
 				return nil, nil, fmt.Errorf("cert generation fails at EC key generation (%v)", err)
 			}
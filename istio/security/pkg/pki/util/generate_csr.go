@@ -21,7 +21,6 @@ package util
 import (
 	"crypto"
 	"crypto/ecdsa"
-	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
@@ -45,7 +44,11 @@ func GenCSR(options CertOptions) ([]byte, []byte, error) {
 	if options.ECSigAlg != "" {
 		switch options.ECSigAlg {
 		case EcdsaSigAlg:
-			priv, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			curve, err := ellipticCurve(options.ECCCurve)
+			if err != nil {
+				return nil, nil, fmt.Errorf("EC key generation failed (%v)", err)
+			}
+			priv, err = ecdsa.GenerateKey(curve, rand.Reader)
 			if err != nil {
 				return nil, nil, fmt.Errorf("EC key generation failed (%v)", err)
 			}
@@ -119,6 +119,11 @@ func NewKeyCertBundleWithRootCertFromFile(rootCertFile string) (*KeyCertBundleIm
 	if err != nil {
 		return nil, err
 	}
+	return NewKeyCertBundleWithRootCert(rootCertBytes), nil
+}
+
+// NewKeyCertBundleWithRootCert returns a new KeyCertBundle with the root cert without verification.
+func NewKeyCertBundleWithRootCert(rootCertBytes []byte) *KeyCertBundleImpl {
 	return &KeyCertBundleImpl{
 		certBytes:      []byte{},
 		cert:           nil,
@@ -126,7 +131,7 @@ func NewKeyCertBundleWithRootCertFromFile(rootCertFile string) (*KeyCertBundleIm
 		privKey:        nil,
 		certChainBytes: []byte{},
 		rootCertBytes:  rootCertBytes,
-	}, nil
+	}
 }
 
 // GetAllPem returns all key/cert PEMs in KeyCertBundle together. Getting all values together avoids inconsistency.
@@ -0,0 +1,184 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KeySpec is a key algorithm allowed by a KeyPolicy.
+type KeySpec struct {
+	// The EC signature algorithm of the key, empty for RSA keys.
+	ECSigAlg SupportedECSignatureAlgorithms
+	// The minimum size of RSA keys.
+	RSAKeySize int
+	// The curve of EC keys.
+	ECCCurve SupportedEllipticCurves
+}
+
+// String returns the spec in the format of ParseKeyPolicy, e.g. RSA-2048 or ECDSA-P256.
+func (k KeySpec) String() string {
+	if k.ECSigAlg == "" {
+		return "RSA-" + strconv.Itoa(k.RSAKeySize)
+	}
+	return string(k.ECSigAlg) + "-" + string(k.ECCCurve)
+}
+
+// allowsOptions returns true if the keys generated with the given options satisfy the spec.
+func (k KeySpec) allowsOptions(options CertOptions) bool {
+	if k.ECSigAlg == "" || options.ECSigAlg == "" {
+		return k.ECSigAlg == options.ECSigAlg && options.RSAKeySize >= k.RSAKeySize
+	}
+	curve := options.ECCCurve
+	if curve == "" {
+		curve = P256Curve
+	}
+	return k.ECSigAlg == options.ECSigAlg && k.ECCCurve == curve
+}
+
+// allowsKey returns true if the public key satisfies the spec.
+func (k KeySpec) allowsKey(pub crypto.PublicKey) bool {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return k.ECSigAlg == "" && key.N.BitLen() >= k.RSAKeySize
+	case *ecdsa.PublicKey:
+		curve, err := ellipticCurve(k.ECCCurve)
+		return k.ECSigAlg == EcdsaSigAlg && err == nil && key.Curve.Params().Name == curve.Params().Name
+	default:
+		return false
+	}
+}
+
+// KeyPolicy restricts the algorithms and sizes of certificate keys to a list of specs, in order of preference.
+// An empty policy allows any key.
+type KeyPolicy []KeySpec
+
+// ParseKeyPolicy parses a comma separated list of key specs, e.g. "ECDSA-P256,RSA-3072". RSA-<size> allows RSA
+// keys of at least the given size, ECDSA-P256 and ECDSA-P384 allow ECDSA keys on the given curve.
+func ParseKeyPolicy(s string) (KeyPolicy, error) {
+	var policy KeyPolicy
+	for _, spec := range strings.Split(s, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		parts := strings.SplitN(spec, "-", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid key spec %q, expected <algorithm>-<size or curve>", spec)
+		}
+		switch strings.ToUpper(parts[0]) {
+		case "RSA":
+			size, err := strconv.Atoi(parts[1])
+			if err != nil || size < minimumRsaKeySize {
+				return nil, fmt.Errorf("invalid key spec %q, the RSA key size must be at least %d", spec, minimumRsaKeySize)
+			}
+			policy = append(policy, KeySpec{RSAKeySize: size})
+		case string(EcdsaSigAlg):
+			curve := SupportedEllipticCurves(strings.ToUpper(parts[1]))
+			if _, err := ellipticCurve(curve); err != nil || curve == "" {
+				return nil, fmt.Errorf("invalid key spec %q, the curve must be %s or %s", spec, P256Curve, P384Curve)
+			}
+			policy = append(policy, KeySpec{ECSigAlg: EcdsaSigAlg, ECCCurve: curve})
+		default:
+			return nil, fmt.Errorf("invalid key spec %q, the algorithm must be RSA or %s", spec, EcdsaSigAlg)
+		}
+	}
+	return policy, nil
+}
+
+// String returns the policy in the format of ParseKeyPolicy.
+func (p KeyPolicy) String() string {
+	specs := make([]string, 0, len(p))
+	for _, k := range p {
+		specs = append(specs, k.String())
+	}
+	return strings.Join(specs, ",")
+}
+
+// CheckPublicKey returns an error if the public key is not allowed by the policy.
+func (p KeyPolicy) CheckPublicKey(pub crypto.PublicKey) error {
+	if len(p) == 0 {
+		return nil
+	}
+	for _, k := range p {
+		if k.allowsKey(pub) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s key is not allowed by the key policy %q", describeKey(pub), p.String())
+}
+
+// CheckCertificate returns an error if the public key of the certificate is not allowed by the policy.
+func (p KeyPolicy) CheckCertificate(cert *x509.Certificate) error {
+	if err := p.CheckPublicKey(cert.PublicKey); err != nil {
+		return fmt.Errorf("certificate %q: %v", cert.Subject.String(), err)
+	}
+	return nil
+}
+
+// CheckPemCertificates returns an error if the public key of any of the PEM encoded certificates, e.g. a CA
+// certificate chain, is not allowed by the policy.
+func (p KeyPolicy) CheckPemCertificates(certsPEM []byte) error {
+	if len(p) == 0 {
+		return nil
+	}
+	for block, rest := pem.Decode(certsPEM); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return fmt.Errorf("failed to parse certificate: %v", err)
+		}
+		if err := p.CheckCertificate(cert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply returns the given options if the keys they generate are allowed by the policy, otherwise the options
+// updated to generate keys of the preferred spec.
+func (p KeyPolicy) Apply(options CertOptions) CertOptions {
+	if len(p) == 0 {
+		return options
+	}
+	for _, k := range p {
+		if k.allowsOptions(options) {
+			return options
+		}
+	}
+	options.ECSigAlg, options.RSAKeySize, options.ECCCurve = p[0].ECSigAlg, p[0].RSAKeySize, p[0].ECCCurve
+	return options
+}
+
+// describeKey returns the algorithm and size of a public key, for error messages.
+func describeKey(pub crypto.PublicKey) string {
+	switch key := pub.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA-%d", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return fmt.Sprintf("%s-%s", EcdsaSigAlg, strings.ReplaceAll(key.Curve.Params().Name, "-", ""))
+	default:
+		return fmt.Sprintf("%T", pub)
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseKeyPolicy(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    KeyPolicy
+		wantErr bool
+	}{
+		{in: "", want: nil},
+		{
+			in: "ECDSA-P256, rsa-3072",
+			want: KeyPolicy{
+				{ECSigAlg: EcdsaSigAlg, ECCCurve: P256Curve},
+				{RSAKeySize: 3072},
+			},
+		},
+		{in: "ECDSA-P384", want: KeyPolicy{{ECSigAlg: EcdsaSigAlg, ECCCurve: P384Curve}}},
+		{in: "RSA-1024", wantErr: true},
+		{in: "RSA", wantErr: true},
+		{in: "ECDSA-P521", wantErr: true},
+		{in: "ED25519-256", wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseKeyPolicy(tc.in)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %v, got %v", tc.want, got)
+			}
+			if err == nil {
+				if again, _ := ParseKeyPolicy(got.String()); !reflect.DeepEqual(again, got) {
+					t.Fatalf("%q does not round trip: %v", got.String(), again)
+				}
+			}
+		})
+	}
+}
+
+func TestKeyPolicyCheckPublicKey(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		policy  string
+		key     interface{}
+		allowed bool
+	}{
+		{policy: "", key: &rsaKey.PublicKey, allowed: true},
+		{policy: "RSA-2048", key: &rsaKey.PublicKey, allowed: true},
+		{policy: "RSA-3072", key: &rsaKey.PublicKey, allowed: false},
+		{policy: "ECDSA-P256", key: &rsaKey.PublicKey, allowed: false},
+		{policy: "ECDSA-P256", key: &p256Key.PublicKey, allowed: true},
+		{policy: "ECDSA-P256", key: &p384Key.PublicKey, allowed: false},
+		{policy: "ECDSA-P256,ECDSA-P384", key: &p384Key.PublicKey, allowed: true},
+		{policy: "RSA-2048", key: &p256Key.PublicKey, allowed: false},
+	}
+	for _, tc := range cases {
+		policy, err := ParseKeyPolicy(tc.policy)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := policy.CheckPublicKey(tc.key); (err == nil) != tc.allowed {
+			t.Errorf("policy %q, key %s: expected allowed %v, got %v", tc.policy, describeKey(tc.key), tc.allowed, err)
+		}
+	}
+}
+
+func TestKeyPolicyApply(t *testing.T) {
+	rsa2048 := CertOptions{Host: "spiffe://cluster.local/ns/default/sa/default", RSAKeySize: 2048}
+	p256 := CertOptions{Host: "spiffe://cluster.local/ns/default/sa/default", ECSigAlg: EcdsaSigAlg}
+	cases := []struct {
+		name    string
+		policy  string
+		options CertOptions
+		want    CertOptions
+	}{
+		{name: "no policy", policy: "", options: rsa2048, want: rsa2048},
+		{name: "allowed", policy: "ECDSA-P256,RSA-2048", options: rsa2048, want: rsa2048},
+		{name: "default curve allowed", policy: "ECDSA-P256", options: p256, want: p256},
+		{
+			name:    "rsa to preferred ecdsa",
+			policy:  "ECDSA-P384,ECDSA-P256",
+			options: rsa2048,
+			want: CertOptions{
+				Host:     "spiffe://cluster.local/ns/default/sa/default",
+				ECSigAlg: EcdsaSigAlg,
+				ECCCurve: P384Curve,
+			},
+		},
+		{
+			name:    "larger rsa key",
+			policy:  "RSA-4096",
+			options: p256,
+			want:    CertOptions{Host: "spiffe://cluster.local/ns/default/sa/default", RSAKeySize: 4096},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := ParseKeyPolicy(tc.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := policy.Apply(tc.options); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("expected %+v, got %+v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestKeyPolicyCheckPemCertificates(t *testing.T) {
+	rsaCert, _, err := GenCertKeyFromOptions(CertOptions{
+		Host: "rsa-ca", IsCA: true, IsSelfSigned: true, TTL: time.Hour, RSAKeySize: 2048,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecCert, _, err := GenCertKeyFromOptions(CertOptions{
+		Host: "ec-ca", IsCA: true, IsSelfSigned: true, TTL: time.Hour, ECSigAlg: EcdsaSigAlg, ECCCurve: P384Curve,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := ParseKeyPolicy("ECDSA-P384")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.CheckPemCertificates(ecCert); err != nil {
+		t.Errorf("expected the ECDSA certificate to be allowed: %v", err)
+	}
+	if err := policy.CheckPemCertificates(append(ecCert, rsaCert...)); err == nil {
+		t.Errorf("expected the chain with an RSA certificate to be rejected")
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ca
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/security/pkg/pki/util"
+	"istio.io/pkg/log"
+)
+
+// serverCaAuditLog records every certificate signing request handled by the CA server. It is a separate scope
+// so that the audit trail can be routed or retained independently of the debug logs.
+var serverCaAuditLog = log.RegisterScope("caaudit", "Citadel server certificate issuance audit log", 0)
+
+// Outcomes of a certificate signing request, as recorded in the audit log.
+const (
+	OutcomeIssued          = "issued"
+	OutcomeUnauthenticated = "unauthenticated"
+	OutcomeDenied          = "denied"
+	OutcomeFailed          = "failed"
+	OutcomeRateLimited     = "rate_limited"
+)
+
+// IssuancePolicy decides whether certificates can be issued to the authenticated identities, and for how long.
+type IssuancePolicy interface {
+	// Evaluate returns the TTL of the certificate to issue to the identities, clamping the requested TTL if needed,
+	// or an error if the issuance is denied.
+	Evaluate(identities []string, requestedTTL time.Duration) (time.Duration, error)
+}
+
+// AuditRecord is the audit trail of a certificate signing request.
+type AuditRecord struct {
+	// Caller is the address of the caller.
+	Caller string
+	// AuthSource is how the caller was authenticated.
+	AuthSource string
+	// Identities are the authenticated identities of the caller, which are the identities of the certificate.
+	Identities []string
+	// SANs are the SANs requested in the CSR.
+	SANs []string
+	// RequestedTTL is the TTL requested by the caller.
+	RequestedTTL time.Duration
+	// TTL is the TTL of the certificate, after the policy is applied.
+	TTL time.Duration
+	// SerialNumber is the hex encoded serial number of the issued certificate.
+	SerialNumber string
+	// Outcome is one of issued, unauthenticated, denied, rate_limited or failed.
+	Outcome string
+	// Reason explains why the certificate was not issued.
+	Reason string
+}
+
+// newAuditRecord returns the audit record of a request, before it is authenticated.
+func newAuditRecord(ctx context.Context, csrPEM string, requestedTTL time.Duration) *AuditRecord {
+	r := &AuditRecord{
+		Caller:       getConnectionAddress(ctx),
+		RequestedTTL: requestedTTL,
+	}
+	if csr, err := util.ParsePemEncodedCSR([]byte(csrPEM)); err == nil {
+		if ids, err := util.ExtractIDs(csr.Extensions); err == nil {
+			r.SANs = ids
+		}
+	}
+	return r
+}
+
+func (r *AuditRecord) setCaller(caller *security.Caller) {
+	r.Identities = caller.Identities
+	switch caller.AuthSource {
+	case security.AuthSourceClientCertificate:
+		r.AuthSource = "client-certificate"
+	case security.AuthSourceIDToken:
+		r.AuthSource = "id-token"
+	default:
+		r.AuthSource = fmt.Sprint(caller.AuthSource)
+	}
+}
+
+// setCertificate records the serial number of the issued certificate.
+func (r *AuditRecord) setCertificate(certPEM []byte) {
+	if cert, err := util.ParsePemEncodedCertificate(certPEM); err == nil {
+		r.SerialNumber = cert.SerialNumber.Text(16)
+	}
+}
+
+// log writes the record to the audit log.
+func (r *AuditRecord) log() {
+	scope := serverCaAuditLog.WithLabels(
+		"caller", r.Caller,
+		"authSource", r.AuthSource,
+		"identities", strings.Join(r.Identities, ","),
+		"sans", strings.Join(r.SANs, ","),
+		"requestedTTL", r.RequestedTTL.String(),
+		"ttl", r.TTL.String(),
+		"serial", r.SerialNumber,
+		"outcome", r.Outcome,
+	)
+	if r.Reason != "" {
+		scope = scope.WithLabels("reason", r.Reason)
+	}
+	scope.Info("certificate signing request")
+}
@@ -51,6 +51,16 @@ var (
 		monitoring.WithLabels(errorTag),
 	)
 
+	policyDeniedCounts = monitoring.NewSum(
+		"citadel_server_csr_policy_denied_count",
+		"The number of CSRs denied by the certificate issuance policy.",
+	)
+
+	keyPolicyDeniedCounts = monitoring.NewSum(
+		"citadel_server_csr_key_policy_denied_count",
+		"The number of CSRs rejected because their key is not allowed by the workload key policy.",
+	)
+
 	successCounts = monitoring.NewSum(
 		"citadel_server_success_cert_issuance_count",
 		"The number of certificates issuances that have succeeded.",
@@ -75,6 +85,8 @@ func init() {
 		csrParsingErrorCounts,
 		idExtractionErrorCounts,
 		certSignErrorCounts,
+		policyDeniedCounts,
+		keyPolicyDeniedCounts,
 		successCounts,
 		rootCertExpiryTimestamp,
 		certChainExpiryTimestamp,
@@ -88,6 +100,8 @@ type monitoringMetrics struct {
 	Success           monitoring.Metric
 	CSRError          monitoring.Metric
 	IDExtractionError monitoring.Metric
+	PolicyDenied      monitoring.Metric
+	KeyPolicyDenied   monitoring.Metric
 	certSignErrors    monitoring.Metric
 }
 
@@ -99,6 +113,8 @@ func newMonitoringMetrics() monitoringMetrics {
 		Success:           successCounts,
 		CSRError:          csrParsingErrorCounts,
 		IDExtractionError: idExtractionErrorCounts,
+		PolicyDenied:      policyDeniedCounts,
+		KeyPolicyDenied:   keyPolicyDeniedCounts,
 		certSignErrors:    certSignErrorCounts,
 	}
 }
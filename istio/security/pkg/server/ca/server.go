@@ -21,10 +21,12 @@ import (
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 
 	pb "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/ratelimit"
 	"istio.io/istio/pkg/security"
 	caerror "istio.io/istio/security/pkg/pki/error"
 	"istio.io/istio/security/pkg/pki/util"
@@ -51,6 +53,15 @@ type Server struct {
 	Authenticators []security.Authenticator
 	ca             CertificateAuthority
 	serverCertTTL  time.Duration
+	// IssuancePolicy, if set, can deny or clamp the TTL of the certificates.
+	IssuancePolicy IssuancePolicy
+	// RateLimiter, if set, limits the CSRs of each caller.
+	RateLimiter *ratelimit.Limiter
+	// KeyPolicy, if set, restricts the keys of the CSRs. It is sent to the clients in the
+	// WorkloadKeyPolicyHeader response header, so they can generate compliant keys.
+	KeyPolicy util.KeyPolicy
+	// auditHandler replaces the audit log, for tests.
+	auditHandler func(*AuditRecord)
 }
 
 func getConnectionAddress(ctx context.Context) string {
@@ -68,25 +79,58 @@ func getConnectionAddress(ctx context.Context) string {
 // the subject public key is the public key in the CSR.
 // the validity duration is the ValidityDuration in request, or default value if the given duration is invalid.
 // it is signed by the CA signing key.
+// The issuance policy, if any, may deny the request or clamp the validity duration, the rate limiter, if any,
+// may reject the request, the key policy, if any, may reject the key of the CSR, and each request is recorded
+// in the audit log.
 func (s *Server) CreateCertificate(ctx context.Context, request *pb.IstioCertificateRequest) (
 	*pb.IstioCertificateResponse, error) {
 	s.monitoring.CSR.Increment()
+	requestedTTL := time.Duration(request.ValidityDuration) * time.Second
+	audit := newAuditRecord(ctx, request.Csr, requestedTTL)
+	defer s.audit(audit)
 	caller := Authenticate(ctx, s.Authenticators)
 	if caller == nil {
 		s.monitoring.AuthnError.Increment()
+		audit.Outcome = OutcomeUnauthenticated
 		return nil, status.Error(codes.Unauthenticated, "request authenticate failure")
 	}
+	audit.setCaller(caller)
 
-	// TODO: Call authorizer.
+	if err := s.RateLimiter.Check(caller.Identities, getConnectionAddress(ctx)); err != nil {
+		audit.Outcome, audit.Reason = OutcomeRateLimited, err.Error()
+		return nil, err
+	}
+
+	if err := s.checkKeyPolicy(ctx, request.Csr); err != nil {
+		s.monitoring.KeyPolicyDenied.Increment()
+		audit.Outcome, audit.Reason = OutcomeDenied, err.Error()
+		serverCaLog.Warnf("CSR of %v denied by the workload key policy: %v", caller.Identities, err)
+		return nil, status.Errorf(codes.InvalidArgument, "CSR denied by the workload key policy: %v", err)
+	}
+
+	ttl := requestedTTL
+	if s.IssuancePolicy != nil {
+		var err error
+		if ttl, err = s.IssuancePolicy.Evaluate(caller.Identities, requestedTTL); err != nil {
+			s.monitoring.PolicyDenied.Increment()
+			audit.Outcome, audit.Reason = OutcomeDenied, err.Error()
+			serverCaLog.Warnf("CSR of %v denied by the issuance policy: %v", caller.Identities, err)
+			return nil, status.Errorf(codes.PermissionDenied, "CSR denied by the issuance policy: %v", err)
+		}
+	}
+	audit.TTL = ttl
 
 	_, _, certChainBytes, rootCertBytes := s.ca.GetCAKeyCertBundle().GetAll()
 	cert, signErr := s.ca.Sign(
-		[]byte(request.Csr), caller.Identities, time.Duration(request.ValidityDuration)*time.Second, false)
+		[]byte(request.Csr), caller.Identities, ttl, false)
 	if signErr != nil {
 		serverCaLog.Errorf("CSR signing error (%v)", signErr.Error())
+		audit.Outcome, audit.Reason = OutcomeFailed, signErr.Error()
 		s.monitoring.GetCertSignError(signErr.(*caerror.Error).ErrorType()).Increment()
 		return nil, status.Errorf(signErr.(*caerror.Error).HTTPErrorCode(), "CSR signing error (%v)", signErr.(*caerror.Error))
 	}
+	audit.Outcome = OutcomeIssued
+	audit.setCertificate(cert)
 	respCertChain := []string{string(cert)}
 	if len(certChainBytes) != 0 {
 		respCertChain = append(respCertChain, string(certChainBytes))
@@ -100,6 +144,30 @@ func (s *Server) CreateCertificate(ctx context.Context, request *pb.IstioCertifi
 	return response, nil
 }
 
+// checkKeyPolicy sends the workload key policy to the client and checks the key of the CSR against it.
+func (s *Server) checkKeyPolicy(ctx context.Context, csrPEM string) error {
+	if len(s.KeyPolicy) == 0 {
+		return nil
+	}
+	// Setting the header fails outside of a gRPC call, which only happens in tests.
+	_ = grpc.SetHeader(ctx, metadata.Pairs(security.WorkloadKeyPolicyHeader, s.KeyPolicy.String()))
+	csr, err := util.ParsePemEncodedCSR([]byte(csrPEM))
+	if err != nil {
+		// Malformed CSRs are reported by the CA.
+		return nil
+	}
+	return s.KeyPolicy.CheckPublicKey(csr.PublicKey)
+}
+
+// audit records the outcome of a certificate signing request.
+func (s *Server) audit(r *AuditRecord) {
+	if s.auditHandler != nil {
+		s.auditHandler(r)
+		return
+	}
+	r.log()
+}
+
 func recordCertsExpiry(keyCertBundle util.KeyCertBundle) {
 	rootCertExpiry, err := keyCertBundle.ExtractRootCertExpiryTimestamp()
 	if err != nil {
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net"
 	"testing"
+	"time"
 
 	"golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
@@ -29,6 +30,7 @@ import (
 	"google.golang.org/grpc/status"
 
 	pb "istio.io/api/security/v1alpha1"
+	"istio.io/istio/pkg/ratelimit"
 	"istio.io/istio/pkg/security"
 	mockca "istio.io/istio/security/pkg/pki/ca/mock"
 	caerror "istio.io/istio/security/pkg/pki/error"
@@ -264,3 +266,212 @@ func TestCreateCertificate(t *testing.T) {
 		}
 	}
 }
+
+type fakeIssuancePolicy struct {
+	maxTTL time.Duration
+	denied string
+}
+
+func (p *fakeIssuancePolicy) Evaluate(identities []string, requestedTTL time.Duration) (time.Duration, error) {
+	for _, id := range identities {
+		if id == p.denied {
+			return 0, fmt.Errorf("identity %s is denied", id)
+		}
+	}
+	if p.maxTTL > 0 && requestedTTL > p.maxTTL {
+		return p.maxTTL, nil
+	}
+	return requestedTTL, nil
+}
+
+func TestCreateCertificateIssuancePolicyAndAudit(t *testing.T) {
+	allowed := "spiffe://cluster.local/ns/default/sa/allowed"
+	denied := "spiffe://cluster.local/ns/default/sa/denied"
+	cert, _, err := util.GenCertKeyFromOptions(util.CertOptions{
+		Host:         allowed,
+		TTL:          time.Hour,
+		IsSelfSigned: true,
+		ECSigAlg:     util.EcdsaSigAlg,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := util.ParsePemEncodedCertificate(cert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name        string
+		identities  []string
+		authnErr    string
+		signErr     *caerror.Error
+		ttl         time.Duration
+		code        codes.Code
+		expectedTTL time.Duration
+		outcome     string
+		serial      string
+	}{
+		{
+			name:        "issued",
+			identities:  []string{allowed},
+			ttl:         30 * time.Minute,
+			code:        codes.OK,
+			expectedTTL: 30 * time.Minute,
+			outcome:     OutcomeIssued,
+			serial:      parsed.SerialNumber.Text(16),
+		},
+		{
+			name:        "TTL clamped",
+			identities:  []string{allowed},
+			ttl:         3 * time.Hour,
+			code:        codes.OK,
+			expectedTTL: time.Hour,
+			outcome:     OutcomeIssued,
+			serial:      parsed.SerialNumber.Text(16),
+		},
+		{
+			name:       "denied",
+			identities: []string{denied},
+			ttl:        time.Hour,
+			code:       codes.PermissionDenied,
+			outcome:    OutcomeDenied,
+		},
+		{
+			name:     "unauthenticated",
+			authnErr: "not authorized",
+			code:     codes.Unauthenticated,
+			outcome:  OutcomeUnauthenticated,
+		},
+		{
+			name:        "failed",
+			identities:  []string{allowed},
+			signErr:     caerror.NewError(caerror.CertGenError, fmt.Errorf("cannot sign")),
+			ttl:         time.Hour,
+			code:        codes.Internal,
+			expectedTTL: time.Hour,
+			outcome:     OutcomeFailed,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeCA := &mockca.FakeCA{SignedCert: cert, SignErr: tc.signErr}
+			var records []*AuditRecord
+			server := &Server{
+				ca: fakeCA,
+				Authenticators: []security.Authenticator{&mockAuthenticator{
+					authSource: security.AuthSourceIDToken,
+					identities: tc.identities,
+					errMsg:     tc.authnErr,
+				}},
+				IssuancePolicy: &fakeIssuancePolicy{maxTTL: time.Hour, denied: denied},
+				monitoring:     newMonitoringMetrics(),
+				auditHandler: func(r *AuditRecord) {
+					records = append(records, r)
+				},
+			}
+			_, err := server.CreateCertificate(context.Background(), &pb.IstioCertificateRequest{
+				Csr:              "dumb CSR",
+				ValidityDuration: int64(tc.ttl.Seconds()),
+			})
+			if code := status.Code(err); code != tc.code {
+				t.Fatalf("expected code %v, got %v: %v", tc.code, code, err)
+			}
+			if tc.code != codes.PermissionDenied && tc.code != codes.Unauthenticated && fakeCA.ReceivedLifetime != tc.expectedTTL {
+				t.Errorf("expected the CA to sign for %v, got %v", tc.expectedTTL, fakeCA.ReceivedLifetime)
+			}
+			if len(records) != 1 {
+				t.Fatalf("expected 1 audit record, got %d", len(records))
+			}
+			r := records[0]
+			if r.Outcome != tc.outcome {
+				t.Errorf("expected outcome %q, got %q", tc.outcome, r.Outcome)
+			}
+			if r.SerialNumber != tc.serial {
+				t.Errorf("expected serial %q, got %q", tc.serial, r.SerialNumber)
+			}
+			if r.RequestedTTL != tc.ttl {
+				t.Errorf("expected requested TTL %v, got %v", tc.ttl, r.RequestedTTL)
+			}
+			if tc.authnErr == "" && (r.AuthSource != "id-token" || len(r.Identities) != 1 || r.Identities[0] != tc.identities[0]) {
+				t.Errorf("unexpected caller in the audit record: %+v", r)
+			}
+			if tc.outcome != OutcomeIssued && tc.outcome != OutcomeUnauthenticated && r.Reason == "" {
+				t.Errorf("expected a reason for outcome %q", tc.outcome)
+			}
+		})
+	}
+}
+
+func TestCreateCertificateRateLimit(t *testing.T) {
+	var records []*AuditRecord
+	server := &Server{
+		ca: &mockca.FakeCA{SignedCert: []byte("cert")},
+		Authenticators: []security.Authenticator{&mockAuthenticator{
+			identities: []string{"spiffe://cluster.local/ns/default/sa/restarting"},
+		}},
+		RateLimiter: ratelimit.New("ca", ratelimit.Config{IdentityQPS: 0.001, Burst: 1}),
+		monitoring:  newMonitoringMetrics(),
+		auditHandler: func(r *AuditRecord) {
+			records = append(records, r)
+		},
+	}
+	for i, code := range []codes.Code{codes.OK, codes.ResourceExhausted} {
+		_, err := server.CreateCertificate(context.Background(), &pb.IstioCertificateRequest{Csr: "dumb CSR"})
+		if status.Code(err) != code {
+			t.Fatalf("request %d: expected code %v, got %v", i, code, err)
+		}
+	}
+	if len(records) != 2 || records[1].Outcome != OutcomeRateLimited || records[1].Reason == "" {
+		t.Errorf("expected the rate limited CSR to be audited, got %+v", records)
+	}
+}
+
+func TestCreateCertificateKeyPolicy(t *testing.T) {
+	policy, err := util.ParseKeyPolicy("ECDSA-P256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		name    string
+		options util.CertOptions
+		code    codes.Code
+	}{
+		{
+			name:    "allowed",
+			options: util.CertOptions{Host: "spiffe://cluster.local/ns/default/sa/default", ECSigAlg: util.EcdsaSigAlg},
+			code:    codes.OK,
+		},
+		{
+			name:    "denied",
+			options: util.CertOptions{Host: "spiffe://cluster.local/ns/default/sa/default", RSAKeySize: 2048},
+			code:    codes.InvalidArgument,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			csr, _, err := util.GenCSR(tc.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var records []*AuditRecord
+			server := &Server{
+				ca: &mockca.FakeCA{SignedCert: []byte("cert")},
+				Authenticators: []security.Authenticator{&mockAuthenticator{
+					identities: []string{"spiffe://cluster.local/ns/default/sa/default"},
+				}},
+				KeyPolicy:  policy,
+				monitoring: newMonitoringMetrics(),
+				auditHandler: func(r *AuditRecord) {
+					records = append(records, r)
+				},
+			}
+			_, err = server.CreateCertificate(context.Background(), &pb.IstioCertificateRequest{Csr: string(csr)})
+			if status.Code(err) != tc.code {
+				t.Fatalf("expected code %v, got %v", tc.code, err)
+			}
+			if tc.code != codes.OK && (len(records) != 1 || records[0].Outcome != OutcomeDenied) {
+				t.Errorf("expected the rejected CSR to be audited as denied, got %+v", records)
+			}
+		})
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oauth implements a token exchange plugin for any OAuth 2.0 Security Token Service, following
+// https://tools.ietf.org/html/rfc8693.
+package oauth
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/security/pkg/stsservice"
+	"istio.io/pkg/log"
+)
+
+const (
+	httpTimeOutInSec = 5
+	maxRequestRetry  = 5
+	// TokenExchangeGrantType is the grant type of a token exchange request.
+	TokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+	// JWTTokenType is the default subject token type.
+	JWTTokenType = "urn:ietf:params:oauth:token-type:jwt"
+	// AccessTokenType is the default requested token type.
+	AccessTokenType = "urn:ietf:params:oauth:token-type:access_token"
+
+	// ClientAuthNone sends no client credentials.
+	ClientAuthNone = "none"
+	// ClientAuthBasic sends the client credentials in the HTTP Basic authorization header.
+	ClientAuthBasic = "client_secret_basic"
+	// ClientAuthPost sends the client credentials in the request body.
+	ClientAuthPost = "client_secret_post"
+)
+
+var (
+	pluginLog = log.RegisterScope("oauthtoken", "OAuth 2.0 token exchange plugin debugging", 0)
+	// default grace period of an exchanged token. If the remaining life time of a cached token is within this period,
+	// or within half of its lifetime, a new token is exchanged.
+	defaultGracePeriod = 5 * time.Minute
+)
+
+// Config configures the token exchange with the Security Token Service.
+type Config struct {
+	// Endpoint is the token endpoint of the Security Token Service.
+	Endpoint string
+	// Audience is the audience of the requested token, if the STS request does not specify one.
+	Audience string
+	// Scope is the scope of the requested token, if the STS request does not specify one.
+	Scope string
+	// SubjectTokenType is the type of the workload token sent to the Security Token Service.
+	// Defaults to JWTTokenType.
+	SubjectTokenType string
+	// RequestedTokenType is the type of the requested token, if the STS request does not specify one.
+	// Defaults to AccessTokenType.
+	RequestedTokenType string
+	// ClientAuth is how the client authenticates to the Security Token Service: ClientAuthNone,
+	// ClientAuthBasic or ClientAuthPost. Defaults to ClientAuthBasic if ClientID is set, and ClientAuthNone otherwise.
+	ClientAuth string
+	ClientID   string
+	// ClientSecretFile is the file holding the client secret. It is read for every request, so it can be rotated.
+	ClientSecretFile string
+	// CACertFile is the file of the CA certificates verifying the Security Token Service.
+	// If empty, the system roots are used.
+	CACertFile string
+}
+
+// Plugin supports token exchange with an OAuth 2.0 Security Token Service.
+type Plugin struct {
+	config     Config
+	httpClient *http.Client
+	// tokens is the cache for exchanged tokens.
+	// map key is derived from the request parameters, map value is cachedToken.
+	tokens sync.Map
+}
+
+type cachedToken struct {
+	info            stsservice.TokenInfo
+	issuedTokenType string
+	tokenType       string
+	scope           string
+}
+
+// CreateTokenManagerPlugin creates a plugin that exchanges tokens with an OAuth 2.0 Security Token Service.
+func CreateTokenManagerPlugin(config Config) (*Plugin, error) {
+	if config.Endpoint == "" {
+		return nil, errors.New("token exchange endpoint is not set")
+	}
+	if config.SubjectTokenType == "" {
+		config.SubjectTokenType = JWTTokenType
+	}
+	if config.RequestedTokenType == "" {
+		config.RequestedTokenType = AccessTokenType
+	}
+	if config.ClientAuth == "" {
+		config.ClientAuth = ClientAuthNone
+		if config.ClientID != "" {
+			config.ClientAuth = ClientAuthBasic
+		}
+	}
+	switch config.ClientAuth {
+	case ClientAuthNone, ClientAuthBasic, ClientAuthPost:
+	default:
+		return nil, fmt.Errorf("unsupported client authentication %q", config.ClientAuth)
+	}
+
+	caCertPool, err := x509.SystemCertPool()
+	if err != nil {
+		pluginLog.Errorf("Failed to get SystemCertPool: %v", err)
+		return nil, err
+	}
+	if config.CACertFile != "" {
+		caCert, err := ioutil.ReadFile(config.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificates: %v", err)
+		}
+		caCertPool = x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse CA certificates from %s", config.CACertFile)
+		}
+	}
+	return &Plugin{
+		config: config,
+		httpClient: &http.Client{
+			Timeout: httpTimeOutInSec * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{
+					RootCAs: caCertPool,
+				},
+			},
+		},
+	}, nil
+}
+
+// ExchangeToken exchanges the subject token of the STS request with the Security Token Service, and returns
+// StsResponseParameters in JSON. Exchanged tokens are cached until they are about to expire.
+func (p *Plugin) ExchangeToken(parameters security.StsRequestParameters) ([]byte, error) {
+	form := p.requestForm(parameters)
+	key := cacheKey(form)
+	if v, ok := p.tokens.Load(key); ok {
+		token := v.(cachedToken)
+		if lifetime := token.info.ExpireTime.Sub(token.info.IssueTime); time.Until(token.info.ExpireTime) > gracePeriod(lifetime) {
+			return p.generateSTSResp(token)
+		}
+		p.tokens.Delete(key)
+	}
+
+	token, err := p.fetchToken(form)
+	if err != nil {
+		return nil, err
+	}
+	if !token.info.ExpireTime.IsZero() {
+		p.pruneExpired()
+		p.tokens.Store(key, token)
+	}
+	return p.generateSTSResp(token)
+}
+
+// pruneExpired removes the expired tokens, e.g. those exchanged for a subject token which has since been rotated.
+func (p *Plugin) pruneExpired() {
+	now := time.Now()
+	p.tokens.Range(func(k interface{}, v interface{}) bool {
+		if v.(cachedToken).info.ExpireTime.Before(now) {
+			p.tokens.Delete(k)
+		}
+		return true
+	})
+}
+
+func gracePeriod(lifetime time.Duration) time.Duration {
+	if lifetime/2 < defaultGracePeriod {
+		return lifetime / 2
+	}
+	return defaultGracePeriod
+}
+
+// requestForm returns the form of the token exchange request sent to the Security Token Service. The audience,
+// scope, resource and requested token type of the STS request take precedence over the configured ones.
+func (p *Plugin) requestForm(parameters security.StsRequestParameters) url.Values {
+	form := url.Values{}
+	form.Set("grant_type", TokenExchangeGrantType)
+	form.Set("subject_token", parameters.SubjectToken)
+	form.Set("subject_token_type", p.config.SubjectTokenType)
+	setFirst(form, "audience", parameters.Audience, p.config.Audience)
+	setFirst(form, "scope", parameters.Scope, p.config.Scope)
+	setFirst(form, "requested_token_type", parameters.RequestedTokenType, p.config.RequestedTokenType)
+	setFirst(form, "resource", parameters.Resource)
+	if parameters.ActorToken != "" {
+		form.Set("actor_token", parameters.ActorToken)
+		form.Set("actor_token_type", parameters.ActorTokenType)
+	}
+	return form
+}
+
+func setFirst(form url.Values, key string, values ...string) {
+	for _, v := range values {
+		if v != "" {
+			form.Set(key, v)
+			return
+		}
+	}
+}
+
+// cacheKey identifies the exchanged token by a digest of the request form, which includes the subject token, so
+// the token is exchanged again when the subject token is rotated.
+func cacheKey(form url.Values) string {
+	sum := sha256.Sum256([]byte(form.Encode()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (p *Plugin) newRequest(form url.Values) (*http.Request, error) {
+	body := url.Values{}
+	for k, v := range form {
+		body[k] = v
+	}
+	var clientSecret string
+	if p.config.ClientAuth != ClientAuthNone && p.config.ClientSecretFile != "" {
+		secret, err := ioutil.ReadFile(p.config.ClientSecretFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client secret: %v", err)
+		}
+		clientSecret = strings.TrimSpace(string(secret))
+	}
+	if p.config.ClientAuth == ClientAuthPost {
+		body.Set("client_id", p.config.ClientID)
+		body.Set("client_secret", clientSecret)
+	}
+	req, err := http.NewRequest(http.MethodPost, p.config.Endpoint, strings.NewReader(body.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token exchange request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if p.config.ClientAuth == ClientAuthBasic {
+		// https://tools.ietf.org/html/rfc6749#section-2.3.1: the client credentials are form encoded.
+		req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(clientSecret))
+	}
+	return req, nil
+}
+
+// fetchToken sends the token exchange request, retrying on server errors.
+func (p *Plugin) fetchToken(form url.Values) (cachedToken, error) {
+	start := time.Now()
+	var lastErr error
+	for i := 0; i < maxRequestRetry; i++ {
+		req, err := p.newRequest(form)
+		if err != nil {
+			return cachedToken{}, err
+		}
+		resp, err := p.httpClient.Do(req)
+		if err != nil {
+			pluginLog.Errorf("failed to send out token exchange request: %v", err)
+			lastErr = err
+			time.Sleep(10 * time.Millisecond)
+			continue
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return cachedToken{}, fmt.Errorf("failed to read token exchange response body: %v", err)
+		}
+		if resp.StatusCode == http.StatusOK {
+			pluginLog.Infof("Received token exchange response after %s", time.Since(start))
+			return parseResponse(body)
+		}
+		lastErr = fmt.Errorf("HTTP status %d: %s", resp.StatusCode, errorDescription(body))
+		if resp.StatusCode < http.StatusInternalServerError {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	pluginLog.Errorf("Failed to exchange token (total time elapsed %s): %v", time.Since(start), lastErr)
+	return cachedToken{}, fmt.Errorf("failed to exchange token: %v", lastErr)
+}
+
+func parseResponse(body []byte) (cachedToken, error) {
+	resp := stsservice.StsResponseParameters{}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return cachedToken{}, fmt.Errorf("failed to unmarshal token exchange response: %v", err)
+	}
+	if resp.AccessToken == "" {
+		return cachedToken{}, errors.New("token exchange response does not have access token")
+	}
+	now := time.Now()
+	token := cachedToken{
+		info: stsservice.TokenInfo{
+			TokenType: resp.IssuedTokenType,
+			IssueTime: now,
+			Token:     resp.AccessToken,
+		},
+		issuedTokenType: resp.IssuedTokenType,
+		tokenType:       resp.TokenType,
+		scope:           resp.Scope,
+	}
+	// Tokens without expiration are not cached.
+	if resp.ExpiresIn > 0 {
+		token.info.ExpireTime = now.Add(time.Duration(resp.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// errorDescription returns the error of an STS error response, or the raw body.
+func errorDescription(body []byte) string {
+	errResp := stsservice.StsErrorResponse{}
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error != "" {
+		if errResp.ErrorDescription != "" {
+			return errResp.Error + ": " + errResp.ErrorDescription
+		}
+		return errResp.Error
+	}
+	return string(body)
+}
+
+// generateSTSResp returns the StsResponseParameters of the exchanged token in JSON.
+func (p *Plugin) generateSTSResp(token cachedToken) ([]byte, error) {
+	tokenType := token.tokenType
+	if tokenType == "" {
+		tokenType = "Bearer"
+	}
+	stsRespParam := stsservice.StsResponseParameters{
+		AccessToken:     token.info.Token,
+		IssuedTokenType: token.issuedTokenType,
+		TokenType:       tokenType,
+		Scope:           token.scope,
+	}
+	if !token.info.ExpireTime.IsZero() {
+		stsRespParam.ExpiresIn = int64(time.Until(token.info.ExpireTime).Seconds())
+	}
+	return json.MarshalIndent(stsRespParam, "", " ")
+}
+
+// DumpPluginStatus dumps the status of all cached tokens in JSON, without the tokens.
+func (p *Plugin) DumpPluginStatus() ([]byte, error) {
+	tokenStatus := make([]stsservice.TokenInfo, 0)
+	p.tokens.Range(func(k interface{}, v interface{}) bool {
+		token := v.(cachedToken)
+		tokenStatus = append(tokenStatus, stsservice.TokenInfo{
+			TokenType: token.info.TokenType, IssueTime: token.info.IssueTime, ExpireTime: token.info.ExpireTime,
+		})
+		return true
+	})
+	td := stsservice.TokensDump{
+		Tokens: tokenStatus,
+	}
+	return json.MarshalIndent(td, "", " ")
+}
+
+// GetMetadata returns the metadata headers related to the token
+func (p *Plugin) GetMetadata(_ bool, _, token string) (map[string]string, error) {
+	if token == "" {
+		return nil, fmt.Errorf("empty token in plugin GetMetadata")
+	}
+	return map[string]string{
+		"authorization": "Bearer " + token,
+	}, nil
+}
@@ -0,0 +1,300 @@
+// Copyright Istio Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oauth
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"istio.io/istio/pkg/security"
+	"istio.io/istio/security/pkg/stsservice"
+)
+
+// fakeSTS is a fake Security Token Service, which issues a token derived from the subject token and scope.
+type fakeSTS struct {
+	mutex     sync.Mutex
+	requests  []url.Values
+	basicAuth []string
+	status    int
+	body      string
+	expiresIn int64
+}
+
+func (f *fakeSTS) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	_ = req.ParseForm()
+	f.requests = append(f.requests, req.PostForm)
+	if id, secret, ok := req.BasicAuth(); ok {
+		f.basicAuth = append(f.basicAuth, id+":"+secret)
+	}
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		_, _ = w.Write([]byte(f.body))
+		return
+	}
+	resp := stsservice.StsResponseParameters{
+		AccessToken:     "exchanged-" + req.PostForm.Get("subject_token") + "-" + req.PostForm.Get("scope"),
+		IssuedTokenType: AccessTokenType,
+		TokenType:       "Bearer",
+		ExpiresIn:       f.expiresIn,
+		Scope:           req.PostForm.Get("scope"),
+	}
+	b, _ := json.Marshal(resp)
+	_, _ = w.Write(b)
+}
+
+func (f *fakeSTS) numRequests() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.requests)
+}
+
+func (f *fakeSTS) lastRequest() url.Values {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.requests[len(f.requests)-1]
+}
+
+func exchange(t *testing.T, p *Plugin, params security.StsRequestParameters) stsservice.StsResponseParameters {
+	t.Helper()
+	b, err := p.ExchangeToken(params)
+	if err != nil {
+		t.Fatalf("ExchangeToken() returns err: %v", err)
+	}
+	resp := stsservice.StsResponseParameters{}
+	if err := json.Unmarshal(b, &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestExchangeToken(t *testing.T) {
+	sts := &fakeSTS{expiresIn: 3600}
+	server := httptest.NewServer(sts)
+	defer server.Close()
+
+	p, err := CreateTokenManagerPlugin(Config{
+		Endpoint: server.URL,
+		Audience: "backend",
+		Scope:    "default-scope",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := exchange(t, p, security.StsRequestParameters{SubjectToken: "k8s-token", SubjectTokenType: JWTTokenType})
+	if resp.AccessToken != "exchanged-k8s-token-default-scope" || resp.TokenType != "Bearer" || resp.ExpiresIn <= 0 {
+		t.Errorf("unexpected STS response %+v", resp)
+	}
+	req := sts.lastRequest()
+	for k, v := range map[string]string{
+		"grant_type":           TokenExchangeGrantType,
+		"subject_token":        "k8s-token",
+		"subject_token_type":   JWTTokenType,
+		"audience":             "backend",
+		"scope":                "default-scope",
+		"requested_token_type": AccessTokenType,
+	} {
+		if req.Get(k) != v {
+			t.Errorf("token exchange request has %s=%q, want %q", k, req.Get(k), v)
+		}
+	}
+
+	// The cached token is returned until it is about to expire.
+	exchange(t, p, security.StsRequestParameters{SubjectToken: "k8s-token", SubjectTokenType: JWTTokenType})
+	if sts.numRequests() != 1 {
+		t.Errorf("expected the cached token to be used, got %d requests", sts.numRequests())
+	}
+
+	// The scope and audience of the STS request take precedence, and are cached separately.
+	resp = exchange(t, p, security.StsRequestParameters{
+		SubjectToken: "k8s-token", SubjectTokenType: JWTTokenType, Scope: "telemetry", Audience: "telemetry-backend",
+	})
+	if resp.AccessToken != "exchanged-k8s-token-telemetry" || sts.lastRequest().Get("audience") != "telemetry-backend" {
+		t.Errorf("unexpected STS response %+v for request %v", resp, sts.lastRequest())
+	}
+
+	// A rotated subject token is exchanged again.
+	exchange(t, p, security.StsRequestParameters{SubjectToken: "rotated-token", SubjectTokenType: JWTTokenType})
+	if sts.numRequests() != 3 {
+		t.Errorf("expected the rotated subject token to be exchanged, got %d requests", sts.numRequests())
+	}
+
+	dump, err := p.DumpPluginStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	td := stsservice.TokensDump{}
+	if err := json.Unmarshal(dump, &td); err != nil {
+		t.Fatal(err)
+	}
+	if len(td.Tokens) != 3 {
+		t.Errorf("expected 3 cached tokens, got %+v", td.Tokens)
+	}
+	for _, token := range td.Tokens {
+		if token.Token != "" || token.ExpireTime.IsZero() {
+			t.Errorf("unexpected token status %+v", token)
+		}
+	}
+}
+
+func TestExchangeTokenShortLived(t *testing.T) {
+	sts := &fakeSTS{}
+	server := httptest.NewServer(sts)
+	defer server.Close()
+
+	p, err := CreateTokenManagerPlugin(Config{Endpoint: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Tokens without expiration are not cached.
+	for i := 0; i < 2; i++ {
+		exchange(t, p, security.StsRequestParameters{SubjectToken: "k8s-token"})
+	}
+	if sts.numRequests() != 2 {
+		t.Errorf("expected tokens without expiration to not be cached, got %d requests", sts.numRequests())
+	}
+}
+
+func TestClientAuth(t *testing.T) {
+	secretFile := filepath.Join(t.TempDir(), "secret")
+	if err := ioutil.WriteFile(secretFile, []byte("s3cr:t\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		clientAuth    string
+		expectedBasic string
+		expectedForm  map[string]string
+	}{
+		{
+			clientAuth:    "",
+			expectedBasic: "client:s3cr%3At",
+		},
+		{
+			clientAuth:   ClientAuthPost,
+			expectedForm: map[string]string{"client_id": "client", "client_secret": "s3cr:t"},
+		},
+		{
+			clientAuth:   ClientAuthNone,
+			expectedForm: map[string]string{"client_id": "", "client_secret": ""},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.clientAuth, func(t *testing.T) {
+			sts := &fakeSTS{}
+			server := httptest.NewServer(sts)
+			defer server.Close()
+			p, err := CreateTokenManagerPlugin(Config{
+				Endpoint:         server.URL,
+				ClientAuth:       tc.clientAuth,
+				ClientID:         "client",
+				ClientSecretFile: secretFile,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			exchange(t, p, security.StsRequestParameters{SubjectToken: "k8s-token"})
+			basic := strings.Join(sts.basicAuth, ",")
+			if basic != tc.expectedBasic {
+				t.Errorf("got basic auth %q, want %q", basic, tc.expectedBasic)
+			}
+			for k, v := range tc.expectedForm {
+				if got := sts.lastRequest().Get(k); got != v {
+					t.Errorf("got %s=%q, want %q", k, got, v)
+				}
+			}
+		})
+	}
+
+	if _, err := CreateTokenManagerPlugin(Config{Endpoint: "http://localhost", ClientAuth: "private_key_jwt"}); err == nil {
+		t.Errorf("expected an error for unsupported client authentication")
+	}
+	if _, err := CreateTokenManagerPlugin(Config{}); err == nil {
+		t.Errorf("expected an error for a missing endpoint")
+	}
+}
+
+func TestExchangeTokenErrors(t *testing.T) {
+	testCases := []struct {
+		name             string
+		status           int
+		body             string
+		expectedErr      string
+		expectedRequests int
+	}{
+		{
+			name:             "client error is not retried",
+			status:           http.StatusBadRequest,
+			body:             `{"error": "invalid_grant", "error_description": "subject token expired"}`,
+			expectedErr:      "failed to exchange token: HTTP status 400: invalid_grant: subject token expired",
+			expectedRequests: 1,
+		},
+		{
+			name:             "server error is retried",
+			status:           http.StatusServiceUnavailable,
+			body:             "unavailable",
+			expectedErr:      "failed to exchange token: HTTP status 503: unavailable",
+			expectedRequests: maxRequestRetry,
+		},
+		{
+			name:             "response without token",
+			status:           http.StatusOK,
+			body:             `{"token_type": "Bearer"}`,
+			expectedErr:      "token exchange response does not have access token",
+			expectedRequests: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sts := &fakeSTS{status: tc.status, body: tc.body}
+			server := httptest.NewServer(sts)
+			defer server.Close()
+			p, err := CreateTokenManagerPlugin(Config{Endpoint: server.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = p.ExchangeToken(security.StsRequestParameters{SubjectToken: "k8s-token"})
+			if err == nil || err.Error() != tc.expectedErr {
+				t.Errorf("got error %v, want %s", err, tc.expectedErr)
+			}
+			if sts.numRequests() != tc.expectedRequests {
+				t.Errorf("got %d requests, want %d", sts.numRequests(), tc.expectedRequests)
+			}
+		})
+	}
+}
+
+func TestGetMetadata(t *testing.T) {
+	p := &Plugin{}
+	md, err := p.GetMetadata(false, "", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(md) != fmt.Sprint(map[string]string{"authorization": "Bearer token"}) {
+		t.Errorf("unexpected metadata %v", md)
+	}
+	if _, err := p.GetMetadata(true, "", ""); err == nil {
+		t.Errorf("expected an error for an empty token")
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/http/httputil"
 	"net/url"
 	"strings"
@@ -31,6 +32,7 @@ import (
 	stsServer "istio.io/istio/security/pkg/stsservice/server"
 	"istio.io/istio/security/pkg/stsservice/tokenmanager/google"
 	"istio.io/istio/security/pkg/stsservice/tokenmanager/google/mock"
+	"istio.io/istio/security/pkg/stsservice/tokenmanager/oauth"
 )
 
 // Number of test client to create for testing.
@@ -63,6 +65,40 @@ func TestStsFlow(t *testing.T) {
 	}
 }
 
+// TestOAuthStsFlow sets up a STS server and token manager which has enabled the generic
+// OAuth token exchange plugin, and verifies that Envoy style STS requests are exchanged
+// at the configured security token service.
+func TestOAuthStsFlow(t *testing.T) {
+	var received url.Values
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_ = req.ParseForm()
+		received = req.PostForm
+		_, _ = w.Write([]byte(`{"access_token": "exchanged", "issued_token_type": ` +
+			`"urn:ietf:params:oauth:token-type:access_token", "token_type": "Bearer", "expires_in": 3600}`))
+	}))
+	defer backend.Close()
+
+	tokenManager := CreateTokenManager(OAuthTokenExchange, Config{OAuth: oauth.Config{
+		Endpoint: backend.URL,
+		Audience: "backend",
+	}})
+	server, err := stsServer.NewServer(stsServer.Config{LocalHostAddr: "127.0.0.1", LocalPort: 0}, tokenManager)
+	if err != nil {
+		t.Fatalf("failed to start STS server: %v", err)
+	}
+	defer server.Stop()
+	stsServerAddress = fmt.Sprintf("127.0.0.1:%d", server.Port)
+
+	resp, err := sendHTTPRequestWithRetry(&http.Client{}, genStsReq(t))
+	if err != nil {
+		t.Fatalf("failure in sending STS request: %v", err)
+	}
+	verifyStsResponse(t, resp)
+	if received.Get("subject_token") != mock.FakeSubjectToken || received.Get("audience") != "audience" {
+		t.Errorf("unexpected token exchange request %v", received)
+	}
+}
+
 // TestStsCache enables caching at token exchange plugin, which will return cached token if that token
 // is not going to expire soon.
 func TestStsCache(t *testing.T) {
@@ -20,11 +20,15 @@ import (
 	"istio.io/istio/pkg/bootstrap/platform"
 	"istio.io/istio/pkg/security"
 	"istio.io/istio/security/pkg/stsservice/tokenmanager/google"
+	"istio.io/istio/security/pkg/stsservice/tokenmanager/oauth"
+	"istio.io/pkg/log"
 )
 
 const (
 	// GoogleTokenExchange is the name of the google token exchange service.
 	GoogleTokenExchange = "GoogleTokenExchange"
+	// OAuthTokenExchange is the name of the OAuth 2.0 (RFC 8693) token exchange service.
+	OAuthTokenExchange = "OAuthTokenExchange"
 )
 
 // Plugin provides common interfaces for specific token exchange services.
@@ -42,6 +46,8 @@ type TokenManager struct {
 type Config struct {
 	CredFetcher security.CredFetcher
 	TrustDomain string
+	// OAuth configures the OAuthTokenExchange plugin.
+	OAuth oauth.Config
 }
 
 // GCPProjectInfo stores GCP project information, including project number,
@@ -91,6 +97,12 @@ func CreateTokenManager(tokenManagerType string, config Config) security.TokenMa
 				tm.plugin = p
 			}
 		}
+	case OAuthTokenExchange:
+		if p, err := oauth.CreateTokenManagerPlugin(config.OAuth); err == nil {
+			tm.plugin = p
+		} else {
+			log.Errorf("failed to create OAuth token exchange plugin: %v", err)
+		}
 	}
 	return tm
 }
@@ -35,6 +35,7 @@ func removeOldChains(cfg *config.Config, ext dep.Dependencies, cmd string) {
 		ext.RunQuietlyAndIgnore(cmd, "-t", table, "-D", constants.PREROUTING, "-p", constants.TCP, "-j", constants.ISTIOINBOUND)
 	}
 	ext.RunQuietlyAndIgnore(cmd, "-t", constants.NAT, "-D", constants.OUTPUT, "-p", constants.TCP, "-j", constants.ISTIOOUTPUT)
+	ext.RunQuietlyAndIgnore(cmd, "-t", constants.NAT, "-D", constants.OUTPUT, "-p", constants.UDP, "-j", constants.ISTIOOUTPUTUDP)
 
 	redirectDNS := cfg.RedirectDNS
 	// Remove the old DNS UDP rules
@@ -43,7 +44,7 @@ func removeOldChains(cfg *config.Config, ext dep.Dependencies, cmd string) {
 	}
 
 	// Flush and delete the istio chains from NAT table.
-	chains := []string{constants.ISTIOOUTPUT, constants.ISTIOINBOUND}
+	chains := []string{constants.ISTIOOUTPUT, constants.ISTIOINBOUND, constants.ISTIOOUTPUTUDP}
 	flushAndDeleteChains(ext, cmd, constants.NAT, chains)
 	// Flush and delete the istio chains from MANGLE table.
 	chains = []string{constants.ISTIOINBOUND, constants.ISTIODIVERT, constants.ISTIOTPROXY}
@@ -90,6 +90,7 @@ func constructConfig() *config.Config {
 		InboundPortsExclude:     viper.GetString(constants.LocalExcludePorts),
 		OutboundPortsInclude:    viper.GetString(constants.OutboundPorts),
 		OutboundPortsExclude:    viper.GetString(constants.LocalOutboundPortsExclude),
+		OutboundUDPPorts:        viper.GetString(constants.OutboundUDPPorts),
 		OutboundIPRangesInclude: viper.GetString(constants.ServiceCidr),
 		OutboundIPRangesExclude: viper.GetString(constants.ServiceExcludeCidr),
 		KubevirtInterfaces:      viper.GetString(constants.KubeVirtInterfaces),
@@ -232,6 +233,11 @@ func bindFlags(cmd *cobra.Command, args []string) {
 	}
 	viper.SetDefault(constants.LocalOutboundPortsExclude, "")
 
+	if err := viper.BindPFlag(constants.OutboundUDPPorts, cmd.Flags().Lookup(constants.OutboundUDPPorts)); err != nil {
+		handleError(err)
+	}
+	viper.SetDefault(constants.OutboundUDPPorts, "")
+
 	if err := viper.BindPFlag(constants.KubeVirtInterfaces, cmd.Flags().Lookup(constants.KubeVirtInterfaces)); err != nil {
 		handleError(err)
 	}
@@ -326,6 +332,9 @@ func init() {
 	rootCmd.Flags().StringP(constants.LocalOutboundPortsExclude, "o", "",
 		"Comma separated list of outbound ports to be excluded from redirection to Envoy")
 
+	rootCmd.Flags().String(constants.OutboundUDPPorts, "",
+		"Comma separated list of outbound UDP ports for which traffic is to be redirected to the Envoy UDP listener on the same port")
+
 	rootCmd.Flags().StringP(constants.KubeVirtInterfaces, "k", "",
 		"Comma separated list of virtual interfaces whose inbound traffic (from VM) will be treated as outbound")
 
@@ -123,6 +123,7 @@ func (iptConfigurator *IptablesConfigurator) logConfig() {
 	fmt.Printf("ISTIO_INBOUND_TPROXY_ROUTE_TABLE=%s\n", os.Getenv("ISTIO_INBOUND_TPROXY_ROUTE_TABLE"))
 	fmt.Printf("ISTIO_INBOUND_PORTS=%s\n", os.Getenv("ISTIO_INBOUND_PORTS"))
 	fmt.Printf("ISTIO_OUTBOUND_PORTS=%s\n", os.Getenv("ISTIO_OUTBOUND_PORTS"))
+	fmt.Printf("ISTIO_OUTBOUND_UDP_PORTS=%s\n", os.Getenv("ISTIO_OUTBOUND_UDP_PORTS"))
 	fmt.Printf("ISTIO_LOCAL_EXCLUDE_PORTS=%s\n", os.Getenv("ISTIO_LOCAL_EXCLUDE_PORTS"))
 	fmt.Printf("ISTIO_SERVICE_CIDR=%s\n", os.Getenv("ISTIO_SERVICE_CIDR"))
 	fmt.Printf("ISTIO_SERVICE_EXCLUDE_CIDR=%s\n", os.Getenv("ISTIO_SERVICE_EXCLUDE_CIDR"))
@@ -544,6 +545,8 @@ func (iptConfigurator *IptablesConfigurator) run() {
 			iptConfigurator.cfg.ProxyUID, iptConfigurator.cfg.ProxyGID, iptConfigurator.cfg.DNSServersV4)
 	}
 
+	iptConfigurator.handleOutboundUDPPorts()
+
 	if iptConfigurator.cfg.InboundInterceptionMode == constants.TPROXY {
 		// save packet mark set by envoy.filters.listener.original_src as connection mark
 		iptConfigurator.iptables.AppendRuleV4(constants.PREROUTING, constants.MANGLE,
@@ -627,6 +630,29 @@ func (iptConfigurator *IptablesConfigurator) handleOutboundPortsInclude() {
 	}
 }
 
+// handleOutboundUDPPorts redirects outbound UDP traffic on the given ports to the Envoy UDP listener bound to the
+// same port on localhost. Unlike TCP, the original destination of redirected UDP packets can not be recovered, so
+// Envoy has one listener per port rather than a single capture port.
+func (iptConfigurator *IptablesConfigurator) handleOutboundUDPPorts() {
+	if iptConfigurator.cfg.OutboundUDPPorts == "" {
+		return
+	}
+	iptConfigurator.iptables.AppendRuleV4(constants.OUTPUT, constants.NAT, "-p", constants.UDP, "-j", constants.ISTIOOUTPUTUDP)
+	// Avoid infinite loops. Don't redirect the upstream packets of Envoy back to Envoy.
+	for _, uid := range split(iptConfigurator.cfg.ProxyUID) {
+		iptConfigurator.iptables.AppendRuleV4(constants.ISTIOOUTPUTUDP, constants.NAT, "-m", "owner", "--uid-owner", uid, "-j", constants.RETURN)
+	}
+	for _, gid := range split(iptConfigurator.cfg.ProxyGID) {
+		iptConfigurator.iptables.AppendRuleV4(constants.ISTIOOUTPUTUDP, constants.NAT, "-m", "owner", "--gid-owner", gid, "-j", constants.RETURN)
+	}
+	// Skip redirection for container-to-container traffic, which explicitly uses localhost.
+	iptConfigurator.iptables.AppendRuleV4(constants.ISTIOOUTPUTUDP, constants.NAT, "-d", "127.0.0.1/32", "-j", constants.RETURN)
+	for _, port := range split(iptConfigurator.cfg.OutboundUDPPorts) {
+		iptConfigurator.iptables.AppendRuleV4(
+			constants.ISTIOOUTPUTUDP, constants.NAT, "-p", constants.UDP, "--dport", port, "-j", constants.REDIRECT, "--to-ports", port)
+	}
+}
+
 func (iptConfigurator *IptablesConfigurator) createRulesFile(f *os.File, contents string) error {
 	defer f.Close()
 	fmt.Println("Writing following contents to rules file: ", f.Name())
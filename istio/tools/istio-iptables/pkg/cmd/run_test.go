@@ -804,3 +804,36 @@ func TestRulesWithLoopbackIpInOutboundIpRanges(t *testing.T) {
 		t.Errorf("Output mismatch. Expected: \n%#v ; Actual: \n%#v", expected, actual)
 	}
 }
+
+func TestHandleOutboundUDPPorts(t *testing.T) {
+	cfg := constructTestConfig()
+	cfg.OutboundUDPPorts = "514,8125"
+
+	iptConfigurator := NewIptablesConfigurator(cfg, &dep.StdoutStubDependencies{})
+	iptConfigurator.handleOutboundUDPPorts()
+
+	ip4Rules := FormatIptablesCommands(iptConfigurator.iptables.BuildV4())
+	ip6Rules := FormatIptablesCommands(iptConfigurator.iptables.BuildV6())
+	if !reflect.DeepEqual([]string{}, ip6Rules) {
+		t.Errorf("Expected ip6Rules to be empty; instead got %#v", ip6Rules)
+	}
+	expectedIpv4Rules := []string{
+		"iptables -t nat -N ISTIO_OUTPUT_UDP",
+		"iptables -t nat -A OUTPUT -p udp -j ISTIO_OUTPUT_UDP",
+		"iptables -t nat -A ISTIO_OUTPUT_UDP -m owner --uid-owner 1337 -j RETURN",
+		"iptables -t nat -A ISTIO_OUTPUT_UDP -m owner --gid-owner 1337 -j RETURN",
+		"iptables -t nat -A ISTIO_OUTPUT_UDP -d 127.0.0.1/32 -j RETURN",
+		"iptables -t nat -A ISTIO_OUTPUT_UDP -p udp --dport 514 -j REDIRECT --to-ports 514",
+		"iptables -t nat -A ISTIO_OUTPUT_UDP -p udp --dport 8125 -j REDIRECT --to-ports 8125",
+	}
+	if !reflect.DeepEqual(ip4Rules, expectedIpv4Rules) {
+		t.Errorf("Output mismatch\nExpected: %#v\nActual: %#v", expectedIpv4Rules, ip4Rules)
+	}
+
+	cfg.OutboundUDPPorts = ""
+	iptConfigurator = NewIptablesConfigurator(cfg, &dep.StdoutStubDependencies{})
+	iptConfigurator.handleOutboundUDPPorts()
+	if ip4Rules := FormatIptablesCommands(iptConfigurator.iptables.BuildV4()); len(ip4Rules) != 0 {
+		t.Errorf("Expected no rules without UDP ports; instead got %#v", ip4Rules)
+	}
+}
@@ -37,6 +37,7 @@ type Config struct {
 	InboundPortsExclude     string        `json:"INBOUND_PORTS_EXCLUDE"`
 	OutboundPortsInclude    string        `json:"OUTBOUND_PORTS_INCLUDE"`
 	OutboundPortsExclude    string        `json:"OUTBOUND_PORTS_EXCLUDE"`
+	OutboundUDPPorts        string        `json:"OUTBOUND_UDP_PORTS"`
 	OutboundIPRangesInclude string        `json:"OUTBOUND_IPRANGES_INCLUDE"`
 	OutboundIPRangesExclude string        `json:"OUTBOUND_IPRANGES_EXCLUDE"`
 	KubevirtInterfaces      string        `json:"KUBEVIRT_INTERFACES"`
@@ -77,6 +78,7 @@ func (c *Config) Print() {
 	fmt.Printf("OUTBOUND_IP_RANGES_EXCLUDE=%s\n", c.OutboundIPRangesExclude)
 	fmt.Printf("OUTBOUND_PORTS_INCLUDE=%s\n", c.OutboundPortsInclude)
 	fmt.Printf("OUTBOUND_PORTS_EXCLUDE=%s\n", c.OutboundPortsExclude)
+	fmt.Printf("OUTBOUND_UDP_PORTS=%s\n", c.OutboundUDPPorts)
 	fmt.Printf("KUBEVIRT_INTERFACES=%s\n", c.KubevirtInterfaces)
 	fmt.Printf("ENABLE_INBOUND_IPV6=%t\n", c.EnableInboundIPv6)
 	fmt.Printf("DNS_CAPTURE=%t\n", c.RedirectDNS)
@@ -61,6 +61,7 @@ const (
 	ISTIOTPROXY     = "ISTIO_TPROXY"
 	ISTIOREDIRECT   = "ISTIO_REDIRECT"
 	ISTIOINREDIRECT = "ISTIO_IN_REDIRECT"
+	ISTIOOUTPUTUDP  = "ISTIO_OUTPUT_UDP"
 )
 
 // Constants used in cobra/viper CLI
@@ -74,6 +75,7 @@ const (
 	ServiceExcludeCidr        = "istio-service-exclude-cidr"
 	OutboundPorts             = "istio-outbound-ports"
 	LocalOutboundPortsExclude = "istio-local-outbound-ports-exclude"
+	OutboundUDPPorts          = "istio-outbound-udp-ports"
 	EnvoyPort                 = "envoy-port"
 	InboundCapturePort        = "inbound-capture-port"
 	InboundTunnelPort         = "inbound-tunnel-port"